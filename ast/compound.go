@@ -0,0 +1,34 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+const (
+	UNION     = "UNION"
+	INTERSECT = "INTERSECT"
+	EXCEPT    = "EXCEPT"
+)
+
+// a CompoundTerm combines the result of a SELECT with the
+// result of the statement it is attached to using a set operation
+type CompoundTerm struct {
+	Operator string           `json:"operator"`
+	All      bool             `json:"all"`
+	Select   *SelectStatement `json:"select"`
+}
+
+func NewCompoundTerm(operator string, all bool, sel *SelectStatement) *CompoundTerm {
+	return &CompoundTerm{
+		Operator: operator,
+		All:      all,
+		Select:   sel,
+	}
+}
+
+type CompoundTermList []*CompoundTerm
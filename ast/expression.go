@@ -85,5 +85,16 @@ type AggregateFunctionCallExpression interface {
 	FunctionCallExpression
 	UpdateAggregate(group *dparval.Value, item *dparval.Value) error
 	DefaultAggregate(group *dparval.Value) error
+	// merges the state of another part of the same group, which was
+	// aggregated separately, into the state of the group
+	MergeAggregate(group *dparval.Value, part *dparval.Value) error
 	Key() string
 }
+
+// functions like ROW_NUMBER() that only have a value
+// over the window of an OVER clause
+type WindowFunctionCallExpression interface {
+	FunctionCallExpression
+	// the value for the item at the position in the partition
+	EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error)
+}
@@ -63,6 +63,10 @@ func (this *ExpressionFormalNotationConverter) Visit(e Expression) (Expression,
 	case *Property:
 		// has no children anyway
 		return this.VisitProperty(expr)
+	case *Subquery:
+		// the subquery is verified with our aliases in scope
+		err = expr.VerifySemantics(this.aliases)
+		return e, err
 	default:
 		return VisitChildren(this, expr)
 	}
@@ -20,12 +20,16 @@ import (
 type ExpressionFunctionalDependencyChecker struct {
 	Dependencies        ExpressionList
 	AggregatesSatisfied bool
+	// parameters are the same for every document, but they have no
+	// value until the statement is run
+	ParametersSatisfied bool
 }
 
 func NewExpressionFunctionalDependencyChecker(deps ExpressionList) *ExpressionFunctionalDependencyChecker {
 	return &ExpressionFunctionalDependencyChecker{
 		Dependencies:        deps,
 		AggregatesSatisfied: true,
+		ParametersSatisfied: true,
 	}
 }
 
@@ -33,6 +37,7 @@ func NewExpressionFunctionalDependencyCheckerFull(deps ExpressionList) *Expressi
 	return &ExpressionFunctionalDependencyChecker{
 		Dependencies:        deps,
 		AggregatesSatisfied: false,
+		ParametersSatisfied: false,
 	}
 }
 
@@ -47,6 +52,11 @@ func (this *ExpressionFunctionalDependencyChecker) Visit(expr Expression) (Expre
 		return nil, nil
 	case *LiteralString:
 		return nil, nil
+	case *Parameter:
+		if this.ParametersSatisfied {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("The expression %v is not satisfied by these dependencies", expr)
 	case *LiteralArray:
 		//empty array is satisfied (non-empty handled later)
 		if len(expr.Val) == 0 {
@@ -65,6 +75,20 @@ func (this *ExpressionFunctionalDependencyChecker) Visit(expr Expression) (Expre
 			// this can't actually be represented by the dep list
 			return nil, fmt.Errorf("The expression %v is not satisfied by these dependencies", expr)
 		}
+	case *WindowOperator:
+		if !this.AggregatesSatisfied {
+			// like aggregates, windows depend on having been calculated
+			return nil, fmt.Errorf("The expression %v is not satisfied by these dependencies", expr)
+		}
+		// otherwise the window is satisfied by what it is computed from,
+		// which is nothing at all for ROW_NUMBER() OVER ()
+		for _, dep := range expr.Dependencies() {
+			_, err := dep.Accept(this)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
 	}
 
 	// next see if this expression is directly equivalent
@@ -107,6 +131,7 @@ func (this *ExpressionFunctionalDependencyChecker) getAppropriateCheckerForDeps(
 		}
 		newDeps[len(this.Dependencies)] = NewProperty(expr.GetAs())
 		colDepChecker := NewExpressionFunctionalDependencyChecker(newDeps)
+		colDepChecker.ParametersSatisfied = this.ParametersSatisfied
 		return colDepChecker
 	default:
 		return this
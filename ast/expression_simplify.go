@@ -24,6 +24,12 @@ func NewExpressionSimplifier() *ExpressionSimplifier {
 
 func (this *ExpressionSimplifier) Visit(e Expression) (Expression, error) {
 
+	// subqueries are never evaluated here, but their statements are simplified
+	subquery, ok := e.(*Subquery)
+	if ok {
+		return e, subquery.Select.Simplify()
+	}
+
 	// see if the expression depends on anything
 	dc := NewExpressionFunctionalDependencyCheckerFull(ExpressionList{})
 
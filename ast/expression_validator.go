@@ -16,6 +16,8 @@ import (
 type ExpressionValidator struct {
 	insideAggregate bool
 	allowAggregates bool
+	insideWindow    bool
+	allowWindows    bool
 }
 
 func NewExpressionValidator() *ExpressionValidator {
@@ -24,6 +26,14 @@ func NewExpressionValidator() *ExpressionValidator {
 	}
 }
 
+// window functions are only computed for the projection and ORDER BY
+func NewExpressionValidatorWithWindows() *ExpressionValidator {
+	return &ExpressionValidator{
+		allowAggregates: true,
+		allowWindows:    true,
+	}
+}
+
 func NewExpressionValidatorNoAggregates() *ExpressionValidator {
 	return &ExpressionValidator{
 		allowAggregates: false,
@@ -41,10 +51,39 @@ func (this *ExpressionValidator) Visit(e Expression) (Expression, error) {
 		if this.insideAggregate {
 			return e, fmt.Errorf("Cannot use aggregate function inside another aggregate function")
 		}
+		if this.allowWindows {
+			// aggregates are computed before the windows
+			wf := NewExpressionWindowFinder()
+			_, err := VisitChildren(wf, expr)
+			if err == nil && len(wf.GetWindows()) > 0 {
+				return e, fmt.Errorf("Cannot use window function inside an aggregate function")
+			}
+		}
 		this.insideAggregate = true
 		err := this.ValidateFunctionCall(expr)
 		this.insideAggregate = false
 		return e, err
+	case *WindowOperator:
+		if !this.allowWindows {
+			return e, fmt.Errorf("Window function not allowed here")
+		}
+		if this.insideWindow {
+			return e, fmt.Errorf("Cannot use window function inside another window function")
+		}
+		err := expr.Validate()
+		if err != nil {
+			return e, err
+		}
+		err = this.ValidateFunctionCall(expr.Function)
+		if err != nil {
+			return e, err
+		}
+		this.insideWindow = true
+		_, err = VisitChildren(this, e)
+		this.insideWindow = false
+		return e, err
+	case WindowFunctionCallExpression:
+		return e, fmt.Errorf("the %s() function requires an OVER clause", expr.GetName())
 	case FunctionCallExpression:
 		err := this.ValidateFunctionCall(expr)
 		if err != nil {
@@ -69,12 +108,6 @@ func (this *ExpressionValidator) ValidateFunctionCall(expr FunctionCallExpressio
 			return err
 		}
 	}
-	// an exception for COUNT and ARRAY_AGG
-	if expr.GetName() != "COUNT" && expr.GetName() != "ARRAY_AGG" {
-		err = expr.ValidateDistinct()
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	// only aggregates allow DISTINCT
+	return expr.ValidateDistinct()
 }
@@ -45,6 +45,29 @@ func VisitChildren(v ExpressionVisitor, e Expression) (Expression, error) {
 				return expr, err
 			}
 		}
+	// window, the function itself is computed over the
+	// window so only its arguments are visited
+	case *WindowOperator:
+		for _, arg := range expr.Function.GetOperands() {
+			if arg.Expr != nil {
+				arg.Expr, err = arg.Expr.Accept(v)
+				if err != nil {
+					return e, err
+				}
+			}
+		}
+		for i, partitionExpr := range expr.Window.PartitionBy {
+			expr.Window.PartitionBy[i], err = partitionExpr.Accept(v)
+			if err != nil {
+				return e, err
+			}
+		}
+		for _, orderExpr := range expr.Window.OrderBy {
+			orderExpr.Expr, err = orderExpr.Expr.Accept(v)
+			if err != nil {
+				return e, err
+			}
+		}
 	// handle all collection operators
 	case CollectionOperatorExpression:
 		newOver, err := expr.GetOver().Accept(v)
@@ -0,0 +1,39 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import ()
+
+// this ExpressionVisitor searches the expression
+// for any window functions embedded inside so
+// that the window operator can compute them
+type ExpressionWindowFinder struct {
+	windows ExpressionList
+}
+
+func NewExpressionWindowFinder() *ExpressionWindowFinder {
+	return &ExpressionWindowFinder{
+		windows: make(ExpressionList, 0),
+	}
+}
+
+func (this *ExpressionWindowFinder) GetWindows() ExpressionList {
+	return this.windows
+}
+
+func (this *ExpressionWindowFinder) Visit(e Expression) (Expression, error) {
+	switch e.(type) {
+	case *WindowOperator:
+		this.windows = append(this.windows, e)
+		return e, nil
+	default:
+		return VisitChildren(this, e)
+	}
+}
@@ -20,8 +20,17 @@ type From struct {
 	Oper       string //differentiate between NEST/UNNEST/JOIN
 	As         string
 	Keys       *KeyExpression // Used with Key-joins
+	On         Expression     // Used with ON joins
 	Type       string
-	Over       *From // used with document joins
+	Over       *From       // used with document joins
+	Indexes    []*IndexRef // USE INDEX hints
+}
+
+// an index named in a USE INDEX hint, Method
+// is empty when no USING was given
+type IndexRef struct {
+	Name   string
+	Method string
 }
 
 func (this *From) GetAliases() []string {
@@ -178,3 +187,43 @@ func (this *From) ConvertToBucketFrom() {
 		}
 	}
 }
+
+// EquiJoinKeys finds the conditions of an ON join that equate an
+// expression over the items joined so far with an expression over
+// the joined bucket alone.  the pairs of keys returned are equal for
+// every pair of items the join produces, so both sides can be hashed
+// on them.  leftAliases are the aliases in scope before this join
+func (this *From) EquiJoinKeys(leftAliases []string) (ExpressionList, ExpressionList) {
+	leftKeys := ExpressionList{}
+	rightKeys := ExpressionList{}
+	if this.On == nil {
+		return leftKeys, rightKeys
+	}
+
+	conditions := ExpressionList{this.On}
+	and, ok := this.On.(*AndOperator)
+	if ok {
+		conditions = and.Operands
+	}
+
+	for _, condition := range conditions {
+		equals, ok := condition.(*EqualToOperator)
+		if !ok {
+			continue
+		}
+		left, right := equals.Left, equals.Right
+		if !this.isJoinKey(right, leftAliases) {
+			left, right = right, left
+		}
+		if this.isJoinKey(right, leftAliases) && !expressionReferencesAliases(left, []string{this.As}) {
+			leftKeys = append(leftKeys, left)
+			rightKeys = append(rightKeys, right)
+		}
+	}
+	return leftKeys, rightKeys
+}
+
+// a key of the joined bucket refers to its alias and nothing else
+func (this *From) isJoinKey(expr Expression, leftAliases []string) bool {
+	return expressionReferencesAliases(expr, []string{this.As}) && !expressionReferencesAliases(expr, leftAliases)
+}
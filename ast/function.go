@@ -27,6 +27,9 @@ type FunctionCall struct {
 
 func NewFunctionCall(name string, operands FunctionArgExpressionList) FunctionCallExpression {
 	functionConstructor := SystemFunctionRegistry[strings.ToUpper(name)]
+	if functionConstructor == nil {
+		functionConstructor = lookupUserFunctionConstructor(name)
+	}
 	if functionConstructor != nil {
 		return functionConstructor(operands)
 	} else {
@@ -151,6 +154,26 @@ func (this *FunctionCall) ValidateArity() error {
 	return nil
 }
 
+// evaluates all the operands of the function, ok is false
+// when one of them is undefined
+func (this *FunctionCall) evaluateOperandValues(item *dparval.Value) ([]interface{}, bool, error) {
+	rv := make([]interface{}, len(this.Operands))
+	for i, operand := range this.Operands {
+		v, err := operand.Expr.Evaluate(item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				return nil, false, nil
+			default:
+				// any other error return to caller
+				return nil, false, err
+			}
+		}
+		rv[i] = v.Value()
+	}
+	return rv, true, nil
+}
+
 func (this *FunctionCall) EvaluateBoth(context *dparval.Value) (*dparval.Value, *dparval.Value, error) {
 	lv, err := this.Operands[0].Expr.Evaluate(context)
 	if err != nil {
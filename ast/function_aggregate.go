@@ -55,92 +55,70 @@ func (this *FunctionCallCount) Evaluate(item *dparval.Value) (*dparval.Value, er
 
 func (this *FunctionCallCount) DefaultAggregate(group *dparval.Value) error {
 	aggregate_key := this.Key()
-	if this.Distinct {
-		aggregate_unique_key := aggregate_key + "_unique"
-		uniqueness_map := dparval.NewValue(map[string]interface{}{})
-		setAggregateValue(group, aggregate_unique_key, uniqueness_map)
-	}
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue(0.0)
 		// store this, so that even if all values are eliminated we return 0
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		setAggregateValue(group, aggregate_key, dparval.NewValue(0.0))
 	}
 	return nil
 }
 
 func (this *FunctionCallCount) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
-	aggregate_key := this.Key()
-	currentVal, err := aggregateValue(group, aggregate_key)
-	if err != nil {
-		return fmt.Errorf("group defaults not set correctly")
+	if this.Operands[0].Star && this.Operands[0].Expr == nil {
+		// pure star
+		return this.accumulate(group, 1)
 	}
-	currentValVal := currentVal.Value()
-	currentFloat, ok := currentValVal.(float64)
-	if !ok {
-		return fmt.Errorf("count value not a number")
+	if this.Operands[0].Expr == nil {
+		return nil
 	}
 
-	if this.Operands[0].Star && this.Operands[0].Expr == nil {
-		// pure star
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentFloat+1))
-	} else if this.Operands[0].Star && this.Operands[0].Expr != nil {
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	if this.Operands[0].Star {
 		// dot star
-		val, err := this.Operands[0].Expr.Evaluate(item)
 		val, err = eliminateNonObject(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-
-			if this.Distinct {
-				uniqueness_map, err := aggregateValue(group, aggregate_key+"_unique")
-				if err != nil {
-					return fmt.Errorf("group uniqueness defaults not set correctly")
-				}
-				// check to see if we already have this value
-				valkey := string(val.Bytes())
-				exists, _ := uniqueness_map.Path(valkey)
-				if exists != nil {
-					return nil
-				} else {
-					uniqueness_map.SetPath(valkey, true)
-					setAggregateValue(group, aggregate_key+"_unique", uniqueness_map)
-					// and allow it to continue
-				}
-			}
-
-			setAggregateValue(group, aggregate_key, dparval.NewValue(currentFloat+1))
-		}
-	} else if !this.Operands[0].Star && this.Operands[0].Expr != nil {
+	} else {
 		// no star
-		val, err := this.Operands[0].Expr.Evaluate(item)
 		val, err = eliminateNullMissing(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-
-			if this.Distinct {
-				uniqueness_map, err := aggregateValue(group, aggregate_key+"_unique")
-				if err != nil {
-					return fmt.Errorf("group uniqueness defaults not set correctly")
-				}
-				// check to see if we already have this value
-				valkey := string(val.Bytes())
-				exists, _ := uniqueness_map.Path(valkey)
-				if exists != nil {
-					return nil
-				} else {
-					uniqueness_map.SetPath(valkey, true)
-					setAggregateValue(group, aggregate_key+"_unique", uniqueness_map)
-					// and allow it to continue
-				}
-			}
-
-			setAggregateValue(group, aggregate_key, dparval.NewValue(currentFloat+1))
-		}
 	}
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, 1)
+}
+
+func (this *FunctionCallCount) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, 1)
+		})
+	}
+	partVal, err := aggregateValue(part, this.Key())
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	partFloat, ok := partVal.Value().(float64)
+	if !ok {
+		return fmt.Errorf("count value not a number")
+	}
+	return this.accumulate(group, partFloat)
+}
+
+func (this *FunctionCallCount) accumulate(group *dparval.Value, count float64) error {
+	aggregate_key := this.Key()
+	currentVal, err := aggregateValue(group, aggregate_key)
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	currentFloat, ok := currentVal.Value().(float64)
+	if !ok {
+		return fmt.Errorf("count value not a number")
+	}
+	setAggregateValue(group, aggregate_key, dparval.NewValue(currentFloat+count))
 	return nil
 }
 
@@ -187,41 +165,66 @@ func (this *FunctionCallSum) Evaluate(item *dparval.Value) (*dparval.Value, erro
 
 func (this *FunctionCallSum) DefaultAggregate(group *dparval.Value) error {
 	aggregate_key := this.Key()
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue(nil)
 		// store this, so that even if all values are eliminated we return null
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nil))
 	}
 	return nil
 }
 
 func (this *FunctionCallSum) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNonNumber(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, val)
+}
+
+func (this *FunctionCallSum) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, val)
+		})
+	}
+	partVal, err := aggregateValue(part, this.Key())
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	if partVal.Type() != dparval.NUMBER {
+		// all values of the part were eliminated
+		return nil
+	}
+	return this.accumulate(group, partVal)
+}
+
+func (this *FunctionCallSum) accumulate(group *dparval.Value, val *dparval.Value) error {
 	aggregate_key := this.Key()
 	currentVal, err := aggregateValue(group, aggregate_key)
 	if err != nil {
 		return fmt.Errorf("group defaults not set correctly")
 	}
-
-	if this.Operands[0].Expr != nil {
-		val, err := this.Operands[0].Expr.Evaluate(item)
-		val, err = eliminateNonNumber(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-			nextVal := val.Value()
-			if currentVal.Type() == dparval.NUMBER {
-				currentValVal := currentVal.Value()
-				currentFloat, ok := currentValVal.(float64)
-				if !ok {
-					return fmt.Errorf("count value not a number")
-				}
-				nextVal = nextVal.(float64) + currentFloat
-			}
-			setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
+	nextVal, ok := val.Value().(float64)
+	if !ok {
+		return fmt.Errorf("sum value not a number")
+	}
+	if currentVal.Type() == dparval.NUMBER {
+		currentFloat, ok := currentVal.Value().(float64)
+		if !ok {
+			return fmt.Errorf("sum value not a number")
 		}
+		nextVal += currentFloat
 	}
+	setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
 	return nil
 }
 
@@ -271,82 +274,83 @@ func (this *FunctionCallAvg) DefaultAggregate(group *dparval.Value) error {
 	// avg needs to track sum and count to produce its value
 	count_key := aggregate_key + "_count"
 	sum_key := aggregate_key + "_sum"
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue(nil)
 		// store this, so that even if all values are eliminated we return null
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nil))
 	}
 
-	currentCount, err := aggregateValue(group, count_key)
+	_, err = aggregateValue(group, count_key)
 	if err != nil {
-		currentCount = dparval.NewValue(0.0)
-		setAggregateValue(group, count_key, dparval.NewValue(currentCount))
+		setAggregateValue(group, count_key, dparval.NewValue(0.0))
 	}
 
-	currentSum, err := aggregateValue(group, sum_key)
+	_, err = aggregateValue(group, sum_key)
 	if err != nil {
-		currentSum = dparval.NewValue(0.0)
-		setAggregateValue(group, sum_key, dparval.NewValue(currentSum))
+		setAggregateValue(group, sum_key, dparval.NewValue(0.0))
 	}
 
 	return nil
 }
 
 func (this *FunctionCallAvg) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNonNumber(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, 1, val.Value().(float64))
+}
+
+func (this *FunctionCallAvg) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, 1, val.Value().(float64))
+		})
+	}
 	aggregate_key := this.Key()
-	// avg needs to track sum and count to produce its value
-	count_key := aggregate_key + "_count"
-	sum_key := aggregate_key + "_sum"
-	_, err := aggregateValue(group, aggregate_key)
+	partCount, err := aggregateFloat(part, aggregate_key+"_count")
 	if err != nil {
-		return fmt.Errorf("group defaults not set correctly")
+		return err
 	}
-
-	currentCount, err := aggregateValue(group, count_key)
+	partSum, err := aggregateFloat(part, aggregate_key+"_sum")
 	if err != nil {
-		return fmt.Errorf("group defaults not set correctly")
+		return err
 	}
+	if partCount == 0 {
+		// all values of the part were eliminated
+		return nil
+	}
+	return this.accumulate(group, partCount, partSum)
+}
 
-	currentSum, err := aggregateValue(group, sum_key)
+func (this *FunctionCallAvg) accumulate(group *dparval.Value, count float64, sum float64) error {
+	aggregate_key := this.Key()
+	// avg needs to track sum and count to produce its value
+	count_key := aggregate_key + "_count"
+	sum_key := aggregate_key + "_sum"
+	currentCount, err := aggregateFloat(group, count_key)
 	if err != nil {
-		return fmt.Errorf("group defaults not set correctly")
+		return err
 	}
-
-	if this.Operands[0].Expr != nil {
-		val, err := this.Operands[0].Expr.Evaluate(item)
-		val, err = eliminateNonNumber(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-			nextVal := val.Value()
-			nextValFloat := nextVal.(float64)
-
-			if currentCount.Type() == dparval.NUMBER {
-				currentCountVal := currentCount.Value()
-				currentCountFloat, ok := currentCountVal.(float64)
-				if !ok {
-					return fmt.Errorf("count value not a number")
-				}
-				if currentSum.Type() == dparval.NUMBER {
-					currentSumVal := currentSum.Value()
-					currentSumFloat, ok := currentSumVal.(float64)
-					if !ok {
-						return fmt.Errorf("sum value not a number")
-					}
-					nextCountFloat := currentCountFloat + 1
-					nextSumFloat := currentSumFloat + nextValFloat
-					nextVal := nextSumFloat / nextCountFloat
-
-					setAggregateValue(group, count_key, dparval.NewValue(nextCountFloat))
-					setAggregateValue(group, sum_key, dparval.NewValue(nextSumFloat))
-					setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
-				}
-			}
-
-		}
+	currentSum, err := aggregateFloat(group, sum_key)
+	if err != nil {
+		return err
 	}
+
+	nextCount := currentCount + count
+	nextSum := currentSum + sum
+	setAggregateValue(group, count_key, dparval.NewValue(nextCount))
+	setAggregateValue(group, sum_key, dparval.NewValue(nextSum))
+	setAggregateValue(group, aggregate_key, dparval.NewValue(nextSum/nextCount))
 	return nil
 }
 
@@ -393,43 +397,66 @@ func (this *FunctionCallMin) Evaluate(item *dparval.Value) (*dparval.Value, erro
 
 func (this *FunctionCallMin) DefaultAggregate(group *dparval.Value) error {
 	aggregate_key := this.Key()
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue(nil)
 		// store this, so that even if all values are eliminated we return null
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nil))
 	}
 	return nil
 }
 
 func (this *FunctionCallMin) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNullMissing(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, val)
+}
+
+func (this *FunctionCallMin) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, val)
+		})
+	}
+	partVal, err := aggregateValue(part, this.Key())
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	if partVal.Type() == dparval.NULL {
+		// all values of the part were eliminated
+		return nil
+	}
+	return this.accumulate(group, partVal)
+}
+
+func (this *FunctionCallMin) accumulate(group *dparval.Value, val *dparval.Value) error {
 	aggregate_key := this.Key()
 	currentVal, err := aggregateValue(group, aggregate_key)
 	if err != nil {
 		return fmt.Errorf("group defaults not set correctly")
 	}
 
-	if this.Operands[0].Expr != nil {
-		val, err := this.Operands[0].Expr.Evaluate(item)
-		val, err = eliminateNullMissing(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-
-			nextVal := val.Value()
-			currVal := currentVal.Value()
-
-			if currVal == nil {
-				// any value is greater than nil (we eliminated null/mising already)
-				setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
-			} else {
-				// check to see
-				comp := CollateJSON(nextVal, currVal)
-				if comp < 0 {
-					setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
-				}
-			}
+	nextVal := val.Value()
+	currVal := currentVal.Value()
+
+	if currVal == nil {
+		// any value is greater than nil (we eliminated null/mising already)
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
+	} else {
+		// check to see
+		comp := CollateJSON(nextVal, currVal)
+		if comp < 0 {
+			setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
 		}
 	}
 	return nil
@@ -478,40 +505,62 @@ func (this *FunctionCallMax) Evaluate(item *dparval.Value) (*dparval.Value, erro
 
 func (this *FunctionCallMax) DefaultAggregate(group *dparval.Value) error {
 	aggregate_key := this.Key()
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue(nil)
 		// store this, so that even if all values are eliminated we return null
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nil))
 	}
 	return nil
 }
 
 func (this *FunctionCallMax) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNullMissing(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, val)
+}
+
+func (this *FunctionCallMax) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, val)
+		})
+	}
+	partVal, err := aggregateValue(part, this.Key())
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	if partVal.Type() == dparval.NULL {
+		// all values of the part were eliminated
+		return nil
+	}
+	return this.accumulate(group, partVal)
+}
+
+func (this *FunctionCallMax) accumulate(group *dparval.Value, val *dparval.Value) error {
 	aggregate_key := this.Key()
 	currentVal, err := aggregateValue(group, aggregate_key)
 	if err != nil {
 		return fmt.Errorf("group defaults not set correctly")
 	}
 
-	if this.Operands[0].Expr != nil {
-		val, err := this.Operands[0].Expr.Evaluate(item)
-		val, err = eliminateNullMissing(val, err)
-		if err != nil {
-			return err
-		}
-		if val != nil {
-
-			nextVal := val.Value()
-			currVal := currentVal.Value()
-
-			// check to see
-			comp := CollateJSON(nextVal, currVal)
-			if comp > 0 {
-				setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
-			}
+	nextVal := val.Value()
+	currVal := currentVal.Value()
 
-		}
+	// check to see
+	comp := CollateJSON(nextVal, currVal)
+	if comp > 0 {
+		setAggregateValue(group, aggregate_key, dparval.NewValue(nextVal))
 	}
 	return nil
 }
@@ -559,64 +608,65 @@ func (this *FunctionCallArrayAgg) Evaluate(item *dparval.Value) (*dparval.Value,
 
 func (this *FunctionCallArrayAgg) DefaultAggregate(group *dparval.Value) error {
 	aggregate_key := this.Key()
-	if this.Distinct {
-		aggregate_unique_key := aggregate_key + "_unique"
-		uniqueness_map := dparval.NewValue(map[string]interface{}{})
-		setAggregateValue(group, aggregate_unique_key, uniqueness_map)
-	}
-	currentVal, err := aggregateValue(group, aggregate_key)
+	this.defaultDistinct(group)
+	_, err := aggregateValue(group, aggregate_key)
 	if err != nil {
-		currentVal = dparval.NewValue([]interface{}{})
-		// store this, so that even if all values are eliminated we return null
-		setAggregateValue(group, aggregate_key, dparval.NewValue(currentVal))
+		// store this, so that even if all values are eliminated we return an empty array
+		setAggregateValue(group, aggregate_key, dparval.NewValue([]interface{}{}))
 	}
 	return nil
 }
 
 func (this *FunctionCallArrayAgg) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	if err != nil {
+		// only eliminate missing
+		return err
+	}
+	if val == nil {
+		return nil
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	return this.accumulate(group, []interface{}{val.Value()})
+}
+
+func (this *FunctionCallArrayAgg) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			return this.accumulate(group, []interface{}{val.Value()})
+		})
+	}
+	partVal, err := aggregateValue(part, this.Key())
+	if err != nil {
+		return fmt.Errorf("group defaults not set correctly")
+	}
+	partArray, ok := partVal.Value().([]interface{})
+	if !ok {
+		return fmt.Errorf("array_agg value not an array")
+	}
+	return this.accumulate(group, partArray)
+}
+
+func (this *FunctionCallArrayAgg) accumulate(group *dparval.Value, values []interface{}) error {
 	aggregate_key := this.Key()
 	currentVal, err := aggregateValue(group, aggregate_key)
 	if err != nil {
 		return fmt.Errorf("group defaults not set correctly")
 	}
 
-	if this.Operands[0].Expr != nil {
-		val, err := this.Operands[0].Expr.Evaluate(item)
-		if err != nil {
-			// only eliminate missing
-			return err
-		}
-		if val != nil {
-
-			nextVal := val.Value()
-
-			if this.Distinct {
-				uniqueness_map, err := aggregateValue(group, aggregate_key+"_unique")
-				if err != nil {
-					return fmt.Errorf("group uniqueness defaults not set correctly")
-				}
-				// check to see if we already have this value
-				valkey := string(val.Bytes())
-				exists, _ := uniqueness_map.Path(valkey)
-				if exists != nil {
-					return nil
-				} else {
-					uniqueness_map.SetPath(valkey, true)
-					setAggregateValue(group, aggregate_key+"_unique", uniqueness_map)
-					// and allow it to continue
-				}
-			}
-
-			currVal := currentVal.Value()
-
-			currValArray, ok := currVal.([]interface{})
-			if ok {
-				currValArray = append(currValArray, nextVal)
-			}
-			setAggregateValue(group, aggregate_key, dparval.NewValue(currValArray))
+	currVal := currentVal.Value()
 
-		}
+	currValArray, ok := currVal.([]interface{})
+	if ok {
+		currValArray = append(currValArray, values...)
 	}
+	setAggregateValue(group, aggregate_key, dparval.NewValue(currValArray))
 	return nil
 }
 
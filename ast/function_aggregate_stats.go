@@ -0,0 +1,437 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// the count, the mean and the sum of the squared differences from the
+// mean of the values (welford's algorithm), from which the variance
+// is computed.  the state of two parts of a group can be merged
+type varianceState struct {
+	count float64
+	mean  float64
+	m2    float64
+}
+
+func (this *varianceState) add(x float64) {
+	this.count++
+	delta := x - this.mean
+	this.mean += delta / this.count
+	this.m2 += delta * (x - this.mean)
+}
+
+func (this *varianceState) merge(that *varianceState) {
+	if that.count == 0 {
+		return
+	}
+	count := this.count + that.count
+	delta := that.mean - this.mean
+	this.mean += delta * that.count / count
+	this.m2 += that.m2 + delta*delta*this.count*that.count/count
+	this.count = count
+}
+
+// returns false if there are too few values
+func (this *varianceState) variance(population bool) (float64, bool) {
+	if population {
+		if this.count < 1 {
+			return 0, false
+		}
+		return this.m2 / this.count, true
+	}
+	if this.count < 2 {
+		return 0, false
+	}
+	return this.m2 / (this.count - 1), true
+}
+
+// VARIANCE() and STDDEV() only differ in the square root
+type varianceFunctionCall struct {
+	AggregateFunctionCall
+	// the variance of the values rather than of the population they are a sample of
+	population bool
+}
+
+func newVarianceFunctionCall(name string, operands FunctionArgExpressionList, population bool) varianceFunctionCall {
+	return varianceFunctionCall{
+		AggregateFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     name,
+				Operands: operands,
+				minArgs:  1,
+				maxArgs:  1,
+			},
+		},
+		population,
+	}
+}
+
+func (this *varianceFunctionCall) DefaultAggregate(group *dparval.Value) error {
+	this.defaultDistinct(group)
+	_, err := aggregateState(group, this.Key())
+	if err != nil {
+		setAggregateState(group, this.Key(), &varianceState{})
+	}
+	return nil
+}
+
+func (this *varianceFunctionCall) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNonNumber(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	state, err := this.state(group)
+	if err != nil {
+		return err
+	}
+	state.add(val.Value().(float64))
+	return nil
+}
+
+func (this *varianceFunctionCall) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	state, err := this.state(group)
+	if err != nil {
+		return err
+	}
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			state.add(val.Value().(float64))
+			return nil
+		})
+	}
+	partState, err := this.state(part)
+	if err != nil {
+		return err
+	}
+	state.merge(partState)
+	return nil
+}
+
+func (this *varianceFunctionCall) state(group *dparval.Value) (*varianceState, error) {
+	state, err := aggregateState(group, this.Key())
+	if err != nil {
+		return nil, fmt.Errorf("group defaults not set correctly")
+	}
+	rv, ok := state.(*varianceState)
+	if !ok {
+		return nil, fmt.Errorf("group defaults not set correctly")
+	}
+	return rv, nil
+}
+
+// NULL when there are too few values
+func (this *varianceFunctionCall) evaluate(item *dparval.Value, stddev bool) (*dparval.Value, error) {
+	state, err := this.state(item)
+	if err != nil {
+		return nil, err
+	}
+	variance, ok := state.variance(this.population)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	if stddev {
+		return dparval.NewValue(math.Sqrt(variance)), nil
+	}
+	return dparval.NewValue(variance), nil
+}
+
+type FunctionCallVariance struct {
+	varianceFunctionCall
+}
+
+func NewFunctionCallVariance(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallVariance{
+		newVarianceFunctionCall("VARIANCE", operands, false),
+	}
+}
+
+func NewFunctionCallVarianceSamp(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallVariance{
+		newVarianceFunctionCall("VARIANCE_SAMP", operands, false),
+	}
+}
+
+func NewFunctionCallVariancePop(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallVariance{
+		newVarianceFunctionCall("VARIANCE_POP", operands, true),
+	}
+}
+
+func (this *FunctionCallVariance) Copy() Expression {
+	return &FunctionCallVariance{
+		newVarianceFunctionCall(this.Name, this.Operands.Copy(), this.population),
+	}
+}
+
+func (this *FunctionCallVariance) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	return this.evaluate(item, false)
+}
+
+func (this *FunctionCallVariance) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallStddev struct {
+	varianceFunctionCall
+}
+
+func NewFunctionCallStddev(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallStddev{
+		newVarianceFunctionCall("STDDEV", operands, false),
+	}
+}
+
+func NewFunctionCallStddevSamp(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallStddev{
+		newVarianceFunctionCall("STDDEV_SAMP", operands, false),
+	}
+}
+
+func NewFunctionCallStddevPop(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallStddev{
+		newVarianceFunctionCall("STDDEV_POP", operands, true),
+	}
+}
+
+func (this *FunctionCallStddev) Copy() Expression {
+	return &FunctionCallStddev{
+		newVarianceFunctionCall(this.Name, this.Operands.Copy(), this.population),
+	}
+}
+
+func (this *FunctionCallStddev) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	return this.evaluate(item, true)
+}
+
+func (this *FunctionCallStddev) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the values of the group, sorted when a percentile is computed.
+// the values of two parts of a group are merged by appending them
+type percentileState struct {
+	values []float64
+	sorted bool
+}
+
+func (this *percentileState) add(values ...float64) {
+	this.values = append(this.values, values...)
+	this.sorted = false
+}
+
+func (this *percentileState) sort() {
+	if !this.sorted {
+		sort.Float64s(this.values)
+		this.sorted = true
+	}
+}
+
+// interpolates between the values around the fraction
+func (this *percentileState) continuous(fraction float64) float64 {
+	this.sort()
+	position := fraction * float64(len(this.values)-1)
+	lower := math.Floor(position)
+	upper := math.Ceil(position)
+	lowerValue := this.values[int(lower)]
+	return lowerValue + (position-lower)*(this.values[int(upper)]-lowerValue)
+}
+
+// the first value with at least the fraction of the values up to it
+func (this *percentileState) discrete(fraction float64) float64 {
+	this.sort()
+	position := int(math.Ceil(fraction*float64(len(this.values)))) - 1
+	if position < 0 {
+		position = 0
+	}
+	return this.values[position]
+}
+
+// MEDIAN() is PERCENTILE_CONT() of 0.5
+type percentileFunctionCall struct {
+	AggregateFunctionCall
+	// interpolate between values, rather than return one of them
+	continuous bool
+}
+
+func newPercentileFunctionCall(name string, operands FunctionArgExpressionList, args int, continuous bool) percentileFunctionCall {
+	return percentileFunctionCall{
+		AggregateFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     name,
+				Operands: operands,
+				minArgs:  args,
+				maxArgs:  args,
+			},
+		},
+		continuous,
+	}
+}
+
+func (this *percentileFunctionCall) DefaultAggregate(group *dparval.Value) error {
+	this.defaultDistinct(group)
+	_, err := aggregateState(group, this.Key())
+	if err != nil {
+		setAggregateState(group, this.Key(), &percentileState{})
+	}
+	return nil
+}
+
+func (this *percentileFunctionCall) UpdateAggregate(group *dparval.Value, item *dparval.Value) error {
+	if this.Operands[0].Expr == nil {
+		return nil
+	}
+	val, err := this.Operands[0].Expr.Evaluate(item)
+	val, err = eliminateNonNumber(val, err)
+	if err != nil || val == nil {
+		return err
+	}
+	seen, err := this.seenBefore(group, val)
+	if err != nil || seen {
+		return err
+	}
+	state, err := this.state(group)
+	if err != nil {
+		return err
+	}
+	state.add(val.Value().(float64))
+	return nil
+}
+
+func (this *percentileFunctionCall) MergeAggregate(group *dparval.Value, part *dparval.Value) error {
+	state, err := this.state(group)
+	if err != nil {
+		return err
+	}
+	if this.Distinct {
+		return this.mergeDistinct(group, part, func(val *dparval.Value) error {
+			state.add(val.Value().(float64))
+			return nil
+		})
+	}
+	partState, err := this.state(part)
+	if err != nil {
+		return err
+	}
+	state.add(partState.values...)
+	return nil
+}
+
+func (this *percentileFunctionCall) state(group *dparval.Value) (*percentileState, error) {
+	state, err := aggregateState(group, this.Key())
+	if err != nil {
+		return nil, fmt.Errorf("group defaults not set correctly")
+	}
+	rv, ok := state.(*percentileState)
+	if !ok {
+		return nil, fmt.Errorf("group defaults not set correctly")
+	}
+	return rv, nil
+}
+
+// NULL when there are no values, or the fraction is
+// not a number between 0 and 1
+func (this *percentileFunctionCall) evaluate(item *dparval.Value, fraction float64) (*dparval.Value, error) {
+	state, err := this.state(item)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.values) == 0 || fraction < 0 || fraction > 1 {
+		return dparval.NewValue(nil), nil
+	}
+	if this.continuous {
+		return dparval.NewValue(state.continuous(fraction)), nil
+	}
+	return dparval.NewValue(state.discrete(fraction)), nil
+}
+
+type FunctionCallMedian struct {
+	percentileFunctionCall
+}
+
+func NewFunctionCallMedian(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallMedian{
+		newPercentileFunctionCall("MEDIAN", operands, 1, true),
+	}
+}
+
+func (this *FunctionCallMedian) Copy() Expression {
+	return NewFunctionCallMedian(this.Operands.Copy())
+}
+
+func (this *FunctionCallMedian) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	return this.evaluate(item, 0.5)
+}
+
+func (this *FunctionCallMedian) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// PERCENTILE_CONT(expr, fraction) and PERCENTILE_DISC(expr, fraction),
+// the fraction is evaluated against the group
+type FunctionCallPercentile struct {
+	percentileFunctionCall
+}
+
+func NewFunctionCallPercentileCont(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallPercentile{
+		newPercentileFunctionCall("PERCENTILE_CONT", operands, 2, true),
+	}
+}
+
+func NewFunctionCallPercentileDisc(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallPercentile{
+		newPercentileFunctionCall("PERCENTILE_DISC", operands, 2, false),
+	}
+}
+
+func (this *FunctionCallPercentile) Copy() Expression {
+	return &FunctionCallPercentile{
+		newPercentileFunctionCall(this.Name, this.Operands.Copy(), 2, this.continuous),
+	}
+}
+
+func (this *FunctionCallPercentile) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	fv, err := this.Operands[1].Expr.Evaluate(item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			// undefined returns null
+			return dparval.NewValue(nil), nil
+		default:
+			// any other error return to caller
+			return nil, err
+		}
+	}
+	fraction, ok := fv.Value().(float64)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return this.evaluate(item, fraction)
+}
+
+func (this *FunctionCallPercentile) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
@@ -10,6 +10,7 @@
 package ast
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -34,8 +35,7 @@ func (this AggregateTestSet) Run(t *testing.T, dataset dparval.ValueCollection)
 		for _, d := range dataset {
 			af.UpdateAggregate(group, d)
 		}
-		groupMeta := group.GetAttachment("aggregates").(map[string]interface{})
-		final := groupMeta[af.Key()]
+		final, _ := af.Evaluate(group)
 
 		if !reflect.DeepEqual(final, x.result) {
 			t.Errorf("Expected %v, got %v, for %v", x.result, final, af)
@@ -181,3 +181,216 @@ func TestMinAndMax(t *testing.T) {
 	tests.Run(t, dataset)
 
 }
+
+func distinctFunctionCall(name string, operands FunctionArgExpressionList) FunctionCallExpression {
+	rv := NewFunctionCall(name, operands)
+	rv.SetDistinct(true)
+	return rv
+}
+
+var scoreDataset = dparval.ValueCollection{
+	dparval.NewValue(map[string]interface{}{
+		"name":  "marty",
+		"score": 2.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "gerald",
+		"score": 4.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "steve",
+		"score": 4.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "siri",
+		"score": "four",
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "deep",
+		"score": 4.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name": "ketaki",
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "pratap",
+		"score": 5.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "karen",
+		"score": 5.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "dustin",
+		"score": 7.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "aaron",
+		"score": 9.0,
+	}),
+}
+
+func TestDistinctAggregates(t *testing.T) {
+
+	score := FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("score"))}
+	tests := AggregateTestSet{
+		{
+			distinctFunctionCall("COUNT", score),
+			dparval.NewValue(6.0),
+		},
+		{
+			distinctFunctionCall("SUM", score),
+			dparval.NewValue(27.0),
+		},
+		{
+			distinctFunctionCall("AVG", score),
+			dparval.NewValue(5.4),
+		},
+		{
+			distinctFunctionCall("ARRAY_AGG", score),
+			dparval.NewValue([]interface{}{2.0, 4.0, "four", 5.0, 7.0, 9.0}),
+		},
+		{
+			distinctFunctionCall("MEDIAN", score),
+			dparval.NewValue(5.0),
+		},
+	}
+
+	tests.Run(t, scoreDataset)
+
+}
+
+func TestVarianceAndStddev(t *testing.T) {
+
+	score := FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("score"))}
+	tests := AggregateTestSet{
+		{
+			NewFunctionCall("VARIANCE_POP", score),
+			dparval.NewValue(4.0),
+		},
+		{
+			NewFunctionCall("STDDEV_POP", score),
+			dparval.NewValue(2.0),
+		},
+		{
+			NewFunctionCall("VARIANCE", score),
+			dparval.NewValue(32.0 / 7.0),
+		},
+		{
+			NewFunctionCall("VAR_SAMP", score),
+			dparval.NewValue(32.0 / 7.0),
+		},
+		{
+			NewFunctionCall("STDDEV", score),
+			dparval.NewValue(math.Sqrt(32.0 / 7.0)),
+		},
+		// too few values
+		{
+			NewFunctionCall("VARIANCE", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("name"))}),
+			dparval.NewValue(nil),
+		},
+	}
+
+	tests.Run(t, scoreDataset)
+
+}
+
+func TestMedianAndPercentiles(t *testing.T) {
+
+	score := NewFunctionArgExpression(NewProperty("score"))
+	tests := AggregateTestSet{
+		{
+			NewFunctionCall("MEDIAN", FunctionArgExpressionList{score}),
+			dparval.NewValue(4.5),
+		},
+		{
+			NewFunctionCall("PERCENTILE_CONT", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(0.5))}),
+			dparval.NewValue(4.5),
+		},
+		{
+			NewFunctionCall("PERCENTILE_CONT", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(0.9))}),
+			dparval.NewValue(7.6),
+		},
+		{
+			NewFunctionCall("PERCENTILE_DISC", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(0.5))}),
+			dparval.NewValue(4.0),
+		},
+		{
+			NewFunctionCall("PERCENTILE_DISC", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(0))}),
+			dparval.NewValue(2.0),
+		},
+		{
+			NewFunctionCall("PERCENTILE_DISC", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(1.5))}),
+			dparval.NewValue(nil),
+		},
+		{
+			NewFunctionCall("MEDIAN", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("name"))}),
+			dparval.NewValue(nil),
+		},
+	}
+
+	tests.Run(t, scoreDataset)
+
+}
+
+// aggregating two parts of the data and merging them
+// must be the same as aggregating all of it
+func TestMergeAggregates(t *testing.T) {
+
+	score := NewFunctionArgExpression(NewProperty("score"))
+	functions := []FunctionCallExpression{
+		NewFunctionCall("COUNT", FunctionArgExpressionList{NewStarFunctionArgExpression()}),
+		NewFunctionCall("COUNT", FunctionArgExpressionList{score}),
+		distinctFunctionCall("COUNT", FunctionArgExpressionList{score}),
+		NewFunctionCall("SUM", FunctionArgExpressionList{score}),
+		distinctFunctionCall("SUM", FunctionArgExpressionList{score}),
+		NewFunctionCall("AVG", FunctionArgExpressionList{score}),
+		distinctFunctionCall("AVG", FunctionArgExpressionList{score}),
+		NewFunctionCall("MIN", FunctionArgExpressionList{score}),
+		NewFunctionCall("MAX", FunctionArgExpressionList{score}),
+		NewFunctionCall("ARRAY_AGG", FunctionArgExpressionList{score}),
+		NewFunctionCall("VARIANCE", FunctionArgExpressionList{score}),
+		distinctFunctionCall("STDDEV_POP", FunctionArgExpressionList{score}),
+		NewFunctionCall("MEDIAN", FunctionArgExpressionList{score}),
+		distinctFunctionCall("PERCENTILE_DISC", FunctionArgExpressionList{score, NewFunctionArgExpression(NewLiteralNumber(0.75))}),
+	}
+
+	// the parts have values in common
+	for _, function := range functions {
+		af := function.(AggregateFunctionCallExpression)
+		all := aggregateDataset(af, scoreDataset)
+		group := aggregateDataset(af, scoreDataset[:6])
+		part := aggregateDataset(af, scoreDataset[4:])
+		group2 := aggregateDataset(af, scoreDataset[:4])
+		err := af.MergeAggregate(group2, part)
+		if err != nil {
+			t.Fatalf("Error merging %v: %v", af, err)
+		}
+		expected, _ := af.Evaluate(all)
+		actual, _ := af.Evaluate(group2)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, got %v, for %v", expected, actual, af)
+		}
+
+		// an empty part changes nothing
+		err = af.MergeAggregate(group, aggregateDataset(af, dparval.ValueCollection{}))
+		if err != nil {
+			t.Fatalf("Error merging %v: %v", af, err)
+		}
+		expected, _ = af.Evaluate(aggregateDataset(af, scoreDataset[:6]))
+		actual, _ = af.Evaluate(group)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %v, got %v, for %v", expected, actual, af)
+		}
+	}
+
+}
+
+func aggregateDataset(af AggregateFunctionCallExpression, dataset dparval.ValueCollection) *dparval.Value {
+	group := dparval.NewValue(map[string]interface{}{})
+	af.DefaultAggregate(group)
+	for _, d := range dataset {
+		af.UpdateAggregate(group, d)
+	}
+	return group
+}
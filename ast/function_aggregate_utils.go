@@ -11,6 +11,7 @@ package ast
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/couchbaselabs/dparval"
 )
@@ -22,7 +23,81 @@ type AggregateFunctionCall struct {
 // create a unique key where the current value for this
 // aggregate function will be stored
 func (this AggregateFunctionCall) Key() string {
-	return fmt.Sprintf("%s-%t-%v-%t", this.FunctionCall.Name, this.FunctionCall.Operands[0].Star, this.FunctionCall.Operands[0].Expr, this.FunctionCall.Distinct)
+	key := fmt.Sprintf("%s-%t-%v-%t", this.FunctionCall.Name, this.FunctionCall.Operands[0].Star, this.FunctionCall.Operands[0].Expr, this.FunctionCall.Distinct)
+	// like the fraction of PERCENTILE_CONT()
+	for _, operand := range this.FunctionCall.Operands[1:] {
+		key = fmt.Sprintf("%s-%v", key, operand.Expr)
+	}
+	return key
+}
+
+// all aggregate functions allow DISTINCT
+func (this *AggregateFunctionCall) ValidateDistinct() error {
+	return nil
+}
+
+// with DISTINCT, the values already seen in the group are kept in a map
+// by their json encoding.  the values themselves are kept, so that the
+// state of two parts of a group can be merged
+func (this AggregateFunctionCall) defaultDistinct(group *dparval.Value) {
+	if this.Distinct {
+		aggregate_unique_key := this.Key() + "_unique"
+		uniqueness_map := dparval.NewValue(map[string]interface{}{})
+		setAggregateValue(group, aggregate_unique_key, uniqueness_map)
+	}
+}
+
+// returns true with DISTINCT if the value was already seen in the group,
+// otherwise the value is remembered
+func (this AggregateFunctionCall) seenBefore(group *dparval.Value, val *dparval.Value) (bool, error) {
+	if !this.Distinct {
+		return false, nil
+	}
+	uniqueness_map, err := aggregateValue(group, this.Key()+"_unique")
+	if err != nil {
+		return false, fmt.Errorf("group uniqueness defaults not set correctly")
+	}
+	// check to see if we already have this value
+	valkey := string(val.Bytes())
+	exists, _ := uniqueness_map.Path(valkey)
+	if exists != nil {
+		return true, nil
+	}
+	uniqueness_map.SetPath(valkey, val.Value())
+	return false, nil
+}
+
+// with DISTINCT, the values seen in the part of the group but not
+// in the group are accumulated into the group
+func (this AggregateFunctionCall) mergeDistinct(group *dparval.Value, part *dparval.Value, accumulate func(val *dparval.Value) error) error {
+	uniqueness_map, err := aggregateValue(part, this.Key()+"_unique")
+	if err != nil {
+		return fmt.Errorf("group uniqueness defaults not set correctly")
+	}
+	values, ok := uniqueness_map.Value().(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("group uniqueness defaults not set correctly")
+	}
+	// in the same order every time
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := dparval.NewValue(values[key])
+		seen, err := this.seenBefore(group, val)
+		if err != nil {
+			return err
+		}
+		if !seen {
+			err = accumulate(val)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // lookup the current value of the aggregate stored
@@ -51,6 +126,44 @@ func setAggregateValue(item *dparval.Value, key string, val *dparval.Value) {
 	aggregatesMap[key] = val
 }
 
+// the number stored at the key, like the count of AVG()
+func aggregateFloat(item *dparval.Value, key string) (float64, error) {
+	val, err := aggregateValue(item, key)
+	if err != nil {
+		return 0, fmt.Errorf("group defaults not set correctly")
+	}
+	rv, ok := val.Value().(float64)
+	if !ok {
+		return 0, fmt.Errorf("aggregate value %s not a number", key)
+	}
+	return rv, nil
+}
+
+// aggregates with more state than a value, like the values of MEDIAN(),
+// keep it in the aggregates of the group as it is
+func aggregateState(item *dparval.Value, key string) (interface{}, error) {
+	aggregates := item.GetAttachment("aggregates")
+	aggregatesMap, ok := aggregates.(map[string]interface{})
+	if ok {
+		state, ok := aggregatesMap[key]
+		if ok {
+			return state, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find aggregate %s", key)
+}
+
+func setAggregateState(item *dparval.Value, key string, state interface{}) {
+	aggregates := item.GetAttachment("aggregates")
+	aggregatesMap, ok := aggregates.(map[string]interface{})
+	if !ok {
+		// create a new aggregates map
+		aggregatesMap = map[string]interface{}{}
+		item.SetAttachment("aggregates", aggregatesMap)
+	}
+	aggregatesMap[key] = state
+}
+
 func eliminateNullMissing(val *dparval.Value, err error) (*dparval.Value, error) {
 	if err != nil {
 		switch err := err.(type) {
@@ -9,6 +9,10 @@
 package ast
 
 import (
+	"fmt"
+	"math"
+	"sort"
+
 	"github.com/couchbaselabs/dparval"
 )
 
@@ -189,3 +193,570 @@ func (this *FunctionCallArrayRemove) Evaluate(context *dparval.Value) (*dparval.
 func (this *FunctionCallArrayRemove) Accept(ev ExpressionVisitor) (Expression, error) {
 	return ev.Visit(this)
 }
+
+// the key by which array elements are compared for ARRAY_DISTINCT()
+// and ARRAY_INTERSECT(), their json encoding like for DISTINCT aggregates
+func distinctKey(value interface{}) string {
+	return string(dparval.NewValue(value).Bytes())
+}
+
+// true if the array has an element equal to the value
+type FunctionCallArrayContains struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayContains(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayContains{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_CONTAINS",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallArrayContains) Copy() Expression {
+	return NewFunctionCallArrayContains(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayContains) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	for _, element := range array {
+		if CollateJSON(element, values[1]) == 0 {
+			return dparval.NewValue(true), nil
+		}
+	}
+	return dparval.NewValue(false), nil
+}
+
+func (this *FunctionCallArrayContains) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the position of the first element equal to the value, counting
+// from 0 like SUBSTR(), or -1 when there is none
+type FunctionCallArrayPosition struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayPosition(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayPosition{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_POSITION",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallArrayPosition) Copy() Expression {
+	return NewFunctionCallArrayPosition(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayPosition) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	for i, element := range array {
+		if CollateJSON(element, values[1]) == 0 {
+			return dparval.NewValue(float64(i)), nil
+		}
+	}
+	return dparval.NewValue(-1.0), nil
+}
+
+func (this *FunctionCallArrayPosition) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the elements of the array without duplicates, in the order
+// they first appear
+type FunctionCallArrayDistinct struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayDistinct(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayDistinct{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_DISTINCT",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallArrayDistinct) Copy() Expression {
+	return NewFunctionCallArrayDistinct(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayDistinct) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	seen := make(map[string]bool, len(array))
+	rv := make([]interface{}, 0, len(array))
+	for _, element := range array {
+		key := distinctKey(element)
+		if !seen[key] {
+			seen[key] = true
+			rv = append(rv, element)
+		}
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallArrayDistinct) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the elements found in all of the arrays, without duplicates,
+// in the order they appear in the first array
+type FunctionCallArrayIntersect struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayIntersect(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayIntersect{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_INTERSECT",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  -1,
+		},
+	}
+}
+
+func (this *FunctionCallArrayIntersect) Copy() Expression {
+	return NewFunctionCallArrayIntersect(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayIntersect) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	arrays := make([][]interface{}, len(values))
+	for i, value := range values {
+		arrays[i], ok = value.([]interface{})
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+	}
+
+	// the number of arrays each element was found in so far
+	found := make(map[string]int, len(arrays[0]))
+	for i, array := range arrays {
+		for _, element := range array {
+			key := distinctKey(element)
+			if found[key] == i {
+				found[key] = i + 1
+			}
+		}
+	}
+
+	rv := make([]interface{}, 0)
+	for _, element := range arrays[0] {
+		key := distinctKey(element)
+		if found[key] == len(arrays) {
+			rv = append(rv, element)
+			// only once
+			found[key] = 0
+		}
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallArrayIntersect) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the elements of the array in the order of ORDER BY
+type FunctionCallArraySort struct {
+	FunctionCall
+}
+
+func NewFunctionCallArraySort(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArraySort{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_SORT",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallArraySort) Copy() Expression {
+	return NewFunctionCallArraySort(this.Operands.Copy())
+}
+
+func (this *FunctionCallArraySort) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	// the array is already a copy
+	sort.Stable(collatedArray(array))
+	return dparval.NewValue(array), nil
+}
+
+func (this *FunctionCallArraySort) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type collatedArray []interface{}
+
+func (this collatedArray) Len() int           { return len(this) }
+func (this collatedArray) Less(i, j int) bool { return CollateJSON(this[i], this[j]) < 0 }
+func (this collatedArray) Swap(i, j int)      { this[i], this[j] = this[j], this[i] }
+
+// the elements of the array in reverse order
+type FunctionCallArrayReverse struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayReverse(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayReverse{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_REVERSE",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallArrayReverse) Copy() Expression {
+	return NewFunctionCallArrayReverse(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayReverse) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv := make([]interface{}, len(array))
+	for i, element := range array {
+		rv[len(array)-1-i] = element
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallArrayReverse) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the numeric array functions work like their aggregate functions
+// over the elements of the array
+type numericArrayFunctionCall struct {
+	FunctionCall
+	compute func(array []interface{}) interface{}
+}
+
+func newNumericArrayFunctionCall(name string, operands FunctionArgExpressionList, compute func(array []interface{}) interface{}) numericArrayFunctionCall {
+	return numericArrayFunctionCall{
+		FunctionCall{
+			Type:     "function",
+			Name:     name,
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+		compute,
+	}
+}
+
+func (this *numericArrayFunctionCall) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return dparval.NewValue(this.compute(array)), nil
+}
+
+// the sum of the numbers in the array, other elements are eliminated
+type FunctionCallArraySum struct {
+	numericArrayFunctionCall
+}
+
+func NewFunctionCallArraySum(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArraySum{
+		newNumericArrayFunctionCall("ARRAY_SUM", operands, arraySum),
+	}
+}
+
+func (this *FunctionCallArraySum) Copy() Expression {
+	return NewFunctionCallArraySum(this.Operands.Copy())
+}
+
+func (this *FunctionCallArraySum) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+func arraySum(array []interface{}) interface{} {
+	sum := 0.0
+	for _, element := range array {
+		number, ok := element.(float64)
+		if ok {
+			sum += number
+		}
+	}
+	return sum
+}
+
+// the average of the numbers in the array, NULL if there are none
+type FunctionCallArrayAvg struct {
+	numericArrayFunctionCall
+}
+
+func NewFunctionCallArrayAvg(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayAvg{
+		newNumericArrayFunctionCall("ARRAY_AVG", operands, arrayAvg),
+	}
+}
+
+func (this *FunctionCallArrayAvg) Copy() Expression {
+	return NewFunctionCallArrayAvg(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayAvg) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+func arrayAvg(array []interface{}) interface{} {
+	sum := 0.0
+	count := 0
+	for _, element := range array {
+		number, ok := element.(float64)
+		if ok {
+			sum += number
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return sum / float64(count)
+}
+
+// the smallest non-NULL element of the array in the order of ORDER BY,
+// NULL if there is none
+type FunctionCallArrayMin struct {
+	numericArrayFunctionCall
+}
+
+func NewFunctionCallArrayMin(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayMin{
+		newNumericArrayFunctionCall("ARRAY_MIN", operands, func(array []interface{}) interface{} {
+			return arrayExtreme(array, -1)
+		}),
+	}
+}
+
+func (this *FunctionCallArrayMin) Copy() Expression {
+	return NewFunctionCallArrayMin(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayMin) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the largest element of the array in the order of ORDER BY,
+// NULL if there is no non-NULL element
+type FunctionCallArrayMax struct {
+	numericArrayFunctionCall
+}
+
+func NewFunctionCallArrayMax(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayMax{
+		newNumericArrayFunctionCall("ARRAY_MAX", operands, func(array []interface{}) interface{} {
+			return arrayExtreme(array, 1)
+		}),
+	}
+}
+
+func (this *FunctionCallArrayMax) Copy() Expression {
+	return NewFunctionCallArrayMax(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayMax) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// like the MIN() and MAX() aggregates, NULL elements are eliminated
+// but elements of any other type are compared
+func arrayExtreme(array []interface{}, direction int) interface{} {
+	var rv interface{}
+	for _, element := range array {
+		if element == nil {
+			continue
+		}
+		if rv == nil || CollateJSON(element, rv)*direction > 0 {
+			rv = element
+		}
+	}
+	return rv
+}
+
+// ARRAY_RANGE() refuses to build arrays longer than this
+const ARRAY_RANGE_LIMIT = 1000000
+
+// the numbers from start up to but not including end, by step (1 if not
+// specified).  a negative step counts down
+type FunctionCallArrayRange struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayRange(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayRange{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_RANGE",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  3,
+		},
+	}
+}
+
+func (this *FunctionCallArrayRange) Copy() Expression {
+	return NewFunctionCallArrayRange(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayRange) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	numbers := []float64{0, 0, 1}
+	for i, value := range values {
+		numbers[i], ok = value.(float64)
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+	}
+	start, end, step := numbers[0], numbers[1], numbers[2]
+	if step == 0 || math.IsNaN(start) || math.IsNaN(end) || math.IsNaN(step) {
+		return dparval.NewValue(nil), nil
+	}
+
+	length := math.Ceil((end - start) / step)
+	if length <= 0 {
+		return dparval.NewValue([]interface{}{}), nil
+	}
+	if length > ARRAY_RANGE_LIMIT {
+		return nil, fmt.Errorf("the %s() function cannot return more than %d elements", this.Name, ARRAY_RANGE_LIMIT)
+	}
+
+	rv := make([]interface{}, int(length))
+	for i := range rv {
+		rv[i] = start + float64(i)*step
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallArrayRange) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the array with the elements of nested arrays in their place, up to
+// depth levels deep (1 if not specified).  a negative depth flattens
+// all the levels
+type FunctionCallArrayFlatten struct {
+	FunctionCall
+}
+
+func NewFunctionCallArrayFlatten(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallArrayFlatten{
+		FunctionCall{
+			Type:     "function",
+			Name:     "ARRAY_FLATTEN",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallArrayFlatten) Copy() Expression {
+	return NewFunctionCallArrayFlatten(this.Operands.Copy())
+}
+
+func (this *FunctionCallArrayFlatten) Evaluate(context *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(context)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	array, ok := values[0].([]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	depth := 1.0
+	if len(values) > 1 {
+		depth, ok = values[1].(float64)
+		if !ok || depth != math.Trunc(depth) {
+			return dparval.NewValue(nil), nil
+		}
+	}
+
+	return dparval.NewValue(flattenArray(make([]interface{}, 0, len(array)), array, int(depth))), nil
+}
+
+func (this *FunctionCallArrayFlatten) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+func flattenArray(rv []interface{}, array []interface{}, depth int) []interface{} {
+	for _, element := range array {
+		nested, ok := element.([]interface{})
+		if ok && depth != 0 {
+			rv = flattenArray(rv, nested, depth-1)
+		} else {
+			rv = append(rv, element)
+		}
+	}
+	return rv
+}
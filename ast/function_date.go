@@ -12,6 +12,8 @@ package ast
 import (
 	"fmt"
 	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/couchbaselabs/dparval"
@@ -45,7 +47,7 @@ func NewFunctionCallDatePartStr(operands FunctionArgExpressionList) FunctionCall
 			Name:     "DATE_PART_STR",
 			Operands: operands,
 			minArgs:  2,
-			maxArgs:  2,
+			maxArgs:  3,
 		},
 	}
 }
@@ -57,72 +59,38 @@ func (this *FunctionCallDatePartStr) Copy() Expression {
 			Name:     "DATE_PART_STR",
 			Operands: this.Operands.Copy(),
 			minArgs:  2,
-			maxArgs:  2,
+			maxArgs:  3,
 		},
 	}
 }
 
 func (this *FunctionCallDatePartStr) Evaluate(item *dparval.Value) (*dparval.Value, error) {
-	// first evaluate the argument (part)
-	dv, err := this.Operands[0].Expr.Evaluate(item)
-
-	// the part must be a string, undefined results in null
+	values, ok, err := this.evaluateOperandValues(item)
 	if err != nil {
-		switch err := err.(type) {
-		case *dparval.Undefined:
-			// undefined returns null
-			return dparval.NewValue(nil), nil
-		default:
-			// any other error return to caller
-			return nil, err
-		}
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
 	}
 
-	if dv.Type() == dparval.STRING {
-		date := dv.Value()
-		switch date := date.(type) {
-		case string:
-			// now look at the second argument
-			av, err := this.Operands[1].Expr.Evaluate(item)
-
-			// *currently* the date must be an rfc3339 string
-			// the part must be a string, undefined results in null
-			if err != nil {
-				switch err := err.(type) {
-				case *dparval.Undefined:
-					// undefined returns null
-					return dparval.NewValue(nil), nil
-				default:
-					// any other error return to caller
-					return nil, err
-				}
-			}
-
-			if av.Type() == dparval.STRING {
-				part := av.Value()
-				switch part := part.(type) {
-				case string:
-					var t time.Time
-					for _, sf := range supportedDateFormats {
-						t, err = time.Parse(sf, date)
-						if err == nil {
-							// found a matching format
-							break
-						}
-					}
-
-					if err != nil {
-						return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
-					}
-
-					return datePart(t, part)
-				}
-			}
+	// *currently* the date must be an rfc3339 string
+	date, dateOk := values[0].(string)
+	part, partOk := values[1].(string)
+	loc, locOk, err := timeZoneOperand(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !dateOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
 
-		}
+	t, _, err := strToTime(date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
 	}
 
-	return dparval.NewValue(nil), nil
+	return datePart(t, part)
 }
 
 func (this *FunctionCallDatePartStr) Accept(ev ExpressionVisitor) (Expression, error) {
@@ -140,7 +108,7 @@ func NewFunctionCallDatePartMillis(operands FunctionArgExpressionList) FunctionC
 			Name:     "DATE_PART_MILLIS",
 			Operands: operands,
 			minArgs:  2,
-			maxArgs:  2,
+			maxArgs:  3,
 		},
 	}
 }
@@ -152,59 +120,32 @@ func (this *FunctionCallDatePartMillis) Copy() Expression {
 			Name:     "DATE_PART_MILLIS",
 			Operands: this.Operands.Copy(),
 			minArgs:  2,
-			maxArgs:  2,
+			maxArgs:  3,
 		},
 	}
 }
 
 func (this *FunctionCallDatePartMillis) Evaluate(item *dparval.Value) (*dparval.Value, error) {
-	// first evaluate the argument (part)
-	dv, err := this.Operands[0].Expr.Evaluate(item)
-
-	// the part must be a string, undefined results in ull
+	values, ok, err := this.evaluateOperandValues(item)
 	if err != nil {
-		switch err := err.(type) {
-		case *dparval.Undefined:
-			// undefined returns null
-			return dparval.NewValue(nil), nil
-		default:
-			// any other error return to caller
-			return nil, err
-		}
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
 	}
 
-	if dv.Type() == dparval.NUMBER {
-		millis := dv.Value()
-		switch millis := millis.(type) {
-		case float64:
-			// now look at the second argument
-			av, err := this.Operands[1].Expr.Evaluate(item)
-
-			// the part must be a string, undefined results in null
-			if err != nil {
-				switch err := err.(type) {
-				case *dparval.Undefined:
-					// undefined returns null
-					return dparval.NewValue(nil), nil
-				default:
-					// any other error return to caller
-					return nil, err
-				}
-			}
-
-			if av.Type() == dparval.STRING {
-				part := av.Value()
-				switch part := part.(type) {
-				case string:
-					t := time.Unix(0, int64(millis)*1000000)
-					return datePart(t, part)
-				}
-			}
-
-		}
+	millis, millisOk := values[0].(float64)
+	part, partOk := values[1].(string)
+	loc, locOk, err := timeZoneOperand(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !millisOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
 	}
 
-	return dparval.NewValue(nil), nil
+	return datePart(millisToTime(millis, loc), part)
 }
 
 func (this *FunctionCallDatePartMillis) Accept(ev ExpressionVisitor) (Expression, error) {
@@ -213,7 +154,7 @@ func (this *FunctionCallDatePartMillis) Accept(ev ExpressionVisitor) (Expression
 
 func datePart(t time.Time, part string) (*dparval.Value, error) {
 	// now look for the requested part
-	switch part {
+	switch strings.ToLower(part) {
 	case "century":
 		cen := float64(t.Year() / 100.0)
 		if cen > 0 {
@@ -238,6 +179,9 @@ func datePart(t time.Time, part string) (*dparval.Value, error) {
 			isodow = 7.0
 		}
 		return dparval.NewValue(isodow), nil
+	case "isoweek":
+		_, w := t.ISOWeek()
+		return dparval.NewValue(float64(w)), nil
 	case "isoyear":
 		y, _ := t.ISOWeek()
 		return dparval.NewValue(float64(y)), nil
@@ -385,7 +329,7 @@ func NewFunctionCallStrToMillis(operands FunctionArgExpressionList) FunctionCall
 			Name:     "STR_TO_MILLIS",
 			Operands: operands,
 			minArgs:  1,
-			maxArgs:  1,
+			maxArgs:  2,
 		},
 	}
 }
@@ -397,37 +341,36 @@ func (this *FunctionCallStrToMillis) Copy() Expression {
 			Name:     "STR_TO_MILLIS",
 			Operands: this.Operands.Copy(),
 			minArgs:  1,
-			maxArgs:  1,
+			maxArgs:  2,
 		},
 	}
 }
 
 func (this *FunctionCallStrToMillis) Evaluate(item *dparval.Value) (*dparval.Value, error) {
-	av, err := this.Operands[0].Expr.Evaluate(item)
-
+	values, ok, err := this.evaluateOperandValues(item)
 	if err != nil {
-		switch err := err.(type) {
-		case *dparval.Undefined:
-			// undefined returns null
-			return dparval.NewValue(nil), nil
-		default:
-			// any other error return to caller
-			return nil, err
-		}
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
 	}
 
-	val := av.Value()
-
-	switch val := val.(type) {
-	case string:
-		t, err := strToTime(val)
-		if err != nil {
-			return nil, fmt.Errorf("Date not in a recognized format.")
-		}
-		return dparval.NewValue(timeToMillis(t)), nil
-	default:
+	// a date without a zone offset is in the time zone, if one is given
+	date, dateOk := values[0].(string)
+	loc, locOk, err := timeZoneOperand(values, 1)
+	if err != nil {
+		return nil, err
+	}
+	if !dateOk || !locOk {
 		return dparval.NewValue(nil), nil
 	}
+
+	t, _, err := strToTime(date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format.")
+	}
+	return dparval.NewValue(timeToMillis(t)), nil
 }
 
 func (this *FunctionCallStrToMillis) Accept(ev ExpressionVisitor) (Expression, error) {
@@ -445,7 +388,7 @@ func NewFunctionCallMillisToStr(operands FunctionArgExpressionList) FunctionCall
 			Name:     "MILLIS_TO_STR",
 			Operands: operands,
 			minArgs:  1,
-			maxArgs:  1,
+			maxArgs:  3,
 		},
 	}
 }
@@ -457,61 +400,130 @@ func (this *FunctionCallMillisToStr) Copy() Expression {
 			Name:     "MILLIS_TO_STR",
 			Operands: this.Operands.Copy(),
 			minArgs:  1,
-			maxArgs:  1,
+			maxArgs:  3,
 		},
 	}
 }
 
 func (this *FunctionCallMillisToStr) Evaluate(item *dparval.Value) (*dparval.Value, error) {
-	av, err := this.Operands[0].Expr.Evaluate(item)
-
+	values, ok, err := this.evaluateOperandValues(item)
 	if err != nil {
-		switch err := err.(type) {
-		case *dparval.Undefined:
-			// undefined returns null
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	millis, millisOk := values[0].(float64)
+	layout := supportedDateFormats[0]
+	if len(values) > 1 {
+		format, ok := values[1].(string)
+		if !ok {
 			return dparval.NewValue(nil), nil
-		default:
-			// any other error return to caller
-			return nil, err
 		}
+		layout = formatToLayout(format)
 	}
-
-	val := av.Value()
-
-	switch val := val.(type) {
-	case float64:
-		t := millisToTime(val)
-		return dparval.NewValue(timeToStr(t)), nil
-	default:
+	loc, locOk, err := timeZoneOperand(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !millisOk || !locOk {
 		return dparval.NewValue(nil), nil
 	}
+
+	return dparval.NewValue(millisToTime(millis, loc).Format(layout)), nil
 }
 
 func (this *FunctionCallMillisToStr) Accept(ev ExpressionVisitor) (Expression, error) {
 	return ev.Visit(this)
 }
 
-func strToTime(s string) (time.Time, error) {
+// the time zone named by the optional operand at position, nil if
+// there is no such operand.  ok is false when the name is not a string
+func timeZoneOperand(values []interface{}, position int) (*time.Location, bool, error) {
+	if position >= len(values) {
+		return nil, true, nil
+	}
+	name, ok := values[position].(string)
+	if !ok {
+		return nil, false, nil
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		return nil, false, err
+	}
+	return loc, true, nil
+}
+
+// loading a time zone reads the zoneinfo database, so keep them around
+var timeZones = make(map[string]*time.Location)
+var timeZonesMutex sync.RWMutex
+
+func loadLocation(name string) (*time.Location, error) {
+	timeZonesMutex.RLock()
+	loc, ok := timeZones[name]
+	timeZonesMutex.RUnlock()
+	if ok {
+		return loc, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown time zone %s", name)
+	}
+	timeZonesMutex.Lock()
+	timeZones[name] = loc
+	timeZonesMutex.Unlock()
+	return loc, nil
+}
+
+// a format is either an example date in one of the supported formats,
+// like "1111-11-11", or a go layout of the reference time
+func formatToLayout(format string) string {
+	_, layout, err := strToTime(format, nil)
+	if err != nil {
+		return format
+	}
+	return layout
+}
+
+// returns the time and the layout it was parsed with.  if loc is not
+// nil, a date without a zone offset is in loc and the time is moved
+// to loc, otherwise the time keeps the offset of the date
+func strToTime(s string, loc *time.Location) (time.Time, string, error) {
 	var t time.Time
 	var err error
 
 	for _, sf := range supportedDateFormats {
-		t, err = time.Parse(sf, s)
+		if loc == nil {
+			t, err = time.Parse(sf, s)
+		} else {
+			t, err = time.ParseInLocation(sf, s, loc)
+		}
 		if err == nil {
 			// found a matching format
-			return t, nil
+			if loc != nil {
+				t = t.In(loc)
+			}
+			return t, sf, nil
 		}
 	}
 
-	return t, err
+	return t, "", err
 }
 
 func timeToStr(t time.Time) string {
 	return t.Format(supportedDateFormats[0])
 }
 
-func millisToTime(millis float64) time.Time {
-	return time.Unix(0, int64(millis)*1000000)
+// the time in loc, nil is the local time zone
+func millisToTime(millis float64, loc *time.Location) time.Time {
+	t := time.Unix(0, int64(millis)*1000000)
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t
 }
 
 func timeToMillis(t time.Time) float64 {
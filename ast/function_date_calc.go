@@ -0,0 +1,499 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// the parts understood by DATE_ADD, DATE_DIFF and DATE_TRUNC, mapped
+// to the number of months in them for the parts of variable length
+var calendarDateParts = map[string]int{
+	"millennium": 12000,
+	"century":    1200,
+	"decade":     120,
+	"year":       12,
+	"quarter":    3,
+	"month":      1,
+}
+
+// and to the number of milliseconds in them for the others
+var clockDateParts = map[string]int64{
+	"hour":        60 * 60 * 1000,
+	"minute":      60 * 1000,
+	"second":      1000,
+	"millisecond": 1,
+}
+
+// days and weeks are not always the same length, because of
+// daylight saving time
+var dayDateParts = map[string]int{
+	"week": 7,
+	"day":  1,
+}
+
+func dateAdd(t time.Time, n int64, part string) (time.Time, error) {
+	part = strings.ToLower(part)
+	months, ok := calendarDateParts[part]
+	if ok {
+		// the day is kept within the month, january 31 plus
+		// a month is february 28
+		y, m, d := t.Date()
+		first := time.Date(y, m+time.Month(int(n)*months), 1, 0, 0, 0, 0, t.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		if d > last {
+			d = last
+		}
+		return time.Date(first.Year(), first.Month(), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), nil
+	}
+	days, ok := dayDateParts[part]
+	if ok {
+		return t.AddDate(0, 0, int(n)*days), nil
+	}
+	millis, ok := clockDateParts[part]
+	if ok {
+		// long durations overflow time.Duration
+		n *= millis
+		return time.Unix(t.Unix()+n/1000, int64(t.Nanosecond())+(n%1000)*1000000).In(t.Location()), nil
+	}
+	return t, fmt.Errorf("Unknown date part %s", part)
+}
+
+// the number of whole parts from t2 to t1, negative when t1 is before t2
+func dateDiff(t1, t2 time.Time, part string) (int64, error) {
+	if t1.Before(t2) {
+		rv, err := dateDiff(t2, t1, part)
+		return -rv, err
+	}
+
+	part = strings.ToLower(part)
+	millis, ok := clockDateParts[part]
+	if ok {
+		seconds := t1.Unix() - t2.Unix()
+		nanos := int64(t1.Nanosecond() - t2.Nanosecond())
+		if nanos < 0 {
+			seconds--
+			nanos += 1000000000
+		}
+		return (seconds*1000 + nanos/1000000) / millis, nil
+	}
+
+	// guess from the calendar, then step to the last whole part before t1
+	var n int64
+	months, ok := calendarDateParts[part]
+	if ok {
+		y1, m1, _ := t1.Date()
+		y2, m2, _ := t2.Date()
+		n = int64(((y1-y2)*12 + int(m1-m2)) / months)
+	} else {
+		days, ok := dayDateParts[part]
+		if !ok {
+			return 0, fmt.Errorf("Unknown date part %s", part)
+		}
+		n = (t1.Unix() - t2.Unix()) / int64(days*24*60*60)
+	}
+	for n > 0 {
+		t, _ := dateAdd(t2, n, part)
+		if !t.After(t1) {
+			break
+		}
+		n--
+	}
+	for {
+		t, _ := dateAdd(t2, n+1, part)
+		if t.After(t1) {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// the start of the part the time is in, weeks start on monday
+func dateTrunc(t time.Time, part string) (time.Time, error) {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch strings.ToLower(part) {
+	case "millennium":
+		// there is no year 0, the third millennium started in 2001
+		return time.Date((y-1)/1000*1000+1, 1, 1, 0, 0, 0, 0, loc), nil
+	case "century":
+		return time.Date((y-1)/100*100+1, 1, 1, 0, 0, 0, 0, loc), nil
+	case "decade":
+		return time.Date(y/10*10, 1, 1, 0, 0, 0, 0, loc), nil
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc), nil
+	case "quarter":
+		return time.Date(y, (m-1)/3*3+1, 1, 0, 0, 0, 0, loc), nil
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc), nil
+	case "week":
+		isodow := int(t.Weekday())
+		if isodow == 0 {
+			isodow = 7
+		}
+		return time.Date(y, m, d-isodow+1, 0, 0, 0, 0, loc), nil
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), nil
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc), nil
+	case "minute":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc), nil
+	case "second":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+	case "millisecond":
+		ns := t.Nanosecond() / 1000000 * 1000000
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), ns, loc), nil
+	}
+	return t, fmt.Errorf("Unknown date part %s", part)
+}
+
+// the number of parts to add must be an integer
+func dateAddCount(value interface{}) (int64, bool) {
+	n, ok := value.(float64)
+	if !ok || n != math.Trunc(n) {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+type FunctionCallDateAddStr struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateAddStr(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateAddStr{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_ADD_STR",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  4,
+		},
+	}
+}
+
+func (this *FunctionCallDateAddStr) Copy() Expression {
+	return NewFunctionCallDateAddStr(this.Operands.Copy())
+}
+
+// the result has the format of the date
+func (this *FunctionCallDateAddStr) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	date, dateOk := values[0].(string)
+	n, nOk := dateAddCount(values[1])
+	part, partOk := values[2].(string)
+	loc, locOk, err := timeZoneOperand(values, 3)
+	if err != nil {
+		return nil, err
+	}
+	if !dateOk || !nOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	t, layout, err := strToTime(date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
+	}
+	t, err = dateAdd(t, n, part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(t.Format(layout)), nil
+}
+
+func (this *FunctionCallDateAddStr) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallDateAddMillis struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateAddMillis(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateAddMillis{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_ADD_MILLIS",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  4,
+		},
+	}
+}
+
+func (this *FunctionCallDateAddMillis) Copy() Expression {
+	return NewFunctionCallDateAddMillis(this.Operands.Copy())
+}
+
+func (this *FunctionCallDateAddMillis) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	millis, millisOk := values[0].(float64)
+	n, nOk := dateAddCount(values[1])
+	part, partOk := values[2].(string)
+	loc, locOk, err := timeZoneOperand(values, 3)
+	if err != nil {
+		return nil, err
+	}
+	if !millisOk || !nOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	t, err := dateAdd(millisToTime(millis, loc), n, part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(timeToMillis(t)), nil
+}
+
+func (this *FunctionCallDateAddMillis) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallDateDiffStr struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateDiffStr(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateDiffStr{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_DIFF_STR",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  4,
+		},
+	}
+}
+
+func (this *FunctionCallDateDiffStr) Copy() Expression {
+	return NewFunctionCallDateDiffStr(this.Operands.Copy())
+}
+
+func (this *FunctionCallDateDiffStr) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	date1, date1Ok := values[0].(string)
+	date2, date2Ok := values[1].(string)
+	part, partOk := values[2].(string)
+	loc, locOk, err := timeZoneOperand(values, 3)
+	if err != nil {
+		return nil, err
+	}
+	if !date1Ok || !date2Ok || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	t1, _, err := strToTime(date1, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
+	}
+	t2, _, err := strToTime(date2, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
+	}
+	// calendar parts are counted in the zone of the first date
+	n, err := dateDiff(t1, t2.In(t1.Location()), part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(float64(n)), nil
+}
+
+func (this *FunctionCallDateDiffStr) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallDateDiffMillis struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateDiffMillis(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateDiffMillis{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_DIFF_MILLIS",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  4,
+		},
+	}
+}
+
+func (this *FunctionCallDateDiffMillis) Copy() Expression {
+	return NewFunctionCallDateDiffMillis(this.Operands.Copy())
+}
+
+func (this *FunctionCallDateDiffMillis) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	millis1, millis1Ok := values[0].(float64)
+	millis2, millis2Ok := values[1].(float64)
+	part, partOk := values[2].(string)
+	loc, locOk, err := timeZoneOperand(values, 3)
+	if err != nil {
+		return nil, err
+	}
+	if !millis1Ok || !millis2Ok || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	n, err := dateDiff(millisToTime(millis1, loc), millisToTime(millis2, loc), part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(float64(n)), nil
+}
+
+func (this *FunctionCallDateDiffMillis) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallDateTruncStr struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateTruncStr(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateTruncStr{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_TRUNC_STR",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  3,
+		},
+	}
+}
+
+func (this *FunctionCallDateTruncStr) Copy() Expression {
+	return NewFunctionCallDateTruncStr(this.Operands.Copy())
+}
+
+// the result has the format of the date
+func (this *FunctionCallDateTruncStr) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	date, dateOk := values[0].(string)
+	part, partOk := values[1].(string)
+	loc, locOk, err := timeZoneOperand(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !dateOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	t, layout, err := strToTime(date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("Date not in a recognized format. Error: %v", err)
+	}
+	t, err = dateTrunc(t, part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(t.Format(layout)), nil
+}
+
+func (this *FunctionCallDateTruncStr) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallDateTruncMillis struct {
+	FunctionCall
+}
+
+func NewFunctionCallDateTruncMillis(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDateTruncMillis{
+		FunctionCall{
+			Type:     "function",
+			Name:     "DATE_TRUNC_MILLIS",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  3,
+		},
+	}
+}
+
+func (this *FunctionCallDateTruncMillis) Copy() Expression {
+	return NewFunctionCallDateTruncMillis(this.Operands.Copy())
+}
+
+func (this *FunctionCallDateTruncMillis) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// undefined returns null
+		return dparval.NewValue(nil), nil
+	}
+
+	millis, millisOk := values[0].(float64)
+	part, partOk := values[1].(string)
+	loc, locOk, err := timeZoneOperand(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !millisOk || !partOk || !locOk {
+		return dparval.NewValue(nil), nil
+	}
+
+	t, err := dateTrunc(millisToTime(millis, loc), part)
+	if err != nil {
+		return nil, err
+	}
+	return dparval.NewValue(timeToMillis(t)), nil
+}
+
+func (this *FunctionCallDateTruncMillis) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
@@ -10,6 +10,7 @@
 package ast
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -254,3 +255,304 @@ func TestFunctionMillis(t *testing.T) {
 
 	tests.Run(t)
 }
+
+// The UNIX timestamp 1397203323000 represents the datetime
+// "2014-04-11 01:02:03" in Los Angeles
+func TestFunctionDateTimeZones(t *testing.T) {
+	tests := ExpressionTestSet{
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("hour")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			8.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("HOUR")),
+				NewFunctionArgExpression(NewLiteralString("America/Los_Angeles")),
+			}),
+			1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("isoweek")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			15.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("quarter")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			2.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("hour")),
+				NewFunctionArgExpression(NewLiteralNumber(1)),
+			}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_PART_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("hour")),
+				NewFunctionArgExpression(NewLiteralString("Middle/Earth")),
+			}),
+			nil,
+			fmt.Errorf("Unknown time zone Middle/Earth"),
+		},
+		{
+			NewFunctionCall("DATE_PART_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:02:03-07:00")),
+				NewFunctionArgExpression(NewLiteralString("hour")),
+				NewFunctionArgExpression(NewLiteralString("Asia/Tokyo")),
+			}),
+			17.0,
+			nil,
+		},
+		{
+			NewFunctionCall("STR_TO_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11 01:02:03")),
+				NewFunctionArgExpression(NewLiteralString("America/Los_Angeles")),
+			}),
+			1397203323000.0,
+			nil,
+		},
+		{
+			NewFunctionCall("MILLIS_TO_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("1111-11-11")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			"2014-04-11",
+			nil,
+		},
+		{
+			NewFunctionCall("MILLIS_TO_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("Jan 2, 2006 15:04 MST")),
+				NewFunctionArgExpression(NewLiteralString("America/New_York")),
+			}),
+			"Apr 11, 2014 04:02 EDT",
+			nil,
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestFunctionDateAdd(t *testing.T) {
+	tests := ExpressionTestSet{
+		{
+			NewFunctionCall("DATE_ADD_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:02:03-07:00")),
+				NewFunctionArgExpression(NewLiteralNumber(-2)),
+				NewFunctionArgExpression(NewLiteralString("hour")),
+			}),
+			"2014-04-10T23:02:03-07:00",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-01-31")),
+				NewFunctionArgExpression(NewLiteralNumber(1)),
+				NewFunctionArgExpression(NewLiteralString("month")),
+			}),
+			"2014-02-28",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralNumber(3)),
+				NewFunctionArgExpression(NewLiteralString("quarter")),
+			}),
+			"2015-01-11",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralNumber(1.5)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+			}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralNumber(1)),
+				NewFunctionArgExpression(NewLiteralString("fortnight")),
+			}),
+			nil,
+			fmt.Errorf("Unknown date part fortnight"),
+		},
+		// a day in new york is 23 hours long when daylight saving time starts
+		{
+			NewFunctionCall("DATE_ADD_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1394298000000)),
+				NewFunctionArgExpression(NewLiteralNumber(1)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("America/New_York")),
+			}),
+			1394380800000.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1394298000000)),
+				NewFunctionArgExpression(NewLiteralNumber(1)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			1394384400000.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_ADD_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1394298000000)),
+				NewFunctionArgExpression(NewLiteralNumber(-1500)),
+				NewFunctionArgExpression(NewLiteralString("millisecond")),
+			}),
+			1394297998500.0,
+			nil,
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestFunctionDateDiff(t *testing.T) {
+	tests := ExpressionTestSet{
+		{
+			NewFunctionCall("DATE_DIFF_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralString("2013-04-12")),
+				NewFunctionArgExpression(NewLiteralString("year")),
+			}),
+			0.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralString("2013-04-11")),
+				NewFunctionArgExpression(NewLiteralString("year")),
+			}),
+			1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2013-04-11")),
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralString("month")),
+			}),
+			-12.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:02:03")),
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T00:00:00")),
+				NewFunctionArgExpression(NewLiteralString("minute")),
+			}),
+			62.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:00:00+02:00")),
+				NewFunctionArgExpression(NewLiteralString("2014-04-10T23:00:00Z")),
+				NewFunctionArgExpression(NewLiteralString("millisecond")),
+			}),
+			0.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1394380800000)),
+				NewFunctionArgExpression(NewLiteralNumber(1394298000000)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("America/New_York")),
+			}),
+			1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_DIFF_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1394380800000)),
+				NewFunctionArgExpression(NewLiteralNumber(1394298000000)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			0.0,
+			nil,
+		},
+	}
+
+	tests.Run(t)
+}
+
+func TestFunctionDateTrunc(t *testing.T) {
+	tests := ExpressionTestSet{
+		{
+			NewFunctionCall("DATE_TRUNC_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:02:03-07:00")),
+				NewFunctionArgExpression(NewLiteralString("week")),
+			}),
+			"2014-04-07T00:00:00-07:00",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_TRUNC_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-05-11T01:02:03-07:00")),
+				NewFunctionArgExpression(NewLiteralString("quarter")),
+			}),
+			"2014-04-01T00:00:00-07:00",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_TRUNC_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11")),
+				NewFunctionArgExpression(NewLiteralString("century")),
+			}),
+			"2001-01-01",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_TRUNC_STR", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralString("2014-04-11T01:02:03-07:00")),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("UTC")),
+			}),
+			"2014-04-11T00:00:00Z",
+			nil,
+		},
+		{
+			NewFunctionCall("DATE_TRUNC_MILLIS", FunctionArgExpressionList{
+				NewFunctionArgExpression(NewLiteralNumber(1397203323000)),
+				NewFunctionArgExpression(NewLiteralString("day")),
+				NewFunctionArgExpression(NewLiteralString("America/Los_Angeles")),
+			}),
+			1397199600000.0,
+			nil,
+		},
+	}
+
+	tests.Run(t)
+}
@@ -0,0 +1,354 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"sort"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// ***********************************************************************************
+// Object Utility Functions
+// ***********************************************************************************
+
+// the names of the object in sorted order, so that the results
+// of OBJECT_KEYS(), OBJECT_VALUES() and OBJECT_PAIRS() line up
+func sortedNames(object map[string]interface{}) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	rv := make(map[string]interface{}, len(object)+1)
+	for name, value := range object {
+		rv[name] = value
+	}
+	return rv
+}
+
+type FunctionCallObjectKeys struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectKeys(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectKeys{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_KEYS",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallObjectKeys) Copy() Expression {
+	return NewFunctionCallObjectKeys(this.Operands.Copy())
+}
+
+// the names of the attributes of the object, in sorted order
+func (this *FunctionCallObjectKeys) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	names := sortedNames(object)
+	rv := make([]interface{}, len(names))
+	for i, name := range names {
+		rv[i] = name
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectKeys) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectValues struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectValues(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectValues{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_VALUES",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallObjectValues) Copy() Expression {
+	return NewFunctionCallObjectValues(this.Operands.Copy())
+}
+
+// the values of the attributes of the object, in the order of their names
+func (this *FunctionCallObjectValues) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	names := sortedNames(object)
+	rv := make([]interface{}, len(names))
+	for i, name := range names {
+		rv[i] = object[name]
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectValues) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectPairs struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectPairs(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectPairs{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_PAIRS",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallObjectPairs) Copy() Expression {
+	return NewFunctionCallObjectPairs(this.Operands.Copy())
+}
+
+// an array of {"name": name, "value": value} objects, one for every
+// attribute of the object, in the order of their names
+func (this *FunctionCallObjectPairs) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	names := sortedNames(object)
+	rv := make([]interface{}, len(names))
+	for i, name := range names {
+		rv[i] = map[string]interface{}{
+			"name":  name,
+			"value": object[name],
+		}
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectPairs) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectPut struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectPut(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectPut{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_PUT",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  3,
+		},
+	}
+}
+
+func (this *FunctionCallObjectPut) Copy() Expression {
+	return NewFunctionCallObjectPut(this.Operands.Copy())
+}
+
+// a copy of the object with the attribute set to the value,
+// whether the object had the attribute or not
+func (this *FunctionCallObjectPut) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	name, ok := values[1].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv := copyObject(object)
+	rv[name] = values[2]
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectPut) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectAdd struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectAdd(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectAdd{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_ADD",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  3,
+		},
+	}
+}
+
+func (this *FunctionCallObjectAdd) Copy() Expression {
+	return NewFunctionCallObjectAdd(this.Operands.Copy())
+}
+
+// a copy of the object with the attribute added, unlike OBJECT_PUT()
+// an attribute the object already has is left as it is
+func (this *FunctionCallObjectAdd) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	name, ok := values[1].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv := copyObject(object)
+	_, exists := rv[name]
+	if !exists {
+		rv[name] = values[2]
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectAdd) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectRemove struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectRemove(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectRemove{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_REMOVE",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallObjectRemove) Copy() Expression {
+	return NewFunctionCallObjectRemove(this.Operands.Copy())
+}
+
+// a copy of the object without the attribute
+func (this *FunctionCallObjectRemove) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	object, ok := values[0].(map[string]interface{})
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	name, ok := values[1].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv := copyObject(object)
+	delete(rv, name)
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectRemove) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallObjectConcat struct {
+	FunctionCall
+}
+
+func NewFunctionCallObjectConcat(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallObjectConcat{
+		FunctionCall{
+			Type:     "function",
+			Name:     "OBJECT_CONCAT",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  -1,
+		},
+	}
+}
+
+func (this *FunctionCallObjectConcat) Copy() Expression {
+	return NewFunctionCallObjectConcat(this.Operands.Copy())
+}
+
+// an object with the attributes of all the objects, when several
+// have the same attribute the value of the last one wins
+func (this *FunctionCallObjectConcat) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+
+	rv := make(map[string]interface{})
+	for _, value := range values {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+		for name, attribute := range object {
+			rv[name] = attribute
+		}
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallObjectConcat) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
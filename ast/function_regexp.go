@@ -0,0 +1,346 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// the regular expression functions take a string and a pattern, when
+// the pattern is a literal it is only compiled once for the query
+type regexpFunctionCall struct {
+	FunctionCall
+	// the pattern must match the whole string
+	anchored bool
+	cache    *regexpCache
+}
+
+func newRegexpFunctionCall(name string, operands FunctionArgExpressionList, minArgs, maxArgs int) regexpFunctionCall {
+	return regexpFunctionCall{
+		FunctionCall{
+			Type:     "function",
+			Name:     name,
+			Operands: operands,
+			minArgs:  minArgs,
+			maxArgs:  maxArgs,
+		},
+		false,
+		&regexpCache{},
+	}
+}
+
+type regexpCache struct {
+	mutex   sync.Mutex
+	pattern string
+	re      *regexp.Regexp
+}
+
+func (this *regexpFunctionCall) compile(pattern string) (*regexp.Regexp, error) {
+	_, literal := this.Operands[1].Expr.(*LiteralString)
+	if !literal {
+		return this.compileNew(pattern)
+	}
+
+	cache := this.cache
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// operands may be rewritten, so make sure it is still the same pattern
+	if cache.re != nil && cache.pattern == pattern {
+		return cache.re, nil
+	}
+	re, err := this.compileNew(pattern)
+	if err != nil {
+		return nil, err
+	}
+	cache.pattern = pattern
+	cache.re = re
+	return re, nil
+}
+
+func (this *regexpFunctionCall) compileNew(pattern string) (*regexp.Regexp, error) {
+	if this.anchored {
+		pattern = "^(?:" + pattern + ")$"
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for the %s() function: %v", this.Name, err)
+	}
+	return re, nil
+}
+
+// evaluates the string and the pattern, ok is false when either
+// is not a string and the result is NULL
+func (this *regexpFunctionCall) evaluateStringAndPattern(item *dparval.Value) (string, *regexp.Regexp, bool, error) {
+	av, err := this.Operands[0].Expr.Evaluate(item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			// undefined returns null
+			return "", nil, false, nil
+		default:
+			// any other error return to caller
+			return "", nil, false, err
+		}
+	}
+	pv, err := this.Operands[1].Expr.Evaluate(item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			// undefined returns null
+			return "", nil, false, nil
+		default:
+			// any other error return to caller
+			return "", nil, false, err
+		}
+	}
+
+	avalue, ok := av.Value().(string)
+	if !ok || av.Type() != dparval.STRING {
+		return "", nil, false, nil
+	}
+	pvalue, ok := pv.Value().(string)
+	if !ok || pv.Type() != dparval.STRING {
+		return "", nil, false, nil
+	}
+
+	re, err := this.compile(pvalue)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return avalue, re, true, nil
+}
+
+type FunctionCallRegexpContains struct {
+	regexpFunctionCall
+}
+
+func NewFunctionCallRegexpContains(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRegexpContains{
+		newRegexpFunctionCall("REGEXP_CONTAINS", operands, 2, 2),
+	}
+}
+
+func (this *FunctionCallRegexpContains) Copy() Expression {
+	return NewFunctionCallRegexpContains(this.Operands.Copy())
+}
+
+// true when the pattern matches any part of the string
+func (this *FunctionCallRegexpContains) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	avalue, re, ok, err := this.evaluateStringAndPattern(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return dparval.NewValue(re.MatchString(avalue)), nil
+}
+
+func (this *FunctionCallRegexpContains) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRegexpLike struct {
+	regexpFunctionCall
+}
+
+func NewFunctionCallRegexpLike(operands FunctionArgExpressionList) FunctionCallExpression {
+	rv := &FunctionCallRegexpLike{
+		newRegexpFunctionCall("REGEXP_LIKE", operands, 2, 2),
+	}
+	rv.anchored = true
+	return rv
+}
+
+func (this *FunctionCallRegexpLike) Copy() Expression {
+	return NewFunctionCallRegexpLike(this.Operands.Copy())
+}
+
+// true when the pattern matches the whole string
+func (this *FunctionCallRegexpLike) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	avalue, re, ok, err := this.evaluateStringAndPattern(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return dparval.NewValue(re.MatchString(avalue)), nil
+}
+
+func (this *FunctionCallRegexpLike) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRegexpPosition struct {
+	regexpFunctionCall
+}
+
+func NewFunctionCallRegexpPosition(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRegexpPosition{
+		newRegexpFunctionCall("REGEXP_POSITION", operands, 2, 2),
+	}
+}
+
+func (this *FunctionCallRegexpPosition) Copy() Expression {
+	return NewFunctionCallRegexpPosition(this.Operands.Copy())
+}
+
+// the position of the first match, counting from 0 like SUBSTR(),
+// or -1 when the pattern does not match
+func (this *FunctionCallRegexpPosition) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	avalue, re, ok, err := this.evaluateStringAndPattern(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	match := re.FindStringIndex(avalue)
+	if match == nil {
+		return dparval.NewValue(-1.0), nil
+	}
+	// in characters, like SUBSTR()
+	return dparval.NewValue(float64(utf8.RuneCountInString(avalue[:match[0]]))), nil
+}
+
+func (this *FunctionCallRegexpPosition) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRegexpReplace struct {
+	regexpFunctionCall
+}
+
+func NewFunctionCallRegexpReplace(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRegexpReplace{
+		newRegexpFunctionCall("REGEXP_REPLACE", operands, 3, 4),
+	}
+}
+
+func (this *FunctionCallRegexpReplace) Copy() Expression {
+	return NewFunctionCallRegexpReplace(this.Operands.Copy())
+}
+
+// replaces the matches with the replacement, in which $1 or ${name}
+// stand for the capture groups of the match.  the optional 4th
+// argument limits the number of matches replaced, from the start
+func (this *FunctionCallRegexpReplace) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	avalue, re, ok, err := this.evaluateStringAndPattern(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv, err := this.Operands[2].Expr.Evaluate(item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			// undefined returns null
+			return dparval.NewValue(nil), nil
+		default:
+			// any other error return to caller
+			return nil, err
+		}
+	}
+	replacement, ok := rv.Value().(string)
+	if !ok || rv.Type() != dparval.STRING {
+		return dparval.NewValue(nil), nil
+	}
+
+	limit := -1
+	if len(this.Operands) > 3 {
+		lv, err := this.Operands[3].Expr.Evaluate(item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				// undefined returns null
+				return dparval.NewValue(nil), nil
+			default:
+				// any other error return to caller
+				return nil, err
+			}
+		}
+		lvalue, ok := lv.Value().(float64)
+		if !ok || lv.Type() != dparval.NUMBER {
+			return dparval.NewValue(nil), nil
+		}
+		limit = int(lvalue)
+	}
+
+	if limit < 0 {
+		return dparval.NewValue(re.ReplaceAllString(avalue, replacement)), nil
+	}
+
+	result := []byte{}
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(avalue, limit) {
+		result = append(result, avalue[last:match[0]]...)
+		result = re.ExpandString(result, replacement, avalue, match)
+		last = match[1]
+	}
+	result = append(result, avalue[last:]...)
+	return dparval.NewValue(string(result)), nil
+}
+
+func (this *FunctionCallRegexpReplace) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRegexpMatches struct {
+	regexpFunctionCall
+}
+
+func NewFunctionCallRegexpMatches(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRegexpMatches{
+		newRegexpFunctionCall("REGEXP_MATCHES", operands, 2, 2),
+	}
+}
+
+func (this *FunctionCallRegexpMatches) Copy() Expression {
+	return NewFunctionCallRegexpMatches(this.Operands.Copy())
+}
+
+// an array with an element for every match, each an array of the
+// text of the match followed by its capture groups.  groups that
+// did not take part in the match are NULL
+func (this *FunctionCallRegexpMatches) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	avalue, re, ok, err := this.evaluateStringAndPattern(item)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	matches := re.FindAllStringSubmatchIndex(avalue, -1)
+	rv := make([]interface{}, len(matches))
+	for i, match := range matches {
+		groups := make([]interface{}, len(match)/2)
+		for j := range groups {
+			if match[2*j] >= 0 {
+				groups[j] = avalue[match[2*j]:match[2*j+1]]
+			}
+		}
+		rv[i] = groups
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallRegexpMatches) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
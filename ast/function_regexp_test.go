@@ -0,0 +1,92 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+func regexpCall(name string, operands ...Expression) FunctionCallExpression {
+	args := make(FunctionArgExpressionList, len(operands))
+	for i, operand := range operands {
+		args[i] = NewFunctionArgExpression(operand)
+	}
+	return NewFunctionCall(name, args)
+}
+
+func TestRegexpFunctions(t *testing.T) {
+	context := dparval.NewValue(map[string]interface{}{
+		"name":    "fred flintstone",
+		"pattern": "f[a-z]+",
+		"number":  1.0,
+	})
+
+	tests := ExpressionTestSet{
+		{regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewLiteralString("flint")), true, nil},
+		{regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewLiteralString("^flint")), false, nil},
+		{regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewProperty("pattern")), true, nil},
+		{regexpCall("REGEXP_CONTAINS", NewProperty("dne"), NewLiteralString("flint")), nil, nil},
+		{regexpCall("REGEXP_CONTAINS", NewProperty("number"), NewLiteralString("1")), nil, nil},
+		{regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewLiteralNumber(1.0)), nil, nil},
+
+		{regexpCall("REGEXP_LIKE", NewProperty("name"), NewLiteralString("fred")), false, nil},
+		{regexpCall("REGEXP_LIKE", NewProperty("name"), NewLiteralString("fred .*")), true, nil},
+		{regexpCall("REGEXP_LIKE", NewProperty("name"), NewLiteralString("f|fred flintstone")), true, nil},
+
+		{regexpCall("REGEXP_POSITION", NewProperty("name"), NewLiteralString("flint")), 5.0, nil},
+		{regexpCall("REGEXP_POSITION", NewProperty("name"), NewLiteralString("barney")), -1.0, nil},
+		{regexpCall("REGEXP_POSITION", NewLiteralString("日本語のテキスト"), NewLiteralString("テ")), 4.0, nil},
+
+		{regexpCall("REGEXP_REPLACE", NewProperty("name"), NewLiteralString("(f)([a-z]+)"), NewLiteralString("${2}")), "red lintstone", nil},
+		{regexpCall("REGEXP_REPLACE", NewProperty("name"), NewLiteralString("f"), NewLiteralString("F"), NewLiteralNumber(1.0)), "Fred flintstone", nil},
+		{regexpCall("REGEXP_REPLACE", NewProperty("name"), NewLiteralString("f"), NewLiteralString("F"), NewLiteralNumber(0.0)), "fred flintstone", nil},
+		{regexpCall("REGEXP_REPLACE", NewProperty("name"), NewLiteralString("f"), NewProperty("dne")), nil, nil},
+
+		{regexpCall("REGEXP_MATCHES", NewProperty("name"), NewLiteralString("f([a-z])([0-9])?")),
+			[]interface{}{[]interface{}{"fr", "r", nil}, []interface{}{"fl", "l", nil}}, nil},
+		{regexpCall("REGEXP_MATCHES", NewProperty("name"), NewLiteralString("[0-9]")), []interface{}{}, nil},
+	}
+	tests.RunWithItem(t, context)
+
+	invalid := regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewLiteralString("("))
+	_, err := invalid.Evaluate(context)
+	if err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
+
+func TestRegexpLiteralPatternCompiledOnce(t *testing.T) {
+	context := dparval.NewValue(map[string]interface{}{"name": "fred"})
+
+	literal := regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewLiteralString("r")).(*FunctionCallRegexpContains)
+	literal.Evaluate(context)
+	re := literal.cache.re
+	if re == nil {
+		t.Fatalf("expected the literal pattern to be kept")
+	}
+	literal.Evaluate(context)
+	if literal.cache.re != re {
+		t.Errorf("expected the literal pattern to be compiled once")
+	}
+
+	// copies compile their own
+	copied := literal.Copy().(*FunctionCallRegexpContains)
+	if copied.cache.re != nil {
+		t.Errorf("expected the copy to start without a compiled pattern")
+	}
+
+	property := regexpCall("REGEXP_CONTAINS", NewProperty("name"), NewProperty("name")).(*FunctionCallRegexpContains)
+	property.Evaluate(context)
+	if property.cache.re != nil {
+		t.Errorf("expected patterns from documents not to be kept")
+	}
+}
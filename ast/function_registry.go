@@ -19,18 +19,58 @@ var SystemFunctionRegistry map[string]FunctionCallConstructor = map[string]Funct
 	"POLY_LENGTH":   NewFunctionCallPolyLength,
 
 	//array utility functions
-	"ARRAY_CONCAT":  NewFunctionCallArrayConcat,
-	"ARRAY_APPEND":  NewFunctionCallArrayAppend,
-	"ARRAY_PREPEND": NewFunctionCallArrayPrepend,
-	"ARRAY_REMOVE":  NewFunctionCallArrayRemove,
+	"ARRAY_CONCAT":    NewFunctionCallArrayConcat,
+	"ARRAY_APPEND":    NewFunctionCallArrayAppend,
+	"ARRAY_PREPEND":   NewFunctionCallArrayPrepend,
+	"ARRAY_REMOVE":    NewFunctionCallArrayRemove,
+	"ARRAY_CONTAINS":  NewFunctionCallArrayContains,
+	"ARRAY_POSITION":  NewFunctionCallArrayPosition,
+	"ARRAY_DISTINCT":  NewFunctionCallArrayDistinct,
+	"ARRAY_INTERSECT": NewFunctionCallArrayIntersect,
+	"ARRAY_SORT":      NewFunctionCallArraySort,
+	"ARRAY_REVERSE":   NewFunctionCallArrayReverse,
+	"ARRAY_SUM":       NewFunctionCallArraySum,
+	"ARRAY_AVG":       NewFunctionCallArrayAvg,
+	"ARRAY_MIN":       NewFunctionCallArrayMin,
+	"ARRAY_MAX":       NewFunctionCallArrayMax,
+	"ARRAY_RANGE":     NewFunctionCallArrayRange,
+	"ARRAY_FLATTEN":   NewFunctionCallArrayFlatten,
+
+	// object utility functions
+	"OBJECT_KEYS":   NewFunctionCallObjectKeys,
+	"OBJECT_VALUES": NewFunctionCallObjectValues,
+	"OBJECT_PAIRS":  NewFunctionCallObjectPairs,
+	"OBJECT_PUT":    NewFunctionCallObjectPut,
+	"OBJECT_ADD":    NewFunctionCallObjectAdd,
+	"OBJECT_REMOVE": NewFunctionCallObjectRemove,
+	"OBJECT_CONCAT": NewFunctionCallObjectConcat,
 
 	// aggregate functions
-	"COUNT":     NewFunctionCallCount,
-	"SUM":       NewFunctionCallSum,
-	"AVG":       NewFunctionCallAvg,
-	"MIN":       NewFunctionCallMin,
-	"MAX":       NewFunctionCallMax,
-	"ARRAY_AGG": NewFunctionCallArrayAgg,
+	"COUNT":           NewFunctionCallCount,
+	"SUM":             NewFunctionCallSum,
+	"AVG":             NewFunctionCallAvg,
+	"MIN":             NewFunctionCallMin,
+	"MAX":             NewFunctionCallMax,
+	"ARRAY_AGG":       NewFunctionCallArrayAgg,
+	"VARIANCE":        NewFunctionCallVariance,
+	"VARIANCE_SAMP":   NewFunctionCallVarianceSamp,
+	"VAR_SAMP":        NewFunctionCallVarianceSamp,
+	"VARIANCE_POP":    NewFunctionCallVariancePop,
+	"VAR_POP":         NewFunctionCallVariancePop,
+	"STDDEV":          NewFunctionCallStddev,
+	"STDDEV_SAMP":     NewFunctionCallStddevSamp,
+	"STDDEV_POP":      NewFunctionCallStddevPop,
+	"MEDIAN":          NewFunctionCallMedian,
+	"PERCENTILE_CONT": NewFunctionCallPercentileCont,
+	"PERCENTILE_DISC": NewFunctionCallPercentileDisc,
+
+	// window functions
+	"ROW_NUMBER":  NewFunctionCallRowNumber,
+	"RANK":        NewFunctionCallRank,
+	"DENSE_RANK":  NewFunctionCallDenseRank,
+	"LAG":         NewFunctionCallLag,
+	"LEAD":        NewFunctionCallLead,
+	"FIRST_VALUE": NewFunctionCallFirstValue,
 
 	// comparison functions
 	"GREATEST":        NewFunctionCallGreatest,
@@ -65,22 +105,46 @@ var SystemFunctionRegistry map[string]FunctionCallConstructor = map[string]Funct
 	"NEGINFIF":   NewFunctionCallNegInfIf,
 
 	// string functions
-	"LOWER":  NewFunctionCallLower,
-	"UPPER":  NewFunctionCallUpper,
-	"TRIM":   NewFunctionCallTrim,
-	"RTRIM":  NewFunctionCallRTrim,
-	"LTRIM":  NewFunctionCallLTrim,
-	"SUBSTR": NewFunctionCallSubStr,
-	"SPLIT":  NewFunctionCallSplit,
+	"LOWER":    NewFunctionCallLower,
+	"UPPER":    NewFunctionCallUpper,
+	"TRIM":     NewFunctionCallTrim,
+	"RTRIM":    NewFunctionCallRTrim,
+	"LTRIM":    NewFunctionCallLTrim,
+	"SUBSTR":   NewFunctionCallSubStr,
+	"SPLIT":    NewFunctionCallSplit,
+	"CONTAINS": NewFunctionCallContains,
+	"POSITION": NewFunctionCallPosition,
+	"REPLACE":  NewFunctionCallReplace,
+	"REPEAT":   NewFunctionCallRepeat,
+	"REVERSE":  NewFunctionCallReverse,
+	"LPAD":     NewFunctionCallLPad,
+	"RPAD":     NewFunctionCallRPad,
+	"INITCAP":  NewFunctionCallInitCap,
+	"TITLE":    NewFunctionCallInitCap,
+	"SUFFIXES": NewFunctionCallSuffixes,
+	"TOKENS":   NewFunctionCallTokens,
+
+	// regular expression functions
+	"REGEXP_CONTAINS": NewFunctionCallRegexpContains,
+	"REGEXP_LIKE":     NewFunctionCallRegexpLike,
+	"REGEXP_POSITION": NewFunctionCallRegexpPosition,
+	"REGEXP_REPLACE":  NewFunctionCallRegexpReplace,
+	"REGEXP_MATCHES":  NewFunctionCallRegexpMatches,
 
 	// date functions
-	"DATE_PART_STR":    NewFunctionCallDatePartStr,
-	"NOW_STR":          NewFunctionCallNowStr,
-	"DATE_PART_MILLIS": NewFunctionCallDatePartMillis,
-	"NOW_MILLIS":       NewFunctionCallNowMillis,
-	"STR_TO_MILLIS":    NewFunctionCallStrToMillis,
-	"MILLIS":           NewFunctionCallStrToMillis,
-	"MILLIS_TO_STR":    NewFunctionCallMillisToStr,
+	"DATE_PART_STR":     NewFunctionCallDatePartStr,
+	"NOW_STR":           NewFunctionCallNowStr,
+	"DATE_PART_MILLIS":  NewFunctionCallDatePartMillis,
+	"NOW_MILLIS":        NewFunctionCallNowMillis,
+	"STR_TO_MILLIS":     NewFunctionCallStrToMillis,
+	"MILLIS":            NewFunctionCallStrToMillis,
+	"MILLIS_TO_STR":     NewFunctionCallMillisToStr,
+	"DATE_ADD_STR":      NewFunctionCallDateAddStr,
+	"DATE_ADD_MILLIS":   NewFunctionCallDateAddMillis,
+	"DATE_DIFF_STR":     NewFunctionCallDateDiffStr,
+	"DATE_DIFF_MILLIS":  NewFunctionCallDateDiffMillis,
+	"DATE_TRUNC_STR":    NewFunctionCallDateTruncStr,
+	"DATE_TRUNC_MILLIS": NewFunctionCallDateTruncMillis,
 
 	// typecast functions
 	"TO_NUM":   NewFunctionCallToNum,
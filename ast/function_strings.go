@@ -10,7 +10,11 @@
 package ast
 
 import (
+	"fmt"
+	"math"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/couchbaselabs/dparval"
 )
@@ -468,24 +472,26 @@ func (this *FunctionCallSubStr) Evaluate(item *dparval.Value) (*dparval.Value, e
 				switch posval := posval.(type) {
 				case float64:
 					pos := int(posval)
+					// positions count characters, not bytes
+					runes := []rune(avalue)
 
 					//validate that pos is valid
-					if pos < 0 || pos >= len(avalue) {
+					if pos < 0 || pos >= len(runes) {
 						// FIXME add warning for invalid pos?
 						return dparval.NewValue(nil), nil
 					}
 
 					if maxLen < 0 {
 						// no end limit
-						return dparval.NewValue(avalue[pos:]), nil
+						return dparval.NewValue(string(runes[pos:])), nil
 					} else {
 						// validate that maxLen is valid
 						endPos := pos + maxLen
-						if endPos < pos || endPos > len(avalue) {
+						if endPos < pos || endPos > len(runes) {
 							// FIXME add warning for invalid max len?
 							return dparval.NewValue(nil), nil
 						}
-						return dparval.NewValue(avalue[pos:endPos]), nil
+						return dparval.NewValue(string(runes[pos:endPos])), nil
 					}
 				}
 			}
@@ -586,3 +592,554 @@ func (this *FunctionCallSplit) Evaluate(item *dparval.Value) (*dparval.Value, er
 func (this *FunctionCallSplit) Accept(ev ExpressionVisitor) (Expression, error) {
 	return ev.Visit(this)
 }
+
+// REPEAT(), LPAD() and RPAD() refuse to build strings longer than this
+const STRING_LENGTH_LIMIT = 10000000
+
+type FunctionCallContains struct {
+	FunctionCall
+}
+
+func NewFunctionCallContains(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallContains{
+		FunctionCall{
+			Type:     "function",
+			Name:     "CONTAINS",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallContains) Copy() Expression {
+	return NewFunctionCallContains(this.Operands.Copy())
+}
+
+// true if the string contains the substring
+func (this *FunctionCallContains) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	substr, ok := values[1].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return dparval.NewValue(strings.Contains(avalue, substr)), nil
+}
+
+func (this *FunctionCallContains) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallPosition struct {
+	FunctionCall
+}
+
+func NewFunctionCallPosition(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallPosition{
+		FunctionCall{
+			Type:     "function",
+			Name:     "POSITION",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallPosition) Copy() Expression {
+	return NewFunctionCallPosition(this.Operands.Copy())
+}
+
+// the position of the first occurrence of the substring in characters,
+// counting from 0 like SUBSTR(), or -1 when there is none
+func (this *FunctionCallPosition) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	substr, ok := values[1].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	index := strings.Index(avalue, substr)
+	if index < 0 {
+		return dparval.NewValue(-1.0), nil
+	}
+	return dparval.NewValue(float64(utf8.RuneCountInString(avalue[:index]))), nil
+}
+
+func (this *FunctionCallPosition) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallReplace struct {
+	FunctionCall
+}
+
+func NewFunctionCallReplace(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallReplace{
+		FunctionCall{
+			Type:     "function",
+			Name:     "REPLACE",
+			Operands: operands,
+			minArgs:  3,
+			maxArgs:  4,
+		},
+	}
+}
+
+func (this *FunctionCallReplace) Copy() Expression {
+	return NewFunctionCallReplace(this.Operands.Copy())
+}
+
+// replaces every occurrence of old with new, the optional 4th
+// argument limits the number of occurrences replaced, from the start
+func (this *FunctionCallReplace) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	strs := make([]string, 3)
+	for i := range strs {
+		strs[i], ok = values[i].(string)
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+	}
+
+	limit := -1
+	if len(values) > 3 {
+		n, ok := values[3].(float64)
+		if !ok || n != math.Trunc(n) {
+			return dparval.NewValue(nil), nil
+		}
+		// there cannot be more occurrences than bytes, bigger limits
+		// would not even fit an int
+		if n >= 0 && n <= float64(len(strs[0])) {
+			limit = int(n)
+		}
+	}
+	return dparval.NewValue(strings.Replace(strs[0], strs[1], strs[2], limit)), nil
+}
+
+func (this *FunctionCallReplace) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRepeat struct {
+	FunctionCall
+}
+
+func NewFunctionCallRepeat(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRepeat{
+		FunctionCall{
+			Type:     "function",
+			Name:     "REPEAT",
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallRepeat) Copy() Expression {
+	return NewFunctionCallRepeat(this.Operands.Copy())
+}
+
+// the string repeated n times, n must be a non-negative integer
+func (this *FunctionCallRepeat) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	n, ok := values[1].(float64)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return dparval.NewValue(nil), nil
+	}
+
+	// checked before the count is converted, even when it does not
+	// matter for the length of the result
+	if n > STRING_LENGTH_LIMIT || n*float64(utf8.RuneCountInString(avalue)) > STRING_LENGTH_LIMIT {
+		return nil, fmt.Errorf("the %s() function cannot return more than %d characters", this.Name, STRING_LENGTH_LIMIT)
+	}
+	return dparval.NewValue(strings.Repeat(avalue, int(n))), nil
+}
+
+func (this *FunctionCallRepeat) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// keeps characters of emoji sequences together in REVERSE()
+const ZERO_WIDTH_JOINER = '\u200d'
+
+type FunctionCallReverse struct {
+	FunctionCall
+}
+
+func NewFunctionCallReverse(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallReverse{
+		FunctionCall{
+			Type:     "function",
+			Name:     "REVERSE",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallReverse) Copy() Expression {
+	return NewFunctionCallReverse(this.Operands.Copy())
+}
+
+// the characters of the string in reverse order
+func (this *FunctionCallReverse) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	// combining marks stay after the character they modify, and
+	// characters joined with a zero width joiner stay together
+	runes := []rune(avalue)
+	rv := make([]rune, 0, len(runes))
+	end := len(runes)
+	for end > 0 {
+		start := end - 1
+		for start > 0 && (unicode.IsMark(runes[start]) || runes[start] == ZERO_WIDTH_JOINER || runes[start-1] == ZERO_WIDTH_JOINER) {
+			start--
+		}
+		rv = append(rv, runes[start:end]...)
+		end = start
+	}
+	return dparval.NewValue(string(rv)), nil
+}
+
+func (this *FunctionCallReverse) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// LPAD() and RPAD() pad the string to a length in characters with
+// the pad string (a space if not specified), a longer string is cut
+// to the length
+type padFunctionCall struct {
+	FunctionCall
+	left bool
+}
+
+func newPadFunctionCall(name string, operands FunctionArgExpressionList, left bool) padFunctionCall {
+	return padFunctionCall{
+		FunctionCall{
+			Type:     "function",
+			Name:     name,
+			Operands: operands,
+			minArgs:  2,
+			maxArgs:  3,
+		},
+		left,
+	}
+}
+
+func (this *padFunctionCall) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	length, ok := values[1].(float64)
+	if !ok || length < 0 || length != math.Trunc(length) {
+		return dparval.NewValue(nil), nil
+	}
+	pad := " "
+	if len(values) > 2 {
+		pad, ok = values[2].(string)
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+	}
+	if length > STRING_LENGTH_LIMIT {
+		return nil, fmt.Errorf("the %s() function cannot return more than %d characters", this.Name, STRING_LENGTH_LIMIT)
+	}
+
+	runes := []rune(avalue)
+	padRunes := []rune(pad)
+	n := int(length)
+	if len(runes) >= n {
+		return dparval.NewValue(string(runes[:n])), nil
+	}
+	if len(padRunes) == 0 {
+		return dparval.NewValue(avalue), nil
+	}
+
+	padding := make([]rune, 0, n-len(runes))
+	for len(padding) < n-len(runes) {
+		padding = append(padding, padRunes[len(padding)%len(padRunes)])
+	}
+	if this.left {
+		return dparval.NewValue(string(padding) + avalue), nil
+	}
+	return dparval.NewValue(avalue + string(padding)), nil
+}
+
+type FunctionCallLPad struct {
+	padFunctionCall
+}
+
+func NewFunctionCallLPad(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallLPad{
+		newPadFunctionCall("LPAD", operands, true),
+	}
+}
+
+func (this *FunctionCallLPad) Copy() Expression {
+	return NewFunctionCallLPad(this.Operands.Copy())
+}
+
+func (this *FunctionCallLPad) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallRPad struct {
+	padFunctionCall
+}
+
+func NewFunctionCallRPad(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRPad{
+		newPadFunctionCall("RPAD", operands, false),
+	}
+}
+
+func (this *FunctionCallRPad) Copy() Expression {
+	return NewFunctionCallRPad(this.Operands.Copy())
+}
+
+func (this *FunctionCallRPad) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallInitCap struct {
+	FunctionCall
+}
+
+func NewFunctionCallInitCap(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallInitCap{
+		FunctionCall{
+			Type:     "function",
+			Name:     "INITCAP",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallInitCap) Copy() Expression {
+	return NewFunctionCallInitCap(this.Operands.Copy())
+}
+
+// the string with the first letter of every word in upper case
+// and the other letters in lower case
+func (this *FunctionCallInitCap) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	runes := []rune(avalue)
+	inWord := false
+	for i, r := range runes {
+		switch {
+		case isWordRune(r):
+			if inWord {
+				runes[i] = unicode.ToLower(r)
+			} else {
+				runes[i] = unicode.ToTitle(r)
+			}
+			inWord = true
+		case r == '\'' && inWord:
+			// like in "don't"
+		default:
+			inWord = false
+		}
+	}
+	return dparval.NewValue(string(runes)), nil
+}
+
+func (this *FunctionCallInitCap) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// letters, digits and the accents combined with them
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+type FunctionCallSuffixes struct {
+	FunctionCall
+}
+
+func NewFunctionCallSuffixes(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallSuffixes{
+		FunctionCall{
+			Type:     "function",
+			Name:     "SUFFIXES",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallSuffixes) Copy() Expression {
+	return NewFunctionCallSuffixes(this.Operands.Copy())
+}
+
+// an array of the suffixes of the string, starting with the string
+// itself and dropping one character at a time
+func (this *FunctionCallSuffixes) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+	avalue, ok := values[0].(string)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+
+	rv := make([]interface{}, 0, len(avalue))
+	for i := range avalue {
+		rv = append(rv, avalue[i:])
+	}
+	return dparval.NewValue(rv), nil
+}
+
+func (this *FunctionCallSuffixes) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type FunctionCallTokens struct {
+	FunctionCall
+}
+
+func NewFunctionCallTokens(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallTokens{
+		FunctionCall{
+			Type:     "function",
+			Name:     "TOKENS",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  2,
+		},
+	}
+}
+
+func (this *FunctionCallTokens) Copy() Expression {
+	return NewFunctionCallTokens(this.Operands.Copy())
+}
+
+// an array of the distinct words, numbers and booleans in the value,
+// looking into arrays and objects.  the options object can have
+// "case": "lower" or "upper" to change the case of the words, and
+// "names": false to leave out the names of object attributes
+func (this *FunctionCallTokens) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	values, ok, err := this.evaluateOperandValues(item)
+	if err != nil || !ok {
+		return dparval.NewValue(nil), err
+	}
+
+	tokenizer := &tokenizer{
+		names: true,
+		seen:  make(map[string]bool),
+		rv:    make([]interface{}, 0),
+	}
+	if len(values) > 1 {
+		options, ok := values[1].(map[string]interface{})
+		if !ok {
+			return dparval.NewValue(nil), nil
+		}
+		switch options["case"] {
+		case "lower":
+			tokenizer.fold = strings.ToLower
+		case "upper":
+			tokenizer.fold = strings.ToUpper
+		}
+		names, ok := options["names"].(bool)
+		if ok {
+			tokenizer.names = names
+		}
+	}
+
+	tokenizer.add(values[0])
+	return dparval.NewValue(tokenizer.rv), nil
+}
+
+func (this *FunctionCallTokens) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+type tokenizer struct {
+	fold  func(string) string
+	names bool
+	seen  map[string]bool
+	rv    []interface{}
+}
+
+func (this *tokenizer) add(value interface{}) {
+	switch value := value.(type) {
+	case string:
+		for _, word := range strings.FieldsFunc(value, func(r rune) bool { return !isWordRune(r) }) {
+			if this.fold != nil {
+				word = this.fold(word)
+			}
+			this.addToken(word)
+		}
+	case float64, bool:
+		this.addToken(value)
+	case []interface{}:
+		for _, element := range value {
+			this.add(element)
+		}
+	case map[string]interface{}:
+		for _, name := range sortedNames(value) {
+			if this.names {
+				this.add(name)
+			}
+			this.add(value[name])
+		}
+	}
+}
+
+func (this *tokenizer) addToken(token interface{}) {
+	key := distinctKey(token)
+	if !this.seen[key] {
+		this.seen[key] = true
+		this.rv = append(this.rv, token)
+	}
+}
@@ -35,6 +35,26 @@ func TestFunction(t *testing.T) {
 
 	context := dparval.NewValue(sampleContext)
 
+	mixedArray := NewFunctionArgExpression(
+		NewLiteralArray(ExpressionList{
+			NewLiteralNumber(3.0),
+			NewLiteralString("a"),
+			NewLiteralNull(),
+			NewLiteralNumber(1.0),
+			NewLiteralNumber(3.0)}))
+	nestedArray := NewFunctionArgExpression(
+		NewLiteralArray(ExpressionList{
+			NewLiteralNumber(1.0),
+			NewLiteralArray(ExpressionList{
+				NewLiteralNumber(2.0),
+				NewLiteralArray(ExpressionList{
+					NewLiteralNumber(3.0)})})}))
+	sampleObject := NewFunctionArgExpression(
+		NewLiteralObject(map[string]Expression{
+			"b": NewLiteralNumber(2.0),
+			"a": NewLiteralString("x")}))
+	missingArg := NewFunctionArgExpression(NewProperty("dne"))
+
 	tests := ExpressionTestSet{
 		// meta/value functions
 		{
@@ -179,6 +199,164 @@ func TestFunction(t *testing.T) {
 			nil,
 			nil,
 		},
+
+		// string functions on characters rather than bytes
+		{
+			NewFunctionCall("SUBSTR", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("日本語のテキスト")), NewFunctionArgExpression(NewLiteralNumber(2.0)), NewFunctionArgExpression(NewLiteralNumber(3.0))}),
+			"語のテ",
+			nil,
+		},
+		{
+			NewFunctionCall("SUBSTR", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("日本語")), NewFunctionArgExpression(NewLiteralNumber(3.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("LENGTH", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo wörld"))}),
+			11.0,
+			nil,
+		},
+		{
+			NewFunctionCall("POLY_LENGTH", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("日本語"))}),
+			3.0,
+			nil,
+		},
+		{
+			NewFunctionCall("CONTAINS", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo wörld")), NewFunctionArgExpression(NewLiteralString("wö"))}),
+			true,
+			nil,
+		},
+		{
+			NewFunctionCall("CONTAINS", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo wörld")), NewFunctionArgExpression(NewLiteralString("wo"))}),
+			false,
+			nil,
+		},
+		{
+			NewFunctionCall("CONTAINS", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("dne")), NewFunctionArgExpression(NewLiteralString("wo"))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("POSITION", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo wörld")), NewFunctionArgExpression(NewLiteralString("wö"))}),
+			6.0,
+			nil,
+		},
+		{
+			NewFunctionCall("POSITION", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo wörld")), NewFunctionArgExpression(NewLiteralString("x"))}),
+			-1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("REPLACE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ça va, ça va")), NewFunctionArgExpression(NewLiteralString("ça")), NewFunctionArgExpression(NewLiteralString("it"))}),
+			"it va, it va",
+			nil,
+		},
+		{
+			NewFunctionCall("REPLACE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ça va, ça va")), NewFunctionArgExpression(NewLiteralString("ça")), NewFunctionArgExpression(NewLiteralString("it")), NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			"it va, ça va",
+			nil,
+		},
+		{
+			NewFunctionCall("REPLACE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ça va")), NewFunctionArgExpression(NewLiteralString("ça")), NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("REPEAT", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ab")), NewFunctionArgExpression(NewLiteralNumber(3.0))}),
+			"ababab",
+			nil,
+		},
+		{
+			NewFunctionCall("REPEAT", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ab")), NewFunctionArgExpression(NewLiteralNumber(-1.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("REVERSE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("héllo"))}),
+			"olléh",
+			nil,
+		},
+		{
+			NewFunctionCall("REVERSE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("he\u0301llo"))}),
+			"olle\u0301h",
+			nil,
+		},
+		{
+			NewFunctionCall("REVERSE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("a\U0001F468\u200d\U0001F469b"))}),
+			"b\U0001F468\u200d\U0001F469a",
+			nil,
+		},
+		{
+			NewFunctionCall("REPLACE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("a-b-c")), NewFunctionArgExpression(NewLiteralString("-")), NewFunctionArgExpression(NewLiteralString("+")), NewFunctionArgExpression(NewLiteralNumber(1e19))}),
+			"a+b+c",
+			nil,
+		},
+		{
+			NewFunctionCall("LPAD", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("日本")), NewFunctionArgExpression(NewLiteralNumber(5.0)), NewFunctionArgExpression(NewLiteralString("ab"))}),
+			"aba日本",
+			nil,
+		},
+		{
+			NewFunctionCall("LPAD", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("7")), NewFunctionArgExpression(NewLiteralNumber(3.0))}),
+			"  7",
+			nil,
+		},
+		{
+			NewFunctionCall("RPAD", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("日本")), NewFunctionArgExpression(NewLiteralNumber(4.0)), NewFunctionArgExpression(NewLiteralString("語"))}),
+			"日本語語",
+			nil,
+		},
+		{
+			NewFunctionCall("RPAD", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("hello")), NewFunctionArgExpression(NewLiteralNumber(3.0))}),
+			"hel",
+			nil,
+		},
+		{
+			NewFunctionCall("INITCAP", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("élan VITAL of o'neil"))}),
+			"Élan Vital Of O'neil",
+			nil,
+		},
+		{
+			NewFunctionCall("TITLE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ÅSA-lena"))}),
+			"Åsa-Lena",
+			nil,
+		},
+		{
+			NewFunctionCall("SUFFIXES", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("añb"))}),
+			[]interface{}{"añb", "ñb", "b"},
+			nil,
+		},
+		{
+			NewFunctionCall("TOKENS", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("José, josé & Díaz"))}),
+			[]interface{}{"José", "josé", "Díaz"},
+			nil,
+		},
+		{
+			NewFunctionCall("TOKENS", FunctionArgExpressionList{
+				NewFunctionArgExpression(
+					NewLiteralObject(map[string]Expression{
+						"name": NewLiteralString("José Díaz"),
+						"tags": NewLiteralArray(ExpressionList{
+							NewLiteralString("JOSÉ"),
+							NewLiteralNumber(30.0),
+							NewLiteralBool(true)})})),
+				NewFunctionArgExpression(
+					NewLiteralObject(map[string]Expression{
+						"case":  NewLiteralString("lower"),
+						"names": NewLiteralBool(false)}))}),
+			[]interface{}{"josé", "díaz", 30.0, true},
+			nil,
+		},
+		{
+			NewFunctionCall("REPEAT", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("ab")), NewFunctionArgExpression(NewLiteralNumber(1e7))}),
+			nil,
+			fmt.Errorf("the REPEAT() function cannot return more than 10000000 characters"),
+		},
+		{
+			NewFunctionCall("REPEAT", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("")), NewFunctionArgExpression(NewLiteralNumber(1e19))}),
+			nil,
+			fmt.Errorf("the REPEAT() function cannot return more than 10000000 characters"),
+		},
 		{
 			NewFunctionCall("SUBSTR", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("hello")), NewFunctionArgExpression(NewLiteralNumber(0.0)), NewFunctionArgExpression(NewLiteralString("bob"))}),
 			nil,
@@ -463,6 +641,221 @@ func TestFunction(t *testing.T) {
 			nil,
 		},
 
+		// array inspection functions
+		{
+			NewFunctionCall("ARRAY_CONTAINS", FunctionArgExpressionList{mixedArray, NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			true,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_CONTAINS", FunctionArgExpressionList{mixedArray, NewFunctionArgExpression(NewLiteralString("b"))}),
+			false,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_CONTAINS", FunctionArgExpressionList{missingArg, NewFunctionArgExpression(NewLiteralString("b"))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_POSITION", FunctionArgExpressionList{mixedArray, NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			3.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_POSITION", FunctionArgExpressionList{mixedArray, NewFunctionArgExpression(NewLiteralNumber(7.0))}),
+			-1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_POSITION", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralString("a")), NewFunctionArgExpression(NewLiteralString("a"))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_SUM", FunctionArgExpressionList{mixedArray}),
+			7.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_SUM", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralArray(ExpressionList{}))}),
+			0.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_SUM", FunctionArgExpressionList{missingArg}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_AVG", FunctionArgExpressionList{mixedArray}),
+			7.0 / 3.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_AVG", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralArray(ExpressionList{NewLiteralString("a")}))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_MIN", FunctionArgExpressionList{mixedArray}),
+			1.0,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_MAX", FunctionArgExpressionList{mixedArray}),
+			"a",
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_MAX", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralArray(ExpressionList{NewLiteralNull()}))}),
+			nil,
+			nil,
+		},
+
+		// array construction functions
+		{
+			NewFunctionCall("ARRAY_DISTINCT", FunctionArgExpressionList{mixedArray}),
+			[]interface{}{3.0, "a", nil, 1.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_SORT", FunctionArgExpressionList{mixedArray}),
+			[]interface{}{nil, 1.0, 3.0, 3.0, "a"},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_REVERSE", FunctionArgExpressionList{mixedArray}),
+			[]interface{}{3.0, 1.0, nil, "a", 3.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_INTERSECT", FunctionArgExpressionList{
+				mixedArray,
+				NewFunctionArgExpression(
+					NewLiteralArray(ExpressionList{
+						NewLiteralNumber(1.0),
+						NewLiteralNumber(3.0),
+						NewLiteralNumber(3.0),
+						NewLiteralNumber(4.0)}))}),
+			[]interface{}{3.0, 1.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_INTERSECT", FunctionArgExpressionList{mixedArray, NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_RANGE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(0.0)), NewFunctionArgExpression(NewLiteralNumber(4.0))}),
+			[]interface{}{0.0, 1.0, 2.0, 3.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_RANGE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(10.0)), NewFunctionArgExpression(NewLiteralNumber(0.0)), NewFunctionArgExpression(NewLiteralNumber(-4.0))}),
+			[]interface{}{10.0, 6.0, 2.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_RANGE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(4.0)), NewFunctionArgExpression(NewLiteralNumber(0.0))}),
+			[]interface{}{},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_RANGE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(0.0)), NewFunctionArgExpression(NewLiteralNumber(4.0)), NewFunctionArgExpression(NewLiteralNumber(0.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_RANGE", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(0.0)), NewFunctionArgExpression(NewLiteralNumber(1e12))}),
+			nil,
+			fmt.Errorf("the ARRAY_RANGE() function cannot return more than 1000000 elements"),
+		},
+		{
+			NewFunctionCall("ARRAY_FLATTEN", FunctionArgExpressionList{nestedArray}),
+			[]interface{}{1.0, 2.0, []interface{}{3.0}},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_FLATTEN", FunctionArgExpressionList{nestedArray, NewFunctionArgExpression(NewLiteralNumber(-1.0))}),
+			[]interface{}{1.0, 2.0, 3.0},
+			nil,
+		},
+		{
+			NewFunctionCall("ARRAY_FLATTEN", FunctionArgExpressionList{nestedArray, NewFunctionArgExpression(NewLiteralNumber(0.5))}),
+			nil,
+			nil,
+		},
+
+		// object functions
+		{
+			NewFunctionCall("OBJECT_KEYS", FunctionArgExpressionList{sampleObject}),
+			[]interface{}{"a", "b"},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_VALUES", FunctionArgExpressionList{sampleObject}),
+			[]interface{}{"x", 2.0},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_PAIRS", FunctionArgExpressionList{sampleObject}),
+			[]interface{}{
+				map[string]interface{}{"name": "a", "value": "x"},
+				map[string]interface{}{"name": "b", "value": 2.0}},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_KEYS", FunctionArgExpressionList{mixedArray}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_PUT", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralString("a")), NewFunctionArgExpression(NewLiteralBool(true))}),
+			map[string]interface{}{"a": true, "b": 2.0},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_PUT", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralString("c")), missingArg}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_ADD", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralString("a")), NewFunctionArgExpression(NewLiteralBool(true))}),
+			map[string]interface{}{"a": "x", "b": 2.0},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_ADD", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralString("c")), NewFunctionArgExpression(NewLiteralBool(true))}),
+			map[string]interface{}{"a": "x", "b": 2.0, "c": true},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_REMOVE", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralString("a"))}),
+			map[string]interface{}{"b": 2.0},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_REMOVE", FunctionArgExpressionList{sampleObject, NewFunctionArgExpression(NewLiteralNumber(1.0))}),
+			nil,
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_CONCAT", FunctionArgExpressionList{
+				sampleObject,
+				NewFunctionArgExpression(
+					NewLiteralObject(map[string]Expression{
+						"a": NewLiteralNumber(1.0),
+						"c": NewLiteralNumber(3.0)}))}),
+			map[string]interface{}{"a": 1.0, "b": 2.0, "c": 3.0},
+			nil,
+		},
+		{
+			NewFunctionCall("OBJECT_CONCAT", FunctionArgExpressionList{sampleObject, mixedArray}),
+			nil,
+			nil,
+		},
+
 		// numeric functions
 		{
 			NewFunctionCall("CEIL", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(5.8))}),
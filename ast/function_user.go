@@ -0,0 +1,389 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// FunctionDefinition is a function written in N1QL, its body is an
+// expression over its parameters
+type FunctionDefinition struct {
+	Name       string     `json:"name"`
+	Parameters []string   `json:"parameters"`
+	Body       Expression `json:"-"`
+	// the body as it was written, so it can be parsed again
+	Text string `json:"body"`
+}
+
+func NewFunctionDefinition(name string, parameters []string, body Expression) *FunctionDefinition {
+	return &FunctionDefinition{
+		Name:       name,
+		Parameters: parameters,
+		Body:       body,
+	}
+}
+
+// Verify checks that the function does not hide a system function,
+// and that its body only refers to its parameters
+func (this *FunctionDefinition) Verify() error {
+	if SystemFunctionRegistry[strings.ToUpper(this.Name)] != nil {
+		return fmt.Errorf("%s is the name of a system function", this.Name)
+	}
+
+	seen := make(map[string]bool, len(this.Parameters))
+	for _, parameter := range this.Parameters {
+		if seen[parameter] {
+			return fmt.Errorf("parameter %s of function %s appears more than once", parameter, this.Name)
+		}
+		seen[parameter] = true
+	}
+
+	if this.Body == nil {
+		return fmt.Errorf("function %s has no body", this.Name)
+	}
+	_, err := this.Body.Accept(NewExpressionValidatorNoAggregates())
+	if err != nil {
+		return err
+	}
+	_, err = this.Body.Accept(&functionBodyVerifier{})
+	if err != nil {
+		return fmt.Errorf("the body of function %s %v", this.Name, err)
+	}
+	// with no aliases every reference would be accepted, the empty
+	// alias matches no reference
+	aliases := this.Parameters
+	if len(aliases) == 0 {
+		aliases = []string{""}
+	}
+	_, err = this.Body.Copy().Accept(NewExpressionFormalNotationConverter(nil, aliases, ""))
+	if err != nil {
+		return fmt.Errorf("the body of function %s may only refer to its parameters: %v", this.Name, err)
+	}
+	return nil
+}
+
+// the body is evaluated outside of any query, so it cannot run subqueries
+type functionBodyVerifier struct{}
+
+func (this *functionBodyVerifier) Visit(e Expression) (Expression, error) {
+	_, ok := e.(*Subquery)
+	if ok {
+		return e, fmt.Errorf("cannot contain a subquery")
+	}
+	return VisitChildren(this, e)
+}
+
+// functions added at runtime, either defined in N1QL with
+// CREATE FUNCTION or registered by the application embedding us
+type userFunction struct {
+	name        string
+	constructor FunctionCallConstructor
+	// nil for functions registered from go
+	definition *FunctionDefinition
+	sequence   int
+}
+
+var userFunctionMutex sync.RWMutex
+var userFunctionRegistry = make(map[string]*userFunction)
+var userFunctionSequence = 0
+
+// RegisterFunction adds a function implemented in go, it is meant
+// to be called by applications embedding the query engine before
+// they start serving queries.  the name must not be taken by a
+// system function or by another user function
+func RegisterFunction(name string, constructor FunctionCallConstructor) error {
+	if SystemFunctionRegistry[strings.ToUpper(name)] != nil {
+		return fmt.Errorf("%s is the name of a system function", name)
+	}
+	return addUserFunction(&userFunction{name: name, constructor: constructor})
+}
+
+// DefineFunction adds a function written in N1QL
+func DefineFunction(definition *FunctionDefinition) error {
+	err := definition.Verify()
+	if err != nil {
+		return err
+	}
+	return addUserFunction(&userFunction{
+		name: definition.Name,
+		constructor: func(operands FunctionArgExpressionList) FunctionCallExpression {
+			return NewFunctionCallUser(definition, operands)
+		},
+		definition: definition,
+	})
+}
+
+func addUserFunction(function *userFunction) error {
+	userFunctionMutex.Lock()
+	defer userFunctionMutex.Unlock()
+
+	key := strings.ToUpper(function.name)
+	_, exists := userFunctionRegistry[key]
+	if exists {
+		return fmt.Errorf("function %s already exists", function.name)
+	}
+	userFunctionSequence++
+	function.sequence = userFunctionSequence
+	userFunctionRegistry[key] = function
+	return nil
+}
+
+// DropFunction removes a function written in N1QL, statements
+// already parsed keep their inlined copy of it.  a function called
+// by another one cannot be dropped, the body of the other one could
+// not be parsed again after a restart
+func DropFunction(name string) error {
+	userFunctionMutex.Lock()
+	defer userFunctionMutex.Unlock()
+
+	key := strings.ToUpper(name)
+	function, exists := userFunctionRegistry[key]
+	if !exists {
+		return fmt.Errorf("function %s does not exist", name)
+	}
+	if function.definition == nil {
+		return fmt.Errorf("function %s is registered by the application and cannot be dropped", name)
+	}
+	for _, other := range userFunctionRegistry {
+		if other.definition != nil && other != function && callsFunction(other.definition.Body, function.definition) {
+			return fmt.Errorf("function %s is called by function %s and cannot be dropped", name, other.name)
+		}
+	}
+	delete(userFunctionRegistry, key)
+	return nil
+}
+
+// LookupUserFunction finds a user function by name, the definition
+// is nil for functions registered from go
+func LookupUserFunction(name string) (*FunctionDefinition, bool) {
+	userFunctionMutex.RLock()
+	defer userFunctionMutex.RUnlock()
+
+	function, exists := userFunctionRegistry[strings.ToUpper(name)]
+	if !exists {
+		return nil, false
+	}
+	return function.definition, true
+}
+
+// UserFunctionNames lists the user functions in the order they were added
+func UserFunctionNames() []string {
+	userFunctionMutex.RLock()
+	defer userFunctionMutex.RUnlock()
+
+	functions := make(userFunctionsBySequence, 0, len(userFunctionRegistry))
+	for _, function := range userFunctionRegistry {
+		functions = append(functions, function)
+	}
+	sort.Sort(functions)
+	rv := make([]string, len(functions))
+	for i, function := range functions {
+		rv[i] = function.name
+	}
+	return rv
+}
+
+// UserFunctionDefinitions lists the functions written in N1QL, in the
+// order they were defined, so that the functions a body calls come
+// before it
+func UserFunctionDefinitions() []*FunctionDefinition {
+	rv := []*FunctionDefinition{}
+	for _, name := range UserFunctionNames() {
+		definition, _ := LookupUserFunction(name)
+		if definition != nil {
+			rv = append(rv, definition)
+		}
+	}
+	return rv
+}
+
+func lookupUserFunctionConstructor(name string) FunctionCallConstructor {
+	userFunctionMutex.RLock()
+	defer userFunctionMutex.RUnlock()
+
+	function, exists := userFunctionRegistry[strings.ToUpper(name)]
+	if !exists {
+		return nil
+	}
+	return function.constructor
+}
+
+// finds the calls of a function written in N1QL
+type functionCallFinder struct {
+	definition *FunctionDefinition
+	found      bool
+}
+
+func (this *functionCallFinder) Visit(e Expression) (Expression, error) {
+	call, ok := e.(*FunctionCallUser)
+	if ok && call.definition == this.definition {
+		this.found = true
+		return e, nil
+	}
+	return VisitChildren(this, e)
+}
+
+func callsFunction(e Expression, definition *FunctionDefinition) bool {
+	finder := &functionCallFinder{definition: definition}
+	e.Accept(finder)
+	return finder.found
+}
+
+type userFunctionsBySequence []*userFunction
+
+func (this userFunctionsBySequence) Len() int           { return len(this) }
+func (this userFunctionsBySequence) Swap(i, j int)      { this[i], this[j] = this[j], this[i] }
+func (this userFunctionsBySequence) Less(i, j int) bool { return this[i].sequence < this[j].sequence }
+
+// FunctionCallUser is a call of a function written in N1QL, the
+// body is evaluated with the parameters bound to the operands
+type FunctionCallUser struct {
+	FunctionCall
+	definition *FunctionDefinition
+}
+
+func NewFunctionCallUser(definition *FunctionDefinition, operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallUser{
+		FunctionCall{
+			Type:     "function",
+			Name:     definition.Name,
+			Operands: operands,
+			minArgs:  len(definition.Parameters),
+			maxArgs:  len(definition.Parameters),
+		},
+		definition,
+	}
+}
+
+func (this *FunctionCallUser) Copy() Expression {
+	return NewFunctionCallUser(this.definition, this.Operands.Copy())
+}
+
+// the inherited check ignores functions without arguments
+func (this *FunctionCallUser) ValidateArity() error {
+	count := len(this.definition.Parameters)
+	if len(this.Operands) != count {
+		argMessage := "arguments"
+		if count == 1 {
+			argMessage = "argument"
+		}
+		return fmt.Errorf("the %s() function requires exactly %d %s", this.Name, count, argMessage)
+	}
+	return nil
+}
+
+func (this *FunctionCallUser) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	parameters := make(map[string]*dparval.Value, len(this.definition.Parameters))
+	for i, parameter := range this.definition.Parameters {
+		av, err := this.Operands[i].Expr.Evaluate(item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				// a missing argument leaves the parameter missing
+				continue
+			default:
+				return nil, err
+			}
+		}
+		parameters[parameter] = av
+	}
+	return this.definition.Body.Evaluate(dparval.NewValue(parameters))
+}
+
+// Inline returns the body with the parameters replaced by the operands
+func (this *FunctionCallUser) Inline() Expression {
+	substitutions := make(map[string]Expression, len(this.definition.Parameters))
+	for i, parameter := range this.definition.Parameters {
+		if i < len(this.Operands) && this.Operands[i].Expr != nil {
+			substitutions[parameter] = this.Operands[i].Expr
+		}
+	}
+	rv, err := this.definition.Body.Copy().Accept(&parameterSubstituter{substitutions})
+	if err != nil {
+		return this.definition.Body
+	}
+	return rv
+}
+
+// EXPLAIN shows the inlined body next to the call
+func (this *FunctionCallUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":     this.Type,
+		"name":     this.Name,
+		"operands": this.Operands,
+		"distinct": this.Distinct,
+		"body":     this.Inline(),
+	})
+}
+
+func (this *FunctionCallUser) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// replaces references to parameters by copies of the operands
+type parameterSubstituter struct {
+	substitutions map[string]Expression
+}
+
+func (this *parameterSubstituter) Visit(e Expression) (Expression, error) {
+	switch expr := e.(type) {
+	case *Property:
+		substitution, ok := this.substitutions[expr.Path]
+		if ok {
+			return substitution.Copy(), nil
+		}
+		return e, nil
+	case *DotMemberOperator:
+		// the right side names a field, not a parameter
+		var err error
+		expr.Left, err = expr.Left.Accept(this)
+		return e, err
+	case CollectionOperatorExpression:
+		newOver, err := expr.GetOver().Accept(this)
+		if err != nil {
+			return e, err
+		}
+		expr.SetOver(newOver)
+		// the variable of the collection hides a parameter of the same name
+		inner := this
+		_, hidden := this.substitutions[expr.GetAs()]
+		if hidden {
+			inner = &parameterSubstituter{make(map[string]Expression, len(this.substitutions))}
+			for name, substitution := range this.substitutions {
+				if name != expr.GetAs() {
+					inner.substitutions[name] = substitution
+				}
+			}
+		}
+		if expr.GetCondition() != nil {
+			newCondition, err := expr.GetCondition().Accept(inner)
+			if err != nil {
+				return e, err
+			}
+			expr.SetCondition(newCondition)
+		}
+		if expr.GetOutput() != nil {
+			newOutput, err := expr.GetOutput().Accept(inner)
+			if err != nil {
+				return e, err
+			}
+			expr.SetOutput(newOutput)
+		}
+		return e, nil
+	}
+	return VisitChildren(this, e)
+}
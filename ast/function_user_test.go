@@ -0,0 +1,170 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+type FunctionCallTwice struct {
+	FunctionCall
+}
+
+func NewFunctionCallTwice(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallTwice{
+		FunctionCall{
+			Type:     "function",
+			Name:     "TWICE",
+			Operands: operands,
+			minArgs:  1,
+			maxArgs:  1,
+		},
+	}
+}
+
+func (this *FunctionCallTwice) Copy() Expression {
+	return NewFunctionCallTwice(this.Operands.Copy())
+}
+
+func (this *FunctionCallTwice) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	av, err := this.Operands[0].Expr.Evaluate(item)
+	if err != nil {
+		return nil, err
+	}
+	number, ok := av.Value().(float64)
+	if !ok {
+		return dparval.NewValue(nil), nil
+	}
+	return dparval.NewValue(2 * number), nil
+}
+
+func (this *FunctionCallTwice) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+func TestRegisterFunction(t *testing.T) {
+	err := RegisterFunction("twice", NewFunctionCallTwice)
+	if err != nil {
+		t.Fatalf("unexpected error registering function: %v", err)
+	}
+	defer delete(userFunctionRegistry, "TWICE")
+
+	err = RegisterFunction("TWICE", NewFunctionCallTwice)
+	if err == nil {
+		t.Errorf("expected error registering a function twice")
+	}
+	err = RegisterFunction("upper", NewFunctionCallTwice)
+	if err == nil {
+		t.Errorf("expected error registering a function over a system function")
+	}
+
+	call := NewFunctionCall("Twice", FunctionArgExpressionList{NewFunctionArgExpression(NewLiteralNumber(21.0))})
+	result, err := call.Evaluate(dparval.NewValue(map[string]interface{}{}))
+	if err != nil || result.Value() != 42.0 {
+		t.Errorf("expected 42, got %v, err: %v", result, err)
+	}
+
+	// functions from go cannot be dropped
+	err = DropFunction("twice")
+	if err == nil {
+		t.Errorf("expected error dropping a function registered from go")
+	}
+	definition, ok := LookupUserFunction("twice")
+	if !ok || definition != nil {
+		t.Errorf("expected a function without definition, got %v", definition)
+	}
+}
+
+func TestDefineFunction(t *testing.T) {
+	// full(x, y) = x + y + z.a
+	body := NewPlusOperator(NewPlusOperator(NewProperty("x"), NewProperty("y")), NewDotMemberOperator(NewProperty("z"), NewProperty("a")))
+	definition := NewFunctionDefinition("full", []string{"x", "y", "z"}, body)
+	err := DefineFunction(definition)
+	if err != nil {
+		t.Fatalf("unexpected error defining function: %v", err)
+	}
+	defer DropFunction("full")
+
+	err = DefineFunction(NewFunctionDefinition("FULL", []string{}, NewLiteralNumber(1.0)))
+	if err == nil {
+		t.Errorf("expected error defining a function twice")
+	}
+
+	invalid := []*FunctionDefinition{
+		NewFunctionDefinition("lower", []string{"x"}, NewProperty("x")),
+		NewFunctionDefinition("dup", []string{"x", "x"}, NewProperty("x")),
+		NewFunctionDefinition("other", []string{"x"}, NewProperty("y")),
+		NewFunctionDefinition("none", []string{}, NewProperty("y")),
+		NewFunctionDefinition("agg", []string{"x"}, NewFunctionCall("SUM", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("x"))})),
+		NewFunctionDefinition("unknown", []string{"x"}, NewFunctionCall("NOTHING", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("x"))})),
+	}
+	for _, definition := range invalid {
+		err = DefineFunction(definition)
+		if err == nil {
+			t.Errorf("expected error defining %v", definition.Name)
+			DropFunction(definition.Name)
+		}
+	}
+
+	document := dparval.NewValue(map[string]interface{}{"a": 1.0, "b": 2.0, "c": map[string]interface{}{"a": 3.0}})
+	call := NewFunctionCall("FULL", FunctionArgExpressionList{
+		NewFunctionArgExpression(NewProperty("a")),
+		NewFunctionArgExpression(NewProperty("b")),
+		NewFunctionArgExpression(NewProperty("c")),
+	})
+	result, err := call.Evaluate(document)
+	if err != nil || result.Value() != 6.0 {
+		t.Errorf("expected 6, got %v, err: %v", result, err)
+	}
+
+	err = call.ValidateArity()
+	if err != nil {
+		t.Errorf("unexpected arity error: %v", err)
+	}
+	short := NewFunctionCall("full", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("a"))})
+	err = short.ValidateArity()
+	if err == nil {
+		t.Errorf("expected arity error")
+	}
+
+	// the inlined body names the operands, not the parameters
+	inlined := call.(*FunctionCallUser).Inline()
+	expected := NewPlusOperator(NewPlusOperator(NewProperty("a"), NewProperty("b")), NewDotMemberOperator(NewProperty("c"), NewProperty("a")))
+	if !inlined.EquivalentTo(expected) {
+		t.Errorf("expected inlined body %v, got %v", expected, inlined)
+	}
+	bytes, err := json.Marshal(call)
+	if err != nil || !strings.Contains(string(bytes), `"body":`) {
+		t.Errorf("expected the inlined body in %s, err: %v", bytes, err)
+	}
+
+	names := UserFunctionNames()
+	if !reflect.DeepEqual(names, []string{"full"}) {
+		t.Errorf("expected [full], got %v", names)
+	}
+
+	err = DropFunction("full")
+	if err != nil {
+		t.Errorf("unexpected error dropping function: %v", err)
+	}
+	_, ok := LookupUserFunction("full")
+	if ok {
+		t.Errorf("expected function to be dropped")
+	}
+	err = DropFunction("full")
+	if err == nil {
+		t.Errorf("expected error dropping a function twice")
+	}
+}
@@ -10,6 +10,8 @@
 package ast
 
 import (
+	"unicode/utf8"
+
 	"github.com/couchbaselabs/dparval"
 )
 
@@ -62,7 +64,7 @@ func (this *FunctionCallLength) Evaluate(item *dparval.Value) (*dparval.Value, e
 		avalue := av.Value()
 		switch avalue := avalue.(type) {
 		case string:
-			return dparval.NewValue(float64(len(avalue))), nil
+			return dparval.NewValue(float64(utf8.RuneCountInString(avalue))), nil
 		}
 	}
 	return dparval.NewValue(nil), nil
@@ -242,7 +244,7 @@ func (this *FunctionCallPolyLength) Evaluate(item *dparval.Value) (*dparval.Valu
 		avalue := av.Value()
 		switch avalue := avalue.(type) {
 		case string:
-			return dparval.NewValue(float64(len(avalue))), nil
+			return dparval.NewValue(float64(utf8.RuneCountInString(avalue))), nil
 		case []interface{}:
 			return dparval.NewValue(float64(len(avalue))), nil
 		case map[string]interface{}:
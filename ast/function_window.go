@@ -0,0 +1,262 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+type WindowFunctionCall struct {
+	FunctionCall
+}
+
+// window functions only have a value over a window
+func (this *WindowFunctionCall) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	return nil, fmt.Errorf("the %s() function requires an OVER clause", this.Name)
+}
+
+type FunctionCallRowNumber struct {
+	WindowFunctionCall
+}
+
+func NewFunctionCallRowNumber(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRowNumber{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "ROW_NUMBER",
+				Operands: operands,
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallRowNumber) Copy() Expression {
+	return &FunctionCallRowNumber{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "ROW_NUMBER",
+				Operands: this.Operands.Copy(),
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallRowNumber) EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error) {
+	return dparval.NewValue(float64(position + 1)), nil
+}
+
+func (this *FunctionCallRowNumber) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// peers share the rank, leaving a gap after them
+type FunctionCallRank struct {
+	WindowFunctionCall
+}
+
+func NewFunctionCallRank(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallRank{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "RANK",
+				Operands: operands,
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallRank) Copy() Expression {
+	return &FunctionCallRank{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "RANK",
+				Operands: this.Operands.Copy(),
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallRank) EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error) {
+	first, _ := partition.Peers(position)
+	return dparval.NewValue(float64(first + 1)), nil
+}
+
+func (this *FunctionCallRank) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// peers share the rank, without a gap after them
+type FunctionCallDenseRank struct {
+	WindowFunctionCall
+}
+
+func NewFunctionCallDenseRank(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallDenseRank{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "DENSE_RANK",
+				Operands: operands,
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallDenseRank) Copy() Expression {
+	return &FunctionCallDenseRank{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "DENSE_RANK",
+				Operands: this.Operands.Copy(),
+				minArgs:  0,
+				maxArgs:  0,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallDenseRank) EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error) {
+	return dparval.NewValue(float64(partition.PeerGroups[position] + 1)), nil
+}
+
+func (this *FunctionCallDenseRank) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// LAG() and LEAD() evaluate their first argument on the item the
+// offset (1 by default) before or after the current one, or return
+// the default (NULL by default) when there is no such item
+type FunctionCallLag struct {
+	WindowFunctionCall
+	direction int
+}
+
+func NewFunctionCallLag(operands FunctionArgExpressionList) FunctionCallExpression {
+	return newFunctionCallLagLead("LAG", operands, -1)
+}
+
+func NewFunctionCallLead(operands FunctionArgExpressionList) FunctionCallExpression {
+	return newFunctionCallLagLead("LEAD", operands, 1)
+}
+
+func newFunctionCallLagLead(name string, operands FunctionArgExpressionList, direction int) *FunctionCallLag {
+	return &FunctionCallLag{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     name,
+				Operands: operands,
+				minArgs:  1,
+				maxArgs:  3,
+			},
+		},
+		direction,
+	}
+}
+
+func (this *FunctionCallLag) Copy() Expression {
+	return newFunctionCallLagLead(this.Name, this.Operands.Copy(), this.direction)
+}
+
+func (this *FunctionCallLag) EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error) {
+	item := partition.Items[position]
+	offset := 1
+	if len(this.Operands) > 1 {
+		ov, err := this.Operands[1].Expr.Evaluate(item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				return dparval.NewValue(nil), nil
+			default:
+				return nil, err
+			}
+		}
+		offsetValue, ok := ov.Value().(float64)
+		if !ok || offsetValue < 0 || offsetValue != float64(int(offsetValue)) {
+			return dparval.NewValue(nil), nil
+		}
+		offset = int(offsetValue)
+	}
+
+	other := position + this.direction*offset
+	if other < 0 || other >= len(partition.Items) {
+		if len(this.Operands) > 2 {
+			return this.Operands[2].Expr.Evaluate(item)
+		}
+		return dparval.NewValue(nil), nil
+	}
+	return this.Operands[0].Expr.Evaluate(partition.Items[other])
+}
+
+func (this *FunctionCallLag) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the value of the argument for the first item of the frame
+type FunctionCallFirstValue struct {
+	WindowFunctionCall
+}
+
+func NewFunctionCallFirstValue(operands FunctionArgExpressionList) FunctionCallExpression {
+	return &FunctionCallFirstValue{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "FIRST_VALUE",
+				Operands: operands,
+				minArgs:  1,
+				maxArgs:  1,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallFirstValue) Copy() Expression {
+	return &FunctionCallFirstValue{
+		WindowFunctionCall{
+			FunctionCall{
+				Type:     "function",
+				Name:     "FIRST_VALUE",
+				Operands: this.Operands.Copy(),
+				minArgs:  1,
+				maxArgs:  1,
+			},
+		},
+	}
+}
+
+func (this *FunctionCallFirstValue) EvaluateWindow(window *WindowDefinition, partition *WindowPartition, position int) (*dparval.Value, error) {
+	start, end := window.FrameOf(partition, position)
+	if start == end {
+		return dparval.NewValue(nil), nil
+	}
+	return this.Operands[0].Expr.Evaluate(partition.Items[start])
+}
+
+func (this *FunctionCallFirstValue) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
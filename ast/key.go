@@ -36,35 +36,65 @@ func (this *KeyExpression) Validate() error {
 		return err
 	}
 
+	if this.HasParameters() {
+		// the keys are not known until the statement is run
+		this.Keys = nil
+		return nil
+	}
+
 	val, err := this.Expr.Evaluate(dparval.NewValue(map[string]interface{}{}))
 
 	if err != nil {
 		return nil
 	}
 
+	// create the keylist here since we have evaluated the expression
+	this.Keys, err = this.keysOf(val)
+	return err
+}
+
+// HasParameters is true when the keys depend on the parameters of the
+// request, and have to be evaluated each time the statement is run
+func (this *KeyExpression) HasParameters() bool {
+	return ContainsParameters(this.Expr)
+}
+
+// EvaluateKeys evaluates the expression for an item carrying the query
+func (this *KeyExpression) EvaluateKeys(item *dparval.Value) ([]string, error) {
+	val, err := this.Expr.Evaluate(item)
+	if err != nil {
+		return nil, err
+	}
+	return this.keysOf(val)
+}
+
+func (this *KeyExpression) keysOf(val *dparval.Value) ([]string, error) {
 	if val.Type() == dparval.ARRAY && this.Type == "KEY" {
-		return fmt.Errorf("KEY expression used with multiple values")
+		return nil, fmt.Errorf("KEY expression used with multiple values")
 	}
 
 	if val.Type() == dparval.STRING && this.Type == "KEYS" {
-		return fmt.Errorf("KEYS expression used with a single value")
+		return nil, fmt.Errorf("KEYS expression used with a single value")
 	}
 
-	// create the keylist here since we have evaluated the expression
-	this.Keys = make([]string, 0)
+	keys := make([]string, 0)
 
 	if val.Type() == dparval.ARRAY {
 		keylist := val.Value()
 		for _, key := range keylist.([]interface{}) {
-			this.Keys = append(this.Keys, key.(string))
+			skey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("KEYS expression contains a key that is not a string: %v", key)
+			}
+			keys = append(keys, skey)
 		}
 	}
 
 	if val.Type() == dparval.STRING {
-		this.Keys = append(this.Keys, val.Value().(string))
+		keys = append(keys, val.Value().(string))
 	}
 
-	return nil
+	return keys, nil
 }
 
 func (this *KeyExpression) GetKeys() []string {
@@ -43,7 +43,7 @@ func (this SortExpressionList) Simplify() error {
 
 func (this SortExpressionList) Validate() error {
 	var err error
-	validator := NewExpressionValidator()
+	validator := NewExpressionValidatorWithWindows()
 	for _, orderExpr := range this {
 		if orderExpr.Expr != nil {
 			orderExpr.Expr, err = orderExpr.Expr.Accept(validator)
@@ -103,6 +103,14 @@ func (this SortExpressionList) findAggregateFunctionReferences() ExpressionList
 	return af.GetAggregates()
 }
 
+func (this SortExpressionList) findWindowReferences() ExpressionList {
+	wf := NewExpressionWindowFinder()
+	for _, orderExpr := range this {
+		orderExpr.Expr.Accept(wf)
+	}
+	return wf.GetWindows()
+}
+
 func (this SortExpressionList) String() string {
 	rv := ""
 	for i, expr := range this {
@@ -0,0 +1,97 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+// a $1 or $name in the statement, its value is given with each
+// request, so a prepared statement can be run with other values
+type Parameter struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func NewParameter(name string) *Parameter {
+	return &Parameter{
+		Type: "parameter",
+		Name: name,
+	}
+}
+
+func (this *Parameter) Copy() Expression {
+	return &Parameter{
+		Type: "parameter",
+		Name: this.Name,
+	}
+}
+
+func (this *Parameter) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	if item != nil {
+		query, ok := item.GetAttachment("query").(network.Query)
+		if ok {
+			request, ok := query.Request().(network.ParameterizedRequest)
+			if ok {
+				val, ok := request.ParameterValue(this.Name)
+				if ok {
+					return dparval.NewValue(val), nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("No value given for parameter %v", this)
+}
+
+func (this *Parameter) String() string {
+	return fmt.Sprintf("$%s", this.Name)
+}
+
+func (this *Parameter) EquivalentTo(t Expression) bool {
+	that, ok := t.(*Parameter)
+	if !ok {
+		return false
+	}
+	return this.Name == that.Name
+}
+
+// the value is not known until the statement is run, so a parameter
+// is never simplified away, but it is the same for every document
+func (this *Parameter) Dependencies() ExpressionList {
+	return ExpressionList{}
+}
+
+func (this *Parameter) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// this ExpressionVisitor looks for parameters in the expression
+type ExpressionParameterFinder struct {
+	found bool
+}
+
+func (this *ExpressionParameterFinder) Visit(e Expression) (Expression, error) {
+	switch e.(type) {
+	case *Parameter:
+		this.found = true
+		return e, nil
+	default:
+		return VisitChildren(this, e)
+	}
+}
+
+func ContainsParameters(e Expression) bool {
+	finder := &ExpressionParameterFinder{}
+	e.Accept(finder)
+	return finder.found
+}
@@ -78,7 +78,7 @@ func (this ResultExpressionList) Simplify() error {
 
 func (this ResultExpressionList) Validate() error {
 	var err error
-	validator := NewExpressionValidator()
+	validator := NewExpressionValidatorWithWindows()
 	for _, resultExpr := range this {
 		if resultExpr.Expr != nil {
 			resultExpr.Expr, err = resultExpr.Expr.Accept(validator)
@@ -120,6 +120,16 @@ func (this ResultExpressionList) findAggregateFunctionReferences() ExpressionLis
 	return af.GetAggregates()
 }
 
+func (this ResultExpressionList) findWindowReferences() ExpressionList {
+	wf := NewExpressionWindowFinder()
+	for _, resultExpr := range this {
+		if resultExpr.Expr != nil {
+			resultExpr.Expr.Accept(wf)
+		}
+	}
+	return wf.GetWindows()
+}
+
 // this function should be called before assigning default names
 // it should check to see if any explicitly named aliases are duplicated
 // if so, this is an error
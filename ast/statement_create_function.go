@@ -0,0 +1,40 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import ()
+
+// CREATE FUNCTION name(parameters) { expression }
+type CreateFunctionStatement struct {
+	Definition  *FunctionDefinition `json:"definition"`
+	ExplainOnly bool                `json:"explain"`
+}
+
+func NewCreateFunctionStatement(definition *FunctionDefinition) *CreateFunctionStatement {
+	return &CreateFunctionStatement{
+		Definition: definition,
+	}
+}
+
+func (this *CreateFunctionStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *CreateFunctionStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *CreateFunctionStatement) VerifySemantics() error {
+	return this.Definition.Verify()
+}
+
+func (this *CreateFunctionStatement) Simplify() error {
+	return nil
+}
@@ -0,0 +1,114 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+)
+
+// DeleteStatement removes the documents of a bucket
+// selected by the KEYS and WHERE clauses
+type DeleteStatement struct {
+	ExplainOnly bool           `json:"explain"`
+	Pool        string         `json:"pool"`
+	Bucket      string         `json:"bucket"`
+	As          string         `json:"as"`
+	Keys        *KeyExpression `json:"keys"`
+	Where       Expression     `json:"where"`
+	Limit       int            `json:"limit"`
+}
+
+func NewDeleteStatement() *DeleteStatement {
+	return &DeleteStatement{
+		Limit: -1,
+	}
+}
+
+func (this *DeleteStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *DeleteStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *DeleteStatement) GetAlias() string {
+	if this.As != "" {
+		return this.As
+	}
+	return this.Bucket
+}
+
+func (this *DeleteStatement) VerifySemantics() error {
+	var err error
+	this.Keys, this.Where, err = verifyMutationSource(this.GetAlias(), this.Keys, this.Where)
+	return err
+}
+
+func (this *DeleteStatement) Simplify() error {
+	var err error
+	this.Keys, this.Where, err = simplifyMutationSource(this.Keys, this.Where)
+	return err
+}
+
+// the KEYS and WHERE clauses of UPDATE and DELETE
+// refer to the documents through the bucket alias
+func verifyMutationSource(alias string, keys *KeyExpression, where Expression) (*KeyExpression, Expression, error) {
+	var err error
+	formalNotation := NewExpressionFormalNotationConverter([]string{}, []string{alias}, alias)
+
+	if where != nil {
+		where, err = where.Accept(formalNotation)
+		if err != nil {
+			return keys, where, err
+		}
+		where, err = where.Accept(NewExpressionValidatorNoAggregates())
+		if err != nil {
+			return keys, where, err
+		}
+	}
+
+	if keys != nil {
+		keys.Expr, err = keys.Expr.Accept(formalNotation)
+		if err != nil {
+			return keys, where, err
+		}
+		err = keys.Validate()
+		if err != nil {
+			return keys, where, err
+		}
+		if !keys.HasParameters() && len(keys.GetKeys()) == 0 {
+			return keys, where, fmt.Errorf("KEY clause contains an invalid expression")
+		}
+	}
+
+	return keys, where, nil
+}
+
+func simplifyMutationSource(keys *KeyExpression, where Expression) (*KeyExpression, Expression, error) {
+	var err error
+	es := NewExpressionSimplifier()
+
+	if where != nil {
+		where, err = where.Accept(es)
+		if err != nil {
+			return keys, where, err
+		}
+	}
+
+	if keys != nil {
+		keys.Expr, err = keys.Expr.Accept(es)
+		if err != nil {
+			return keys, where, err
+		}
+	}
+
+	return keys, where, nil
+}
@@ -0,0 +1,39 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import ()
+
+type DropFunctionStatement struct {
+	Name        string `json:"name"`
+	ExplainOnly bool   `json:"explain"`
+}
+
+func NewDropFunctionStatement(name string) *DropFunctionStatement {
+	return &DropFunctionStatement{
+		Name: name,
+	}
+}
+
+func (this *DropFunctionStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *DropFunctionStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *DropFunctionStatement) VerifySemantics() error {
+	return nil
+}
+
+func (this *DropFunctionStatement) Simplify() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+)
+
+// InsertStatement is INSERT (or UPSERT) of key/value pairs into a bucket
+type InsertStatement struct {
+	ExplainOnly bool            `json:"explain"`
+	Upsert      bool            `json:"upsert"`
+	Pool        string          `json:"pool"`
+	Bucket      string          `json:"bucket"`
+	Values      InsertValueList `json:"values"`
+}
+
+type InsertValue struct {
+	Key   Expression `json:"key"`
+	Value Expression `json:"value"`
+}
+
+type InsertValueList []*InsertValue
+
+func NewInsertStatement() *InsertStatement {
+	return &InsertStatement{}
+}
+
+func NewInsertValue(key, value Expression) *InsertValue {
+	return &InsertValue{
+		Key:   key,
+		Value: value,
+	}
+}
+
+func (this *InsertStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *InsertStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *InsertStatement) VerifySemantics() error {
+	if len(this.Values) == 0 {
+		return fmt.Errorf("INSERT requires at least one value")
+	}
+
+	// there is no document in scope, the keys and values are
+	// evaluated on their own (like a SELECT without a FROM)
+	validator := NewExpressionValidatorNoAggregates()
+
+	var err error
+	for _, v := range this.Values {
+		v.Key, err = v.Key.Accept(validator)
+		if err != nil {
+			return err
+		}
+		v.Value, err = v.Value.Accept(validator)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *InsertStatement) Simplify() error {
+	var err error
+	es := NewExpressionSimplifier()
+	for _, v := range this.Values {
+		v.Key, err = v.Key.Accept(es)
+		if err != nil {
+			return err
+		}
+		v.Value, err = v.Value.Accept(es)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import ()
+
+// PREPARE name FROM statement, the statement is compiled once
+// and its plan kept under the name for EXECUTE
+type PrepareStatement struct {
+	Name        string    `json:"name"`
+	Statement   Statement `json:"statement"`
+	ExplainOnly bool      `json:"explain"`
+}
+
+func NewPrepareStatement(name string, statement Statement) *PrepareStatement {
+	return &PrepareStatement{
+		Name:      name,
+		Statement: statement,
+	}
+}
+
+func (this *PrepareStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *PrepareStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *PrepareStatement) VerifySemantics() error {
+	return this.Statement.VerifySemantics()
+}
+
+func (this *PrepareStatement) Simplify() error {
+	return this.Statement.Simplify()
+}
+
+// EXECUTE name, runs the plan of a prepared statement
+type ExecuteStatement struct {
+	Name        string `json:"name"`
+	ExplainOnly bool   `json:"explain"`
+}
+
+func NewExecuteStatement(name string) *ExecuteStatement {
+	return &ExecuteStatement{
+		Name: name,
+	}
+}
+
+func (this *ExecuteStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *ExecuteStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *ExecuteStatement) VerifySemantics() error {
+	return nil
+}
+
+func (this *ExecuteStatement) Simplify() error {
+	return nil
+}
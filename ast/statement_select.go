@@ -24,9 +24,13 @@ type SelectStatement struct {
 	Limit                     int                  `json:"limit"`
 	Offset                    int                  `json:"offset"`
 	ExplainOnly               bool                 `json:"explain"`
+	ExplainVerbose            bool                 `json:"explain_verbose"`
 	Keys                      *KeyExpression       `json:"keys"`
+	Compound                  CompoundTermList     `json:"compound"`
 	explicitProjectionAliases []string
 	aggregateReferences       ExpressionList
+	windowReferences          ExpressionList
+	outerAliases              []string
 }
 
 func NewSelectStatement() *SelectStatement {
@@ -94,6 +98,20 @@ func (this *SelectStatement) GetAggregateReferences() ExpressionList {
 	return this.aggregateReferences
 }
 
+func (this *SelectStatement) GetWindowReferences() ExpressionList {
+	return this.windowReferences
+}
+
+func (this *SelectStatement) GetCompound() CompoundTermList {
+	return this.Compound
+}
+
+// a statement with compound terms combines its own result
+// with the results of each term, in order, from left to right
+func (this *SelectStatement) IsCompound() bool {
+	return len(this.Compound) > 0
+}
+
 func (this *SelectStatement) IsAggregate() bool {
 	if this.GroupBy != nil {
 		return true
@@ -104,6 +122,52 @@ func (this *SelectStatement) IsAggregate() bool {
 }
 
 func (this *SelectStatement) VerifySemantics() error {
+	if this.IsCompound() {
+		return this.verifyCompoundSemantics()
+	}
+	return this.verifyCoreSemantics()
+}
+
+// when this statement is compound, ORDER BY applies to the combined
+// result, so it is verified against the projected values only
+func (this *SelectStatement) verifyCompoundSemantics() error {
+	orderBy := this.OrderBy
+	this.OrderBy = nil
+	err := this.verifyCoreSemantics()
+	this.OrderBy = orderBy
+	if err != nil {
+		return err
+	}
+
+	for _, term := range this.Compound {
+		term.Select.setOuterAliases(this.outerAliases)
+		err = term.Select.VerifySemantics()
+		if err != nil {
+			return err
+		}
+	}
+
+	if this.OrderBy != nil {
+		err = this.OrderBy.VerifyFormalNotation([]string{}, []string{}, "")
+		if err != nil {
+			return err
+		}
+		err = this.OrderBy.Validate()
+		if err != nil {
+			return err
+		}
+		if len(this.OrderBy.findAggregateFunctionReferences()) > 0 {
+			return fmt.Errorf("ORDER BY of a compound SELECT cannot reference aggregate functions")
+		}
+		if len(this.OrderBy.findWindowReferences()) > 0 {
+			return fmt.Errorf("ORDER BY of a compound SELECT cannot reference window functions")
+		}
+	}
+
+	return nil
+}
+
+func (this *SelectStatement) verifyCoreSemantics() error {
 
 	var err error
 	// get the list of explicit projection aliases, and check it for duplicates
@@ -127,9 +191,9 @@ func (this *SelectStatement) VerifySemantics() error {
 	if this.From != nil {
 		nextOver := this.From.Over
 		for nextOver != nil {
-			// If Keys is present then this is a key-join,
+			// If Keys or On is present then this is a join,
 			// and we need to make sure that the from clause contains a bucket
-			if nextOver.Keys != nil {
+			if nextOver.Keys != nil || nextOver.On != nil {
 				nextOver.ConvertToBucketFrom()
 			}
 			nextOver = nextOver.Over
@@ -211,6 +275,9 @@ func (this *SelectStatement) VerifySemantics() error {
 		this.aggregateReferences = this.findAggregateFunctionReferences()
 	}
 
+	// the windows are computed after any grouping
+	this.windowReferences = this.findWindowReferences()
+
 	// if you combine DISTINCT with ORDER BY we have to do an additional validation
 	if this.Distinct && this.OrderBy != nil {
 		// every Order By expression MUST be equivalent to one in the projection
@@ -250,6 +317,12 @@ func (this *SelectStatement) validate() error {
 				if err != nil {
 					return err
 				}
+			} else if fromOver.On != nil {
+				onValidator := NewExpressionValidatorNoAggregates()
+				fromOver.On, err = fromOver.On.Accept(onValidator)
+				if err != nil {
+					return err
+				}
 			} else {
 				err = fromOver.VerifyBucket(aliases)
 				if err != nil {
@@ -265,7 +338,7 @@ func (this *SelectStatement) validate() error {
 		if err != nil {
 			return err
 		}
-		if len(this.Keys.GetKeys()) == 0 {
+		if !this.Keys.HasParameters() && len(this.Keys.GetKeys()) == 0 {
 			return fmt.Errorf("KEY clause contains an invalid expression")
 		}
 	}
@@ -305,6 +378,9 @@ func (this *SelectStatement) verifyFormalNotation(explicitProjectionAliases []st
 		defaultAlias = aliases[0]
 	}
 
+	// a subquery may also refer to the aliases of the enclosing statements
+	aliases = append(aliases, this.outerAliases...)
+
 	formalNotation := NewExpressionFormalNotationConverter(explicitProjectionAliases, aliases, defaultAlias)
 
 	// verify the projection (references to projection aliases not allowed)
@@ -328,6 +404,24 @@ func (this *SelectStatement) verifyFormalNotation(explicitProjectionAliases []st
 		}
 	}
 
+	// verify the join conditions, each may only refer to
+	// the aliases up to and including its own join
+	if this.From != nil {
+		fromAliases := this.From.GetAliases()
+		i := 1
+		for fromOver := this.From.Over; fromOver != nil; fromOver = fromOver.Over {
+			if fromOver.On != nil {
+				onAliases := append(append([]string{}, fromAliases[:i+1]...), this.outerAliases...)
+				onNotation := NewExpressionFormalNotationConverter(explicitProjectionAliases, onAliases, "")
+				fromOver.On, err = fromOver.On.Accept(onNotation)
+				if err != nil {
+					return err
+				}
+			}
+			i++
+		}
+	}
+
 	// verify the order by(references to projection aliases ARE allowed)
 	// since order by CAN reference the explicit aliases, these must be added to the list
 	// passed into this phase
@@ -385,12 +479,26 @@ func (this *SelectStatement) findAggregateFunctionReferences() ExpressionList {
 	}
 
 	//finally we need to remove duplicates from this list
-	for i, agga := range ar {
-		if agga != nil {
-			for j, aggb := range ar[i+1:] {
-				if aggb != nil {
-					if agga.EquivalentTo(aggb) {
-						ar[i+j+1] = nil
+	return removeEquivalentExpressions(ar)
+}
+
+// SELECT and ORDER BY may reference window functions, which
+// are computed once all the items (or groups) are known
+func (this *SelectStatement) findWindowReferences() ExpressionList {
+	wr := this.Select.findWindowReferences()
+	if this.OrderBy != nil {
+		wr = append(wr, this.OrderBy.findWindowReferences()...)
+	}
+	return removeEquivalentExpressions(wr)
+}
+
+func removeEquivalentExpressions(exprs ExpressionList) ExpressionList {
+	for i, expra := range exprs {
+		if expra != nil {
+			for j, exprb := range exprs[i+1:] {
+				if exprb != nil {
+					if expra.EquivalentTo(exprb) {
+						exprs[i+j+1] = nil
 					}
 				}
 			}
@@ -398,15 +506,104 @@ func (this *SelectStatement) findAggregateFunctionReferences() ExpressionList {
 	}
 
 	rv := make(ExpressionList, 0)
-	for _, agg := range ar {
-		if agg != nil {
-			rv = append(rv, agg)
+	for _, expr := range exprs {
+		if expr != nil {
+			rv = append(rv, expr)
 		}
 	}
 
 	return rv
 }
 
+func (this *SelectStatement) setOuterAliases(outerAliases []string) {
+	this.outerAliases = outerAliases
+}
+
+// does this statement refer to any of these aliases
+// other than the ones it defines itself
+func (this *SelectStatement) referencesAliases(aliases []string) bool {
+	finder := &expressionAliasReferenceFinder{}
+	fromAliases := this.GetFromAliases()
+	for _, alias := range aliases {
+		shadowed := false
+		for _, fromAlias := range fromAliases {
+			if alias == fromAlias {
+				shadowed = true
+			}
+		}
+		if !shadowed {
+			finder.aliases = append(finder.aliases, alias)
+		}
+	}
+
+	for _, expr := range this.clauseExpressions() {
+		expr.Accept(finder)
+		if finder.found {
+			return true
+		}
+	}
+
+	for _, term := range this.Compound {
+		if term.Select.referencesAliases(aliases) {
+			return true
+		}
+	}
+	return false
+}
+
+// the subqueries of this statement and its compound terms
+// (but not the subqueries nested inside those subqueries)
+func (this *SelectStatement) GetSubqueries() []*Subquery {
+	finder := NewExpressionSubqueryFinder()
+	for _, expr := range this.clauseExpressions() {
+		expr.Accept(finder)
+	}
+	rv := finder.GetSubqueries()
+	for _, term := range this.Compound {
+		rv = append(rv, term.Select.GetSubqueries()...)
+	}
+	return rv
+}
+
+// all the expressions appearing in the clauses of this statement
+func (this *SelectStatement) clauseExpressions() ExpressionList {
+	rv := ExpressionList{}
+	for _, resultExpr := range this.Select {
+		if resultExpr.Expr != nil {
+			rv = append(rv, resultExpr.Expr)
+		}
+	}
+	from := this.From
+	for from != nil {
+		if from.Projection != nil {
+			rv = append(rv, from.Projection)
+		}
+		if from.Keys != nil {
+			rv = append(rv, from.Keys.Expr)
+		}
+		if from.On != nil {
+			rv = append(rv, from.On)
+		}
+		from = from.Over
+	}
+	if this.Where != nil {
+		rv = append(rv, this.Where)
+	}
+	if this.Keys != nil {
+		rv = append(rv, this.Keys.Expr)
+	}
+	rv = append(rv, this.GroupBy...)
+	if this.Having != nil {
+		rv = append(rv, this.Having)
+	}
+	for _, orderExpr := range this.OrderBy {
+		if orderExpr.Expr != nil {
+			rv = append(rv, orderExpr.Expr)
+		}
+	}
+	return rv
+}
+
 func (this *SelectStatement) Simplify() error {
 	err := this.Select.Simplify()
 	if err != nil {
@@ -452,5 +649,13 @@ func (this *SelectStatement) Simplify() error {
 		}
 	}
 
+	// simplify the compound terms
+	for _, term := range this.Compound {
+		err = term.Select.Simplify()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
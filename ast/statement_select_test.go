@@ -269,3 +269,39 @@ func TestSelectStatementIsAggregate(t *testing.T) {
 		t.Errorf("expected an aggregate: %v", selectStmt)
 	}
 }
+
+func TestCompoundSelectStatement(t *testing.T) {
+	term := NewSelectStatement()
+	term.Select = ResultExpressionList{NewResultExpression(NewProperty("name"))}
+	term.From = &From{Projection: NewProperty("users")}
+
+	stmt := NewSelectStatement()
+	stmt.Select = ResultExpressionList{NewResultExpression(NewProperty("name"))}
+	stmt.From = &From{Projection: NewProperty("contacts")}
+	stmt.OrderBy = SortExpressionList{NewSortExpression(NewProperty("name"), true)}
+	stmt.Compound = CompoundTermList{NewCompoundTerm(UNION, false, term)}
+
+	err := stmt.VerifySemantics()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// each side is converted to formal notation with its own alias
+	if !reflect.DeepEqual(stmt.Select[0].Expr, NewDotMemberOperator(NewProperty("contacts"), NewProperty("name"))) {
+		t.Errorf("expected contacts.name, got %v", stmt.Select[0].Expr)
+	}
+	if !reflect.DeepEqual(term.Select[0].Expr, NewDotMemberOperator(NewProperty("users"), NewProperty("name"))) {
+		t.Errorf("expected users.name, got %v", term.Select[0].Expr)
+	}
+
+	// but the order by refers to the combined result
+	if !reflect.DeepEqual(stmt.OrderBy[0].Expr, NewProperty("name")) {
+		t.Errorf("expected name, got %v", stmt.OrderBy[0].Expr)
+	}
+
+	stmt.OrderBy = SortExpressionList{NewSortExpression(NewFunctionCall("COUNT", FunctionArgExpressionList{NewStarFunctionArgExpression()}), true)}
+	err = stmt.VerifySemantics()
+	if err == nil {
+		t.Errorf("expected error ordering compound statement by aggregate")
+	}
+}
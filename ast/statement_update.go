@@ -0,0 +1,120 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+)
+
+// UpdateStatement changes fields of the documents of
+// a bucket selected by the KEYS and WHERE clauses
+type UpdateStatement struct {
+	ExplainOnly bool           `json:"explain"`
+	Pool        string         `json:"pool"`
+	Bucket      string         `json:"bucket"`
+	As          string         `json:"as"`
+	Keys        *KeyExpression `json:"keys"`
+	Set         SetTermList    `json:"set"`
+	Where       Expression     `json:"where"`
+	Limit       int            `json:"limit"`
+}
+
+// SetTerm assigns the value to the path within the document
+type SetTerm struct {
+	Path  Expression `json:"path"`
+	Value Expression `json:"value"`
+}
+
+type SetTermList []*SetTerm
+
+func NewUpdateStatement() *UpdateStatement {
+	return &UpdateStatement{
+		Limit: -1,
+	}
+}
+
+func NewSetTerm(path, value Expression) *SetTerm {
+	return &SetTerm{
+		Path:  path,
+		Value: value,
+	}
+}
+
+func (this *UpdateStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *UpdateStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *UpdateStatement) GetAlias() string {
+	if this.As != "" {
+		return this.As
+	}
+	return this.Bucket
+}
+
+func (this *UpdateStatement) VerifySemantics() error {
+	if len(this.Set) == 0 {
+		return fmt.Errorf("UPDATE requires a SET clause")
+	}
+
+	alias := this.GetAlias()
+
+	var err error
+	this.Keys, this.Where, err = verifyMutationSource(alias, this.Keys, this.Where)
+	if err != nil {
+		return err
+	}
+
+	formalNotation := NewExpressionFormalNotationConverter([]string{}, []string{alias}, alias)
+	validator := NewExpressionValidatorNoAggregates()
+	for _, term := range this.Set {
+		term.Path, err = term.Path.Accept(formalNotation)
+		if err != nil {
+			return err
+		}
+		// the document itself cannot be replaced, only its fields
+		switch term.Path.(type) {
+		case *DotMemberOperator, *BracketMemberOperator:
+		default:
+			return fmt.Errorf("SET %v does not refer to a field of %s", term.Path, alias)
+		}
+
+		term.Value, err = term.Value.Accept(formalNotation)
+		if err != nil {
+			return err
+		}
+		term.Value, err = term.Value.Accept(validator)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *UpdateStatement) Simplify() error {
+	var err error
+	this.Keys, this.Where, err = simplifyMutationSource(this.Keys, this.Where)
+	if err != nil {
+		return err
+	}
+
+	es := NewExpressionSimplifier()
+	for _, term := range this.Set {
+		term.Value, err = term.Value.Accept(es)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import ()
+
+// UpdateStatisticsStatement collects the statistics of one index
+// of a bucket, or of all its range indexes when no index is named
+type UpdateStatisticsStatement struct {
+	Index       string `json:"index"`
+	ExplainOnly bool   `json:"explain"`
+	Bucket      string `json:"bucket"`
+	Pool        string `json:"pool"`
+}
+
+func NewUpdateStatisticsStatement() *UpdateStatisticsStatement {
+	return &UpdateStatisticsStatement{}
+}
+
+func (this *UpdateStatisticsStatement) SetExplainOnly(only bool) {
+	this.ExplainOnly = only
+}
+
+func (this *UpdateStatisticsStatement) IsExplainOnly() bool {
+	return this.ExplainOnly
+}
+
+func (this *UpdateStatisticsStatement) VerifySemantics() error {
+	return nil
+}
+
+func (this *UpdateStatisticsStatement) Simplify() error {
+	return nil
+}
@@ -0,0 +1,214 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// the query attached to items during execution implements
+// this interface when the statement contains subqueries
+type SubqueryEvaluator interface {
+	EvaluateSubquery(subquery *Subquery, item *dparval.Value) (*dparval.Value, error)
+}
+
+// a subquery evaluates to an array of the results of its statement
+// when the statement projects a single expression the array contains
+// the values of that expression, otherwise it contains the projections
+type Subquery struct {
+	Type       string           `json:"type"`
+	Select     *SelectStatement `json:"select"`
+	Correlated bool             `json:"correlated"`
+}
+
+func NewSubquery(sel *SelectStatement) *Subquery {
+	return &Subquery{
+		Type:   "subquery",
+		Select: sel,
+	}
+}
+
+func (this *Subquery) Copy() Expression {
+	// the statement is shared, so the copy is evaluated
+	// using the same plan as the original
+	return &Subquery{
+		Type:       "subquery",
+		Select:     this.Select,
+		Correlated: this.Correlated,
+	}
+}
+
+func (this *Subquery) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	var evaluator SubqueryEvaluator
+	if item != nil {
+		evaluator, _ = item.GetAttachment("query").(SubqueryEvaluator)
+	}
+	if evaluator == nil {
+		return nil, fmt.Errorf("subquery cannot be evaluated here")
+	}
+	return evaluator.EvaluateSubquery(this, item)
+}
+
+func (this *Subquery) EquivalentTo(t Expression) bool {
+	that, ok := t.(*Subquery)
+	if !ok {
+		return false
+	}
+	return this.Select == that.Select
+}
+
+// the statement of a subquery is a separate scope, so
+// it has no dependencies in the enclosing statement
+func (this *Subquery) Dependencies() ExpressionList {
+	return ExpressionList{}
+}
+
+func (this *Subquery) String() string {
+	return "{ SELECT ... }"
+}
+
+func (this *Subquery) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// the statement is verified with the aliases of the enclosing
+// statements in scope, the subquery is correlated if it refers to any
+// of them, otherwise it only needs to be evaluated once
+func (this *Subquery) VerifySemantics(outerAliases []string) error {
+	this.Select.setOuterAliases(outerAliases)
+	err := this.Select.VerifySemantics()
+	if err != nil {
+		return err
+	}
+	this.Correlated = this.Select.referencesAliases(outerAliases)
+	return nil
+}
+
+func (this *Subquery) GetOuterAliases() []string {
+	return this.Select.outerAliases
+}
+
+type ExistsOperator struct {
+	Type string `json:"type"`
+	PrefixUnaryOperator
+}
+
+func NewExistsOperator(operand Expression) *ExistsOperator {
+	return &ExistsOperator{
+		"exists",
+		PrefixUnaryOperator{
+			UnaryOperator{
+				operator: "EXISTS ",
+				Operand:  operand,
+			},
+		},
+	}
+}
+
+func (this *ExistsOperator) Copy() Expression {
+	return &ExistsOperator{
+		"exists",
+		PrefixUnaryOperator{
+			UnaryOperator{
+				operator: "EXISTS ",
+				Operand:  this.Operand.Copy(),
+			},
+		},
+	}
+}
+
+// EXISTS is true for non-empty arrays and false otherwise
+func (this *ExistsOperator) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	ov, err := this.Operand.Evaluate(item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			return dparval.NewValue(false), nil
+		default:
+			return nil, err
+		}
+	}
+
+	if ov.Type() == dparval.ARRAY {
+		_, err := ov.Index(0)
+		return dparval.NewValue(err == nil), nil
+	}
+	return dparval.NewValue(false), nil
+}
+
+func (this *ExistsOperator) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// this ExpressionVisitor finds the subqueries
+// of an expression, it does not search inside
+// the subqueries themselves
+type ExpressionSubqueryFinder struct {
+	subqueries []*Subquery
+}
+
+func NewExpressionSubqueryFinder() *ExpressionSubqueryFinder {
+	return &ExpressionSubqueryFinder{
+		subqueries: make([]*Subquery, 0),
+	}
+}
+
+func (this *ExpressionSubqueryFinder) GetSubqueries() []*Subquery {
+	return this.subqueries
+}
+
+func (this *ExpressionSubqueryFinder) Visit(e Expression) (Expression, error) {
+	switch expr := e.(type) {
+	case *Subquery:
+		this.subqueries = append(this.subqueries, expr)
+		return e, nil
+	default:
+		return VisitChildren(this, e)
+	}
+}
+
+// this ExpressionVisitor determines if an expression
+// refers to any of the given aliases
+type expressionAliasReferenceFinder struct {
+	aliases []string
+	found   bool
+}
+
+func (this *expressionAliasReferenceFinder) Visit(e Expression) (Expression, error) {
+	switch expr := e.(type) {
+	case *Property:
+		for _, alias := range this.aliases {
+			if expr.Path == alias {
+				this.found = true
+			}
+		}
+		return e, nil
+	case *DotMemberOperator:
+		// the right hand side names a member, not an alias
+		_, err := expr.Left.Accept(this)
+		return e, err
+	case *Subquery:
+		// a nested subquery that is correlated may refer to these aliases
+		if expr.Correlated {
+			this.found = true
+		}
+		return e, nil
+	default:
+		return VisitChildren(this, e)
+	}
+}
+
+func expressionReferencesAliases(expr Expression, aliases []string) bool {
+	finder := &expressionAliasReferenceFinder{aliases: aliases}
+	expr.Accept(finder)
+	return finder.found
+}
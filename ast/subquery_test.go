@@ -0,0 +1,97 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExists(t *testing.T) {
+
+	tests := ExpressionTestSet{
+		{NewExistsOperator(NewLiteralArray(ExpressionList{NewLiteralNumber(1.0)})), true, nil},
+		{NewExistsOperator(NewLiteralArray(ExpressionList{})), false, nil},
+		{NewExistsOperator(NewLiteralNull()), false, nil},
+		{NewExistsOperator(NewProperty("missing")), false, nil},
+	}
+
+	tests.Run(t)
+}
+
+func subqueryTestStatement(inner *SelectStatement) (*SelectStatement, *Subquery) {
+	subquery := NewSubquery(inner)
+	stmt := NewSelectStatement()
+	stmt.Select = ResultExpressionList{NewStarResultExpression()}
+	stmt.From = &From{Projection: NewProperty("contacts")}
+	stmt.Where = NewExistsOperator(subquery)
+	return stmt, subquery
+}
+
+func TestSubqueryVerifySemantics(t *testing.T) {
+
+	// unqualified references belong to the subquery
+	inner := NewSelectStatement()
+	inner.Select = ResultExpressionList{NewResultExpression(NewProperty("name"))}
+	inner.From = &From{Projection: NewProperty("users")}
+	stmt, subquery := subqueryTestStatement(inner)
+
+	err := stmt.VerifySemantics()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if subquery.Correlated {
+		t.Errorf("expected subquery to be uncorrelated")
+	}
+	if !reflect.DeepEqual(inner.Select[0].Expr, NewDotMemberOperator(NewProperty("users"), NewProperty("name"))) {
+		t.Errorf("expected users.name, got %v", inner.Select[0].Expr)
+	}
+
+	// references to the enclosing alias make it correlated
+	inner = NewSelectStatement()
+	inner.Select = ResultExpressionList{NewResultExpression(NewProperty("name"))}
+	inner.From = &From{Projection: NewProperty("users")}
+	inner.Where = NewEqualToOperator(NewProperty("id"), NewDotMemberOperator(NewProperty("contacts"), NewProperty("id")))
+	stmt, subquery = subqueryTestStatement(inner)
+
+	err = stmt.VerifySemantics()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !subquery.Correlated {
+		t.Errorf("expected subquery to be correlated")
+	}
+	expectedWhere := NewEqualToOperator(NewDotMemberOperator(NewProperty("users"), NewProperty("id")), NewDotMemberOperator(NewProperty("contacts"), NewProperty("id")))
+	if !reflect.DeepEqual(inner.Where, expectedWhere) {
+		t.Errorf("expected %v, got %v", expectedWhere, inner.Where)
+	}
+
+	// an alias of the subquery hides the enclosing alias of the same name
+	inner = NewSelectStatement()
+	inner.Select = ResultExpressionList{NewResultExpression(NewDotMemberOperator(NewProperty("contacts"), NewProperty("name")))}
+	inner.From = &From{Projection: NewProperty("contacts")}
+	stmt, subquery = subqueryTestStatement(inner)
+
+	err = stmt.VerifySemantics()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if subquery.Correlated {
+		t.Errorf("expected subquery to be uncorrelated")
+	}
+}
+
+func TestSubqueryEvaluateWithoutQuery(t *testing.T) {
+	subquery := NewSubquery(NewSelectStatement())
+	_, err := subquery.Evaluate(nil)
+	if err == nil {
+		t.Errorf("expected error evaluating subquery without a query")
+	}
+}
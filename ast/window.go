@@ -0,0 +1,456 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+// the bounds of a window frame
+const (
+	UNBOUNDED_PRECEDING = "UNBOUNDED PRECEDING"
+	PRECEDING           = "PRECEDING"
+	CURRENT_ROW         = "CURRENT ROW"
+	FOLLOWING           = "FOLLOWING"
+	UNBOUNDED_FOLLOWING = "UNBOUNDED FOLLOWING"
+)
+
+type WindowFrameBound struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset,omitempty"` // the number of rows PRECEDING or FOLLOWING
+}
+
+func NewWindowFrameBound(boundType string, offset int) *WindowFrameBound {
+	return &WindowFrameBound{
+		Type:   boundType,
+		Offset: offset,
+	}
+}
+
+func (this *WindowFrameBound) String() string {
+	switch this.Type {
+	case PRECEDING, FOLLOWING:
+		return fmt.Sprintf("%d %s", this.Offset, this.Type)
+	}
+	return this.Type
+}
+
+// the place of the bound relative to the current row, for
+// checking that a frame does not start after it ends
+func (this *WindowFrameBound) relative() float64 {
+	switch this.Type {
+	case UNBOUNDED_PRECEDING:
+		return math.Inf(-1)
+	case PRECEDING:
+		return float64(-this.Offset)
+	case FOLLOWING:
+		return float64(this.Offset)
+	case UNBOUNDED_FOLLOWING:
+		return math.Inf(1)
+	}
+	return 0
+}
+
+// the position in the partition where the frame of the item at the
+// position starts, or the position just after the frame when this
+// bound ends the frame
+func (this *WindowFrameBound) position(partition *WindowPartition, position int, rows bool, end bool) int {
+	rv := 0
+	switch this.Type {
+	case UNBOUNDED_PRECEDING:
+		rv = 0
+	case UNBOUNDED_FOLLOWING:
+		rv = len(partition.Items)
+	case PRECEDING:
+		rv = position - this.Offset
+	case FOLLOWING:
+		rv = position + this.Offset
+	case CURRENT_ROW:
+		rv = position
+		if !rows {
+			// a range includes the peers of the current row
+			first, last := partition.Peers(position)
+			if end {
+				rv = last
+			} else {
+				rv = first
+			}
+		}
+	}
+	if end && this.Type != UNBOUNDED_FOLLOWING {
+		rv++
+	}
+	if rv < 0 {
+		return 0
+	}
+	if rv > len(partition.Items) {
+		return len(partition.Items)
+	}
+	return rv
+}
+
+// the items of the partition an aggregate is computed over for each
+// item.  a ROWS frame counts items from the current one, a RANGE
+// frame extends the current row to its peers
+type WindowFrame struct {
+	Rows  bool              `json:"rows"`
+	Start *WindowFrameBound `json:"start"`
+	End   *WindowFrameBound `json:"end"`
+}
+
+func NewWindowFrame(rows bool, start *WindowFrameBound, end *WindowFrameBound) *WindowFrame {
+	return &WindowFrame{
+		Rows:  rows,
+		Start: start,
+		End:   end,
+	}
+}
+
+// without a frame, an ordered window runs from the start
+// of the partition to the peers of the current row
+var defaultWindowFrame = NewWindowFrame(false, NewWindowFrameBound(UNBOUNDED_PRECEDING, 0), NewWindowFrameBound(CURRENT_ROW, 0))
+
+func (this *WindowFrame) String() string {
+	unit := "RANGE"
+	if this.Rows {
+		unit = "ROWS"
+	}
+	return fmt.Sprintf("%s BETWEEN %v AND %v", unit, this.Start, this.End)
+}
+
+func (this *WindowFrame) EquivalentTo(that *WindowFrame) bool {
+	if this == nil || that == nil {
+		return this == that
+	}
+	return this.Rows == that.Rows && *this.Start == *that.Start && *this.End == *that.End
+}
+
+func (this *WindowFrame) Validate() error {
+	if this.Start.Type == UNBOUNDED_FOLLOWING {
+		return fmt.Errorf("a window frame cannot start at UNBOUNDED FOLLOWING")
+	}
+	if this.End.Type == UNBOUNDED_PRECEDING {
+		return fmt.Errorf("a window frame cannot end at UNBOUNDED PRECEDING")
+	}
+	if !this.Rows && (this.Start.Type == PRECEDING || this.Start.Type == FOLLOWING ||
+		this.End.Type == PRECEDING || this.End.Type == FOLLOWING) {
+		return fmt.Errorf("RANGE window frames only support UNBOUNDED and CURRENT ROW bounds")
+	}
+	if this.Start.relative() > this.End.relative() {
+		return fmt.Errorf("the window frame %v starts after it ends", this)
+	}
+	return nil
+}
+
+// the PARTITION BY, ORDER BY and frame of an OVER clause
+type WindowDefinition struct {
+	PartitionBy ExpressionList     `json:"partition_by"`
+	OrderBy     SortExpressionList `json:"order_by"`
+	Frame       *WindowFrame       `json:"frame"`
+}
+
+func NewWindowDefinition(partitionBy ExpressionList, orderBy SortExpressionList, frame *WindowFrame) *WindowDefinition {
+	return &WindowDefinition{
+		PartitionBy: partitionBy,
+		OrderBy:     orderBy,
+		Frame:       frame,
+	}
+}
+
+func (this *WindowDefinition) Copy() *WindowDefinition {
+	rv := &WindowDefinition{
+		Frame: this.Frame,
+	}
+	if this.PartitionBy != nil {
+		rv.PartitionBy = make(ExpressionList, len(this.PartitionBy))
+		for i, expr := range this.PartitionBy {
+			rv.PartitionBy[i] = expr.Copy()
+		}
+	}
+	if this.OrderBy != nil {
+		rv.OrderBy = make(SortExpressionList, len(this.OrderBy))
+		for i, orderExpr := range this.OrderBy {
+			rv.OrderBy[i] = NewSortExpression(orderExpr.Expr.Copy(), orderExpr.Ascending)
+		}
+	}
+	return rv
+}
+
+func (this *WindowDefinition) String() string {
+	rv := this.SortKey()
+	if this.Frame != nil {
+		if rv != "" {
+			rv = rv + " "
+		}
+		rv = rv + this.Frame.String()
+	}
+	return rv
+}
+
+// the PARTITION BY and ORDER BY of the window, windows with
+// the same sort key are computed over the same sorted items
+func (this *WindowDefinition) SortKey() string {
+	rv := ""
+	if len(this.PartitionBy) > 0 {
+		rv = "PARTITION BY "
+		for i, expr := range this.PartitionBy {
+			if i != 0 {
+				rv = rv + ", "
+			}
+			rv = rv + fmt.Sprintf("%v", expr)
+		}
+	}
+	if len(this.OrderBy) > 0 {
+		if rv != "" {
+			rv = rv + " "
+		}
+		rv = rv + "ORDER BY "
+		for i, orderExpr := range this.OrderBy {
+			if i != 0 {
+				rv = rv + ", "
+			}
+			rv = rv + fmt.Sprintf("%v", orderExpr.Expr)
+			if !orderExpr.Ascending {
+				rv = rv + " DESC"
+			}
+		}
+	}
+	return rv
+}
+
+func (this *WindowDefinition) EquivalentTo(that *WindowDefinition) bool {
+	if len(this.PartitionBy) != len(that.PartitionBy) || len(this.OrderBy) != len(that.OrderBy) {
+		return false
+	}
+	for i, expr := range this.PartitionBy {
+		if !expr.EquivalentTo(that.PartitionBy[i]) {
+			return false
+		}
+	}
+	for i, orderExpr := range this.OrderBy {
+		thatExpr := that.OrderBy[i]
+		if orderExpr.Ascending != thatExpr.Ascending || !orderExpr.Expr.EquivalentTo(thatExpr.Expr) {
+			return false
+		}
+	}
+	return this.Frame.EquivalentTo(that.Frame)
+}
+
+// the positions where the frame of the item at the
+// position starts and just after where it ends
+func (this *WindowDefinition) FrameOf(partition *WindowPartition, position int) (int, int) {
+	frame := this.Frame
+	if frame == nil {
+		if len(this.OrderBy) == 0 {
+			return 0, len(partition.Items)
+		}
+		frame = defaultWindowFrame
+	}
+	start := frame.Start.position(partition, position, frame.Rows, false)
+	end := frame.End.position(partition, position, frame.Rows, true)
+	if start > end {
+		// an empty frame
+		return end, end
+	}
+	return start, end
+}
+
+// the items of one partition of a window in the order of the window,
+// peers are the items with the same ORDER BY values
+type WindowPartition struct {
+	Items []*dparval.Value
+	// the number of the peer group of each item, counting from 0
+	PeerGroups []int
+	firstPeer  []int
+	lastPeer   []int
+}
+
+func NewWindowPartition(items []*dparval.Value, peerGroups []int) *WindowPartition {
+	rv := &WindowPartition{
+		Items:      items,
+		PeerGroups: peerGroups,
+		firstPeer:  make([]int, len(items)),
+		lastPeer:   make([]int, len(items)),
+	}
+	for i := range items {
+		if i > 0 && peerGroups[i] == peerGroups[i-1] {
+			rv.firstPeer[i] = rv.firstPeer[i-1]
+		} else {
+			rv.firstPeer[i] = i
+		}
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if i < len(items)-1 && peerGroups[i] == peerGroups[i+1] {
+			rv.lastPeer[i] = rv.lastPeer[i+1]
+		} else {
+			rv.lastPeer[i] = i
+		}
+	}
+	return rv
+}
+
+// the positions of the first and the last peer of the item
+func (this *WindowPartition) Peers(position int) (int, int) {
+	return this.firstPeer[position], this.lastPeer[position]
+}
+
+// a window function, or an aggregate function computed over a window
+// instead of a group.  the values are computed by the window operator
+// and attached to the items
+type WindowOperator struct {
+	Type     string                 `json:"type"`
+	Function FunctionCallExpression `json:"function"`
+	Window   *WindowDefinition      `json:"window"`
+}
+
+func NewWindowOperator(function FunctionCallExpression, window *WindowDefinition) *WindowOperator {
+	return &WindowOperator{
+		Type:     "window",
+		Function: function,
+		Window:   window,
+	}
+}
+
+func (this *WindowOperator) Copy() Expression {
+	return &WindowOperator{
+		Type:     "window",
+		Function: this.Function.Copy().(FunctionCallExpression),
+		Window:   this.Window.Copy(),
+	}
+}
+
+// the key where the value of this window is attached to the items
+func (this *WindowOperator) Key() string {
+	return this.String()
+}
+
+func (this *WindowOperator) Evaluate(item *dparval.Value) (*dparval.Value, error) {
+	windows, ok := item.GetAttachment("windows").(map[string]*dparval.Value)
+	if ok {
+		val, ok := windows[this.Key()]
+		if ok {
+			if val == nil {
+				return nil, &dparval.Undefined{}
+			}
+			return val, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find window %v", this)
+}
+
+// EvaluatePartition computes the value of the window for every item of
+// the partition, nil when the value is MISSING
+func (this *WindowOperator) EvaluatePartition(partition *WindowPartition) ([]*dparval.Value, error) {
+	switch function := this.Function.(type) {
+	case WindowFunctionCallExpression:
+		rv := make([]*dparval.Value, len(partition.Items))
+		for i := range partition.Items {
+			val, err := function.EvaluateWindow(this.Window, partition, i)
+			if err != nil {
+				switch err.(type) {
+				case *dparval.Undefined:
+					continue
+				default:
+					return nil, err
+				}
+			}
+			rv[i] = val
+		}
+		return rv, nil
+	case AggregateFunctionCallExpression:
+		return this.aggregatePartition(function, partition)
+	}
+	return nil, fmt.Errorf("%s() cannot be used with OVER", this.Function.GetName())
+}
+
+// the aggregate of every frame is computed in a group of its own,
+// when a frame starts where the one before started (like the frames
+// of a running total) it carries on from the aggregate of that one
+func (this *WindowOperator) aggregatePartition(function AggregateFunctionCallExpression, partition *WindowPartition) ([]*dparval.Value, error) {
+	rv := make([]*dparval.Value, len(partition.Items))
+	var group *dparval.Value
+	groupStart, aggregated := 0, 0
+	for i := range partition.Items {
+		start, end := this.Window.FrameOf(partition, i)
+		if group == nil || start != groupStart || end < aggregated {
+			group = dparval.NewValue(map[string]interface{}{})
+			err := function.DefaultAggregate(group)
+			if err != nil {
+				return nil, err
+			}
+			groupStart, aggregated = start, start
+		}
+		for ; aggregated < end; aggregated++ {
+			err := function.UpdateAggregate(group, partition.Items[aggregated])
+			if err != nil {
+				return nil, err
+			}
+		}
+		val, err := function.Evaluate(group)
+		if err != nil {
+			switch err.(type) {
+			case *dparval.Undefined:
+				continue
+			default:
+				return nil, err
+			}
+		}
+		// the group goes on changing
+		rv[i] = dparval.NewValue(val.Value())
+	}
+	return rv, nil
+}
+
+func (this *WindowOperator) String() string {
+	return fmt.Sprintf("%v OVER (%v)", this.Function, this.Window)
+}
+
+func (this *WindowOperator) EquivalentTo(t Expression) bool {
+	that, ok := t.(*WindowOperator)
+	if !ok {
+		return false
+	}
+	return this.Function.EquivalentTo(that.Function) && this.Window.EquivalentTo(that.Window)
+}
+
+func (this *WindowOperator) Dependencies() ExpressionList {
+	rv := this.Function.Dependencies()
+	rv = append(rv, this.Window.PartitionBy...)
+	for _, orderExpr := range this.Window.OrderBy {
+		rv = append(rv, orderExpr.Expr)
+	}
+	return rv
+}
+
+func (this *WindowOperator) Accept(ev ExpressionVisitor) (Expression, error) {
+	return ev.Visit(this)
+}
+
+// checks what the expression validator cannot see from the
+// children of the window: the function and the frame
+func (this *WindowOperator) Validate() error {
+	switch this.Function.(type) {
+	case *FunctionCallUnknown:
+		return fmt.Errorf("no system function named %s registered", this.Function.GetName())
+	case WindowFunctionCallExpression, AggregateFunctionCallExpression:
+	default:
+		return fmt.Errorf("%s() cannot be used with OVER", this.Function.GetName())
+	}
+	if this.Function.IsDistinct() {
+		return fmt.Errorf("DISTINCT is not supported with OVER")
+	}
+	if this.Window.Frame != nil {
+		return this.Window.Frame.Validate()
+	}
+	return nil
+}
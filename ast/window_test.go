@@ -0,0 +1,205 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+)
+
+type WindowTest struct {
+	window  *WindowOperator
+	results []interface{}
+}
+
+type WindowTestSet []WindowTest
+
+func (this WindowTestSet) Run(t *testing.T, partition *WindowPartition) {
+	for _, x := range this {
+		values, err := x.window.EvaluatePartition(partition)
+		if err != nil {
+			t.Errorf("Error computing %v: %v", x.window, err)
+			continue
+		}
+		results := make([]interface{}, len(values))
+		for i, val := range values {
+			if val != nil {
+				results[i] = val.Value()
+			} else {
+				results[i] = "MISSING"
+			}
+		}
+		if !reflect.DeepEqual(results, x.results) {
+			t.Errorf("Expected %v, got %v, for %v", x.results, results, x.window)
+		}
+	}
+}
+
+// the items ordered by score, gerald and steve are peers
+var windowPartition = NewWindowPartition([]*dparval.Value{
+	dparval.NewValue(map[string]interface{}{
+		"name":  "marty",
+		"score": 2.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "gerald",
+		"score": 4.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "steve",
+		"score": 4.0,
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name": "ketaki",
+	}),
+	dparval.NewValue(map[string]interface{}{
+		"name":  "aaron",
+		"score": 9.0,
+	}),
+}, []int{0, 1, 1, 2, 3})
+
+func scoreWindow(function string, operands FunctionArgExpressionList, frame *WindowFrame) *WindowOperator {
+	return NewWindowOperator(NewFunctionCall(function, operands),
+		NewWindowDefinition(nil, SortExpressionList{NewSortExpression(NewProperty("score"), true)}, frame))
+}
+
+func TestRankingWindowFunctions(t *testing.T) {
+
+	name := NewFunctionArgExpression(NewProperty("name"))
+	tests := WindowTestSet{
+		{
+			scoreWindow("ROW_NUMBER", FunctionArgExpressionList{}, nil),
+			[]interface{}{1.0, 2.0, 3.0, 4.0, 5.0},
+		},
+		{
+			scoreWindow("RANK", FunctionArgExpressionList{}, nil),
+			[]interface{}{1.0, 2.0, 2.0, 4.0, 5.0},
+		},
+		{
+			scoreWindow("DENSE_RANK", FunctionArgExpressionList{}, nil),
+			[]interface{}{1.0, 2.0, 2.0, 3.0, 4.0},
+		},
+		{
+			scoreWindow("LAG", FunctionArgExpressionList{name}, nil),
+			[]interface{}{nil, "marty", "gerald", "steve", "ketaki"},
+		},
+		{
+			scoreWindow("LEAD", FunctionArgExpressionList{name, NewFunctionArgExpression(NewLiteralNumber(2.0)), NewFunctionArgExpression(NewLiteralString("none"))}, nil),
+			[]interface{}{"steve", "ketaki", "aaron", "none", "none"},
+		},
+		{
+			scoreWindow("LAG", FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("score"))}, nil),
+			[]interface{}{nil, 2.0, 4.0, 4.0, "MISSING"},
+		},
+		{
+			scoreWindow("FIRST_VALUE", FunctionArgExpressionList{name}, NewWindowFrame(true, NewWindowFrameBound(PRECEDING, 1), NewWindowFrameBound(FOLLOWING, 1))),
+			[]interface{}{"marty", "marty", "gerald", "steve", "ketaki"},
+		},
+	}
+
+	tests.Run(t, windowPartition)
+
+}
+
+func TestAggregateWindowFunctions(t *testing.T) {
+
+	score := FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("score"))}
+	tests := WindowTestSet{
+		// the default frame of an ordered window includes the peers
+		{
+			scoreWindow("SUM", score, nil),
+			[]interface{}{2.0, 10.0, 10.0, 10.0, 19.0},
+		},
+		{
+			scoreWindow("COUNT", FunctionArgExpressionList{NewStarFunctionArgExpression()}, NewWindowFrame(true, NewWindowFrameBound(UNBOUNDED_PRECEDING, 0), NewWindowFrameBound(CURRENT_ROW, 0))),
+			[]interface{}{1.0, 2.0, 3.0, 4.0, 5.0},
+		},
+		{
+			scoreWindow("AVG", score, NewWindowFrame(true, NewWindowFrameBound(PRECEDING, 1), NewWindowFrameBound(CURRENT_ROW, 0))),
+			[]interface{}{2.0, 3.0, 4.0, 4.0, 9.0},
+		},
+		{
+			scoreWindow("MAX", score, NewWindowFrame(false, NewWindowFrameBound(CURRENT_ROW, 0), NewWindowFrameBound(UNBOUNDED_FOLLOWING, 0))),
+			[]interface{}{9.0, 9.0, 9.0, 9.0, 9.0},
+		},
+		// an empty frame
+		{
+			scoreWindow("SUM", score, NewWindowFrame(true, NewWindowFrameBound(FOLLOWING, 1), NewWindowFrameBound(FOLLOWING, 1))),
+			[]interface{}{4.0, 4.0, nil, 9.0, nil},
+		},
+		// without ORDER BY the frame is the whole partition
+		{
+			NewWindowOperator(NewFunctionCall("SUM", score), NewWindowDefinition(nil, nil, nil)),
+			[]interface{}{19.0, 19.0, 19.0, 19.0, 19.0},
+		},
+	}
+
+	tests.Run(t, windowPartition)
+
+}
+
+func TestWindowValidation(t *testing.T) {
+
+	score := FunctionArgExpressionList{NewFunctionArgExpression(NewProperty("score"))}
+	tests := []struct {
+		window *WindowOperator
+		err    error
+	}{
+		{
+			scoreWindow("SUM", score, NewWindowFrame(true, NewWindowFrameBound(PRECEDING, 2), NewWindowFrameBound(FOLLOWING, 2))),
+			nil,
+		},
+		{
+			scoreWindow("SUM", score, NewWindowFrame(true, NewWindowFrameBound(UNBOUNDED_FOLLOWING, 0), NewWindowFrameBound(UNBOUNDED_FOLLOWING, 0))),
+			fmt.Errorf("a window frame cannot start at UNBOUNDED FOLLOWING"),
+		},
+		{
+			scoreWindow("SUM", score, NewWindowFrame(true, NewWindowFrameBound(CURRENT_ROW, 0), NewWindowFrameBound(PRECEDING, 1))),
+			fmt.Errorf("the window frame ROWS BETWEEN CURRENT ROW AND 1 PRECEDING starts after it ends"),
+		},
+		{
+			scoreWindow("SUM", score, NewWindowFrame(false, NewWindowFrameBound(PRECEDING, 1), NewWindowFrameBound(CURRENT_ROW, 0))),
+			fmt.Errorf("RANGE window frames only support UNBOUNDED and CURRENT ROW bounds"),
+		},
+		{
+			scoreWindow("UPPER", score, nil),
+			fmt.Errorf("UPPER() cannot be used with OVER"),
+		},
+		{
+			NewWindowOperator(distinctFunctionCall("COUNT", score), NewWindowDefinition(nil, nil, nil)),
+			fmt.Errorf("DISTINCT is not supported with OVER"),
+		},
+	}
+
+	for _, x := range tests {
+		err := x.window.Validate()
+		if !reflect.DeepEqual(err, x.err) {
+			t.Errorf("Expected error %v, got %v, for %v", x.err, err, x.window)
+		}
+	}
+
+	// and only where they can be computed
+	window := scoreWindow("RANK", FunctionArgExpressionList{}, nil)
+	_, err := window.Accept(NewExpressionValidator())
+	if !reflect.DeepEqual(err, fmt.Errorf("Window function not allowed here")) {
+		t.Errorf("Expected windows not to be allowed, got %v", err)
+	}
+	_, err = window.Accept(NewExpressionValidatorWithWindows())
+	if err != nil {
+		t.Errorf("Expected windows to be allowed, got %v", err)
+	}
+	_, err = window.Function.Accept(NewExpressionValidatorWithWindows())
+	if !reflect.DeepEqual(err, fmt.Errorf("the RANK() function requires an OVER clause")) {
+		t.Errorf("Expected RANK() to require OVER, got %v", err)
+	}
+}
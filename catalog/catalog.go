@@ -66,6 +66,10 @@ type Bucket interface {
 	Release()
 	CreatePrimaryIndex() (PrimaryIndex, query.Error)
 	CreateIndex(name string, key IndexKey, using IndexType) (Index, query.Error)
+	Insert(id string, value *dparval.Value) query.Error  // Fails if the key already exists
+	Upsert(id string, value *dparval.Value) query.Error  // Inserts or replaces
+	Replace(id string, value *dparval.Value) query.Error // Fails if the key does not exist
+	Delete(id string) query.Error                        // Fails if the key does not exist
 }
 
 type IndexType string
@@ -152,6 +156,8 @@ type RangeIndex interface {
 	LookupIndex
 	Direction() Direction
 	Statistics() (RangeStatistics, query.Error)
+	// collects the statistics again
+	UpdateStatistics() (RangeStatistics, query.Error)
 	ScanRange(low LookupValue, high LookupValue, inclusion RangeInclusion, limit int64, ch EntryChannel, warnch, errch query.ErrorChannel)
 }
 
@@ -166,7 +172,11 @@ type RangeStatistics interface {
 	Count() (int64, query.Error)
 	Min() (dparval.Value, query.Error)
 	Max() (dparval.Value, query.Error)
-	DistinctCount(int64, query.Error)
+	DistinctCount() (int64, query.Error)
+	// entries with a null leading key value
+	NullCount() (int64, query.Error)
+	// documents of the bucket without an entry
+	MissingCount() (int64, query.Error)
 	Bins() ([]Bin, query.Error)
 }
 
@@ -175,5 +185,5 @@ type Bin interface {
 	Count() (int64, query.Error)
 	Min() (dparval.Value, query.Error)
 	Max() (dparval.Value, query.Error)
-	DistinctCount(int64, query.Error)
+	DistinctCount() (int64, query.Error)
 }
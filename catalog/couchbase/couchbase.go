@@ -16,6 +16,7 @@ import (
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/dparval"
 	cb "github.com/couchbaselabs/go-couchbase"
+	"github.com/dustin/gomemcached"
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/query"
 )
@@ -342,6 +343,61 @@ func (b *bucket) CreateIndex(name string, key catalog.IndexKey, using catalog.In
 	}
 }
 
+func (b *bucket) Insert(id string, value *dparval.Value) query.Error {
+	added, err := b.cbbucket.AddRaw(id, 0, value.Bytes())
+	if err != nil {
+		return query.NewError(err, "Error doing insert")
+	}
+	if !added {
+		return query.NewKeyExists(id)
+	}
+	return nil
+}
+
+func (b *bucket) Upsert(id string, value *dparval.Value) query.Error {
+	err := b.cbbucket.SetRaw(id, 0, value.Bytes())
+	if err != nil {
+		return query.NewError(err, "Error doing upsert")
+	}
+	return nil
+}
+
+func (b *bucket) Replace(id string, value *dparval.Value) query.Error {
+	var notFound query.Error
+	err := b.cbbucket.Update(id, 0, func(current []byte) ([]byte, error) {
+		if current == nil {
+			notFound = query.NewKeyNotFound(id)
+			return nil, notFound
+		}
+		return value.Bytes(), nil
+	})
+	if notFound != nil {
+		return notFound
+	}
+	if err != nil {
+		return query.NewError(err, "Error doing replace")
+	}
+	return nil
+}
+
+func (b *bucket) Delete(id string) query.Error {
+	// the server tells us when the key is missing, checking first
+	// would race with other writers
+	err := b.cbbucket.Delete(id)
+	if err != nil {
+		if isKeyNotFound(err) {
+			return query.NewKeyNotFound(id)
+		}
+		return query.NewError(err, "Error doing delete")
+	}
+	return nil
+}
+
+func isKeyNotFound(err error) bool {
+	res, ok := err.(*gomemcached.MCResponse)
+	return ok && res.Status == gomemcached.KEY_ENOENT
+}
+
 func newBucket(p *pool, name string) (*bucket, query.Error) {
 	clog.To(catalog.CHANNEL, "Created New Bucket %s", name)
 	cbbucket, err := p.cbpool.GetBucket(name)
@@ -12,6 +12,7 @@ package couchbase
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/dparval"
@@ -31,6 +32,10 @@ type viewIndex struct {
 	on     catalog.IndexKey
 	ddoc   *designdoc
 	bucket *bucket
+	// scanning a view is expensive, so statistics are
+	// only collected by UPDATE STATISTICS
+	statisticsLock sync.Mutex
+	statistics     *catalog.IndexStatistics
 }
 
 type primaryIndex struct {
@@ -227,7 +232,29 @@ func (vi *viewIndex) Lookup(value catalog.LookupValue, ch catalog.EntryChannel,
 }
 
 func (vi *viewIndex) Statistics() (catalog.RangeStatistics, query.Error) {
-	return nil, query.NewError(nil, "statistics not implemented")
+	vi.statisticsLock.Lock()
+	defer vi.statisticsLock.Unlock()
+
+	if vi.statistics == nil {
+		return nil, query.NewError(nil, fmt.Sprintf("No statistics for index %s, run UPDATE STATISTICS", vi.name))
+	}
+	return vi.statistics, nil
+}
+
+func (vi *viewIndex) UpdateStatistics() (catalog.RangeStatistics, query.Error) {
+	documents, err := vi.bucket.Count()
+	if err != nil {
+		return nil, err
+	}
+	statistics, err := catalog.CollectStatistics(vi, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	vi.statisticsLock.Lock()
+	defer vi.statisticsLock.Unlock()
+	vi.statistics = statistics
+	return statistics, nil
 }
 
 func (vi *viewIndex) Direction() catalog.Direction {
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/couchbaselabs/dparval"
 	"github.com/couchbaselabs/tuqtng/catalog"
@@ -202,6 +203,9 @@ type bucket struct {
 	name    string
 	indexes map[string]catalog.Index
 	primary catalog.PrimaryIndex
+	// serializes writes to the bucket directory, and guards indexes.
+	// when both are needed it is taken before the lock of an index
+	lock sync.RWMutex
 }
 
 func (b *bucket) Release() {
@@ -224,10 +228,19 @@ func (b *bucket) Count() (int64, query.Error) {
 	if err != nil {
 		return 0, query.NewError(err, "")
 	}
-	return int64(len(dirEntries)), nil
+	count := int64(0)
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			count++
+		}
+	}
+	return count, nil
 }
 
 func (b *bucket) IndexIds() ([]string, query.Error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
 	rv := make([]string, 0, len(b.indexes))
 	for name, _ := range b.indexes {
 		rv = append(rv, name)
@@ -236,6 +249,9 @@ func (b *bucket) IndexIds() ([]string, query.Error) {
 }
 
 func (b *bucket) IndexNames() ([]string, query.Error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
 	rv := make([]string, 0, len(b.indexes))
 	for name, _ := range b.indexes {
 		rv = append(rv, name)
@@ -248,6 +264,9 @@ func (b *bucket) IndexById(id string) (catalog.Index, query.Error) {
 }
 
 func (b *bucket) IndexByName(name string) (catalog.Index, query.Error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
 	index, ok := b.indexes[name]
 	if !ok {
 		return nil, query.NewError(nil, fmt.Sprintf("Index %v not found.", name))
@@ -264,6 +283,9 @@ func (b *bucket) IndexesByPrimary() ([]catalog.PrimaryIndex, query.Error) {
 }
 
 func (b *bucket) Indexes() ([]catalog.Index, query.Error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
 	rv := make([]catalog.Index, 0, len(b.indexes))
 	for _, index := range b.indexes {
 		rv = append(rv, index)
@@ -286,7 +308,10 @@ func (b *bucket) BulkFetch(ids []string) (map[string]*dparval.Value, query.Error
 }
 
 func (b *bucket) Fetch(id string) (item *dparval.Value, e query.Error) {
-	path := filepath.Join(b.path(), id+".json")
+	path, e := b.documentPath(id)
+	if e != nil {
+		return nil, e
+	}
 	item, e = fetch(path)
 	if e != nil {
 		item = nil
@@ -304,13 +329,131 @@ func (b *bucket) CreatePrimaryIndex() (catalog.PrimaryIndex, query.Error) {
 }
 
 func (b *bucket) CreateIndex(name string, key catalog.IndexKey, using catalog.IndexType) (catalog.Index, query.Error) {
-	return nil, query.NewError(nil, "Not supported.")
+	if using != "" && using != catalog.UNSPECIFIED {
+		return nil, query.NewError(nil, fmt.Sprintf("Index type %s is not supported.", using))
+	}
+	if !validIndexName(name) {
+		return nil, query.NewError(nil, fmt.Sprintf("Invalid index name: %s", name))
+	}
+
+	// writes wait until the index is built, so it misses none of them
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, exists := b.indexes[name]; exists {
+		return nil, query.NewError(nil, fmt.Sprintf("Index already exists: %s", name))
+	}
+
+	idx, e := newRangeIndex(b, name, key)
+	if e != nil {
+		return nil, e
+	}
+	b.indexes[idx.Name()] = idx
+	return idx, nil
+}
+
+func (b *bucket) Insert(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	path, e := b.documentPath(id)
+	if e != nil {
+		return e
+	}
+	exists, e := documentExists(path)
+	if e != nil {
+		return e
+	}
+	if exists {
+		return query.NewKeyExists(id)
+	}
+	return b.updateIndexes(id, store(path, value))
+}
+
+func (b *bucket) Upsert(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	path, e := b.documentPath(id)
+	if e != nil {
+		return e
+	}
+	return b.updateIndexes(id, store(path, value))
+}
+
+func (b *bucket) Replace(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	path, e := b.documentPath(id)
+	if e != nil {
+		return e
+	}
+	exists, e := documentExists(path)
+	if e != nil {
+		return e
+	}
+	if !exists {
+		return query.NewKeyNotFound(id)
+	}
+	return b.updateIndexes(id, store(path, value))
+}
+
+func (b *bucket) Delete(id string) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	path, e := b.documentPath(id)
+	if e != nil {
+		return e
+	}
+	err := os.Remove(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return query.NewKeyNotFound(id)
+		}
+		return query.NewError(err, "")
+	}
+	return b.updateIndexes(id, nil)
+}
+
+// updateIndexes brings the range indexes up to date with a
+// document that was just written, unless the write failed.  the
+// caller must hold the lock
+func (b *bucket) updateIndexes(id string, e query.Error) query.Error {
+	if e != nil {
+		return e
+	}
+	for _, index := range b.indexes {
+		ri, ok := index.(*rangeIndex)
+		if ok {
+			e = ri.update(id)
+			if e != nil {
+				return e
+			}
+		}
+	}
+	return nil
 }
 
 func (b *bucket) path() string {
 	return filepath.Join(b.pool.path(), b.name)
 }
 
+// documentPath returns the file of the document, keys that could
+// name a file outside the bucket directory are rejected
+func (b *bucket) documentPath(id string) (string, query.Error) {
+	if !validDocumentId(id) {
+		return "", query.NewError(nil, fmt.Sprintf("Invalid document key: %q", id))
+	}
+	return filepath.Join(b.path(), id+".json"), nil
+}
+
+// no empty keys, path separators (of any platform) or ".."
+func validDocumentId(id string) bool {
+	return id != "" && !strings.ContainsAny(id, "/\\\x00") && !strings.Contains(id, "..")
+}
+
 // newBucket creates a new bucket.
 func newBucket(p *pool, dir string) (b *bucket, e query.Error) {
 	b = new(bucket)
@@ -333,6 +476,14 @@ func newBucket(p *pool, dir string) (b *bucket, e query.Error) {
 	pi.name = "all_docs"
 	b.indexes[pi.name] = pi
 
+	rangeIndexes, e := loadRangeIndexes(b)
+	if e != nil {
+		return nil, e
+	}
+	for _, ri := range rangeIndexes {
+		b.indexes[ri.Name()] = ri
+	}
+
 	return
 }
 
@@ -390,8 +541,10 @@ func (pi *primaryIndex) ScanEntries(limit int64, ch catalog.EntryChannel, warnch
 		if limit > 0 && int64(i) > limit {
 			break
 		}
-		if !dirEntry.IsDir() {
-			entry := catalog.IndexEntry{PrimaryKey: documentPathToId(dirEntry.Name())}
+		id := documentPathToId(dirEntry.Name())
+		// files not named by a valid key cannot be fetched
+		if !dirEntry.IsDir() && validDocumentId(id) {
+			entry := catalog.IndexEntry{PrimaryKey: id}
 			ch <- &entry
 		}
 	}
@@ -413,6 +566,10 @@ func (pi *primaryIndex) Lookup(value catalog.LookupValue, ch catalog.EntryChanne
 		return
 	}
 
+	if !validDocumentId(val) {
+		// no such document can exist
+		return
+	}
 	fi, err := os.Lstat(filepath.Join(pi.bucket.path(), val+".json"))
 	if err != nil && !os.IsNotExist(err) {
 		errch <- query.NewError(err, "IO error during lookup.")
@@ -442,6 +599,25 @@ func fetch(path string) (item *dparval.Value, e query.Error) {
 	return
 }
 
+func store(path string, value *dparval.Value) query.Error {
+	err := ioutil.WriteFile(path, value.Bytes(), 0666)
+	if err != nil {
+		return query.NewError(err, "")
+	}
+	return nil
+}
+
+func documentExists(path string) (bool, query.Error) {
+	_, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, query.NewError(err, "IO error checking document.")
+	}
+	return true, nil
+}
+
 func documentPathToId(p string) string {
 	_, file := filepath.Split(p)
 	ext := filepath.Ext(file)
@@ -10,8 +10,17 @@
 package file
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/query"
 )
@@ -107,3 +116,458 @@ func TestFile(t *testing.T) {
 	}
 
 }
+
+func TestFileWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-file")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "pool", "docs"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+
+	site, qerr := NewSite(dir)
+	if qerr != nil {
+		t.Fatalf("failed to create site: %v", qerr)
+	}
+	pool, qerr := site.PoolByName("pool")
+	if qerr != nil {
+		t.Fatalf("failed to get pool: %v", qerr)
+	}
+	bucket, qerr := pool.BucketByName("docs")
+	if qerr != nil {
+		t.Fatalf("failed to get bucket: %v", qerr)
+	}
+
+	doc := dparval.NewValue(map[string]interface{}{"name": "fred"})
+	qerr = bucket.Insert("fred", doc)
+	if qerr != nil {
+		t.Errorf("failed to insert fred: %v", qerr)
+	}
+	qerr = bucket.Insert("fred", doc)
+	if qerr == nil || qerr.TranslationKey() != "key_exists" {
+		t.Errorf("expected key_exists inserting fred twice, got %v", qerr)
+	}
+	qerr = bucket.Replace("wilma", doc)
+	if qerr == nil || qerr.TranslationKey() != "key_not_found" {
+		t.Errorf("expected key_not_found replacing wilma, got %v", qerr)
+	}
+	qerr = bucket.Upsert("wilma", dparval.NewValue(map[string]interface{}{"name": "wilma"}))
+	if qerr != nil {
+		t.Errorf("failed to upsert wilma: %v", qerr)
+	}
+	qerr = bucket.Replace("fred", dparval.NewValue(map[string]interface{}{"name": "fred", "age": 40.0}))
+	if qerr != nil {
+		t.Errorf("failed to replace fred: %v", qerr)
+	}
+
+	item, qerr := bucket.Fetch("fred")
+	if qerr != nil || item == nil {
+		t.Fatalf("failed to fetch fred: %v", qerr)
+	}
+	age, _ := item.Path("age")
+	if age == nil || age.Value() != 40.0 {
+		t.Errorf("expected replaced fred to have age 40, got %v", age)
+	}
+
+	count, qerr := bucket.Count()
+	if qerr != nil || count != 2 {
+		t.Errorf("expected 2 documents, got %d (%v)", count, qerr)
+	}
+
+	qerr = bucket.Delete("fred")
+	if qerr != nil {
+		t.Errorf("failed to delete fred: %v", qerr)
+	}
+	qerr = bucket.Delete("fred")
+	if qerr == nil || qerr.TranslationKey() != "key_not_found" {
+		t.Errorf("expected key_not_found deleting fred twice, got %v", qerr)
+	}
+	item, _ = bucket.Fetch("fred")
+	if item != nil {
+		t.Errorf("expected fred to be gone, got %v", item)
+	}
+}
+
+func TestFileInvalidKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-file")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "pool", "docs"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+	// a document outside the bucket that must not be read or removed
+	outside := filepath.Join(dir, "pool", "outside.json")
+	err = ioutil.WriteFile(outside, []byte(`{"name": "outside"}`), 0666)
+	if err != nil {
+		t.Fatalf("failed to write document: %v", err)
+	}
+
+	site, qerr := NewSite(dir)
+	if qerr != nil {
+		t.Fatalf("failed to create site: %v", qerr)
+	}
+	pool, qerr := site.PoolByName("pool")
+	if qerr != nil {
+		t.Fatalf("failed to get pool: %v", qerr)
+	}
+	bucket, qerr := pool.BucketByName("docs")
+	if qerr != nil {
+		t.Fatalf("failed to get bucket: %v", qerr)
+	}
+
+	doc := dparval.NewValue(map[string]interface{}{"name": "evil"})
+	for _, id := range []string{"", "../outside", "../../escaped", "a/b", "a\\b", ".."} {
+		if qerr := bucket.Insert(id, doc); qerr == nil {
+			t.Errorf("expected inserting %q to fail", id)
+		}
+		if qerr := bucket.Upsert(id, doc); qerr == nil {
+			t.Errorf("expected upserting %q to fail", id)
+		}
+		if qerr := bucket.Replace(id, doc); qerr == nil {
+			t.Errorf("expected replacing %q to fail", id)
+		}
+		if qerr := bucket.Delete(id); qerr == nil {
+			t.Errorf("expected deleting %q to fail", id)
+		}
+		if item, qerr := bucket.Fetch(id); qerr == nil || item != nil {
+			t.Errorf("expected fetching %q to fail, got %v", id, item)
+		}
+	}
+
+	bytes, err := ioutil.ReadFile(outside)
+	if err != nil || string(bytes) != `{"name": "outside"}` {
+		t.Errorf("expected the document outside the bucket to be untouched, got %s (%v)", bytes, err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "escaped.json"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected no document to be written outside the bucket")
+	}
+	count, qerr := bucket.Count()
+	if qerr != nil || count != 0 {
+		t.Errorf("expected no documents in the bucket, got %d (%v)", count, qerr)
+	}
+}
+
+func TestFileRangeIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-file")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	docs := filepath.Join(dir, "pool", "docs")
+	err = os.MkdirAll(docs, 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+	writeDoc := func(id string, doc string) {
+		err := ioutil.WriteFile(filepath.Join(docs, id+".json"), []byte(doc), 0666)
+		if err != nil {
+			t.Fatalf("failed to write %v: %v", id, err)
+		}
+	}
+	writeDoc("a", `{"age": 30}`)
+	writeDoc("b", `{"age": 40}`)
+	writeDoc("c", `{"name": "c"}`)
+	writeDoc("d", `{"age": "x"}`)
+
+	openBucket := func() catalog.Bucket {
+		site, qerr := NewSite(dir)
+		if qerr != nil {
+			t.Fatalf("failed to create site: %v", qerr)
+		}
+		pool, qerr := site.PoolByName("pool")
+		if qerr != nil {
+			t.Fatalf("failed to get pool: %v", qerr)
+		}
+		bucket, qerr := pool.BucketByName("docs")
+		if qerr != nil {
+			t.Fatalf("failed to get bucket: %v", qerr)
+		}
+		return bucket
+	}
+	openIndex := func(bucket catalog.Bucket) catalog.RangeIndex {
+		index, qerr := bucket.IndexByName("age_idx")
+		if qerr != nil {
+			t.Fatalf("failed to get index: %v", qerr)
+		}
+		return index.(catalog.RangeIndex)
+	}
+
+	bucket := openBucket()
+	_, qerr := bucket.CreateIndex("age_idx", catalog.IndexKey{ast.NewProperty("age")}, "")
+	if qerr != nil {
+		t.Fatalf("failed to create index: %v", qerr)
+	}
+	_, qerr = bucket.CreateIndex("age_idx", catalog.IndexKey{ast.NewProperty("age")}, "")
+	if qerr == nil {
+		t.Errorf("expected error creating index twice")
+	}
+	index := openIndex(bucket)
+
+	thirtyToForty := func(index catalog.RangeIndex) []string {
+		return scanRange(t, index, lookup(30.0), lookup(40.0), catalog.Both)
+	}
+
+	expectIds(t, "initial range", thirtyToForty(index), []string{"a", "b"})
+	expectIds(t, "open range", scanRange(t, index, lookup(35.0), nil, catalog.Both), []string{"b", "d"})
+	expectIds(t, "exclusive range", scanRange(t, index, lookup(30.0), lookup(40.0), catalog.Neither), []string{})
+
+	// writes through the bucket
+	qerr = bucket.Upsert("c", dparval.NewValue(map[string]interface{}{"name": "c", "age": 35.0}))
+	if qerr != nil {
+		t.Fatalf("failed to upsert c: %v", qerr)
+	}
+	expectIds(t, "after upsert", thirtyToForty(index), []string{"a", "c", "b"})
+
+	// changes made directly on disk
+	writeDoc("e", `{"age": 31}`)
+	os.Remove(filepath.Join(docs, "a.json"))
+	expectIds(t, "after disk changes", thirtyToForty(index), []string{"e", "c", "b"})
+
+	// and after a restart
+	index = openIndex(openBucket())
+	expectIds(t, "after restart", thirtyToForty(index), []string{"e", "c", "b"})
+
+	ch := make(catalog.EntryChannel)
+	warnch := make(query.ErrorChannel)
+	errch := make(query.ErrorChannel)
+	go index.Lookup(lookup(35.0), ch, warnch, errch)
+	expectIds(t, "lookup", collectIds(t, ch, warnch, errch), []string{"c"})
+
+	// reading statistics does not collect them
+	_, qerr = index.Statistics()
+	if qerr == nil {
+		t.Errorf("expected no statistics before they are updated")
+	}
+	_, qerr = index.UpdateStatistics()
+	if qerr != nil {
+		t.Fatalf("failed to update statistics: %v", qerr)
+	}
+	stats, qerr := index.Statistics()
+	if qerr != nil {
+		t.Fatalf("failed to get statistics: %v", qerr)
+	}
+	count, _ := stats.Count()
+	min, _ := stats.Min()
+	max, _ := stats.Max()
+	if count != 4 || min.Value() != 31.0 || max.Value() != "x" {
+		t.Errorf("expected 4 entries from 31 to x, got %v from %v to %v", count, min.Value(), max.Value())
+	}
+	distinct, _ := stats.DistinctCount()
+	missing, _ := stats.MissingCount()
+	if distinct != 4 || missing != 0 {
+		t.Errorf("expected 4 distinct and none missing, got %v and %v", distinct, missing)
+	}
+
+	// statistics only change when they are updated
+	writeDoc("f", `{"name": "f"}`)
+	stats, _ = index.Statistics()
+	missing, _ = stats.MissingCount()
+	if missing != 0 {
+		t.Errorf("expected statistics to be unchanged, got %v missing", missing)
+	}
+	_, qerr = index.UpdateStatistics()
+	if qerr != nil {
+		t.Fatalf("failed to update statistics: %v", qerr)
+	}
+	stats, _ = openIndex(openBucket()).Statistics()
+	count, _ = stats.Count()
+	missing, _ = stats.MissingCount()
+	if count != 4 || missing != 1 {
+		t.Errorf("expected 4 entries and 1 missing after a restart, got %v and %v", count, missing)
+	}
+
+	bucket = openBucket()
+	qerr = openIndex(bucket).Drop()
+	if qerr != nil {
+		t.Fatalf("failed to drop index: %v", qerr)
+	}
+	_, qerr = openBucket().IndexByName("age_idx")
+	if qerr == nil {
+		t.Errorf("expected dropped index to be gone after a restart")
+	}
+	count, _ = bucket.Count()
+	if count != 5 {
+		t.Errorf("expected 5 documents, got %v", count)
+	}
+}
+
+func TestFileIndexLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-file")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	docs := filepath.Join(dir, "pool", "docs")
+	err = os.MkdirAll(docs, 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+	openBucket := func() catalog.Bucket {
+		site, _ := NewSite(dir)
+		pool, _ := site.PoolByName("pool")
+		bucket, qerr := pool.BucketByName("docs")
+		if qerr != nil {
+			t.Fatalf("failed to get bucket: %v", qerr)
+		}
+		return bucket
+	}
+
+	bucket := openBucket()
+	_, qerr := bucket.CreateIndex("n_idx", catalog.IndexKey{ast.NewProperty("n")}, "")
+	if qerr != nil {
+		t.Fatalf("failed to create index: %v", qerr)
+	}
+	indexFile := filepath.Join(docs, INDEX_DIR, "n_idx.json")
+	logFile := filepath.Join(docs, INDEX_DIR, "n_idx.log")
+	before, _ := ioutil.ReadFile(indexFile)
+
+	// writes only go to the log
+	for i := 0; i < 10; i++ {
+		qerr = bucket.Upsert(fmt.Sprintf("doc%d", i), dparval.NewValue(map[string]interface{}{"n": float64(i)}))
+		if qerr != nil {
+			t.Fatalf("failed to upsert: %v", qerr)
+		}
+	}
+	qerr = bucket.Delete("doc0")
+	if qerr != nil {
+		t.Fatalf("failed to delete: %v", qerr)
+	}
+	after, _ := ioutil.ReadFile(indexFile)
+	if string(before) != string(after) {
+		t.Errorf("expected the index file to be unchanged by writes")
+	}
+
+	index, _ := openBucket().IndexByName("n_idx")
+	ids := scanRange(t, index.(catalog.RangeIndex), lookup(0.0), lookup(3.0), catalog.Both)
+	expectIds(t, "after replaying the log", ids, []string{"doc1", "doc2", "doc3"})
+
+	// until the log is folded into the index file
+	for i := 0; i < INDEX_LOG_LIMIT; i++ {
+		qerr = bucket.Upsert("doc1", dparval.NewValue(map[string]interface{}{"n": float64(i)}))
+		if qerr != nil {
+			t.Fatalf("failed to upsert: %v", qerr)
+		}
+	}
+	after, _ = ioutil.ReadFile(indexFile)
+	if string(before) == string(after) {
+		t.Errorf("expected the index file to be saved once the log is full")
+	}
+	log, _ := ioutil.ReadFile(logFile)
+	lines := strings.Count(string(log), "\n")
+	if lines >= INDEX_LOG_LIMIT {
+		t.Errorf("expected the log to be started again, it has %v changes", lines)
+	}
+	index, _ = openBucket().IndexByName("n_idx")
+	ids = scanRange(t, index.(catalog.RangeIndex), lookup(0.0), lookup(3.0), catalog.Both)
+	expectIds(t, "after saving the index", ids, []string{"doc2", "doc3"})
+}
+
+func TestFileIndexConcurrency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-file")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "pool", "docs"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+	site, _ := NewSite(dir)
+	pool, _ := site.PoolByName("pool")
+	bucket, qerr := pool.BucketByName("docs")
+	if qerr != nil {
+		t.Fatalf("failed to get bucket: %v", qerr)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		name := fmt.Sprintf("idx%d", i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				index, qerr := bucket.CreateIndex(name, catalog.IndexKey{ast.NewProperty("n")}, "")
+				if qerr != nil {
+					t.Errorf("failed to create index: %v", qerr)
+					return
+				}
+				qerr = index.Drop()
+				if qerr != nil {
+					t.Errorf("failed to drop index: %v", qerr)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				bucket.Upsert(fmt.Sprintf("%s-%d", name, j), dparval.NewValue(map[string]interface{}{"n": float64(j)}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				bucket.Indexes()
+				bucket.IndexNames()
+				bucket.IndexByName(name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	names, _ := bucket.IndexNames()
+	if !reflect.DeepEqual(names, []string{"all_docs"}) {
+		t.Errorf("expected only the primary index to be left, got %v", names)
+	}
+}
+
+func lookup(val interface{}) catalog.LookupValue {
+	return catalog.LookupValue{dparval.NewValue(val)}
+}
+
+func scanRange(t *testing.T, index catalog.RangeIndex, low, high catalog.LookupValue, inclusion catalog.RangeInclusion) []string {
+	ch := make(catalog.EntryChannel)
+	warnch := make(query.ErrorChannel)
+	errch := make(query.ErrorChannel)
+	go index.ScanRange(low, high, inclusion, 0, ch, warnch, errch)
+	return collectIds(t, ch, warnch, errch)
+}
+
+func collectIds(t *testing.T, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) []string {
+	ids := []string{}
+	var entry *catalog.IndexEntry
+	var err query.Error
+	ok := true
+	for ok {
+		select {
+		case entry, ok = <-ch:
+			if ok {
+				ids = append(ids, entry.PrimaryKey)
+			}
+		case _, ok = <-warnch:
+		case err, ok = <-errch:
+			if err != nil {
+				t.Errorf("got error while scanning: %v", err)
+			}
+		}
+	}
+	return ids
+}
+
+func expectIds(t *testing.T, what string, actual, expected []string) {
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("%s: expected %v, got %v", what, expected, actual)
+	}
+}
@@ -0,0 +1,664 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// range indexes are kept in this directory inside the bucket
+// directory, one file per index
+const INDEX_DIR = ".indexes"
+
+// changes to an index are appended to its log, which is folded into
+// the index file once it holds this many of them
+const INDEX_LOG_LIMIT = 1000
+
+// how long a scan trusts the index when the bucket directory itself
+// has not changed.  documents rewritten in place by other means than
+// the bucket do not change the directory, so they are reindexed by
+// the first scan after this
+const INDEX_REFRESH_INTERVAL = 5 * time.Second
+
+// rangeIndex is a persistent secondary index on the documents of a
+// file-based bucket.  the entries are kept in memory and saved with
+// the definition, along with the modification time and size of every
+// document when it was indexed.  before a scan the bucket directory
+// is compared against those, so documents changed on disk by other
+// means than the bucket are reindexed
+type rangeIndex struct {
+	name      string
+	bucket    *bucket
+	key       catalog.IndexKey
+	lock      sync.Mutex
+	documents map[string]*indexedDocument
+	entries   indexEntries // sorted by key, then primary key
+	// collected by UPDATE STATISTICS only
+	statistics *catalog.IndexStatistics
+	logged     int       // changes in the log since the index file was saved
+	dirChanged time.Time // of the bucket directory at the last refresh
+	refreshed  time.Time
+}
+
+// indexedDocument is the state of a document when it was indexed,
+// Key is nil for documents that have no value for the index key
+type indexedDocument struct {
+	Modified int64         `json:"modified"`
+	Size     int64         `json:"size"`
+	Key      []interface{} `json:"key,omitempty"`
+}
+
+// the file format of an index
+type indexFile struct {
+	Name       string                      `json:"name"`
+	Key        []json.RawMessage           `json:"key"`
+	Documents  map[string]*indexedDocument `json:"documents"`
+	Statistics *catalog.IndexStatistics    `json:"statistics,omitempty"`
+}
+
+// a line of the log, Document is nil for a removed document
+type indexChange struct {
+	Id       string           `json:"id"`
+	Document *indexedDocument `json:"document"`
+}
+
+type indexEntry struct {
+	key []interface{}
+	id  string
+}
+
+type indexEntries []*indexEntry
+
+func (this indexEntries) Len() int {
+	return len(this)
+}
+
+func (this indexEntries) Less(i, j int) bool {
+	comp := ast.CollateJSON(this[i].key, this[j].key)
+	if comp == 0 {
+		return this[i].id < this[j].id
+	}
+	return comp < 0
+}
+
+func (this indexEntries) Swap(i, j int) {
+	this[i], this[j] = this[j], this[i]
+}
+
+// newRangeIndex creates and builds a new index
+func newRangeIndex(b *bucket, name string, key catalog.IndexKey) (*rangeIndex, query.Error) {
+	// make sure the index can be loaded again after a restart
+	for _, expr := range key {
+		bytes, err := json.Marshal(expr)
+		if err != nil {
+			return nil, query.NewError(err, fmt.Sprintf("Cannot save index key %v", expr))
+		}
+		_, err = unmarshalIndexKey(bytes)
+		if err != nil {
+			return nil, query.NewError(err, fmt.Sprintf("Index key %v is not supported", expr))
+		}
+	}
+
+	ri := &rangeIndex{
+		name:      name,
+		bucket:    b,
+		key:       key,
+		documents: make(map[string]*indexedDocument),
+	}
+
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	_, e := ri.refresh()
+	if e != nil {
+		return nil, e
+	}
+	e = ri.save()
+	if e != nil {
+		return nil, e
+	}
+	return ri, nil
+}
+
+// loadRangeIndexes loads the indexes saved in the bucket directory
+func loadRangeIndexes(b *bucket) ([]*rangeIndex, query.Error) {
+	dirEntries, err := ioutil.ReadDir(filepath.Join(b.path(), INDEX_DIR))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, query.NewError(err, "Error loading indexes")
+	}
+
+	rv := make([]*rangeIndex, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || filepath.Ext(dirEntry.Name()) != ".json" {
+			continue
+		}
+		ri, e := loadRangeIndex(b, filepath.Join(b.path(), INDEX_DIR, dirEntry.Name()))
+		if e != nil {
+			// one bad index should not make the bucket unusable
+			clog.Warnf("Unable to load index %v of bucket %v: %v", dirEntry.Name(), b.Name(), e)
+			continue
+		}
+		rv = append(rv, ri)
+	}
+	return rv, nil
+}
+
+func loadRangeIndex(b *bucket, path string) (*rangeIndex, query.Error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, query.NewError(err, "")
+	}
+
+	var saved indexFile
+	err = json.Unmarshal(bytes, &saved)
+	if err != nil {
+		return nil, query.NewError(err, "Index file is not valid")
+	}
+
+	key := make(catalog.IndexKey, len(saved.Key))
+	for i, ser := range saved.Key {
+		key[i], err = unmarshalIndexKey(ser)
+		if err != nil {
+			return nil, query.NewError(err, "Cannot unmarshal index key")
+		}
+	}
+
+	ri := &rangeIndex{
+		name:       saved.Name,
+		bucket:     b,
+		key:        key,
+		documents:  saved.Documents,
+		statistics: saved.Statistics,
+	}
+	if ri.documents == nil {
+		ri.documents = make(map[string]*indexedDocument)
+	}
+	e := ri.replayLog()
+	if e != nil {
+		return nil, e
+	}
+	ri.sortEntries()
+	return ri, nil
+}
+
+// replayLog applies the changes made since the index file was saved
+func (ri *rangeIndex) replayLog() query.Error {
+	f, err := os.Open(ri.logPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return query.NewError(err, "Cannot read index log")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var change indexChange
+		err = json.Unmarshal(scanner.Bytes(), &change)
+		if err != nil {
+			// a crash in the middle of a write leaves half a line,
+			// whatever it was about is reindexed by the next refresh
+			clog.Warnf("Ignoring the rest of the log of index %v: %v", ri.name, err)
+			break
+		}
+		if change.Document == nil {
+			delete(ri.documents, change.Id)
+		} else {
+			ri.documents[change.Id] = change.Document
+		}
+		ri.logged++
+	}
+	err = scanner.Err()
+	if err != nil {
+		return query.NewError(err, "Cannot read index log")
+	}
+	return nil
+}
+
+// UnmarshalExpression panics on expressions it does not support
+func unmarshalIndexKey(bytes []byte) (expr ast.Expression, err error) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return ast.UnmarshalExpression(bytes)
+}
+
+func (ri *rangeIndex) BucketId() string {
+	return ri.bucket.Id()
+}
+
+func (ri *rangeIndex) Id() string {
+	return ri.Name()
+}
+
+func (ri *rangeIndex) Name() string {
+	return ri.name
+}
+
+func (ri *rangeIndex) Type() catalog.IndexType {
+	return catalog.UNSPECIFIED
+}
+
+func (ri *rangeIndex) IsPrimary() bool {
+	return false
+}
+
+func (ri *rangeIndex) Key() catalog.IndexKey {
+	return ri.key
+}
+
+func (ri *rangeIndex) Direction() catalog.Direction {
+	return catalog.ASC
+}
+
+func (ri *rangeIndex) Drop() query.Error {
+	ri.bucket.lock.Lock()
+	defer ri.bucket.lock.Unlock()
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	err := os.Remove(ri.path())
+	if err != nil && !os.IsNotExist(err) {
+		return query.NewError(err, fmt.Sprintf("Cannot drop index %s", ri.Name()))
+	}
+	err = os.Remove(ri.logPath())
+	if err != nil && !os.IsNotExist(err) {
+		return query.NewError(err, fmt.Sprintf("Cannot drop index %s", ri.Name()))
+	}
+	delete(ri.bucket.indexes, ri.name)
+	return nil
+}
+
+func (ri *rangeIndex) ScanEntries(limit int64, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	ri.ScanRange(nil, nil, catalog.Both, limit, ch, warnch, errch)
+}
+
+func (ri *rangeIndex) Lookup(value catalog.LookupValue, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	ri.ScanRange(value, value, catalog.Both, 0, ch, warnch, errch)
+}
+
+func (ri *rangeIndex) ScanRange(low catalog.LookupValue, high catalog.LookupValue, inclusion catalog.RangeInclusion, limit int64, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	defer close(ch)
+	defer close(warnch)
+	defer close(errch)
+
+	entries, e := ri.currentEntries()
+	if e != nil {
+		errch <- e
+		return
+	}
+
+	lowKey := lookupValueToKey(low)
+	highKey := lookupValueToKey(high)
+	lowIncluded := inclusion == catalog.Low || inclusion == catalog.Both
+	highIncluded := inclusion == catalog.High || inclusion == catalog.Both
+
+	// find the first entry in the range
+	start := 0
+	if lowKey != nil {
+		start = sort.Search(len(entries), func(i int) bool {
+			comp := comparePrefix(entries[i].key, lowKey)
+			return comp > 0 || (comp == 0 && lowIncluded)
+		})
+	}
+
+	sent := int64(0)
+	for _, entry := range entries[start:] {
+		if limit > 0 && sent >= limit {
+			break
+		}
+		if highKey != nil {
+			comp := comparePrefix(entry.key, highKey)
+			if comp > 0 || (comp == 0 && !highIncluded) {
+				break
+			}
+		}
+		ch <- &catalog.IndexEntry{EntryKey: keyToLookupValue(entry.key), PrimaryKey: entry.id}
+		sent++
+	}
+}
+
+func (ri *rangeIndex) Statistics() (catalog.RangeStatistics, query.Error) {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	if ri.statistics == nil {
+		return nil, query.NewError(nil, fmt.Sprintf("No statistics for index %s, run UPDATE STATISTICS", ri.name))
+	}
+	return ri.statistics, nil
+}
+
+func (ri *rangeIndex) UpdateStatistics() (catalog.RangeStatistics, query.Error) {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	_, e := ri.refresh()
+	if e != nil {
+		return nil, e
+	}
+
+	collector := catalog.NewStatisticsCollector()
+	for _, entry := range ri.entries {
+		collector.Add(entry.key[0])
+	}
+	ri.statistics = collector.Statistics(int64(len(ri.documents)))
+	e = ri.save()
+	if e != nil {
+		return nil, e
+	}
+	return ri.statistics, nil
+}
+
+// currentEntries brings the index up to date with the bucket directory
+// and returns its entries, which are never modified once returned
+func (ri *rangeIndex) currentEntries() (indexEntries, query.Error) {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	fi, err := os.Stat(ri.bucket.path())
+	if err != nil {
+		return nil, query.NewError(err, "")
+	}
+	if fi.ModTime().Equal(ri.dirChanged) && time.Since(ri.refreshed) < INDEX_REFRESH_INTERVAL {
+		return ri.entries, nil
+	}
+
+	changed, e := ri.refresh()
+	if e != nil {
+		return nil, e
+	}
+	e = ri.logChanges(changed)
+	if e != nil {
+		return nil, e
+	}
+	return ri.entries, nil
+}
+
+// refresh reindexes the documents that changed since they were
+// indexed and returns their ids, the caller must hold the lock and
+// persist the changes
+func (ri *rangeIndex) refresh() ([]string, query.Error) {
+	// taken first, so changes made while reading are seen next time
+	fi, err := os.Stat(ri.bucket.path())
+	if err != nil {
+		return nil, query.NewError(err, "")
+	}
+	refreshed := time.Now()
+	dirEntries, err := ioutil.ReadDir(ri.bucket.path())
+	if err != nil {
+		return nil, query.NewError(err, "")
+	}
+
+	changed := []string{}
+	seen := make(map[string]bool, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		id := documentPathToId(dirEntry.Name())
+		if !validDocumentId(id) {
+			continue
+		}
+		seen[id] = true
+		doc, ok := ri.documents[id]
+		if ok && doc.Modified == dirEntry.ModTime().UnixNano() && doc.Size == dirEntry.Size() {
+			continue
+		}
+		e := ri.indexDocument(id, dirEntry)
+		if e != nil {
+			return nil, e
+		}
+		changed = append(changed, id)
+	}
+
+	for id, _ := range ri.documents {
+		if !seen[id] {
+			delete(ri.documents, id)
+			changed = append(changed, id)
+		}
+	}
+
+	if len(changed) > 0 {
+		ri.sortEntries()
+	}
+	ri.dirChanged = fi.ModTime()
+	ri.refreshed = refreshed
+	return changed, nil
+}
+
+// update reindexes a single document written through the bucket
+func (ri *rangeIndex) update(id string) query.Error {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	path, e := ri.bucket.documentPath(id)
+	if e != nil {
+		return e
+	}
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return query.NewError(err, "")
+		}
+		delete(ri.documents, id)
+	} else {
+		e := ri.indexDocument(id, fi)
+		if e != nil {
+			return e
+		}
+	}
+
+	ri.sortEntries()
+	return ri.logChanges([]string{id})
+}
+
+// the caller must hold the lock
+func (ri *rangeIndex) indexDocument(id string, fi os.FileInfo) query.Error {
+	doc := &indexedDocument{
+		Modified: fi.ModTime().UnixNano(),
+		Size:     fi.Size(),
+	}
+	ri.documents[id] = doc
+
+	path, e := ri.bucket.documentPath(id)
+	if e != nil {
+		return e
+	}
+	item, e := fetch(path)
+	if e != nil {
+		return e
+	}
+	if item == nil {
+		// removed since the directory was read
+		delete(ri.documents, id)
+		return nil
+	}
+
+	key := make([]interface{}, len(ri.key))
+	for i, expr := range ri.key {
+		val, err := expr.Evaluate(item)
+		if err != nil {
+			// documents missing any part of the key are not indexed
+			_, isUndefined := err.(*dparval.Undefined)
+			if !isUndefined {
+				clog.To(catalog.CHANNEL, "unable to index document %v: %v", id, err)
+			}
+			return nil
+		}
+		key[i] = val.Value()
+	}
+	doc.Key = key
+	return nil
+}
+
+// the caller must hold the lock
+func (ri *rangeIndex) sortEntries() {
+	entries := make(indexEntries, 0, len(ri.documents))
+	for id, doc := range ri.documents {
+		if doc.Key != nil {
+			entries = append(entries, &indexEntry{key: doc.Key, id: id})
+		}
+	}
+	sort.Sort(entries)
+	ri.entries = entries
+}
+
+// logChanges appends the current state of the documents to the log,
+// or saves the whole index when the log has grown long enough.  the
+// caller must hold the lock
+func (ri *rangeIndex) logChanges(ids []string) query.Error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if ri.logged+len(ids) > INDEX_LOG_LIMIT {
+		return ri.save()
+	}
+
+	buf := make([]byte, 0, 128*len(ids))
+	for _, id := range ids {
+		bytes, err := json.Marshal(indexChange{Id: id, Document: ri.documents[id]})
+		if err != nil {
+			return query.NewError(err, "Cannot save index")
+		}
+		buf = append(append(buf, bytes...), '\n')
+	}
+
+	err := os.MkdirAll(filepath.Dir(ri.logPath()), 0777)
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+	f, err := os.OpenFile(ri.logPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+	_, err = f.Write(buf)
+	if err != nil {
+		f.Close()
+		return query.NewError(err, "Cannot save index")
+	}
+	err = f.Close()
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+	ri.logged += len(ids)
+	return nil
+}
+
+// save writes the whole index and starts a new log, the caller must
+// hold the lock
+func (ri *rangeIndex) save() query.Error {
+	saved := indexFile{
+		Name:       ri.name,
+		Key:        make([]json.RawMessage, len(ri.key)),
+		Documents:  ri.documents,
+		Statistics: ri.statistics,
+	}
+	for i, expr := range ri.key {
+		bytes, err := json.Marshal(expr)
+		if err != nil {
+			return query.NewError(err, "Cannot save index key")
+		}
+		saved.Key[i] = bytes
+	}
+
+	bytes, err := json.Marshal(saved)
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+
+	err = os.MkdirAll(filepath.Dir(ri.path()), 0777)
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+
+	// write a new file and rename it, so a crash never leaves half an index
+	tmp := ri.path() + ".tmp"
+	err = ioutil.WriteFile(tmp, bytes, 0666)
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+	err = os.Rename(tmp, ri.path())
+	if err != nil {
+		return query.NewError(err, "Cannot save index")
+	}
+	// replaying a log that survives a crash here only brings back
+	// states the next refresh finds out of date
+	err = os.Remove(ri.logPath())
+	if err != nil && !os.IsNotExist(err) {
+		return query.NewError(err, "Cannot save index")
+	}
+	ri.logged = 0
+	return nil
+}
+
+func (ri *rangeIndex) path() string {
+	return filepath.Join(ri.bucket.path(), INDEX_DIR, ri.name+".json")
+}
+
+func (ri *rangeIndex) logPath() string {
+	return filepath.Join(ri.bucket.path(), INDEX_DIR, ri.name+".log")
+}
+
+// compares the leading values of an index key with a range boundary,
+// so a boundary on the first part of a composite key covers all the
+// entries that start with it
+func comparePrefix(key []interface{}, boundary []interface{}) int {
+	if len(key) > len(boundary) {
+		key = key[:len(boundary)]
+	}
+	return ast.CollateJSON(key, boundary)
+}
+
+func lookupValueToKey(value catalog.LookupValue) []interface{} {
+	if value == nil {
+		return nil
+	}
+	rv := make([]interface{}, len(value))
+	for i, v := range value {
+		rv[i] = v.Value()
+	}
+	return rv
+}
+
+func keyToLookupValue(key []interface{}) catalog.LookupValue {
+	rv := make(catalog.LookupValue, len(key))
+	for i, v := range key {
+		rv[i] = dparval.NewValue(v)
+	}
+	return rv
+}
+
+// the names of range indexes are also file names
+func validIndexName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && !strings.HasPrefix(name, ".")
+}
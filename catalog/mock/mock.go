@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/couchbaselabs/dparval"
 	"github.com/couchbaselabs/tuqtng/catalog"
@@ -71,7 +72,7 @@ func NewSite(path string) (catalog.Site, query.Error) {
 		p := &pool{site: s, name: "p" + strconv.Itoa(i), buckets: map[string]*bucket{}}
 		for j := 0; j < nbuckets; j++ {
 			b := &bucket{pool: p, name: "b" + strconv.Itoa(j), nitems: nitems,
-				indexes: map[string]catalog.Index{}}
+				indexes: map[string]catalog.Index{}, writes: map[string]*dparval.Value{}}
 			pi := &primaryIndex{site: s, name: "all_docs", bucket: b}
 			b.primary = pi
 			b.indexes["all_docs"] = pi
@@ -108,6 +109,8 @@ type bucket struct {
 	nitems  int
 	indexes map[string]catalog.Index
 	primary catalog.PrimaryIndex
+	lock    sync.RWMutex
+	writes  map[string]*dparval.Value // written items, nil for deleted ones
 }
 
 func (s *site) Id() string {
@@ -196,7 +199,19 @@ func (b *bucket) Name() string {
 }
 
 func (b *bucket) Count() (int64, query.Error) {
-	return int64(b.nitems), nil
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	count := b.nitems
+	for id, item := range b.writes {
+		generated := b.generated(id)
+		if item == nil && generated {
+			count--
+		} else if item != nil && !generated {
+			count++
+		}
+	}
+	return int64(count), nil
 }
 
 func (b *bucket) IndexIds() ([]string, query.Error) {
@@ -246,12 +261,27 @@ func (b *bucket) BulkFetch(ids []string) (map[string]*dparval.Value, query.Error
 		if e != nil {
 			return nil, e
 		}
-		rv[id] = item
+		if item != nil {
+			rv[id] = item
+		}
 	}
 	return rv, nil
 }
 
 func (b *bucket) Fetch(id string) (item *dparval.Value, e query.Error) {
+	b.lock.RLock()
+	written, ok := b.writes[id]
+	b.lock.RUnlock()
+	if ok {
+		if written == nil {
+			// deleted
+			return nil, nil
+		}
+		doc := written.Duplicate()
+		doc.SetAttachment("meta", map[string]interface{}{"id": id})
+		return doc, nil
+	}
+
 	i, err := strconv.Atoi(id)
 	if err != nil {
 		return nil, query.NewError(err,
@@ -272,6 +302,81 @@ func (b *bucket) CreateIndex(name string, key catalog.IndexKey, using catalog.In
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *bucket) Insert(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.exists(id) {
+		return query.NewKeyExists(id)
+	}
+	b.writes[id] = value.Duplicate()
+	return nil
+}
+
+func (b *bucket) Upsert(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.writes[id] = value.Duplicate()
+	return nil
+}
+
+func (b *bucket) Replace(id string, value *dparval.Value) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.exists(id) {
+		return query.NewKeyNotFound(id)
+	}
+	b.writes[id] = value.Duplicate()
+	return nil
+}
+
+func (b *bucket) Delete(id string) query.Error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.exists(id) {
+		return query.NewKeyNotFound(id)
+	}
+	b.writes[id] = nil
+	return nil
+}
+
+// generated reports whether the id is one of the generated mock items
+func (b *bucket) generated(id string) bool {
+	i, err := strconv.Atoi(id)
+	return err == nil && i >= 0 && i < b.nitems && strconv.Itoa(i) == id
+}
+
+// callers must hold the lock
+func (b *bucket) exists(id string) bool {
+	item, ok := b.writes[id]
+	if ok {
+		return item != nil
+	}
+	return b.generated(id)
+}
+
+// ids of written items that are not generated ones, and
+// the set of deleted ids, as of now
+func (b *bucket) writtenIds() ([]string, map[string]bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	added := make([]string, 0)
+	deleted := make(map[string]bool)
+	for id, item := range b.writes {
+		if item == nil {
+			deleted[id] = true
+		} else if !b.generated(id) {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+	return added, deleted
+}
+
 type primaryIndex struct {
 	site   *site
 	name   string
@@ -320,13 +425,30 @@ func (pi *primaryIndex) scanEntries(limit int64, ch catalog.EntryChannel, warnch
 	defer close(warnch)
 	defer close(errch)
 
+	added, deleted := pi.bucket.writtenIds()
+
 	if limit == 0 {
-		limit = int64(pi.bucket.nitems)
+		limit = int64(pi.bucket.nitems + len(added))
+	}
+
+	var count int64
+	for i := 0; i < pi.bucket.nitems && count < limit; i++ {
+		id := strconv.Itoa(i)
+		if deleted[id] {
+			continue
+		}
+		entry := catalog.IndexEntry{PrimaryKey: id}
+		ch <- &entry
+		count++
 	}
 
-	for i := 0; i < pi.bucket.nitems && int64(i) < limit; i++ {
-		entry := catalog.IndexEntry{PrimaryKey: strconv.Itoa(i)}
+	for _, id := range added {
+		if count >= limit {
+			break
+		}
+		entry := catalog.IndexEntry{PrimaryKey: id}
 		ch <- &entry
+		count++
 	}
 }
 
@@ -346,8 +468,11 @@ func (pi *primaryIndex) Lookup(value catalog.LookupValue, ch catalog.EntryChanne
 		return
 	}
 
-	ival, err := strconv.Atoi(val)
-	if err == nil && ival < pi.bucket.nitems {
+	pi.bucket.lock.RLock()
+	exists := pi.bucket.exists(val)
+	pi.bucket.lock.RUnlock()
+
+	if exists {
 		entry := catalog.IndexEntry{PrimaryKey: val}
 		ch <- &entry
 	}
@@ -10,8 +10,13 @@
 package mock
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/query"
 )
 
 func TestMock(t *testing.T) {
@@ -134,3 +139,64 @@ func TestMock(t *testing.T) {
 		t.Errorf("expected not-an-item")
 	}
 }
+
+func TestMockWrite(t *testing.T) {
+	s, _ := NewSite("mock:items=10")
+	p, _ := s.PoolByName("p0")
+	b, _ := p.BucketByName("b0")
+
+	doc := dparval.NewValue(map[string]interface{}{"name": "fred"})
+	err := b.Insert("3", doc)
+	if err == nil || err.TranslationKey() != "key_exists" {
+		t.Errorf("expected key_exists inserting a generated item, got %v", err)
+	}
+	err = b.Insert("fred", doc)
+	if err != nil {
+		t.Errorf("failed to insert fred: %v", err)
+	}
+	err = b.Replace("wilma", doc)
+	if err == nil || err.TranslationKey() != "key_not_found" {
+		t.Errorf("expected key_not_found replacing wilma, got %v", err)
+	}
+	err = b.Replace("3", doc)
+	if err != nil {
+		t.Errorf("failed to replace 3: %v", err)
+	}
+	err = b.Delete("4")
+	if err != nil {
+		t.Errorf("failed to delete 4: %v", err)
+	}
+	err = b.Delete("4")
+	if err == nil || err.TranslationKey() != "key_not_found" {
+		t.Errorf("expected key_not_found deleting 4 twice, got %v", err)
+	}
+
+	item, _ := b.Fetch("3")
+	name, _ := item.Path("name")
+	if name == nil || name.Value() != "fred" {
+		t.Errorf("expected replaced item 3, got %v", item)
+	}
+	item, _ = b.Fetch("4")
+	if item != nil {
+		t.Errorf("expected item 4 to be deleted, got %v", item)
+	}
+
+	count, _ := b.Count()
+	if count != 10 {
+		t.Errorf("expected 10 items, got %v", count)
+	}
+
+	ids := make([]string, 0)
+	pi, _ := b.IndexByPrimary()
+	ch := make(catalog.EntryChannel)
+	warnch := make(query.ErrorChannel)
+	errch := make(query.ErrorChannel)
+	go pi.ScanEntries(0, ch, warnch, errch)
+	for entry := range ch {
+		ids = append(ids, entry.PrimaryKey)
+	}
+	expected := []string{"0", "1", "2", "3", "5", "6", "7", "8", "9", "fred"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected scan %v, got %v", expected, ids)
+	}
+}
@@ -0,0 +1,268 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package catalog
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// the number of bins of the histogram of an index
+const STATISTICS_BINS = 20
+
+// how many index entries are sampled to build the statistics
+var StatisticsSample = 10000
+
+// IndexStatistics are statistics collected from the entries of an
+// index, they only change when they are collected again
+type IndexStatistics struct {
+	Entries   int64       `json:"count"`
+	Distinct  int64       `json:"distinct_count"`
+	Nulls     int64       `json:"null_count"`
+	Missing   int64       `json:"missing_count"`
+	Sampled   int64       `json:"sampled"`
+	Histogram []*IndexBin `json:"bins"`
+	Updated   time.Time   `json:"updated"`
+}
+
+func (this *IndexStatistics) Count() (int64, query.Error) {
+	return this.Entries, nil
+}
+
+func (this *IndexStatistics) Min() (dparval.Value, query.Error) {
+	if len(this.Histogram) == 0 {
+		return dparval.Value{}, query.NewError(nil, "Index is empty.")
+	}
+	return this.Histogram[0].Min()
+}
+
+func (this *IndexStatistics) Max() (dparval.Value, query.Error) {
+	if len(this.Histogram) == 0 {
+		return dparval.Value{}, query.NewError(nil, "Index is empty.")
+	}
+	return this.Histogram[len(this.Histogram)-1].Max()
+}
+
+func (this *IndexStatistics) DistinctCount() (int64, query.Error) {
+	return this.Distinct, nil
+}
+
+func (this *IndexStatistics) NullCount() (int64, query.Error) {
+	return this.Nulls, nil
+}
+
+func (this *IndexStatistics) MissingCount() (int64, query.Error) {
+	return this.Missing, nil
+}
+
+func (this *IndexStatistics) Bins() ([]Bin, query.Error) {
+	rv := make([]Bin, len(this.Histogram))
+	for i, bin := range this.Histogram {
+		rv[i] = bin
+	}
+	return rv, nil
+}
+
+// IndexBin is a bin of the histogram of an index, holding the entries
+// with leading key values from Low to High
+type IndexBin struct {
+	Entries  int64       `json:"count"`
+	Distinct int64       `json:"distinct_count"`
+	Low      interface{} `json:"min"`
+	High     interface{} `json:"max"`
+}
+
+func (this *IndexBin) Count() (int64, query.Error) {
+	return this.Entries, nil
+}
+
+func (this *IndexBin) Min() (dparval.Value, query.Error) {
+	return *dparval.NewValue(this.Low), nil
+}
+
+func (this *IndexBin) Max() (dparval.Value, query.Error) {
+	return *dparval.NewValue(this.High), nil
+}
+
+func (this *IndexBin) DistinctCount() (int64, query.Error) {
+	return this.Distinct, nil
+}
+
+// StatisticsCollector builds index statistics from the leading key
+// values of all the entries of an index, keeping a uniform sample
+// of StatisticsSample values
+type StatisticsCollector struct {
+	entries int64
+	nulls   int64
+	sample  []interface{}
+	random  *rand.Rand
+}
+
+func NewStatisticsCollector() *StatisticsCollector {
+	return &StatisticsCollector{
+		sample: make([]interface{}, 0),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (this *StatisticsCollector) Add(value interface{}) {
+	this.entries++
+	if value == nil {
+		this.nulls++
+	}
+
+	// reservoir sampling, the n-th value replaces one
+	// of the sample with a probability of size/n
+	if len(this.sample) < StatisticsSample {
+		this.sample = append(this.sample, value)
+		return
+	}
+	i := this.random.Int63n(this.entries)
+	if i < int64(len(this.sample)) {
+		this.sample[i] = value
+	}
+}
+
+// Statistics of the values added so far, documents is the number of
+// documents of the bucket, those without an entry are missing
+func (this *StatisticsCollector) Statistics(documents int64) *IndexStatistics {
+	rv := &IndexStatistics{
+		Entries:   this.entries,
+		Nulls:     this.nulls,
+		Sampled:   int64(len(this.sample)),
+		Histogram: []*IndexBin{},
+		Updated:   time.Now(),
+	}
+	if documents > this.entries {
+		rv.Missing = documents - this.entries
+	}
+	if len(this.sample) == 0 {
+		return rv
+	}
+
+	sort.Sort(collatedValues(this.sample))
+	distinct, singletons := countDistinct(this.sample)
+	rv.Distinct = this.estimateDistinct(distinct, singletons)
+
+	// equi-depth bins, equal values are never split over two bins
+	scale := float64(this.entries) / float64(len(this.sample))
+	distinctScale := float64(rv.Distinct) / float64(distinct)
+	depth := int(math.Ceil(float64(len(this.sample)) / STATISTICS_BINS))
+	for start := 0; start < len(this.sample); {
+		end := start + depth
+		for end < len(this.sample) && ast.CollateJSON(this.sample[end], this.sample[end-1]) == 0 {
+			end++
+		}
+		if end > len(this.sample) {
+			end = len(this.sample)
+		}
+		binDistinct, _ := countDistinct(this.sample[start:end])
+		rv.Histogram = append(rv.Histogram, &IndexBin{
+			Entries:  int64(math.Floor(float64(end-start)*scale + 0.5)),
+			Distinct: int64(math.Max(1, math.Floor(float64(binDistinct)*distinctScale+0.5))),
+			Low:      this.sample[start],
+			High:     this.sample[end-1],
+		})
+		start = end
+	}
+	return rv
+}
+
+// estimates the distinct values of all the entries from those of the
+// sample, with the Duj1 estimator of Haas et al.
+func (this *StatisticsCollector) estimateDistinct(distinct, singletons int) int64 {
+	n := float64(len(this.sample))
+	total := float64(this.entries)
+	if n >= total {
+		return int64(distinct)
+	}
+	estimate := n * float64(distinct) / (n - float64(singletons) + float64(singletons)*n/total)
+	return int64(math.Floor(math.Min(total, math.Max(float64(distinct), estimate)) + 0.5))
+}
+
+// the distinct values of sorted values, and how many of them occur once
+func countDistinct(values []interface{}) (distinct, singletons int) {
+	run := 0
+	for i, value := range values {
+		if i > 0 && ast.CollateJSON(value, values[i-1]) != 0 {
+			distinct++
+			if run == 1 {
+				singletons++
+			}
+			run = 0
+		}
+		run++
+	}
+	if run > 0 {
+		distinct++
+		if run == 1 {
+			singletons++
+		}
+	}
+	return
+}
+
+type collatedValues []interface{}
+
+func (this collatedValues) Len() int {
+	return len(this)
+}
+
+func (this collatedValues) Less(i, j int) bool {
+	return ast.CollateJSON(this[i], this[j]) < 0
+}
+
+func (this collatedValues) Swap(i, j int) {
+	this[i], this[j] = this[j], this[i]
+}
+
+// CollectStatistics scans all the entries of a range index
+func CollectStatistics(index RangeIndex, documents int64) (*IndexStatistics, query.Error) {
+	collector := NewStatisticsCollector()
+
+	ch := make(EntryChannel)
+	warnch := make(query.ErrorChannel)
+	errch := make(query.ErrorChannel)
+	go index.ScanRange(nil, nil, Both, 0, ch, warnch, errch)
+
+	var err query.Error
+	for ch != nil || warnch != nil || errch != nil {
+		select {
+		case entry, ok := <-ch:
+			if !ok {
+				ch = nil
+			} else if len(entry.EntryKey) > 0 {
+				collector.Add(entry.EntryKey[0].Value())
+			} else {
+				collector.Add(nil)
+			}
+		case _, ok := <-warnch:
+			if !ok {
+				warnch = nil
+			}
+		case e, ok := <-errch:
+			if !ok {
+				errch = nil
+			} else if err == nil {
+				err = e
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return collector.Statistics(documents), nil
+}
@@ -0,0 +1,89 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package catalog
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/tuqtng/ast"
+)
+
+func TestStatisticsCollector(t *testing.T) {
+	collector := NewStatisticsCollector()
+	for i := 0; i < 100; i++ {
+		collector.Add(float64(i % 10))
+	}
+	collector.Add(nil)
+	collector.Add("a")
+	stats := collector.Statistics(110)
+
+	if stats.Entries != 102 || stats.Nulls != 1 || stats.Missing != 8 || stats.Sampled != 102 {
+		t.Errorf("expected 102 entries, 1 null and 8 missing, got %#v", stats)
+	}
+	// null, 0 to 9 and "a"
+	if stats.Distinct != 12 {
+		t.Errorf("expected 12 distinct values, got %v", stats.Distinct)
+	}
+
+	min, _ := stats.Min()
+	max, _ := stats.Max()
+	if min.Value() != nil || max.Value() != "a" {
+		t.Errorf("expected values from null to a, got %v to %v", min.Value(), max.Value())
+	}
+
+	// equal values stay in one bin
+	total := int64(0)
+	for i, bin := range stats.Histogram {
+		total += bin.Entries
+		if i > 0 && ast.CollateJSON(bin.Low, stats.Histogram[i-1].High) == 0 {
+			t.Errorf("value %v split over bins %v and %v", bin.Low, i-1, i)
+		}
+	}
+	if total != 102 || len(stats.Histogram) > STATISTICS_BINS {
+		t.Errorf("expected 102 entries in at most %v bins, got %v in %v", STATISTICS_BINS, total, len(stats.Histogram))
+	}
+}
+
+func TestStatisticsSample(t *testing.T) {
+	defer func(sample int) { StatisticsSample = sample }(StatisticsSample)
+	StatisticsSample = 100
+
+	collector := NewStatisticsCollector()
+	for i := 0; i < 10000; i++ {
+		collector.Add(float64(i))
+	}
+	stats := collector.Statistics(10000)
+
+	if stats.Entries != 10000 || stats.Sampled != 100 {
+		t.Errorf("expected 100 of 10000 entries sampled, got %v of %v", stats.Sampled, stats.Entries)
+	}
+	// every value is unique, so all are expected to be distinct
+	if stats.Distinct != 10000 {
+		t.Errorf("expected 10000 distinct values, got %v", stats.Distinct)
+	}
+	total := int64(0)
+	for _, bin := range stats.Histogram {
+		total += bin.Entries
+	}
+	if total != 10000 {
+		t.Errorf("expected bins to scale to 10000 entries, got %v", total)
+	}
+}
+
+func TestStatisticsEmpty(t *testing.T) {
+	stats := NewStatisticsCollector().Statistics(5)
+	if stats.Entries != 0 || stats.Missing != 5 || len(stats.Histogram) != 0 {
+		t.Errorf("expected no entries and 5 missing, got %#v", stats)
+	}
+	_, err := stats.Min()
+	if err == nil {
+		t.Errorf("expected error for the min of an empty index")
+	}
+}
@@ -21,6 +21,9 @@ const BUCKET_NAME_POOLS = "pools"
 const BUCKET_NAME_BUCKETS = "buckets"
 const BUCKET_NAME_INDEXES = "indexes"
 const BUCKET_NAME_DUAL = "dual"
+const BUCKET_NAME_ACTIVE_REQUESTS = "active_requests"
+const BUCKET_NAME_COMPLETED_REQUESTS = "completed_requests"
+const BUCKET_NAME_FUNCTIONS = "functions"
 
 type site struct {
 	actualSite        catalog.Site
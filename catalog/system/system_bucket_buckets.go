@@ -132,6 +132,22 @@ func (b *bucketbucket) CreateIndex(name string, key catalog.IndexKey, using cata
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *bucketbucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *bucketbucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *bucketbucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *bucketbucket) Delete(id string) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
 func newBucketsBucket(p *pool) (*bucketbucket, query.Error) {
 	b := new(bucketbucket)
 	b.pool = p
@@ -99,6 +99,22 @@ func (b *dualbucket) CreateIndex(name string, key catalog.IndexKey, using catalo
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *dualbucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *dualbucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *dualbucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *dualbucket) Delete(id string) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
 func newDualBucket(p *pool) (*dualbucket, query.Error) {
 	b := new(dualbucket)
 	b.pool = p
@@ -0,0 +1,159 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package system
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// FunctionsFile keeps the functions created with CREATE FUNCTION
+// across restarts of the server, when empty they only live in memory.
+// the server sets it to a file in the site directory unless told
+// otherwise
+var FunctionsFile = ""
+
+// creating or dropping a function and saving the result happen together
+var functionsMutex sync.Mutex
+
+// DefineFunction adds a function written in N1QL and saves it
+func DefineFunction(definition *ast.FunctionDefinition) query.Error {
+	functionsMutex.Lock()
+	defer functionsMutex.Unlock()
+
+	_, exists := ast.LookupUserFunction(definition.Name)
+	if exists {
+		return query.NewFunctionExists(definition.Name)
+	}
+	err := ast.DefineFunction(definition)
+	if err != nil {
+		return query.NewError(err, "Unable to create function "+definition.Name)
+	}
+	e := saveFunctions()
+	if e != nil {
+		ast.DropFunction(definition.Name)
+		return e
+	}
+	return nil
+}
+
+// DropFunction removes a function written in N1QL and saves the rest
+func DropFunction(name string) query.Error {
+	functionsMutex.Lock()
+	defer functionsMutex.Unlock()
+
+	_, exists := ast.LookupUserFunction(name)
+	if !exists {
+		return query.NewFunctionDoesNotExist(name)
+	}
+	err := ast.DropFunction(name)
+	if err != nil {
+		return query.NewError(err, "Unable to drop function "+name)
+	}
+	return saveFunctions()
+}
+
+// StoredFunctions reads the functions saved in the FunctionsFile, their
+// bodies are only text and must be parsed before they are defined
+func StoredFunctions() ([]*ast.FunctionDefinition, query.Error) {
+	rv := []*ast.FunctionDefinition{}
+	if FunctionsFile == "" {
+		return rv, nil
+	}
+	bytes, err := ioutil.ReadFile(FunctionsFile)
+	if os.IsNotExist(err) {
+		return rv, nil
+	}
+	if err != nil {
+		return nil, query.NewError(err, "Unable to read functions from "+FunctionsFile)
+	}
+	err = json.Unmarshal(bytes, &rv)
+	if err != nil {
+		return nil, query.NewError(err, "Unable to read functions from "+FunctionsFile)
+	}
+	return rv, nil
+}
+
+// the file is replaced as a whole, so a crash leaves the old one
+func saveFunctions() query.Error {
+	if FunctionsFile == "" {
+		return nil
+	}
+	bytes, err := json.MarshalIndent(ast.UserFunctionDefinitions(), "", "    ")
+	if err != nil {
+		return query.NewError(err, "Unable to save functions")
+	}
+	temp, err := ioutil.TempFile(filepath.Dir(FunctionsFile), filepath.Base(FunctionsFile))
+	if err != nil {
+		return query.NewError(err, "Unable to save functions to "+FunctionsFile)
+	}
+	_, err = temp.Write(bytes)
+	if err == nil {
+		err = temp.Close()
+	} else {
+		temp.Close()
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), FunctionsFile)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return query.NewError(err, "Unable to save functions to "+FunctionsFile)
+	}
+	return nil
+}
+
+func functionIds() []string {
+	return ast.UserFunctionNames()
+}
+
+func fetchFunction(id string) (map[string]interface{}, bool) {
+	definition, exists := ast.LookupUserFunction(id)
+	if !exists {
+		return nil, false
+	}
+	if definition == nil {
+		return map[string]interface{}{
+			"name":     id,
+			"language": "go",
+		}, true
+	}
+	parameters := make([]interface{}, len(definition.Parameters))
+	for i, parameter := range definition.Parameters {
+		parameters[i] = parameter
+	}
+	return map[string]interface{}{
+		"name":       definition.Name,
+		"parameters": parameters,
+		"body":       definition.Text,
+		"language":   "n1ql",
+	}, true
+}
+
+// the functions bucket lists the user functions, deleting
+// one of them drops it
+func newFunctionsBucket(p *pool) (*requestbucket, query.Error) {
+	b := new(requestbucket)
+	b.pool = p
+	b.name = BUCKET_NAME_FUNCTIONS
+	b.ids = functionIds
+	b.fetch = fetchFunction
+	b.remove = DropFunction
+
+	b.primary = &requestIndex{name: "primary", bucket: b}
+
+	return b, nil
+}
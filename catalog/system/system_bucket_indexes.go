@@ -132,6 +132,13 @@ func (b *indexbucket) Fetch(id string) (item *dparval.Value, e query.Error) {
 					"index_key":  catalogObjectToJSONSafe(indexKeyToIndexKeyStringArray(index.Key())),
 					"index_type": catalogObjectToJSONSafe(index.Type()),
 				}
+				rangeIndex, ok := index.(catalog.RangeIndex)
+				if ok {
+					statistics, err := rangeIndex.Statistics()
+					if err == nil {
+						doc["statistics"] = catalogObjectToJSONSafe(statistics)
+					}
+				}
 				return dparval.NewValue(doc), nil
 			}
 		}
@@ -168,6 +175,22 @@ func (b *indexbucket) CreateIndex(name string, key catalog.IndexKey, using catal
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *indexbucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *indexbucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *indexbucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *indexbucket) Delete(id string) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
 func newIndexesBucket(p *pool) (*indexbucket, query.Error) {
 	b := new(indexbucket)
 	b.pool = p
@@ -109,6 +109,22 @@ func (b *poolbucket) CreateIndex(name string, key catalog.IndexKey, using catalo
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *poolbucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *poolbucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *poolbucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *poolbucket) Delete(id string) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
 func newPoolsBucket(p *pool) (*poolbucket, query.Error) {
 	b := new(poolbucket)
 	b.pool = p
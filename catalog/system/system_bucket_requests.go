@@ -0,0 +1,233 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package system
+
+import (
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// requestbucket lists the requests of the server, either the active
+// or the completed ones of network.Requests, it also lists the user
+// functions (see system_bucket_functions.go)
+type requestbucket struct {
+	pool    *pool
+	name    string
+	primary catalog.PrimaryIndex
+	ids     func() []string
+	fetch   func(id string) (map[string]interface{}, bool)
+	// deleting an active request cancels it
+	remove func(id string) query.Error
+}
+
+func (b *requestbucket) Release() {
+}
+
+func (b *requestbucket) PoolId() string {
+	return b.pool.Id()
+}
+
+func (b *requestbucket) Id() string {
+	return b.Name()
+}
+
+func (b *requestbucket) Name() string {
+	return b.name
+}
+
+func (b *requestbucket) Count() (int64, query.Error) {
+	return int64(len(b.ids())), nil
+}
+
+func (b *requestbucket) IndexIds() ([]string, query.Error) {
+	return []string{b.primary.Id()}, nil
+}
+
+func (b *requestbucket) IndexNames() ([]string, query.Error) {
+	return []string{b.primary.Name()}, nil
+}
+
+func (b *requestbucket) IndexById(id string) (catalog.Index, query.Error) {
+	return b.primary, nil
+}
+
+func (b *requestbucket) IndexByName(name string) (catalog.Index, query.Error) {
+	return b.primary, nil
+}
+
+func (b *requestbucket) IndexByPrimary() (catalog.PrimaryIndex, query.Error) {
+	return b.primary, nil
+}
+
+func (b *requestbucket) IndexesByPrimary() ([]catalog.PrimaryIndex, query.Error) {
+	return []catalog.PrimaryIndex{b.primary}, nil
+}
+
+func (b *requestbucket) Indexes() ([]catalog.Index, query.Error) {
+	return []catalog.Index{b.primary}, nil
+}
+
+func (b *requestbucket) BulkFetch(ids []string) (map[string]*dparval.Value, query.Error) {
+	rv := make(map[string]*dparval.Value, 0)
+	for _, id := range ids {
+		item, e := b.Fetch(id)
+		if e != nil {
+			return nil, e
+		}
+		if item != nil {
+			rv[id] = item
+		}
+	}
+	return rv, nil
+}
+
+// requests come and go, so one that is gone by now is simply missing
+func (b *requestbucket) Fetch(id string) (item *dparval.Value, e query.Error) {
+	doc, ok := b.fetch(id)
+	if !ok {
+		return nil, nil
+	}
+	rv := dparval.NewValue(doc)
+	rv.SetAttachment("meta", map[string]interface{}{"id": id})
+	return rv, nil
+}
+
+func (b *requestbucket) CreatePrimaryIndex() (catalog.PrimaryIndex, query.Error) {
+	if b.primary != nil {
+		return b.primary, nil
+	}
+
+	return nil, query.NewError(nil, "Not supported.")
+}
+
+func (b *requestbucket) CreateIndex(name string, key catalog.IndexKey, using catalog.IndexType) (catalog.Index, query.Error) {
+	return nil, query.NewError(nil, "Not supported.")
+}
+
+func (b *requestbucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *requestbucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *requestbucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *requestbucket) Delete(id string) query.Error {
+	if b.remove == nil {
+		return query.NewError(nil, "Not supported.")
+	}
+	return b.remove(id)
+}
+
+func newActiveRequestsBucket(p *pool) (*requestbucket, query.Error) {
+	b := new(requestbucket)
+	b.pool = p
+	b.name = BUCKET_NAME_ACTIVE_REQUESTS
+	b.ids = network.Requests.ActiveIds
+	b.fetch = network.Requests.Active
+	b.remove = network.Requests.Cancel
+
+	b.primary = &requestIndex{name: "primary", bucket: b}
+
+	return b, nil
+}
+
+func newCompletedRequestsBucket(p *pool) (*requestbucket, query.Error) {
+	b := new(requestbucket)
+	b.pool = p
+	b.name = BUCKET_NAME_COMPLETED_REQUESTS
+	b.ids = network.Requests.CompletedIds
+	b.fetch = network.Requests.Completed
+
+	b.primary = &requestIndex{name: "primary", bucket: b}
+
+	return b, nil
+}
+
+type requestIndex struct {
+	name   string
+	bucket *requestbucket
+}
+
+func (ri *requestIndex) BucketId() string {
+	return ri.bucket.Id()
+}
+
+func (ri *requestIndex) Id() string {
+	return ri.Name()
+}
+
+func (ri *requestIndex) Name() string {
+	return ri.name
+}
+
+func (ri *requestIndex) Type() catalog.IndexType {
+	return catalog.UNSPECIFIED
+}
+
+func (ri *requestIndex) IsPrimary() bool {
+	return true
+}
+
+func (ri *requestIndex) Key() catalog.IndexKey {
+	return nil
+}
+
+func (ri *requestIndex) Drop() query.Error {
+	return query.NewError(nil, "Primary index cannot be dropped.")
+}
+
+func (ri *requestIndex) ScanBucket(limit int64, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	ri.ScanEntries(limit, ch, warnch, errch)
+}
+
+func (ri *requestIndex) ScanEntries(limit int64, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	defer close(ch)
+	defer close(warnch)
+	defer close(errch)
+
+	for i, id := range ri.bucket.ids() {
+		if limit > 0 && int64(i) >= limit {
+			break
+		}
+
+		entry := catalog.IndexEntry{PrimaryKey: id}
+		ch <- &entry
+	}
+}
+
+func (ri *requestIndex) Lookup(value catalog.LookupValue, ch catalog.EntryChannel, warnch, errch query.ErrorChannel) {
+	defer close(ch)
+	defer close(warnch)
+	defer close(errch)
+
+	if value == nil || len(value) != 1 || value[0].Type() != dparval.STRING {
+		errch <- query.NewError(nil, "Invalid lookup value: string required.")
+		return
+	}
+
+	val, ok := value[0].Value().(string)
+	if !ok {
+		errch <- query.NewError(nil, "Invalid lookup value: string required.")
+		return
+	}
+
+	_, ok = ri.bucket.fetch(val)
+	if ok {
+		entry := catalog.IndexEntry{PrimaryKey: val}
+		ch <- &entry
+	}
+}
@@ -105,6 +105,22 @@ func (b *sitebucket) CreateIndex(name string, key catalog.IndexKey, using catalo
 	return nil, query.NewError(nil, "Not supported.")
 }
 
+func (b *sitebucket) Insert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *sitebucket) Upsert(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *sitebucket) Replace(id string, value *dparval.Value) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
+func (b *sitebucket) Delete(id string) query.Error {
+	return query.NewError(nil, "Not supported.")
+}
+
 func newSitesBucket(p *pool) (*sitebucket, query.Error) {
 	b := new(sitebucket)
 	b.pool = p
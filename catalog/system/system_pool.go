@@ -107,5 +107,23 @@ func (p *pool) loadBuckets() (e query.Error) {
 	}
 	p.buckets[ib.Name()] = ib
 
+	ab, e := newActiveRequestsBucket(p)
+	if e != nil {
+		return e
+	}
+	p.buckets[ab.Name()] = ab
+
+	cb, e := newCompletedRequestsBucket(p)
+	if e != nil {
+		return e
+	}
+	p.buckets[cb.Name()] = cb
+
+	fb, e := newFunctionsBucket(p)
+	if e != nil {
+		return e
+	}
+	p.buckets[fb.Name()] = fb
+
 	return nil
 }
@@ -10,8 +10,11 @@
 package standard
 
 import (
+	"container/list"
+	"sync"
 
 	// interfaces
+	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/optimizer"
 	"github.com/couchbaselabs/tuqtng/parser"
@@ -20,17 +23,31 @@ import (
 	"github.com/couchbaselabs/tuqtng/query"
 
 	// implementations
-	simpleOptimizer "github.com/couchbaselabs/tuqtng/optimizer/simple"
+	costOptimizer "github.com/couchbaselabs/tuqtng/optimizer/cost"
 	yaccParser "github.com/couchbaselabs/tuqtng/parser/goyacc"
 	simplePlanner "github.com/couchbaselabs/tuqtng/planner/simple"
 )
 
+// how many plans of prepared statements are kept, the plan executed
+// least recently is forgotten first
+var PreparedLimit = 1000
+
 type StandardCompiler struct {
 	site            catalog.Site
 	defaultPoolName string
 	parser          parser.Parser
 	planner         planner.Planner
 	optimizer       optimizer.Optimizer
+	// the plans of prepared statements by name, and their names
+	// ordered by last use, most recent first
+	prepared      map[string]*list.Element
+	preparedOrder *list.List
+	preparedMutex sync.Mutex
+}
+
+type preparedPlan struct {
+	name string
+	plan *plan.Plan
 }
 
 func NewCompiler(site catalog.Site, defaultPoolName string) *StandardCompiler {
@@ -39,30 +56,87 @@ func NewCompiler(site catalog.Site, defaultPoolName string) *StandardCompiler {
 		defaultPoolName: defaultPoolName,
 		parser:          yaccParser.NewN1qlParser(),
 		planner:         simplePlanner.NewSimplePlanner(site, defaultPoolName),
-		optimizer:       simpleOptimizer.NewSimpleOptimizer(),
+		optimizer:       costOptimizer.NewCostOptimizer(site),
+		prepared:        make(map[string]*list.Element),
+		preparedOrder:   list.New(),
 	}
 }
 
 func (this *StandardCompiler) Compile(queryString string) (*plan.Plan, query.Error) {
 
-	ast, err := this.parser.Parse(queryString)
+	stmt, err := this.parser.Parse(queryString)
 	if err != nil {
 		return nil, query.NewParseError(err, "Parse Error")
 	}
 
+	switch stmt := stmt.(type) {
+	case *ast.ExecuteStatement:
+		// the statement was compiled when it was prepared
+		preparedPlan, ok := this.lookupPrepared(stmt.Name)
+		if !ok {
+			return nil, query.NewPreparedDoesNotExist(stmt.Name)
+		}
+		return preparedPlan, nil
+	case *ast.PrepareStatement:
+		preparedPlan, err := this.compileStatement(stmt.Statement)
+		if err != nil {
+			return nil, err
+		}
+		// the client is told when it replaced the plan of another
+		prepare := plan.NewPrepare(stmt.Name, preparedPlan.Root)
+		prepare.Replaced = this.addPrepared(stmt.Name, preparedPlan)
+		return &plan.Plan{Root: prepare}, nil
+	}
+
+	return this.compileStatement(stmt)
+}
+
+func (this *StandardCompiler) lookupPrepared(name string) (*plan.Plan, bool) {
+	this.preparedMutex.Lock()
+	defer this.preparedMutex.Unlock()
+
+	element, ok := this.prepared[name]
+	if !ok {
+		return nil, false
+	}
+	this.preparedOrder.MoveToFront(element)
+	return element.Value.(*preparedPlan).plan, true
+}
+
+// returns true if a plan with the same name was replaced
+func (this *StandardCompiler) addPrepared(name string, p *plan.Plan) bool {
+	this.preparedMutex.Lock()
+	defer this.preparedMutex.Unlock()
+
+	element, replaced := this.prepared[name]
+	if replaced {
+		element.Value.(*preparedPlan).plan = p
+		this.preparedOrder.MoveToFront(element)
+	} else {
+		this.prepared[name] = this.preparedOrder.PushFront(&preparedPlan{name, p})
+	}
+	for len(this.prepared) > PreparedLimit && this.preparedOrder.Len() > 0 {
+		oldest := this.preparedOrder.Remove(this.preparedOrder.Back()).(*preparedPlan)
+		delete(this.prepared, oldest.name)
+	}
+	return replaced
+}
+
+func (this *StandardCompiler) compileStatement(stmt ast.Statement) (*plan.Plan, query.Error) {
+
 	// perform semantic verification
-	err = ast.VerifySemantics()
+	err := stmt.VerifySemantics()
 	if err != nil {
 		return nil, query.NewSemanticError(err, "Semantic Error")
 	}
 
 	// simplify the statement
-	err = ast.Simplify()
+	err = stmt.Simplify()
 	if err != nil {
 		return nil, query.NewError(err, "Error Simplifying Expression")
 	}
 
-	planChannel, planErrChannel := this.planner.Plan(ast)
+	planChannel, planErrChannel := this.planner.Plan(stmt)
 
 	optimalPlan, err := this.optimizer.Optimize(planChannel, planErrChannel)
 	if err != nil {
@@ -107,6 +107,13 @@ func (this *InterpretedExecutor) executeInternal(optimalPlan *plan.Plan, q netwo
 		}
 	}
 
+	if executablePipeline.Profile != nil {
+		profiled, ok := q.Response().(network.ProfiledResponse)
+		if ok {
+			profiled.SetProfile(executablePipeline.Profile.Annotate())
+		}
+	}
+
 	q.Response().NoMoreResults()
 	clog.To(executor.CHANNEL, "simple executor finished")
 }
@@ -20,9 +20,13 @@ import (
 
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/catalog/system"
+	"github.com/couchbaselabs/tuqtng/compiler/standard"
 	"github.com/couchbaselabs/tuqtng/network"
 	"github.com/couchbaselabs/tuqtng/network/http"
 	"github.com/couchbaselabs/tuqtng/server"
+	"github.com/couchbaselabs/tuqtng/xpipeline"
 )
 
 var VERSION = "0.0.0" // Build-time overriddable.
@@ -35,8 +39,22 @@ var devMode = flag.Bool("dev", false, "Developer Mode")
 var profileMode = flag.Bool("profile", false, "Profile Mode")
 var staticPath = flag.String("staticPath", "static", "Path to static content")
 var queryTimeout = flag.Duration("queryTimeout", -1*time.Second, "Query execution timeout, negative values disable timeout")
+var sortMemory = flag.Int64("sortMemory", xpipeline.SortMemory, "Bytes an ORDER BY may hold in memory before it sorts on disk")
+var groupMemory = flag.Int64("groupMemory", xpipeline.GroupMemory, "Bytes a GROUP BY may hold in memory before it partitions new groups on disk")
+var tempDir = flag.String("tempDir", "", "Directory for the files of queries spilling to disk, the system default when empty")
+var sortTempDir = flag.String("sortTempDir", "", "Deprecated, use -tempDir")
+var completedLimit = flag.Int("completedLimit", network.CompletedLimit, "Number of completed requests kept in :system.completed_requests")
+var completedThreshold = flag.Duration("completedThreshold", network.CompletedThreshold, "Requests running shorter than this are not kept in :system.completed_requests")
+var workers = flag.Int("workers", server.Workers, "Number of requests run at once")
+var queueSize = flag.Int("queueSize", server.QueueSize, "Number of requests that may wait for a worker before requests are refused")
+var scanParallelism = flag.Int("scanParallelism", xpipeline.ScanParallelism, "Number of index ranges a scan reads at once")
+var fetchParallelism = flag.Int("fetchParallelism", xpipeline.FetchParallelism, "Number of bulk fetches of documents a fetch keeps in flight")
+var statisticsSample = flag.Int("statisticsSample", catalog.StatisticsSample, "Number of index entries sampled to build index statistics")
+var functionsFile = flag.String("functionsFile", "", "File keeping the functions created with CREATE FUNCTION across restarts, "+server.FUNCTIONS_FILE+" in the site directory when empty, or none to lose them on restart")
+var preparedLimit = flag.Int("preparedLimit", standard.PreparedLimit, "Number of prepared statements kept, the least recently executed ones are forgotten first")
+var poolLimits = flag.String("poolLimits", "", "Number of requests run at once against a pool, like default=4,beer-sample=2")
 
-var devModeDefaultLogKeys = []string{"HTTP", "NETWORK", "PIPELINE", "CATALOG", "PLANNER", "SCAN", "OPTIMIZER", "PARSER"}
+var devModeDefaultLogKeys = []string{"HTTP", "SERVER", "NETWORK", "PIPELINE", "CATALOG", "PLANNER", "SCAN", "OPTIMIZER", "PARSER"}
 var disableInfo = flag.Bool("disableInfo", false, "Enable query info line")
 
 func main() {
@@ -55,6 +73,37 @@ func main() {
 
 	clog.Log("Info line disabled %v", *disableInfo)
 
+	xpipeline.SortMemory = *sortMemory
+	xpipeline.GroupMemory = *groupMemory
+	xpipeline.TempDir = *tempDir
+	if *sortTempDir != "" {
+		clog.Warnf("-sortTempDir is deprecated, use -tempDir")
+		if *tempDir == "" {
+			xpipeline.TempDir = *sortTempDir
+		}
+	}
+	xpipeline.ScanParallelism = *scanParallelism
+	xpipeline.FetchParallelism = *fetchParallelism
+	catalog.StatisticsSample = *statisticsSample
+	switch *functionsFile {
+	case "":
+		system.FunctionsFile = server.DefaultFunctionsFile(*couchbaseSite)
+	case "none":
+		system.FunctionsFile = ""
+	default:
+		system.FunctionsFile = *functionsFile
+	}
+	network.CompletedLimit = *completedLimit
+	network.CompletedThreshold = *completedThreshold
+	standard.PreparedLimit = *preparedLimit
+	server.Workers = *workers
+	server.QueueSize = *queueSize
+	limits, err := server.ParsePoolLimits(*poolLimits)
+	if err != nil {
+		clog.Fatalf("Unable to parse pool limits, err: %v", err)
+	}
+	server.PoolLimits = limits
+
 	if *profileMode {
 		clog.Log("Enabling HTTP Profiling on :6060")
 		go func() {
@@ -71,7 +120,7 @@ func main() {
 	httpEndpoint := http.NewHttpEndpoint(*addr, *staticPath, !(*disableInfo))
 	httpEndpoint.SendQueriesTo(queryChannel)
 
-	err := server.Server(VERSION, *couchbaseSite, *defaultPoolName, queryChannel, queryTimeout)
+	err = server.Server(VERSION, *couchbaseSite, *defaultPoolName, queryChannel, queryTimeout)
 	if err != nil {
 		clog.Fatalf("Unable to run server, err: %v", err)
 	}
@@ -10,6 +10,7 @@
 package network
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/couchbaselabs/tuqtng/misc"
@@ -21,6 +22,50 @@ type QueryRequest interface {
 
 type StringQueryRequest struct {
 	QueryString string
+	QueryArgs
+}
+
+// StructuredQueryRequest is a statement with the options
+// the client chose for running it
+type StructuredQueryRequest struct {
+	StringQueryRequest
+	// how long the statement may run, the server's
+	// timeout is used when this is 0
+	Timeout time.Duration
+	// statements changing documents or indexes are refused
+	ReadOnly bool
+	// indent the results
+	Pretty bool
+	// return the row count and elapsed time
+	Metrics bool
+	// returned as is, to match responses with requests
+	ClientContextID string
+	// return the plan annotated with what each operator did
+	Profile bool
+}
+
+// the values given for the parameters of a statement,
+// $1 is Positional[0] and $name is Named["name"]
+type QueryArgs struct {
+	Positional []interface{}
+	Named      map[string]interface{}
+}
+
+func (this QueryArgs) ParameterValue(name string) (interface{}, bool) {
+	position, err := strconv.Atoi(name)
+	if err == nil {
+		if position < 1 || position > len(this.Positional) {
+			return nil, false
+		}
+		return this.Positional[position-1], true
+	}
+	val, ok := this.Named[name]
+	return val, ok
+}
+
+// ParameterizedRequest is a request holding values for parameters
+type ParameterizedRequest interface {
+	ParameterValue(name string) (interface{}, bool)
 }
 
 type QueryResponse interface {
@@ -29,6 +74,18 @@ type QueryResponse interface {
 	NoMoreResults()
 }
 
+// IdentifiedResponse is a response able to tell
+// the client the id its request was given
+type IdentifiedResponse interface {
+	SetRequestId(id string)
+}
+
+// ProfiledResponse is a response able to return
+// the profile of the execution of its statement
+type ProfiledResponse interface {
+	SetProfile(profile interface{})
+}
+
 type Query interface {
 	Request() QueryRequest
 	Response() QueryResponse
@@ -32,6 +32,7 @@ func NewHttpEndpoint(address string, staticPath string, infoEnable bool) *HttpEn
 	r := mux.NewRouter()
 
 	r.Handle("/query", rv).Methods("GET", "POST")
+	r.HandleFunc("/admin/requests/{id}", cancelRequest).Methods("DELETE")
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticPath)))
 	rv.infoEnable = infoEnable
 
@@ -58,6 +59,18 @@ func (this *HttpEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// stops the request with the id, its client is sent an error
+func cancelRequest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := network.Requests.Cancel(id)
+	if err != nil {
+		showError(w, err.Error(), 404)
+		return
+	}
+	clog.To(CHANNEL, "request %v cancelled", id)
+	mustEncode(w, map[string]interface{}{"cancelled": id})
+}
+
 func mustEncode(w io.Writer, i interface{}) {
 	if headered, ok := w.(http.ResponseWriter); ok {
 		headered.Header().Set("Cache-Control", "no-cache")
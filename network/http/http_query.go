@@ -10,8 +10,13 @@
 package http
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/couchbaselabs/clog"
@@ -30,17 +35,22 @@ type HttpQuery struct {
 func NewHttpQuery(w http.ResponseWriter, r *http.Request, info bool) *HttpQuery {
 	q := HttpQuery{startTime: time.Now(), info: info}
 
-	queryString := findQueryStringInRequest(r)
+	request, err := findQueryRequest(r, info)
+	if err != nil {
+		showError(w, err.Error(), 400)
+		return nil
+	}
 
-	if queryString == "" {
+	if request.QueryString == "" {
 		showError(w, "Missing required query string", 500)
 		return nil
 	} else {
-		clog.To(CHANNEL, "query string: %v", queryString)
+		clog.To(CHANNEL, "query string: %v", request.QueryString)
 	}
 
-	q.request = network.StringQueryRequest{QueryString: queryString}
-	httpResponse := &HttpResponse{query: &q, w: w, results: make(chan interface{}), returnInfo: info}
+	q.request = request
+	httpResponse := &HttpResponse{query: &q, w: w, results: make(chan interface{}),
+		returnInfo: request.Metrics, pretty: request.Pretty, clientContextID: request.ClientContextID}
 	q.response = httpResponse
 
 	return &q
@@ -83,6 +93,124 @@ func (this *HttpQuery) Process() {
 	}
 }
 
+// the statement is the q form value or the body of a POST, the
+// values of its parameters are in the args form value, a JSON array
+// for $1, $2..., and in form values named like $name holding JSON.
+// the other options have form values of their own.  a POST with a
+// JSON body has them all as fields of an object, with the statement
+// in the statement field.  info is the default for metrics
+func findQueryRequest(r *http.Request, info bool) (network.StructuredQueryRequest, error) {
+	request := network.StructuredQueryRequest{Pretty: true, Metrics: info}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Method == "POST" && contentType == "application/json" {
+		err := findQueryRequestInBody(r, &request)
+		return request, err
+	}
+
+	request.QueryString = findQueryStringInRequest(r)
+	err := r.ParseForm()
+	if err != nil {
+		return request, err
+	}
+	for name, values := range r.Form {
+		if name == "q" || len(values) == 0 {
+			continue
+		}
+		var val interface{} = values[0]
+		if name == "args" || strings.HasPrefix(name, "$") {
+			// parameter values are JSON
+			err = json.Unmarshal([]byte(values[0]), &val)
+			if err != nil {
+				return request, fmt.Errorf("Error parsing value of %v, must be JSON: %v", name, err)
+			}
+		}
+		err = setRequestField(&request, name, val)
+		if err != nil {
+			return request, err
+		}
+	}
+	return request, nil
+}
+
+func findQueryRequestInBody(r *http.Request, request *network.StructuredQueryRequest) error {
+	var body map[string]interface{}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return fmt.Errorf("Error parsing request body: %v", err)
+	}
+
+	for name, val := range body {
+		if name == "statement" {
+			statement, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("statement must be a string")
+			}
+			request.QueryString = statement
+			continue
+		}
+		err = setRequestField(request, name, val)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// options given as form values are strings, in JSON bodies they have proper types
+func setRequestField(request *network.StructuredQueryRequest, name string, val interface{}) error {
+	var err error
+	switch {
+	case name == "args":
+		args, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("args must be an array")
+		}
+		request.Positional = args
+	case strings.HasPrefix(name, "$"):
+		if request.Named == nil {
+			request.Named = make(map[string]interface{})
+		}
+		request.Named[name[1:]] = val
+	case name == "timeout":
+		timeout, ok := val.(string)
+		if ok {
+			request.Timeout, err = time.ParseDuration(timeout)
+		}
+		if !ok || err != nil || request.Timeout <= 0 {
+			return fmt.Errorf("timeout must be a positive duration, like 10s or 500ms")
+		}
+	case name == "readonly":
+		request.ReadOnly, err = boolOption(name, val)
+	case name == "pretty":
+		request.Pretty, err = boolOption(name, val)
+	case name == "metrics":
+		request.Metrics, err = boolOption(name, val)
+	case name == "profile":
+		request.Profile, err = boolOption(name, val)
+	case name == "client_context_id":
+		id, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("client_context_id must be a string")
+		}
+		request.ClientContextID = id
+	}
+	return err
+}
+
+func boolOption(name string, val interface{}) (bool, error) {
+	switch val := val.(type) {
+	case bool:
+		return val, nil
+	case string:
+		rv, err := strconv.ParseBool(val)
+		if err == nil {
+			return rv, nil
+		}
+	}
+	return false, fmt.Errorf("%v must be true or false", name)
+}
+
 func findQueryStringInRequest(r *http.Request) string {
 	queryString := r.FormValue("q")
 	if queryString == "" && r.Method == "POST" {
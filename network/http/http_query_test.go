@@ -0,0 +1,150 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+func TestFindQueryRequest(t *testing.T) {
+	form := url.Values{}
+	form.Set("q", "SELECT * FROM bucket WHERE a = $1 AND b = $b")
+	form.Set("args", `[1, "x"]`)
+	form.Set("$b", `{"c": true}`)
+	req, err := http.NewRequest("GET", "http://localhost:8093/query?"+form.Encode(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request, err := findQueryRequest(req, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.QueryString != form.Get("q") {
+		t.Errorf("expected statement %v, got %v", form.Get("q"), request.QueryString)
+	}
+	if !reflect.DeepEqual(request.Positional, []interface{}{1.0, "x"}) {
+		t.Errorf("expected positional args, got %v", request.Positional)
+	}
+	if !reflect.DeepEqual(request.Named, map[string]interface{}{"b": map[string]interface{}{"c": true}}) {
+		t.Errorf("expected named args, got %v", request.Named)
+	}
+
+	// the same as a JSON body
+	body := `{"statement": "SELECT * FROM bucket WHERE a = $1 AND b = $b", "args": [1, "x"], "$b": {"c": true}}`
+	req, err = http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	jsonRequest, err := findQueryRequest(req, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(jsonRequest, request) {
+		t.Errorf("expected %#v, got %#v", request, jsonRequest)
+	}
+
+	// a raw statement still works
+	req, err = http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("SELECT 1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request, err = findQueryRequest(req, true)
+	if err != nil || request.QueryString != "SELECT 1" {
+		t.Errorf("expected raw statement, got %#v, err: %v", request, err)
+	}
+
+	// args must be an array
+	req, err = http.NewRequest("GET", "http://localhost:8093/query?q=SELECT+1&args=1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = findQueryRequest(req, true)
+	if err == nil {
+		t.Errorf("expected error for args that are not an array")
+	}
+}
+
+func TestFindQueryRequestOptions(t *testing.T) {
+	expected := network.StructuredQueryRequest{
+		StringQueryRequest: network.StringQueryRequest{QueryString: "SELECT 1"},
+		Timeout:            1500 * time.Millisecond,
+		ReadOnly:           true,
+		Pretty:             false,
+		Metrics:            false,
+		ClientContextID:    "abc",
+		Profile:            true,
+	}
+
+	body := `{"statement": "SELECT 1", "timeout": "1.5s", "readonly": true, "pretty": false, "metrics": false, "client_context_id": "abc", "profile": true}`
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request, err := findQueryRequest(req, true)
+	if err != nil || !reflect.DeepEqual(request, expected) {
+		t.Errorf("expected %#v, got %#v, err: %v", expected, request, err)
+	}
+
+	// the same as form values
+	form := url.Values{}
+	form.Set("q", "SELECT 1")
+	form.Set("timeout", "1.5s")
+	form.Set("readonly", "true")
+	form.Set("pretty", "false")
+	form.Set("metrics", "false")
+	form.Set("client_context_id", "abc")
+	form.Set("profile", "true")
+	req, err = http.NewRequest("GET", "http://localhost:8093/query?"+form.Encode(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request, err = findQueryRequest(req, true)
+	if err != nil || !reflect.DeepEqual(request, expected) {
+		t.Errorf("expected %#v, got %#v, err: %v", expected, request, err)
+	}
+
+	// the defaults
+	req, err = http.NewRequest("GET", "http://localhost:8093/query?q=SELECT+1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request, err = findQueryRequest(req, false)
+	if err != nil || request.Timeout != 0 || request.ReadOnly || !request.Pretty || request.Metrics || request.Profile {
+		t.Errorf("expected default options, got %#v, err: %v", request, err)
+	}
+
+	invalid := []string{
+		`{"statement": "SELECT 1", "timeout": "soon"}`,
+		`{"statement": "SELECT 1", "timeout": "-1s"}`,
+		`{"statement": "SELECT 1", "readonly": "maybe"}`,
+		`{"statement": 1}`,
+		`{"statement": "SELECT 1", "client_context_id": 7}`,
+	}
+	for _, body := range invalid {
+		req, err = http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		_, err = findQueryRequest(req, true)
+		if err == nil {
+			t.Errorf("expected error for %v", body)
+		}
+	}
+}
@@ -13,20 +13,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/couchbaselabs/tuqtng/misc"
 	"github.com/couchbaselabs/tuqtng/query"
 )
 
 type HttpResponse struct {
-	query      *HttpQuery
-	w          http.ResponseWriter
-	results    chan interface{}
-	warnings   []query.Error
-	info       []query.Error
-	err        query.Error
-	count      int
-	returnInfo bool
+	query           *HttpQuery
+	w               http.ResponseWriter
+	results         chan interface{}
+	warnings        []query.Error
+	info            []query.Error
+	err             query.Error
+	count           int
+	returnInfo      bool
+	pretty          bool
+	clientContextID string
+	requestID       string
+	profile         interface{}
+	mutated         bool
+	mutations       int
 }
 
 func (this *HttpResponse) SendError(err query.Error) {
@@ -37,6 +44,10 @@ func (this *HttpResponse) SendError(err query.Error) {
 		this.warnings = append(this.warnings, err)
 	case query.INFO:
 		this.info = append(this.info, err)
+		if err.TranslationKey() == "mutation_count" {
+			this.mutated = true
+			this.mutations, _ = strconv.Atoi(err.Error())
+		}
 	}
 
 	if err.IsFatal() {
@@ -48,18 +59,28 @@ func (this *HttpResponse) SendResult(val interface{}) {
 	this.results <- val
 }
 
+func (this *HttpResponse) SetRequestId(id string) {
+	this.requestID = id
+}
+
+func (this *HttpResponse) SetProfile(profile interface{}) {
+	this.profile = profile
+}
+
 func (this *HttpResponse) NoMoreResults() {
 	close(this.results)
 }
 
 func (this *HttpResponse) Process() error {
 
-	_, err := this.openResponse()
+	// the response is opened by the first result, or by the end of
+	// the results so an error can still choose the status
+	_, err := this.ProcessResults()
 	if err != nil {
 		return err
 	}
 
-	_, err = this.ProcessResults()
+	_, err = this.ProcessMutationCount()
 	if err != nil {
 		return err
 	}
@@ -69,6 +90,10 @@ func (this *HttpResponse) Process() error {
 		if err != nil {
 			return err
 		}
+		_, err = this.ProcessProfile()
+		if err != nil {
+			return err
+		}
 	} else {
 		// an error occured
 		_, err = this.ProcessError()
@@ -77,6 +102,16 @@ func (this *HttpResponse) Process() error {
 		}
 	}
 
+	_, err = this.ProcessRequestID()
+	if err != nil {
+		return err
+	}
+
+	_, err = this.ProcessClientContextID()
+	if err != nil {
+		return err
+	}
+
 	_, err = this.closeResponse()
 	if err != nil {
 		return err
@@ -88,7 +123,11 @@ func (this *HttpResponse) Process() error {
 func (this *HttpResponse) ProcessResults() (int, error) {
 	for val := range this.results {
 		if this.count == 0 {
-			_, err := this.openArray("resultset")
+			_, err := this.openResponse()
+			if err != nil {
+				return 0, err
+			}
+			_, err = this.openArray("resultset")
 			if err != nil {
 				return 0, err
 			}
@@ -111,6 +150,17 @@ func (this *HttpResponse) ProcessResults() (int, error) {
 		}
 	}
 
+	if this.count == 0 {
+		status := httpStatus(this.err)
+		if status != http.StatusOK {
+			this.w.WriteHeader(status)
+		}
+		_, err := this.openResponse()
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// close resultset
 
 	if this.count == 0 && this.err == nil {
@@ -136,6 +186,22 @@ func (this *HttpResponse) ProcessResults() (int, error) {
 	return 0, nil
 }
 
+// statements that change documents always report how many they
+// changed, even if they failed part way through
+func (this *HttpResponse) ProcessMutationCount() (int, error) {
+	if !this.mutated {
+		return 0, nil
+	}
+	// no resultset is written when an error occured without results
+	if this.count != 0 || this.err == nil {
+		_, err := this.continueResponse()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return fmt.Fprint(this.w, "    \"mutationCount\": ", this.mutations)
+}
+
 func (this *HttpResponse) ProcessWarningsAndInfo() (int, error) {
 
 	if this.returnInfo == false && len(this.warnings) == 0 {
@@ -177,8 +243,51 @@ func (this *HttpResponse) ProcessWarningsAndInfo() (int, error) {
 	return 0, nil
 }
 
+// the plan annotated with what each operator did,
+// the resultset was written before this
+func (this *HttpResponse) ProcessProfile() (int, error) {
+	if this.profile == nil {
+		return 0, nil
+	}
+	_, err := this.continueResponse()
+	if err != nil {
+		return 0, err
+	}
+	this.openKey("profile", "    ")
+	return this.printObj(this.profile)
+}
+
+// the id to cancel the request with, the resultset
+// or the error was written before this
+func (this *HttpResponse) ProcessRequestID() (int, error) {
+	if this.requestID == "" {
+		return 0, nil
+	}
+	_, err := this.continueResponse()
+	if err != nil {
+		return 0, err
+	}
+	return fmt.Fprint(this.w, "    \"requestID\": \"", this.requestID, "\"")
+}
+
+// the resultset or the error was written before this
+func (this *HttpResponse) ProcessClientContextID() (int, error) {
+	if this.clientContextID == "" {
+		return 0, nil
+	}
+	_, err := this.continueResponse()
+	if err != nil {
+		return 0, err
+	}
+	idBytes, err := json.Marshal(this.clientContextID)
+	if err != nil {
+		return 0, err
+	}
+	return fmt.Fprint(this.w, "    \"clientContextID\": ", string(idBytes))
+}
+
 func (this *HttpResponse) ProcessError() (int, error) {
-	if this.count != 0 {
+	if this.count != 0 || this.mutated {
 		_, err := this.continueResponse()
 		if err != nil {
 			return 0, err
@@ -187,6 +296,17 @@ func (this *HttpResponse) ProcessError() (int, error) {
 	return this.printError(this.err)
 }
 
+// requests refused because the server is busy may be tried again
+func httpStatus(err query.Error) int {
+	if err != nil {
+		switch err.TranslationKey() {
+		case "queue_full", "pool_busy":
+			return http.StatusServiceUnavailable
+		}
+	}
+	return http.StatusOK
+}
+
 func (this *HttpResponse) openResponse() (int, error) {
 	return fmt.Fprint(this.w, "{\n")
 }
@@ -216,7 +336,13 @@ func (this *HttpResponse) closeArray() (int, error) {
 }
 
 func (this *HttpResponse) printObj(obj interface{}) (int, error) {
-	objBytes, err := json.MarshalIndent(obj, "        ", "    ")
+	var objBytes []byte
+	var err error
+	if this.pretty {
+		objBytes, err = json.MarshalIndent(obj, "        ", "    ")
+	} else {
+		objBytes, err = json.Marshal(obj)
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -14,9 +14,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/couchbaselabs/tuqtng/network"
 	"github.com/couchbaselabs/tuqtng/query"
 )
 
@@ -33,6 +35,10 @@ type tuqResponse struct {
 	Info      []tuqError    `json:"info,omitempty"`
 	Warnings  []tuqError    `json:"warnings,omitempty"`
 	Error     *tuqError     `json:"error,omitempty"`
+	Mutations *float64      `json:"mutationCount,omitempty"`
+	ClientID  string        `json:"clientContextID,omitempty"`
+	RequestID string        `json:"requestID,omitempty"`
+	Profile   interface{}   `json:"profile,omitempty"`
 }
 
 func TestHttpResponseNoResults(t *testing.T) {
@@ -207,3 +213,166 @@ func TestHttpErrorResponseSomeResults(t *testing.T) {
 		t.Errorf("exptected error section, was nil")
 	}
 }
+
+func TestHttpResponseMutationCount(t *testing.T) {
+
+	// NOTE the query isn't actually used, its just to allow the constructor to work correctly
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("DELETE FROM bucket"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, false)
+
+	res := q.Response()
+	go func() {
+		res.SendError(query.NewMutationCountInfo(3))
+		res.NoMoreResults()
+	}()
+	q.Process()
+
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+
+	if len(tuqRes.Resultset) != 0 {
+		t.Errorf("expected 0 rows, got %d", len(tuqRes.Resultset))
+	}
+	if tuqRes.Mutations == nil || *tuqRes.Mutations != 3 {
+		t.Errorf("expected mutationCount = 3, got %v", tuqRes.Mutations)
+	}
+}
+
+func TestHttpErrorResponseMutationCount(t *testing.T) {
+
+	// NOTE the query isn't actually used, its just to allow the constructor to work correctly
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("INSERT INTO bucket VALUES ('a', 1)"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, true)
+
+	res := q.Response()
+	go func() {
+		res.SendError(query.NewMutationCountInfo(1))
+		res.SendError(query.NewKeyExists("a"))
+	}()
+	q.Process()
+
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+
+	if tuqRes.Mutations == nil || *tuqRes.Mutations != 1 {
+		t.Errorf("expected mutationCount = 1, got %v", tuqRes.Mutations)
+	}
+	if tuqRes.Error == nil {
+		t.Errorf("exptected error section, was nil")
+	} else if tuqRes.Error.Key != "key_exists" {
+		t.Errorf("exptected key `key_exists`, got %s", tuqRes.Error.Key)
+	}
+}
+
+func TestHttpResponseOptions(t *testing.T) {
+
+	body := `{"statement": "SELECT * FROM bucket", "pretty": false, "metrics": false, "client_context_id": "abc"}`
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader(body))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, true)
+
+	res := q.Response()
+	go func() {
+		res.SendResult(map[string]interface{}{"name": "marty"})
+		res.NoMoreResults()
+	}()
+	q.Process()
+
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+
+	if len(tuqRes.Resultset) != 1 {
+		t.Errorf("expected 1 row, got %d", len(tuqRes.Resultset))
+	}
+	if tuqRes.Info != nil {
+		t.Errorf("expected no info section without metrics, got %v", tuqRes.Info)
+	}
+	if tuqRes.ClientID != "abc" {
+		t.Errorf("expected clientContextID abc, got %v", tuqRes.ClientID)
+	}
+	if !strings.Contains(resrec.Body.String(), `{"name":"marty"}`) {
+		t.Errorf("expected the result on one line, got %s", resrec.Body.String())
+	}
+}
+
+func TestHttpResponseProfile(t *testing.T) {
+
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("SELECT * FROM bucket"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, false)
+
+	profile := map[string]interface{}{"type": "fetch", "#profile": map[string]interface{}{"items_out": 1.0}}
+	res := q.Response()
+	go func() {
+		res.SendResult(map[string]interface{}{"name": "marty"})
+		res.(network.ProfiledResponse).SetProfile(profile)
+		res.NoMoreResults()
+	}()
+	q.Process()
+
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+	if !reflect.DeepEqual(tuqRes.Profile, profile) {
+		t.Errorf("expected profile %v, got %v", profile, tuqRes.Profile)
+	}
+}
+
+func TestHttpResponseQueueFull(t *testing.T) {
+
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("SELECT * FROM bucket"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, true)
+
+	res := q.Response()
+	go func() {
+		res.SendError(query.NewQueueFullError())
+	}()
+	q.Process()
+
+	if resrec.Code != 503 {
+		t.Errorf("expected status 503, got %v", resrec.Code)
+	}
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+	if tuqRes.Error == nil || tuqRes.Error.Code != 5030 {
+		t.Errorf("expected queue_full error, got %v", tuqRes.Error)
+	}
+}
@@ -0,0 +1,77 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/gorilla/mux"
+)
+
+func TestCancelRequest(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/requests/{id}", cancelRequest).Methods("DELETE")
+
+	req, err := http.NewRequest("POST", "http://localhost:8093/query", strings.NewReader("SELECT * FROM bucket"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	resrec := httptest.NewRecorder()
+	q := NewHttpQuery(resrec, req, true)
+
+	// what the server does with the query
+	active := network.Requests.Add(q, "SELECT * FROM bucket")
+	stop := make(misc.StopChannel)
+	active.SetStopChannel(stop)
+
+	req, _ = http.NewRequest("DELETE", "http://localhost:8093/admin/requests/"+active.Id(), nil)
+	cancelrec := httptest.NewRecorder()
+	router.ServeHTTP(cancelrec, req)
+	if cancelrec.Code != 200 {
+		t.Errorf("expected 200 cancelling the request, got %v: %s", cancelrec.Code, cancelrec.Body.String())
+	}
+	_, ok := <-stop
+	if ok {
+		t.Errorf("expected the stop channel to be closed")
+	}
+
+	// the pipeline stops, and the client hears why
+	go func() {
+		active.Response().NoMoreResults()
+	}()
+	q.Process()
+
+	var tuqRes tuqResponse
+	err = json.Unmarshal(resrec.Body.Bytes(), &tuqRes)
+	if err != nil {
+		t.Logf("`%s`", resrec.Body.String())
+		t.Errorf("tuq response didn't parse as json: %v", err)
+	}
+	if tuqRes.Error == nil || tuqRes.Error.Key != "request_cancelled" {
+		t.Errorf("expected request_cancelled error, got %v", tuqRes.Error)
+	}
+	if tuqRes.RequestID != active.Id() {
+		t.Errorf("expected requestID %v, got %v", active.Id(), tuqRes.RequestID)
+	}
+
+	// the request is gone now
+	req, _ = http.NewRequest("DELETE", "http://localhost:8093/admin/requests/"+active.Id(), nil)
+	cancelrec = httptest.NewRecorder()
+	router.ServeHTTP(cancelrec, req)
+	if cancelrec.Code != 404 {
+		t.Errorf("expected 404 cancelling a completed request, got %v", cancelrec.Code)
+	}
+}
@@ -0,0 +1,344 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package network
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// the phases an active request goes through
+const (
+	PHASE_COMPILING = "compiling"
+	PHASE_EXECUTING = "executing"
+)
+
+// how many completed requests are remembered, and how long a request
+// must have run to be remembered (0 remembers all of them)
+var CompletedLimit = 1000
+var CompletedThreshold = time.Duration(0)
+
+// the requests of this server
+var Requests = NewRegistry()
+
+// Registry keeps track of the requests being run, and of the
+// requests that completed recently
+type Registry struct {
+	mutex     sync.RWMutex
+	active    map[string]*ActiveRequest
+	completed map[string]map[string]interface{}
+	// ids of the completed requests, oldest first
+	completedOrder []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		active:    make(map[string]*ActiveRequest),
+		completed: make(map[string]map[string]interface{}),
+	}
+}
+
+// Add registers a query that is about to be run, use the returned
+// request in place of the query so its results are counted
+func (this *Registry) Add(q Query, statement string) *ActiveRequest {
+	rv := &ActiveRequest{
+		Query:     q,
+		id:        newRequestId(),
+		statement: statement,
+		phase:     PHASE_COMPILING,
+		registry:  this,
+		done:      make(chan bool),
+	}
+	rv.response = &countingResponse{QueryResponse: q.Response(), request: rv}
+	identified, ok := q.Response().(IdentifiedResponse)
+	if ok {
+		identified.SetRequestId(rv.id)
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.active[rv.id] = rv
+	return rv
+}
+
+// Complete moves a request from the active to the completed requests,
+// completing a request more than once does nothing
+func (this *Registry) Complete(request *ActiveRequest) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	_, ok := this.active[request.id]
+	if !ok {
+		return
+	}
+	delete(this.active, request.id)
+	close(request.done)
+	for _, f := range request.onComplete {
+		f()
+	}
+
+	end := time.Now()
+	if end.Sub(request.StartTime()) < CompletedThreshold || CompletedLimit <= 0 {
+		return
+	}
+	doc := request.document(end)
+	delete(doc, "phase")
+	doc["end_time"] = end.Format(time.RFC3339Nano)
+	this.completed[request.id] = doc
+	this.completedOrder = append(this.completedOrder, request.id)
+	for len(this.completedOrder) > CompletedLimit {
+		delete(this.completed, this.completedOrder[0])
+		this.completedOrder = this.completedOrder[1:]
+	}
+}
+
+// Cancel stops an active request, its client is sent an error
+func (this *Registry) Cancel(id string) query.Error {
+	this.mutex.RLock()
+	request, ok := this.active[id]
+	this.mutex.RUnlock()
+
+	if !ok {
+		return query.NewRequestDoesNotExist(id)
+	}
+	request.cancel()
+	return nil
+}
+
+func (this *Registry) ActiveIds() []string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	rv := make([]string, 0, len(this.active))
+	for id, _ := range this.active {
+		rv = append(rv, id)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// returns the document describing an active request
+func (this *Registry) Active(id string) (map[string]interface{}, bool) {
+	this.mutex.RLock()
+	request, ok := this.active[id]
+	this.mutex.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return request.document(time.Now()), true
+}
+
+func (this *Registry) CompletedIds() []string {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	rv := make([]string, len(this.completedOrder))
+	copy(rv, this.completedOrder)
+	return rv
+}
+
+// returns the document describing a completed request
+func (this *Registry) Completed(id string) (map[string]interface{}, bool) {
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	doc, ok := this.completed[id]
+	if !ok {
+		return nil, false
+	}
+	// the caller may change the document
+	rv := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		rv[k] = v
+	}
+	return rv, true
+}
+
+// ActiveRequest is a query being run by the server
+type ActiveRequest struct {
+	Query
+	id        string
+	statement string
+	response  *countingResponse
+	registry  *Registry
+	// closed once the request is completed
+	done      chan bool
+	mutex     sync.Mutex
+	phase     string
+	stop      misc.StopChannel
+	cancelled bool
+	// run when the request completes
+	onComplete []func()
+	// set when the request asked for a profile
+	profile interface{}
+}
+
+func (this *ActiveRequest) Id() string {
+	return this.id
+}
+
+func (this *ActiveRequest) Response() QueryResponse {
+	return this.response
+}
+
+// the stop channel of the executor is closed here only, both when the
+// request is cancelled and when the client goes away
+func (this *ActiveRequest) SetStopChannel(stop misc.StopChannel) {
+	clientStop := make(misc.StopChannel)
+	this.Query.SetStopChannel(clientStop)
+	go func() {
+		select {
+		case <-clientStop:
+			this.stopExecution()
+		case <-this.done:
+		}
+	}()
+
+	this.mutex.Lock()
+	this.stop = stop
+	cancelled := this.cancelled
+	this.mutex.Unlock()
+
+	// cancelled while it was compiling
+	if cancelled {
+		this.stopExecution()
+	}
+}
+
+func (this *ActiveRequest) cancel() {
+	this.mutex.Lock()
+	this.cancelled = true
+	this.mutex.Unlock()
+	this.stopExecution()
+}
+
+func (this *ActiveRequest) Cancelled() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.cancelled
+}
+
+func (this *ActiveRequest) stopExecution() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.stop != nil {
+		close(this.stop)
+		this.stop = nil
+	}
+}
+
+// OnComplete runs f once the request completes, before its client
+// learns that it is done
+func (this *ActiveRequest) OnComplete(f func()) {
+	this.onComplete = append(this.onComplete, f)
+}
+
+func (this *ActiveRequest) SetPhase(phase string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.phase = phase
+}
+
+func (this *ActiveRequest) Phase() string {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.phase
+}
+
+func (this *ActiveRequest) document(now time.Time) map[string]interface{} {
+	start := this.StartTime()
+	rv := map[string]interface{}{
+		"id":            this.id,
+		"statement":     this.statement,
+		"start_time":    start.Format(time.RFC3339Nano),
+		"elapsed_time":  now.Sub(start).String(),
+		"phase":         this.Phase(),
+		"result_count":  float64(atomic.LoadInt64(&this.response.results)),
+		"error_count":   float64(atomic.LoadInt64(&this.response.errors)),
+		"warning_count": float64(atomic.LoadInt64(&this.response.warnings)),
+	}
+	this.mutex.Lock()
+	if this.profile != nil {
+		rv["profile"] = this.profile
+	}
+	this.mutex.Unlock()
+	return rv
+}
+
+// countingResponse counts what is sent to the client, and completes
+// the request before the client learns that it is done
+type countingResponse struct {
+	QueryResponse
+	request  *ActiveRequest
+	results  int64
+	errors   int64
+	warnings int64
+}
+
+func (this *countingResponse) SendError(err query.Error) {
+	switch err.Level() {
+	case query.EXCEPTION:
+		atomic.AddInt64(&this.errors, 1)
+	case query.WARNING:
+		atomic.AddInt64(&this.warnings, 1)
+	}
+	if err.IsFatal() {
+		this.request.registry.Complete(this.request)
+	}
+	this.QueryResponse.SendError(err)
+}
+
+func (this *countingResponse) SendResult(val interface{}) {
+	atomic.AddInt64(&this.results, 1)
+	this.QueryResponse.SendResult(val)
+}
+
+// the profile is kept with the completed request too
+func (this *countingResponse) SetProfile(profile interface{}) {
+	this.request.mutex.Lock()
+	this.request.profile = profile
+	this.request.mutex.Unlock()
+
+	profiled, ok := this.QueryResponse.(ProfiledResponse)
+	if ok {
+		profiled.SetProfile(profile)
+	}
+}
+
+// the results of a cancelled request end with an error
+func (this *countingResponse) NoMoreResults() {
+	if this.request.Cancelled() {
+		this.SendError(query.NewCancelledError())
+		return
+	}
+	this.request.registry.Complete(this.request)
+	this.QueryResponse.NoMoreResults()
+}
+
+// a random (version 4) UUID
+func newRequestId() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		// fall back on the clock, ids only need to be unique
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
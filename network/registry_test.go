@@ -0,0 +1,157 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type testQuery struct {
+	response *testResponse
+	start    time.Time
+}
+
+func (this *testQuery) Request() QueryRequest {
+	return nil
+}
+
+func (this *testQuery) Response() QueryResponse {
+	return this.response
+}
+
+func (this *testQuery) SetStopChannel(misc.StopChannel) {
+}
+
+func (this *testQuery) StartTime() time.Time {
+	return this.start
+}
+
+type testResponse struct {
+	done bool
+	err  query.Error
+}
+
+func (this *testResponse) SendError(err query.Error) {
+	this.err = err
+}
+
+func (this *testResponse) SendResult(interface{}) {
+}
+
+func (this *testResponse) NoMoreResults() {
+	this.done = true
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	q := &testQuery{response: &testResponse{}, start: time.Now()}
+
+	request := registry.Add(q, "SELECT 1")
+	request.Response().SendResult(1.0)
+	request.Response().SendResult(2.0)
+	request.Response().SendError(query.NewWarning("careful"))
+	request.SetPhase(PHASE_EXECUTING)
+
+	ids := registry.ActiveIds()
+	if len(ids) != 1 || ids[0] != request.Id() {
+		t.Fatalf("expected active request %v, got %v", request.Id(), ids)
+	}
+	doc, ok := registry.Active(request.Id())
+	if !ok || doc["statement"] != "SELECT 1" || doc["phase"] != PHASE_EXECUTING ||
+		doc["result_count"] != 2.0 || doc["warning_count"] != 1.0 {
+		t.Errorf("unexpected active request %v", doc)
+	}
+
+	// the request is completed before the client hears of it
+	request.Response().NoMoreResults()
+	if !q.response.done {
+		t.Errorf("expected the response to be done")
+	}
+	registry.Complete(request)
+	if len(registry.ActiveIds()) != 0 {
+		t.Errorf("expected no active requests, got %v", registry.ActiveIds())
+	}
+	ids = registry.CompletedIds()
+	if len(ids) != 1 || ids[0] != request.Id() {
+		t.Fatalf("expected completed request %v, got %v", request.Id(), ids)
+	}
+	doc, ok = registry.Completed(request.Id())
+	if !ok || doc["end_time"] == nil || doc["phase"] != nil || doc["result_count"] != 2.0 {
+		t.Errorf("unexpected completed request %v", doc)
+	}
+}
+
+func TestRegistryCompletedLimits(t *testing.T) {
+	defer func(limit int, threshold time.Duration) {
+		CompletedLimit = limit
+		CompletedThreshold = threshold
+	}(CompletedLimit, CompletedThreshold)
+	CompletedLimit = 2
+	CompletedThreshold = time.Minute
+
+	registry := NewRegistry()
+	requests := make([]*ActiveRequest, 4)
+	for i, _ := range requests {
+		// all but the first are slow
+		start := time.Now()
+		if i > 0 {
+			start = start.Add(-time.Hour)
+		}
+		requests[i] = registry.Add(&testQuery{response: &testResponse{}, start: start}, "SELECT 1")
+	}
+	for _, request := range requests {
+		registry.Complete(request)
+	}
+
+	// only the last two slow requests are kept
+	ids := registry.CompletedIds()
+	if len(ids) != 2 || ids[0] != requests[2].Id() || ids[1] != requests[3].Id() {
+		t.Errorf("expected the last two requests, got %v", ids)
+	}
+	_, ok := registry.Completed(requests[1].Id())
+	if ok {
+		t.Errorf("expected request %v to be forgotten", requests[1].Id())
+	}
+}
+
+func TestRegistryCancel(t *testing.T) {
+	registry := NewRegistry()
+	q := &testQuery{response: &testResponse{}, start: time.Now()}
+
+	err := registry.Cancel("nothing")
+	if err == nil || err.TranslationKey() != "request_not_found" {
+		t.Errorf("expected request_not_found, got %v", err)
+	}
+
+	// cancelled before it started executing
+	request := registry.Add(q, "SELECT 1")
+	err = registry.Cancel(request.Id())
+	if err != nil || !request.Cancelled() {
+		t.Errorf("expected the request to be cancelled, err: %v", err)
+	}
+	stop := make(misc.StopChannel)
+	request.SetStopChannel(stop)
+	_, ok := <-stop
+	if ok {
+		t.Errorf("expected the stop channel to be closed")
+	}
+
+	request.Response().NoMoreResults()
+	if q.response.done || q.response.err == nil || q.response.err.TranslationKey() != "request_cancelled" {
+		t.Errorf("expected request_cancelled error, got %v", q.response.err)
+	}
+	if len(registry.ActiveIds()) != 0 {
+		t.Errorf("expected no active requests, got %v", registry.ActiveIds())
+	}
+}
@@ -0,0 +1,93 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package cost
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/optimizer"
+	"github.com/couchbaselabs/tuqtng/plan"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type CostOptimizer struct {
+	site catalog.Site
+}
+
+func NewCostOptimizer(site catalog.Site) *CostOptimizer {
+	return &CostOptimizer{
+		site: site,
+	}
+}
+
+// 1.  read all plans off plan channel
+// 2.  estimate the cost of each plan from bucket counts, index statistics and predicate selectivity
+// 3.  return the cheapest plan, with its cost for EXPLAIN
+func (this *CostOptimizer) Optimize(planChannel plan.PlanChannel, errChannel query.ErrorChannel) (*plan.Plan, query.Error) {
+
+	plans := make([]plan.Plan, 0)
+
+	var p plan.Plan
+	var err query.Error
+	ok := true
+	for ok {
+		select {
+		case p, ok = <-planChannel:
+			if ok {
+				clog.To(optimizer.CHANNEL, "See plan %v", p)
+				plans = append(plans, p)
+			}
+		case err, ok = <-errChannel:
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(plans) == 0 {
+		return nil, query.NewError(nil, "No plans produced for optimizer to choose from")
+	}
+
+	roots := make([]plan.PlanElement, len(plans))
+	for i, p := range plans {
+		roots[i] = p.Root
+	}
+
+	chosen, cost := NewEstimator(this.site).Cheapest(roots)
+	chosenPlan := plans[chosen]
+	explain, ok := chosenPlan.Root.(*plan.Explain)
+	if ok {
+		explain.Cost = cost
+		if explain.Verbose {
+			explain.Candidates = this.candidates(roots, chosen)
+		}
+	}
+
+	clog.To(optimizer.CHANNEL, "Choosing plan %v with cost %v", chosenPlan, cost)
+	return &chosenPlan, nil
+}
+
+// every plan we chose from, for EXPLAIN VERBOSE
+func (this *CostOptimizer) candidates(roots []plan.PlanElement, chosen int) []*plan.Candidate {
+	estimator := NewEstimator(this.site)
+	rv := make([]*plan.Candidate, len(roots))
+	for i, root := range roots {
+		explain, ok := root.(*plan.Explain)
+		if ok {
+			root = explain.Input
+		}
+		rv[i] = &plan.Candidate{
+			Plan:   estimator.Annotate(root),
+			Cost:   estimator.Estimate(root),
+			Chosen: i == chosen,
+		}
+	}
+	return rv
+}
@@ -0,0 +1,287 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package cost
+
+import (
+	"math"
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/catalog/mock"
+	"github.com/couchbaselabs/tuqtng/plan"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type testBin struct {
+	count    int64
+	min, max interface{}
+	distinct int64
+}
+
+func (this *testBin) Count() (int64, query.Error) {
+	return this.count, nil
+}
+
+func (this *testBin) Min() (dparval.Value, query.Error) {
+	return *dparval.NewValue(this.min), nil
+}
+
+func (this *testBin) Max() (dparval.Value, query.Error) {
+	return *dparval.NewValue(this.max), nil
+}
+
+func (this *testBin) DistinctCount() (int64, query.Error) {
+	return this.distinct, nil
+}
+
+type testStatistics struct {
+	testBin
+	bins []catalog.Bin
+}
+
+func (this *testStatistics) NullCount() (int64, query.Error) {
+	return 0, nil
+}
+
+func (this *testStatistics) MissingCount() (int64, query.Error) {
+	return 0, nil
+}
+
+func (this *testStatistics) Bins() ([]catalog.Bin, query.Error) {
+	return this.bins, nil
+}
+
+func lookup(val interface{}) catalog.LookupValue {
+	if val == nil {
+		return nil
+	}
+	return catalog.LookupValue{dparval.NewValue(val)}
+}
+
+func scanRange(low, high interface{}, inclusion catalog.RangeInclusion) *plan.ScanRange {
+	return &plan.ScanRange{Low: lookup(low), High: lookup(high), Inclusion: inclusion}
+}
+
+func optimize(site catalog.Site, roots ...plan.PlanElement) (*plan.Plan, query.Error) {
+	pc := make(plan.PlanChannel)
+	ec := make(query.ErrorChannel)
+	go func() {
+		for _, root := range roots {
+			pc <- plan.Plan{Root: root}
+		}
+		close(pc)
+	}()
+	return NewCostOptimizer(site).Optimize(pc, ec)
+}
+
+func TestOptimizerChoosesCheapest(t *testing.T) {
+	site, err := mock.NewSite("mock:items=1000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	where := ast.NewEqualToOperator(ast.NewProperty("a"), ast.NewLiteralNumber(5.0))
+	primaryScan := plan.NewFilter(plan.NewFetch(plan.NewScan("p0", "b0", "all_docs", nil), "p0", "b0", nil, "b0"), where)
+	rangeScan := plan.NewFilter(plan.NewFetch(plan.NewScan("p0", "b0", "a_idx", plan.ScanRanges{scanRange(5.0, 5.0, catalog.Both)}), "p0", "b0", nil, "b0"), where)
+	keyScan := plan.NewFilter(plan.NewFetch(plan.NewKeyScan([]string{"1", "2"}), "p0", "b0", nil, "b0"), where)
+
+	tests := []struct {
+		roots  []plan.PlanElement
+		chosen plan.PlanElement
+	}{
+		{[]plan.PlanElement{primaryScan, rangeScan}, rangeScan},
+		{[]plan.PlanElement{rangeScan, primaryScan}, rangeScan},
+		{[]plan.PlanElement{keyScan, rangeScan, primaryScan}, keyScan},
+		{[]plan.PlanElement{primaryScan}, primaryScan},
+	}
+
+	for _, x := range tests {
+		p, err := optimize(site, x.roots...)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if p.Root != x.chosen {
+			t.Errorf("expected plan %#v, got %#v", x.chosen, p.Root)
+		}
+	}
+
+	_, err = optimize(site)
+	if err == nil {
+		t.Errorf("expected error when there are no plans")
+	}
+}
+
+func TestOptimizerExplainCost(t *testing.T) {
+	site, err := mock.NewSite("mock:items=1000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	explain := plan.NewExplain(plan.NewFetch(plan.NewScan("p0", "b0", "all_docs", nil), "p0", "b0", nil, "b0"))
+	p, err := optimize(site, explain)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Root != explain || explain.Cost == nil {
+		t.Fatalf("expected explain with cost, got %#v", p.Root)
+	}
+	expected := 1000*SCAN_ENTRY_COST + 1000*FETCH_COST
+	if explain.Cost.Cost != expected || explain.Cost.Cardinality != 1000 {
+		t.Errorf("expected cost %v for 1000 items, got %#v", expected, explain.Cost)
+	}
+}
+
+func TestOptimizerExplainVerbose(t *testing.T) {
+	site, err := mock.NewSite("mock:items=1000")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	primaryScan := plan.NewFetch(plan.NewScan("p0", "b0", "all_docs", nil), "p0", "b0", nil, "b0")
+	rangeScan := plan.NewFetch(plan.NewScan("p0", "b0", "a_idx", plan.ScanRanges{scanRange(5.0, 5.0, catalog.Both)}), "p0", "b0", nil, "b0")
+	primaryExplain := plan.NewExplain(primaryScan)
+	rangeExplain := plan.NewExplain(rangeScan)
+	for _, explain := range []*plan.Explain{primaryExplain, rangeExplain} {
+		explain.Verbose = true
+	}
+
+	p, err := optimize(site, primaryExplain, rangeExplain)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Root != rangeExplain {
+		t.Fatalf("expected the range scan, got %#v", p.Root)
+	}
+	if len(rangeExplain.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %#v", rangeExplain.Candidates)
+	}
+	if rangeExplain.Candidates[0].Chosen || !rangeExplain.Candidates[1].Chosen {
+		t.Errorf("expected the second candidate to be chosen")
+	}
+	if *rangeExplain.Candidates[1].Cost != *rangeExplain.Cost {
+		t.Errorf("expected chosen candidate cost %v, got %v", rangeExplain.Cost, rangeExplain.Candidates[1].Cost)
+	}
+
+	// every element of a candidate carries its estimate
+	fetch := rangeExplain.Candidates[0].Plan
+	if fetch["type"] != "fetch" || fetch["#estimate"] == nil {
+		t.Fatalf("expected annotated fetch, got %v", fetch)
+	}
+	scan, ok := fetch["input"].(map[string]interface{})
+	if !ok || scan["type"] != "scan" {
+		t.Fatalf("expected annotated scan, got %v", fetch["input"])
+	}
+	estimate, ok := scan["#estimate"].(*plan.Cost)
+	if !ok || estimate.Cardinality != 1000 || estimate.Cost != 1000*SCAN_ENTRY_COST {
+		t.Errorf("expected scan estimate of 1000 entries, got %v", scan["#estimate"])
+	}
+}
+
+func TestStatisticsSelectivity(t *testing.T) {
+	stats := &testStatistics{
+		testBin: testBin{400, 0.0, 20.0, 0},
+		bins: []catalog.Bin{
+			&testBin{100, 0.0, 10.0, 0},
+			&testBin{300, 10.0, 20.0, 0},
+		},
+	}
+	noBins := &testStatistics{testBin: testBin{400, 0.0, 20.0, 0}}
+	distinct := &testStatistics{
+		testBin: testBin{400, 0.0, 20.0, 25},
+		bins: []catalog.Bin{
+			&testBin{100, 0.0, 10.0, 5},
+			&testBin{300, 10.0, 20.0, 20},
+		},
+	}
+
+	tests := []struct {
+		stats    catalog.RangeStatistics
+		ranges   plan.ScanRanges
+		expected float64
+	}{
+		{stats, plan.ScanRanges{scanRange(nil, nil, catalog.Neither)}, 1},
+		{stats, plan.ScanRanges{scanRange(15.0, nil, catalog.Low)}, 150.0 / 400},
+		{stats, plan.ScanRanges{scanRange(nil, 5.0, catalog.Neither)}, 50.0 / 400},
+		{stats, plan.ScanRanges{scanRange(12.0, 12.0, catalog.Both)}, 30.0 / 400},
+		{stats, plan.ScanRanges{scanRange(30.0, nil, catalog.Low)}, 0},
+		{stats, plan.ScanRanges{scanRange(nil, 5.0, catalog.Neither), scanRange(15.0, nil, catalog.Low)}, 200.0 / 400},
+		{stats, plan.ScanRanges{scanRange("a", nil, catalog.Low)}, 0},
+		{noBins, plan.ScanRanges{scanRange(15.0, nil, catalog.Low)}, 0.25},
+		{distinct, plan.ScanRanges{scanRange(12.0, 12.0, catalog.Both)}, 15.0 / 400},
+		{distinct, plan.ScanRanges{scanRange(5.0, 5.0, catalog.Both)}, 20.0 / 400},
+	}
+
+	for _, x := range tests {
+		actual := StatisticsSelectivity(x.stats, x.ranges)
+		if math.Abs(actual-x.expected) > 1e-9 {
+			t.Errorf("expected selectivity %v for %v, got %v", x.expected, x.ranges, actual)
+		}
+	}
+}
+
+func TestExpressionSelectivity(t *testing.T) {
+	a := ast.NewProperty("a")
+	one := ast.NewLiteralNumber(1.0)
+
+	tests := []struct {
+		expr     ast.Expression
+		expected float64
+	}{
+		{ast.NewLiteralBool(true), 1},
+		{ast.NewEqualToOperator(a, one), EQUALITY_SELECTIVITY},
+		{ast.NewGreaterThanOperator(a, one), OPEN_SELECTIVITY},
+		{ast.NewAndOperator(ast.ExpressionList{ast.NewEqualToOperator(a, one), ast.NewGreaterThanOperator(a, one)}), EQUALITY_SELECTIVITY * OPEN_SELECTIVITY},
+		{ast.NewOrOperator(ast.ExpressionList{ast.NewEqualToOperator(a, one), ast.NewEqualToOperator(a, one)}), 1 - (1-EQUALITY_SELECTIVITY)*(1-EQUALITY_SELECTIVITY)},
+		{ast.NewNotOperator(ast.NewEqualToOperator(a, one)), 1 - EQUALITY_SELECTIVITY},
+		{ast.NewInOperator(a, ast.NewLiteralArray(ast.ExpressionList{one, one, one})), 3 * EQUALITY_SELECTIVITY},
+		{a, DEFAULT_SELECTIVITY},
+	}
+
+	for _, x := range tests {
+		actual := ExpressionSelectivity(x.expr)
+		if math.Abs(actual-x.expected) > 1e-9 {
+			t.Errorf("expected selectivity %v for %v, got %v", x.expected, x.expr, actual)
+		}
+	}
+}
+
+func TestJoinEstimate(t *testing.T) {
+	site, err := mock.NewSite("mock:items=100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	on := ast.NewEqualToOperator(ast.NewProperty("a"), ast.NewProperty("b"))
+	left := plan.NewKeyScan([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"})
+	right := plan.NewScan("p0", "b0", "all_docs", nil)
+
+	tests := []struct {
+		join        *plan.Join
+		cost        float64
+		cardinality float64
+	}{
+		{plan.NewHashJoin(left, right, "", on, "b", ast.ExpressionList{ast.NewProperty("a")}, ast.ExpressionList{ast.NewProperty("b")}, "left"),
+			10*EVALUATE_COST + 100*SCAN_ENTRY_COST + 110*EVALUATE_COST, 100},
+		{plan.NewNestedLoopJoin(left, right, "", on, "b"),
+			10*EVALUATE_COST + 100*SCAN_ENTRY_COST + 1000*EVALUATE_COST, 1000 * EQUALITY_SELECTIVITY},
+		{plan.NewNestedLoopJoin(left, right, "LEFT", ast.NewLiteralBool(false), "b"),
+			10*EVALUATE_COST + 100*SCAN_ENTRY_COST + 1000*EVALUATE_COST, 10},
+	}
+
+	for _, x := range tests {
+		actual := NewEstimator(site).Estimate(x.join)
+		if math.Abs(actual.Cost-x.cost) > 1e-9 || math.Abs(actual.Cardinality-x.cardinality) > 1e-9 {
+			t.Errorf("expected cost %v and cardinality %v for %v join, got %#v", x.cost, x.cardinality, x.join.Method, actual)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package cost
+
+import (
+	"math"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/optimizer"
+	"github.com/couchbaselabs/tuqtng/plan"
+)
+
+// the cost of each unit of work, relative to reading one index entry
+const (
+	SCAN_ENTRY_COST = 1.0  // reading one index entry
+	RANGE_COST      = 10.0 // starting a scan of one range
+	FETCH_COST      = 4.0  // fetching one document
+	WRITE_COST      = 8.0  // writing one document
+	EVALUATE_COST   = 0.1  // evaluating an expression over one item
+	SORT_COST       = 0.2  // one comparison while sorting
+)
+
+// used when a bucket cannot tell us how many documents it holds
+const DEFAULT_BUCKET_COUNT = 1000
+
+// the number of elements we expect an unnested array to have
+const UNNEST_FANOUT = 3.0
+
+// the fraction of items we expect to form distinct groups
+const GROUP_SELECTIVITY = 0.1
+
+// Estimator computes the cost of plans against the catalog of a site.
+// bucket counts and index statistics are cached for the life of the
+// estimator, so use a new one for every statement
+type Estimator struct {
+	site   catalog.Site
+	counts map[string]float64
+	stats  map[string]catalog.RangeStatistics
+}
+
+func NewEstimator(site catalog.Site) *Estimator {
+	return &Estimator{
+		site:   site,
+		counts: make(map[string]float64),
+		stats:  make(map[string]catalog.RangeStatistics),
+	}
+}
+
+// the estimate for a subtree of a plan
+type estimate struct {
+	cost        float64
+	cardinality float64
+	// the number of items before any predicate was applied, predicates
+	// already applied by an index scan are not counted twice this way
+	documents float64
+}
+
+func (this *Estimator) Estimate(element plan.PlanElement) *plan.Cost {
+	est := this.estimate(element)
+	return &plan.Cost{
+		Cost:        est.cost,
+		Cardinality: est.cardinality,
+	}
+}
+
+// Annotate returns the plan as json values, with the estimate
+// of every element of the plan in its #estimate field
+func (this *Estimator) Annotate(element plan.PlanElement) map[string]interface{} {
+	return plan.Annotate(element, "#estimate", func(element plan.PlanElement) interface{} {
+		return this.Estimate(element)
+	})
+}
+
+// returns the position of the cheapest of the plans and its cost,
+// among plans of equal cost the last one wins
+func (this *Estimator) Cheapest(elements []plan.PlanElement) (int, *plan.Cost) {
+	chosen := -1
+	var chosenCost *plan.Cost
+	for i, element := range elements {
+		cost := this.Estimate(element)
+		clog.To(optimizer.CHANNEL, "Plan %v has cost %v", element, cost)
+		if chosenCost == nil || cost.Cost <= chosenCost.Cost {
+			chosen = i
+			chosenCost = cost
+		}
+	}
+	return chosen, chosenCost
+}
+
+func (this *Estimator) estimate(element plan.PlanElement) estimate {
+	switch element := element.(type) {
+	case *plan.Explain:
+		return this.estimate(element.Input)
+	case *plan.Scan:
+		return this.estimateScan(element)
+	case *plan.KeyScan:
+		keys := float64(len(element.KeyList))
+		if element.Keys != nil {
+			// not known until the statement is run
+			keys = 1
+		}
+		return estimate{cost: keys * EVALUATE_COST, cardinality: keys, documents: keys}
+	case *plan.FastCount:
+		return estimate{cost: RANGE_COST, cardinality: 1, documents: 1}
+	case *plan.Fetch:
+		if element.Input == nil {
+			// the ids were taken from the WHERE clause
+			ids := float64(len(element.Ids))
+			return estimate{cost: ids * FETCH_COST, cardinality: ids, documents: ids}
+		}
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * FETCH_COST
+		return rv
+	case *plan.Filter:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * EVALUATE_COST
+		rv.cardinality = math.Min(rv.cardinality, rv.documents*ExpressionSelectivity(element.Expr))
+		return rv
+	case *plan.Grouper:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * EVALUATE_COST * float64(1+len(element.Aggregates))
+		if len(element.Group) == 0 {
+			rv.cardinality = 1
+		} else {
+			rv.cardinality = math.Max(1, rv.cardinality*GROUP_SELECTIVITY)
+		}
+		rv.documents = rv.cardinality
+		return rv
+	case *plan.Order:
+		rv := this.estimate(element.Input)
+		// with a limit, each item is compared against the few kept
+		kept := rv.cardinality
+		if element.Limit > 0 {
+			kept = math.Min(kept, float64(element.Limit))
+		}
+		if kept > 1 {
+			rv.cost += rv.cardinality * math.Log2(kept) * SORT_COST * float64(len(element.Sort))
+		}
+		return rv
+	case *plan.Window:
+		rv := this.estimate(element.Input)
+		// the items are sorted for every window
+		if rv.cardinality > 1 {
+			rv.cost += rv.cardinality * math.Log2(rv.cardinality) * SORT_COST * float64(len(element.Windows))
+		}
+		return rv
+	case *plan.Limit:
+		rv := this.estimate(element.Input)
+		rv.cardinality = math.Min(rv.cardinality, float64(element.Val))
+		return rv
+	case *plan.Offset:
+		rv := this.estimate(element.Input)
+		rv.cardinality = math.Max(0, rv.cardinality-float64(element.Val))
+		return rv
+	case *plan.Projector, *plan.ProjectorInline, *plan.EliminateDuplicates:
+		rv := this.estimate(element.Sources()[0])
+		rv.cost += rv.cardinality * EVALUATE_COST
+		return rv
+	case *plan.Unnest:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * EVALUATE_COST
+		rv.cardinality *= UNNEST_FANOUT
+		rv.documents *= UNNEST_FANOUT
+		return rv
+	case *plan.KeyJoin:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * FETCH_COST
+		return rv
+	case *plan.Join:
+		return this.estimateJoin(element)
+	case *plan.Union:
+		input, term := this.estimate(element.Input), this.estimate(element.Term)
+		return combine(input, term, input.cardinality+term.cardinality)
+	case *plan.Intersect:
+		input, term := this.estimate(element.Input), this.estimate(element.Term)
+		return combine(input, term, math.Min(input.cardinality, term.cardinality))
+	case *plan.Except:
+		input, term := this.estimate(element.Input), this.estimate(element.Term)
+		return combine(input, term, input.cardinality)
+	case *plan.Insert:
+		values := float64(len(element.Values))
+		return estimate{cost: values * WRITE_COST, cardinality: values, documents: values}
+	case *plan.Update:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * WRITE_COST
+		return rv
+	case *plan.Delete:
+		rv := this.estimate(element.Input)
+		rv.cost += rv.cardinality * WRITE_COST
+		return rv
+	}
+
+	// anything else costs what its sources cost
+	rv := estimate{}
+	for i, source := range element.Sources() {
+		if source == nil {
+			continue
+		}
+		sourceEstimate := this.estimate(source)
+		rv.cost += sourceEstimate.cost
+		if i == 0 {
+			rv.cardinality = sourceEstimate.cardinality
+			rv.documents = sourceEstimate.documents
+		}
+	}
+	return rv
+}
+
+// the set operations read both sides and compare every item
+func combine(input, term estimate, cardinality float64) estimate {
+	return estimate{
+		cost:        input.cost + term.cost + (input.cardinality+term.cardinality)*EVALUATE_COST,
+		cardinality: cardinality,
+		documents:   cardinality,
+	}
+}
+
+func (this *Estimator) estimateJoin(join *plan.Join) estimate {
+	input, right := this.estimate(join.Input), this.estimate(join.Right)
+	rv := estimate{cost: input.cost + right.cost}
+	if join.Method == "hash" {
+		// every item is hashed once, and we expect each item of the
+		// larger side to find one item of the other (like a foreign key)
+		rv.cost += (input.cardinality + right.cardinality) * EVALUATE_COST
+		if input.cardinality > 0 && right.cardinality > 0 {
+			rv.cardinality = math.Max(input.cardinality, right.cardinality)
+		}
+	} else {
+		// the condition is evaluated for every pair
+		rv.cost += input.cardinality * right.cardinality * EVALUATE_COST
+		rv.cardinality = input.cardinality * right.cardinality * ExpressionSelectivity(join.On)
+	}
+	if join.JoinType == "LEFT" {
+		rv.cardinality = math.Max(rv.cardinality, input.cardinality)
+	}
+	rv.documents = rv.cardinality
+	return rv
+}
+
+func (this *Estimator) estimateScan(scan *plan.Scan) estimate {
+	documents := this.bucketCount(scan.Pool, scan.Bucket)
+	if len(scan.Ranges) == 0 {
+		return estimate{cost: documents * SCAN_ENTRY_COST, cardinality: documents, documents: documents}
+	}
+
+	entries := documents
+	selectivity := -1.0
+	stats := this.indexStatistics(scan.Pool, scan.Bucket, scan.ScanIndex)
+	if stats != nil {
+		count, err := stats.Count()
+		if err == nil {
+			entries = float64(count)
+		}
+		selectivity = StatisticsSelectivity(stats, scan.Ranges)
+	}
+	if selectivity < 0 {
+		selectivity = RangesSelectivity(scan.Ranges)
+	}
+
+	cardinality := entries * selectivity
+	// each range may stop early (the MIN() optimization)
+	limited := 0.0
+	for _, r := range scan.Ranges {
+		if r.Limit <= 0 {
+			limited = -1
+			break
+		}
+		limited += float64(r.Limit)
+	}
+	if limited >= 0 {
+		cardinality = math.Min(cardinality, limited)
+	}
+
+	return estimate{
+		cost:        float64(len(scan.Ranges))*RANGE_COST + cardinality*SCAN_ENTRY_COST,
+		cardinality: cardinality,
+		documents:   documents,
+	}
+}
+
+func (this *Estimator) bucketCount(poolName, bucketName string) float64 {
+	key := poolName + ":" + bucketName
+	count, ok := this.counts[key]
+	if ok {
+		return count
+	}
+
+	count = DEFAULT_BUCKET_COUNT
+	bucket := this.bucket(poolName, bucketName)
+	if bucket != nil {
+		bucketCount, err := bucket.Count()
+		if err == nil {
+			count = float64(bucketCount)
+		} else {
+			clog.To(optimizer.CHANNEL, "Unable to count bucket %v: %v", bucketName, err)
+		}
+	}
+	this.counts[key] = count
+	return count
+}
+
+// returns nil when the index has no statistics
+func (this *Estimator) indexStatistics(poolName, bucketName, indexName string) catalog.RangeStatistics {
+	key := poolName + ":" + bucketName + ":" + indexName
+	stats, ok := this.stats[key]
+	if ok {
+		return stats
+	}
+
+	bucket := this.bucket(poolName, bucketName)
+	if bucket != nil {
+		index, err := bucket.IndexByName(indexName)
+		if err == nil {
+			rangeIndex, ok := index.(catalog.RangeIndex)
+			if ok {
+				stats, err = rangeIndex.Statistics()
+				if err != nil {
+					clog.To(optimizer.CHANNEL, "No statistics for index %v: %v", indexName, err)
+					stats = nil
+				}
+			}
+		}
+	}
+	this.stats[key] = stats
+	return stats
+}
+
+func (this *Estimator) bucket(poolName, bucketName string) catalog.Bucket {
+	if this.site == nil {
+		return nil
+	}
+	pool, err := this.site.PoolByName(poolName)
+	if err != nil {
+		return nil
+	}
+	bucket, err := pool.BucketByName(bucketName)
+	if err != nil {
+		return nil
+	}
+	return bucket
+}
@@ -0,0 +1,225 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package cost
+
+import (
+	"math"
+
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/plan"
+)
+
+// the fraction of items we expect to satisfy a predicate
+// when nothing better is known
+const (
+	EQUALITY_SELECTIVITY = 0.1       // a = 1
+	OPEN_SELECTIVITY     = 1.0 / 3.0 // a > 1
+	BOUNDED_SELECTIVITY  = 0.25      // a > 1 AND a < 5
+	LIKE_SELECTIVITY     = 0.25      // a LIKE "x%"
+	NULL_SELECTIVITY     = 0.05      // a IS NULL
+	DEFAULT_SELECTIVITY  = 0.5       // anything else
+)
+
+// ExpressionSelectivity guesses the fraction of items
+// for which the expression is true
+func ExpressionSelectivity(expr ast.Expression) float64 {
+	switch expr := expr.(type) {
+	case *ast.LiteralBool:
+		if expr.Val {
+			return 1
+		}
+		return 0
+	case *ast.AndOperator:
+		rv := 1.0
+		for _, operand := range expr.Operands {
+			rv *= ExpressionSelectivity(operand)
+		}
+		return rv
+	case *ast.OrOperator:
+		// the chance that not all operands are false
+		rv := 1.0
+		for _, operand := range expr.Operands {
+			rv *= 1 - ExpressionSelectivity(operand)
+		}
+		return 1 - rv
+	case *ast.NotOperator:
+		return 1 - ExpressionSelectivity(expr.Operand)
+	case *ast.EqualToOperator:
+		return EQUALITY_SELECTIVITY
+	case *ast.NotEqualToOperator:
+		return 1 - EQUALITY_SELECTIVITY
+	case *ast.GreaterThanOperator, *ast.GreaterThanOrEqualOperator,
+		*ast.LessThanOperator, *ast.LessThanOrEqualOperator:
+		return OPEN_SELECTIVITY
+	case *ast.LikeOperator:
+		return LIKE_SELECTIVITY
+	case *ast.NotLikeOperator:
+		return 1 - LIKE_SELECTIVITY
+	case *ast.InOperator:
+		return inSelectivity(expr.Right)
+	case *ast.NotInOperator:
+		return 1 - inSelectivity(expr.Right)
+	case *ast.IsNullOperator, *ast.IsMissingOperator, *ast.IsNotValuedOperator:
+		return NULL_SELECTIVITY
+	case *ast.IsNotNullOperator, *ast.IsNotMissingOperator, *ast.IsValuedOperator:
+		return 1 - NULL_SELECTIVITY
+	}
+	return DEFAULT_SELECTIVITY
+}
+
+func inSelectivity(values ast.Expression) float64 {
+	array, ok := values.(*ast.LiteralArray)
+	if !ok {
+		return DEFAULT_SELECTIVITY
+	}
+	return math.Min(1, float64(len(array.Val))*EQUALITY_SELECTIVITY)
+}
+
+// RangesSelectivity guesses the fraction of index entries that fall
+// into the ranges from the shape of the ranges alone
+func RangesSelectivity(ranges plan.ScanRanges) float64 {
+	rv := 0.0
+	for _, r := range ranges {
+		switch {
+		case r.Low == nil && r.High == nil:
+			return 1
+		case r.Low == nil || r.High == nil:
+			rv += OPEN_SELECTIVITY
+		case compareLookupValues(r.Low, r.High) == 0:
+			rv += EQUALITY_SELECTIVITY
+		default:
+			rv += BOUNDED_SELECTIVITY
+		}
+	}
+	return math.Min(1, rv)
+}
+
+// StatisticsSelectivity computes the fraction of index entries that
+// fall into the ranges from the histogram of the index, or from its
+// min and max values when it has no histogram.  returns -1 if the
+// statistics are not usable
+func StatisticsSelectivity(stats catalog.RangeStatistics, ranges plan.ScanRanges) float64 {
+	bins, err := stats.Bins()
+	if err != nil || len(bins) == 0 {
+		// the whole index as a single bin
+		bins = []catalog.Bin{stats}
+	}
+
+	total := 0.0
+	matched := 0.0
+	for _, bin := range bins {
+		count, err := bin.Count()
+		if err != nil {
+			return -1
+		}
+		min, err := bin.Min()
+		if err != nil {
+			return -1
+		}
+		max, err := bin.Max()
+		if err != nil {
+			return -1
+		}
+		distinct, err := bin.DistinctCount()
+		if err != nil {
+			distinct = 0
+		}
+		total += float64(count)
+
+		overlap := 0.0
+		for _, r := range ranges {
+			overlap += binOverlap(min.Value(), max.Value(), distinct, r)
+		}
+		matched += float64(count) * math.Min(1, overlap)
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return matched / total
+}
+
+// the fraction of a bin spanning min to max that falls into the range,
+// only the leading component of a composite key is considered.
+// distinct is the number of distinct values of the bin, 0 if unknown
+func binOverlap(min, max interface{}, distinct int64, r *plan.ScanRange) float64 {
+	var low, high interface{}
+	lowIncluded := r.Inclusion == catalog.Low || r.Inclusion == catalog.Both
+	highIncluded := r.Inclusion == catalog.High || r.Inclusion == catalog.Both
+	if len(r.Low) > 0 {
+		low = r.Low[0].Value()
+	}
+	if len(r.High) > 0 {
+		high = r.High[0].Value()
+	}
+
+	// entirely outside the bin
+	if low != nil {
+		comp := ast.CollateJSON(low, max)
+		if comp > 0 || (comp == 0 && !lowIncluded) {
+			return 0
+		}
+	}
+	if high != nil {
+		comp := ast.CollateJSON(high, min)
+		if comp < 0 || (comp == 0 && !highIncluded) {
+			return 0
+		}
+	}
+
+	// covering the whole bin
+	if (low == nil || ast.CollateJSON(low, min) <= 0) && (high == nil || ast.CollateJSON(high, max) >= 0) {
+		return 1
+	}
+
+	// a single value inside the bin
+	if low != nil && high != nil && ast.CollateJSON(low, high) == 0 {
+		if distinct > 0 {
+			return 1 / float64(distinct)
+		}
+		return EQUALITY_SELECTIVITY
+	}
+
+	// interpolate numbers, assuming they are evenly spread over the bin
+	minNumber, minOk := min.(float64)
+	maxNumber, maxOk := max.(float64)
+	if minOk && maxOk && maxNumber > minNumber {
+		from, to := minNumber, maxNumber
+		if lowNumber, ok := low.(float64); ok {
+			from = math.Max(from, lowNumber)
+		}
+		if highNumber, ok := high.(float64); ok {
+			to = math.Min(to, highNumber)
+		}
+		if to > from {
+			return (to - from) / (maxNumber - minNumber)
+		}
+		return 0
+	}
+
+	return DEFAULT_SELECTIVITY
+}
+
+func compareLookupValues(left, right catalog.LookupValue) int {
+	for i, l := range left {
+		if i >= len(right) {
+			return 1
+		}
+		comp := ast.CollateJSON(l.Value(), right[i].Value())
+		if comp != 0 {
+			return comp
+		}
+	}
+	if len(right) > len(left) {
+		return -1
+	}
+	return 0
+}
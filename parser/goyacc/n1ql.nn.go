@@ -17,7 +17,7 @@ type family struct {
   a []dfa
   endcase int
 }
-var a0 [104]dfa
+var a0 [123]dfa
 var a []family
 func init() {
 a = make([]family, 1)
@@ -9053,6 +9053,2868 @@ a0[85].f = fun[:]
 a0[85].id = 85
 }
 {
+var acc [7]bool
+var fun [7]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 117: return 1
+  case 85: return 1
+  case 112: return -1
+  case 80: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return 2
+  case 80: return 2
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return -1
+  case 80: return -1
+  case 115: return 3
+  case 83: return 3
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return -1
+  case 80: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return 4
+  case 69: return 4
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return -1
+  case 80: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return 5
+  case 82: return 5
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return -1
+  case 80: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return 6
+  case 84: return 6
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[6] = true
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 112: return -1
+  case 80: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[86].acc = acc[:]
+a0[86].f = fun[:]
+a0[86].id = 86
+}
+{
+var acc [7]bool
+var fun [7]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 118: return 1
+  case 86: return 1
+  case 97: return -1
+  case 65: return -1
+  case 108: return -1
+  case 76: return -1
+  case 117: return -1
+  case 85: return -1
+  case 101: return -1
+  case 69: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return 2
+  case 65: return 2
+  case 108: return -1
+  case 76: return -1
+  case 117: return -1
+  case 85: return -1
+  case 101: return -1
+  case 69: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return -1
+  case 65: return -1
+  case 108: return 3
+  case 76: return 3
+  case 117: return -1
+  case 85: return -1
+  case 101: return -1
+  case 69: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return -1
+  case 65: return -1
+  case 108: return -1
+  case 76: return -1
+  case 117: return 4
+  case 85: return 4
+  case 101: return -1
+  case 69: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return -1
+  case 65: return -1
+  case 108: return -1
+  case 76: return -1
+  case 117: return -1
+  case 85: return -1
+  case 101: return 5
+  case 69: return 5
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return -1
+  case 65: return -1
+  case 108: return -1
+  case 76: return -1
+  case 117: return -1
+  case 85: return -1
+  case 101: return -1
+  case 69: return -1
+  case 115: return 6
+  case 83: return 6
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[6] = true
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 97: return -1
+  case 65: return -1
+  case 108: return -1
+  case 76: return -1
+  case 117: return -1
+  case 85: return -1
+  case 101: return -1
+  case 69: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[87].acc = acc[:]
+a0[87].f = fun[:]
+a0[87].id = 87
+}
+{
+var acc [4]bool
+var fun [4]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 115: return 1
+  case 83: return 1
+  case 101: return -1
+  case 69: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 101: return 2
+  case 69: return 2
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 116: return 3
+  case 84: return 3
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[3] = true
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[88].acc = acc[:]
+a0[88].f = fun[:]
+a0[88].id = 88
+}
+{
+var acc [8]bool
+var fun [8]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 112: return 1
+  case 80: return 1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return 2
+  case 82: return 2
+  case 101: return -1
+  case 69: return -1
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return 3
+  case 69: return 3
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 112: return 4
+  case 80: return 4
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 97: return 5
+  case 65: return 5
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return 6
+  case 82: return 6
+  case 101: return -1
+  case 69: return -1
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return 7
+  case 69: return 7
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[7] = true
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 97: return -1
+  case 65: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[89].acc = acc[:]
+a0[89].f = fun[:]
+a0[89].id = 89
+}
+{
+var acc [8]bool
+var fun [8]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 101: return 1
+  case 69: return 1
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 101: return -1
+  case 69: return -1
+  case 120: return 2
+  case 88: return 2
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 101: return 3
+  case 69: return 3
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 101: return -1
+  case 69: return -1
+  case 120: return -1
+  case 88: return -1
+  case 99: return 4
+  case 67: return 4
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 101: return -1
+  case 69: return -1
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return 5
+  case 85: return 5
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 101: return -1
+  case 69: return -1
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return 6
+  case 84: return 6
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 101: return 7
+  case 69: return 7
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[7] = true
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 101: return -1
+  case 69: return -1
+  case 120: return -1
+  case 88: return -1
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[90].acc = acc[:]
+a0[90].f = fun[:]
+a0[90].id = 90
+}
+{
+var acc [11]bool
+var fun [11]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 115: return 1
+  case 83: return 1
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return 2
+  case 84: return 2
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return -1
+  case 84: return -1
+  case 97: return 3
+  case 65: return 3
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return 4
+  case 84: return 4
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return 5
+  case 73: return 5
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 115: return 6
+  case 83: return 6
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return 7
+  case 84: return 7
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return 8
+  case 73: return 8
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return 9
+  case 67: return 9
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[9] = func(r rune) int {
+  switch(r) {
+  case 115: return 10
+  case 83: return 10
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[10] = true
+fun[10] = func(r rune) int {
+  switch(r) {
+  case 115: return -1
+  case 83: return -1
+  case 116: return -1
+  case 84: return -1
+  case 97: return -1
+  case 65: return -1
+  case 105: return -1
+  case 73: return -1
+  case 99: return -1
+  case 67: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[91].acc = acc[:]
+a0[91].f = fun[:]
+a0[91].id = 91
+}
+{
+var acc [8]bool
+var fun [8]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 118: return 1
+  case 86: return 1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return 2
+  case 69: return 2
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return 3
+  case 82: return 3
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 98: return 4
+  case 66: return 4
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return 5
+  case 79: return 5
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return 6
+  case 83: return 6
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return 7
+  case 69: return 7
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[7] = true
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[92].acc = acc[:]
+a0[92].f = fun[:]
+a0[92].id = 92
+}
+{
+var acc [4]bool
+var fun [4]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 117: return 1
+  case 85: return 1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 115: return 2
+  case 83: return 2
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return 3
+  case 69: return 3
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[3] = true
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 115: return -1
+  case 83: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[93].acc = acc[:]
+a0[93].f = fun[:]
+a0[93].id = 93
+}
+{
+var acc [9]bool
+var fun [9]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 102: return 1
+  case 70: return 1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return 2
+  case 85: return 2
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return 3
+  case 78: return 3
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return 4
+  case 67: return 4
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return 5
+  case 84: return 5
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return 6
+  case 73: return 6
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return 7
+  case 79: return 7
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return 8
+  case 78: return 8
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[8] = true
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 99: return -1
+  case 67: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[94].acc = acc[:]
+a0[94].f = fun[:]
+a0[94].id = 94
+}
+{
+var acc [5]bool
+var fun [5]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 111: return 1
+  case 79: return 1
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 111: return -1
+  case 79: return -1
+  case 118: return 2
+  case 86: return 2
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 111: return -1
+  case 79: return -1
+  case 118: return -1
+  case 86: return -1
+  case 101: return 3
+  case 69: return 3
+  case 114: return -1
+  case 82: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 111: return -1
+  case 79: return -1
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return 4
+  case 82: return 4
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[4] = true
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 111: return -1
+  case 79: return -1
+  case 118: return -1
+  case 86: return -1
+  case 101: return -1
+  case 69: return -1
+  case 114: return -1
+  case 82: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[95].acc = acc[:]
+a0[95].f = fun[:]
+a0[95].id = 95
+}
+{
+var acc [10]bool
+var fun [10]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 112: return 1
+  case 80: return 1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return 2
+  case 65: return 2
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return 3
+  case 82: return 3
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return 4
+  case 84: return 4
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return 5
+  case 73: return 5
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return 6
+  case 84: return 6
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return 7
+  case 73: return 7
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return 8
+  case 79: return 8
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return 9
+  case 78: return 9
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[9] = true
+fun[9] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 97: return -1
+  case 65: return -1
+  case 114: return -1
+  case 82: return -1
+  case 116: return -1
+  case 84: return -1
+  case 105: return -1
+  case 73: return -1
+  case 111: return -1
+  case 79: return -1
+  case 110: return -1
+  case 78: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[96].acc = acc[:]
+a0[96].f = fun[:]
+a0[96].id = 96
+}
+{
+var acc [5]bool
+var fun [5]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 114: return 1
+  case 82: return 1
+  case 111: return -1
+  case 79: return -1
+  case 119: return -1
+  case 87: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return 2
+  case 79: return 2
+  case 119: return -1
+  case 87: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return -1
+  case 79: return -1
+  case 119: return 3
+  case 87: return 3
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return -1
+  case 79: return -1
+  case 119: return -1
+  case 87: return -1
+  case 115: return 4
+  case 83: return 4
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[4] = true
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return -1
+  case 79: return -1
+  case 119: return -1
+  case 87: return -1
+  case 115: return -1
+  case 83: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[97].acc = acc[:]
+a0[97].f = fun[:]
+a0[97].id = 97
+}
+{
+var acc [6]bool
+var fun [6]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 114: return 1
+  case 82: return 1
+  case 97: return -1
+  case 65: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 97: return 2
+  case 65: return 2
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 97: return -1
+  case 65: return -1
+  case 110: return 3
+  case 78: return 3
+  case 103: return -1
+  case 71: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 97: return -1
+  case 65: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return 4
+  case 71: return 4
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 97: return -1
+  case 65: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  case 101: return 5
+  case 69: return 5
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[5] = true
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 97: return -1
+  case 65: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[98].acc = acc[:]
+a0[98].f = fun[:]
+a0[98].id = 98
+}
+{
+var acc [10]bool
+var fun [10]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 117: return 1
+  case 85: return 1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return 2
+  case 78: return 2
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return 3
+  case 66: return 3
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return 4
+  case 79: return 4
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 117: return 5
+  case 85: return 5
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return 6
+  case 78: return 6
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return 7
+  case 68: return 7
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return 8
+  case 69: return 8
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return 9
+  case 68: return 9
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[9] = true
+fun[9] = func(r rune) int {
+  switch(r) {
+  case 117: return -1
+  case 85: return -1
+  case 110: return -1
+  case 78: return -1
+  case 98: return -1
+  case 66: return -1
+  case 111: return -1
+  case 79: return -1
+  case 100: return -1
+  case 68: return -1
+  case 101: return -1
+  case 69: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[99].acc = acc[:]
+a0[99].f = fun[:]
+a0[99].id = 99
+}
+{
+var acc [10]bool
+var fun [10]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 112: return 1
+  case 80: return 1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return 2
+  case 82: return 2
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return 3
+  case 69: return 3
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return 4
+  case 67: return 4
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return 5
+  case 69: return 5
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return 6
+  case 68: return 6
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return 7
+  case 73: return 7
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return 8
+  case 78: return 8
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return 9
+  case 71: return 9
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[9] = true
+fun[9] = func(r rune) int {
+  switch(r) {
+  case 112: return -1
+  case 80: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 99: return -1
+  case 67: return -1
+  case 100: return -1
+  case 68: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[100].acc = acc[:]
+a0[100].f = fun[:]
+a0[100].id = 100
+}
+{
+var acc [10]bool
+var fun [10]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 102: return 1
+  case 70: return 1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return 2
+  case 79: return 2
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return 3
+  case 76: return 3
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return 4
+  case 76: return 4
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return 5
+  case 79: return 5
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return 6
+  case 87: return 6
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return 7
+  case 73: return 7
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return 8
+  case 78: return 8
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[8] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return 9
+  case 71: return 9
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[9] = true
+fun[9] = func(r rune) int {
+  switch(r) {
+  case 102: return -1
+  case 70: return -1
+  case 111: return -1
+  case 79: return -1
+  case 108: return -1
+  case 76: return -1
+  case 119: return -1
+  case 87: return -1
+  case 105: return -1
+  case 73: return -1
+  case 110: return -1
+  case 78: return -1
+  case 103: return -1
+  case 71: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[101].acc = acc[:]
+a0[101].f = fun[:]
+a0[101].id = 101
+}
+{
+var acc [8]bool
+var fun [8]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 99: return 1
+  case 67: return 1
+  case 117: return -1
+  case 85: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return 2
+  case 85: return 2
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return 3
+  case 82: return 3
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return 4
+  case 82: return 4
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[4] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return 5
+  case 69: return 5
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[5] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 110: return 6
+  case 78: return 6
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[6] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return 7
+  case 84: return 7
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[7] = true
+fun[7] = func(r rune) int {
+  switch(r) {
+  case 99: return -1
+  case 67: return -1
+  case 117: return -1
+  case 85: return -1
+  case 114: return -1
+  case 82: return -1
+  case 101: return -1
+  case 69: return -1
+  case 110: return -1
+  case 78: return -1
+  case 116: return -1
+  case 84: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[102].acc = acc[:]
+a0[102].f = fun[:]
+a0[102].id = 102
+}
+{
+var acc [4]bool
+var fun [4]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 114: return 1
+  case 82: return 1
+  case 111: return -1
+  case 79: return -1
+  case 119: return -1
+  case 87: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return 2
+  case 79: return 2
+  case 119: return -1
+  case 87: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return -1
+  case 79: return -1
+  case 119: return 3
+  case 87: return 3
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[3] = true
+fun[3] = func(r rune) int {
+  switch(r) {
+  case 114: return -1
+  case 82: return -1
+  case 111: return -1
+  case 79: return -1
+  case 119: return -1
+  case 87: return -1
+  default:
+    switch {
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[103].acc = acc[:]
+a0[103].f = fun[:]
+a0[103].id = 103
+}
+{
 var acc [3]bool
 var fun [3]func(rune) int
 fun[0] = func(r rune) int {
@@ -9086,9 +11948,9 @@ fun[2] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[86].acc = acc[:]
-a0[86].f = fun[:]
-a0[86].id = 86
+a0[104].acc = acc[:]
+a0[104].f = fun[:]
+a0[104].id = 104
 }
 {
 var acc [2]bool
@@ -9114,9 +11976,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[87].acc = acc[:]
-a0[87].f = fun[:]
-a0[87].id = 87
+a0[105].acc = acc[:]
+a0[105].f = fun[:]
+a0[105].id = 105
 }
 {
 var acc [2]bool
@@ -9142,9 +12004,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[88].acc = acc[:]
-a0[88].f = fun[:]
-a0[88].id = 88
+a0[106].acc = acc[:]
+a0[106].f = fun[:]
+a0[106].id = 106
 }
 {
 var acc [2]bool
@@ -9170,9 +12032,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[89].acc = acc[:]
-a0[89].f = fun[:]
-a0[89].id = 89
+a0[107].acc = acc[:]
+a0[107].f = fun[:]
+a0[107].id = 107
 }
 {
 var acc [2]bool
@@ -9198,9 +12060,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[90].acc = acc[:]
-a0[90].f = fun[:]
-a0[90].id = 90
+a0[108].acc = acc[:]
+a0[108].f = fun[:]
+a0[108].id = 108
 }
 {
 var acc [2]bool
@@ -9226,9 +12088,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[91].acc = acc[:]
-a0[91].f = fun[:]
-a0[91].id = 91
+a0[109].acc = acc[:]
+a0[109].f = fun[:]
+a0[109].id = 109
 }
 {
 var acc [2]bool
@@ -9254,9 +12116,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[92].acc = acc[:]
-a0[92].f = fun[:]
-a0[92].id = 92
+a0[110].acc = acc[:]
+a0[110].f = fun[:]
+a0[110].id = 110
 }
 {
 var acc [2]bool
@@ -9282,9 +12144,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[93].acc = acc[:]
-a0[93].f = fun[:]
-a0[93].id = 93
+a0[111].acc = acc[:]
+a0[111].f = fun[:]
+a0[111].id = 111
 }
 {
 var acc [2]bool
@@ -9310,9 +12172,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[94].acc = acc[:]
-a0[94].f = fun[:]
-a0[94].id = 94
+a0[112].acc = acc[:]
+a0[112].f = fun[:]
+a0[112].id = 112
 }
 {
 var acc [5]bool
@@ -9403,9 +12265,9 @@ fun[4] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[95].acc = acc[:]
-a0[95].f = fun[:]
-a0[95].id = 95
+a0[113].acc = acc[:]
+a0[113].f = fun[:]
+a0[113].id = 113
 }
 {
 var acc [6]bool
@@ -9525,9 +12387,9 @@ fun[5] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[96].acc = acc[:]
-a0[96].f = fun[:]
-a0[96].id = 96
+a0[114].acc = acc[:]
+a0[114].f = fun[:]
+a0[114].id = 114
 }
 {
 var acc [5]bool
@@ -9608,9 +12470,9 @@ fun[4] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[97].acc = acc[:]
-a0[97].f = fun[:]
-a0[97].id = 97
+a0[115].acc = acc[:]
+a0[115].f = fun[:]
+a0[115].id = 115
 }
 {
 var acc [11]bool
@@ -9795,9 +12657,9 @@ fun[9] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[98].acc = acc[:]
-a0[98].f = fun[:]
-a0[98].id = 98
+a0[116].acc = acc[:]
+a0[116].f = fun[:]
+a0[116].id = 116
 }
 {
 var acc [11]bool
@@ -9980,9 +12842,9 @@ fun[8] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[99].acc = acc[:]
-a0[99].f = fun[:]
-a0[99].id = 99
+a0[117].acc = acc[:]
+a0[117].f = fun[:]
+a0[117].id = 117
 }
 {
 var acc [4]bool
@@ -10034,9 +12896,9 @@ fun[3] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[100].acc = acc[:]
-a0[100].f = fun[:]
-a0[100].id = 100
+a0[118].acc = acc[:]
+a0[118].f = fun[:]
+a0[118].id = 118
 }
 {
 var acc [2]bool
@@ -10066,9 +12928,9 @@ fun[1] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[101].acc = acc[:]
-a0[101].f = fun[:]
-a0[101].id = 101
+a0[119].acc = acc[:]
+a0[119].f = fun[:]
+a0[119].id = 119
 }
 {
 var acc [3]bool
@@ -10117,9 +12979,9 @@ fun[2] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[102].acc = acc[:]
-a0[102].f = fun[:]
-a0[102].id = 102
+a0[120].acc = acc[:]
+a0[120].f = fun[:]
+a0[120].id = 120
 }
 {
 var acc [18]bool
@@ -10521,11 +13383,61 @@ fun[14] = func(r rune) int {
   }
   panic("unreachable")
 }
-a0[103].acc = acc[:]
-a0[103].f = fun[:]
-a0[103].id = 103
+a0[121].acc = acc[:]
+a0[121].f = fun[:]
+a0[121].id = 121
+}
+{
+var acc [3]bool
+var fun [3]func(rune) int
+fun[0] = func(r rune) int {
+  switch(r) {
+  case 36: return 1
+  case 95: return -1
+  default:
+    switch {
+    case 48 <= r && r <= 57: return -1
+    case 65 <= r && r <= 90: return -1
+    case 97 <= r && r <= 122: return -1
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+fun[1] = func(r rune) int {
+  switch(r) {
+  case 36: return -1
+  case 95: return 2
+  default:
+    switch {
+    case 48 <= r && r <= 57: return 2
+    case 65 <= r && r <= 90: return 2
+    case 97 <= r && r <= 122: return 2
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+acc[2] = true
+fun[2] = func(r rune) int {
+  switch(r) {
+  case 36: return -1
+  case 95: return 2
+  default:
+    switch {
+    case 48 <= r && r <= 57: return 2
+    case 65 <= r && r <= 90: return 2
+    case 97 <= r && r <= 122: return 2
+    default: return -1
+    }
+  }
+  panic("unreachable")
+}
+a0[122].acc = acc[:]
+a0[122].f = fun[:]
+a0[122].id = 122
 }
-a[0].endcase = 104
+a[0].endcase = 123
 a[0].a = a0[:]
 }
 func getAction(c *frame) int {
@@ -10908,31 +13820,103 @@ func (yylex Lexer) Lex(lval *yySymType) int {
 {
                     logDebugTokens("NEST"); return NEST
                   }
-    case 86:  //\|\|/
+    case 86:  //[uU][pP][sS][eE][rR][tT]/
+{
+                    logDebugTokens("UPSERT"); return UPSERT
+                  }
+    case 87:  //[vV][aA][lL][uU][eE][sS]/
+{
+                    logDebugTokens("VALUES"); return VALUES
+                  }
+    case 88:  //[sS][eE][tT]/
+{
+                    logDebugTokens("SET"); return SET
+                  }
+    case 89:  //[pP][rR][eE][pP][aA][rR][eE]/
+{
+                    logDebugTokens("PREPARE"); return PREPARE
+                  }
+    case 90:  //[eE][xX][eE][cC][uU][tT][eE]/
+{
+                    logDebugTokens("EXECUTE"); return EXECUTE
+                  }
+    case 91:  //[sS][tT][aA][tT][iI][sS][tT][iI][cC][sS]/
+{
+                    logDebugTokens("STATISTICS"); return STATISTICS
+                  }
+    case 92:  //[vV][eE][rR][bB][oO][sS][eE]/
+{
+                    logDebugTokens("VERBOSE"); return VERBOSE
+                  }
+    case 93:  //[uU][sS][eE]/
+{
+                    logDebugTokens("USE"); return USE
+                  }
+    case 94:  //[fF][uU][nN][cC][tT][iI][oO][nN]/
+{
+                    logDebugTokens("FUNCTION"); return FUNCTION
+                  }
+    case 95:  //[oO][vV][eE][rR]/
+{
+                    logDebugTokens("OVER"); return OVER
+                  }
+    case 96:  //[pP][aA][rR][tT][iI][tT][iI][oO][nN]/
+{
+                    logDebugTokens("PARTITION"); return PARTITION
+                  }
+    case 97:  //[rR][oO][wW][sS]/
+{
+                    logDebugTokens("ROWS"); return ROWS
+                  }
+    case 98:  //[rR][aA][nN][gG][eE]/
+{
+                    logDebugTokens("RANGE"); return RANGE
+                  }
+    case 99:  //[uU][nN][bB][oO][uU][nN][dD][eE][dD]/
+{
+                    logDebugTokens("UNBOUNDED"); return UNBOUNDED
+                  }
+    case 100:  //[pP][rR][eE][cC][eE][dD][iI][nN][gG]/
+{
+                    logDebugTokens("PRECEDING"); return PRECEDING
+                  }
+    case 101:  //[fF][oO][lL][lL][oO][wW][iI][nN][gG]/
+{
+                    logDebugTokens("FOLLOWING"); return FOLLOWING
+                  }
+    case 102:  //[cC][uU][rR][rR][eE][nN][tT]/
+{
+                    logDebugTokens("CURRENT"); return CURRENT
+                  }
+    case 103:  //[rR][oO][wW]/
+{
+                    logDebugTokens("ROW"); return ROW
+                  }
+    case 104:  //\|\|/
 { logDebugTokens("CONCAT"); return CONCAT }
-    case 87:  //\(/
+    case 105:  //\(/
 { logDebugTokens("LPAREN"); return LPAREN }
-    case 88:  //\)/
+    case 106:  //\)/
 { logDebugTokens("RPAREN"); return RPAREN }
-    case 89:  //\{/
+    case 107:  //\{/
 { logDebugTokens("LBRACE"); return LBRACE }
-    case 90:  //\}/
+    case 108:  //\}/
 { logDebugTokens("RBRACE"); return RBRACE }
-    case 91:  //\,/
+    case 109:  //\,/
 { logDebugTokens("COMMA"); return COMMA }
-    case 92:  //\:/
+    case 110:  //\:/
 { logDebugTokens("COLON"); return COLON }
-    case 93:  //\[/
+    case 111:  //\[/
 { logDebugTokens("LBRACKET"); return LBRACKET }
-    case 94:  //\]/
+    case 112:  //\]/
 { logDebugTokens("RBRACKET"); return RBRACKET }
-    case 95:  //[tT][rR][uU][eE]/
+    case 113:  //[tT][rR][uU][eE]/
 { logDebugTokens("TRUE"); return TRUE}
-    case 96:  //[fF][aA][lL][sS][eE]/
+    case 114:  //[fF][aA][lL][sS][eE]/
 { logDebugTokens("FALSE"); return FALSE}
-    case 97:  //[nN][uU][lL][lL]/
+    case 115:  //[nN][uU][lL][lL]/
 { logDebugTokens("NULL"); return NULL}
-    case 98:  //([0-9]|[1-9][0-9]*)(\.[0-9][0-9]*)([eE][+\-]?[0-9][0-9]*)?/
+    case 116:  //([0-9]|[1-9][0-9]*)(\.[0-9][0-9]*)([eE][+\-]?[0-9][0-9]*)?/
 {
                   // there are 2 separate rules for NUMBER
                   // instead of 1 with two optional components
@@ -10941,34 +13925,41 @@ func (yylex Lexer) Lex(lval *yySymType) int {
                     logDebugTokens("NUMBER - %f", lval.f);
                     return NUMBER
                   }
-    case 99:  //([0-9]|[1-9][0-9]*)(\.[0-9][0-9]*)?([eE][+\-]?[0-9][0-9]*)/
+    case 117:  //([0-9]|[1-9][0-9]*)(\.[0-9][0-9]*)?([eE][+\-]?[0-9][0-9]*)/
 {
                     lval.f,_ = strconv.ParseFloat(yylex.Text(), 64);
                     logDebugTokens("NUMBER - %f", lval.f);
                     return NUMBER
                   }
-    case 100:  //[0-9]|[1-9][0-9]*/
+    case 118:  //[0-9]|[1-9][0-9]*/
 {
                     lval.n,_ = strconv.Atoi(yylex.Text());
                     logDebugTokens("INT - %d", lval.n);
                     return INT
                   }
-    case 101:  //[ \t\n]+/
+    case 119:  //[ \t\n]+/
 { logDebugTokens("WHITESPACE (count=%d)", len(yylex.Text())) /* eat up whitespace */ }
-    case 102:  //[a-zA-Z_][a-zA-Z0-9\-_]*/
+    case 120:  //[a-zA-Z_][a-zA-Z0-9\-_]*/
 {
                     lval.s = yylex.Text();
                     logDebugTokens("IDENTIFIER - %s", lval.s);
                     return IDENTIFIER
                   }
-    case 103:  //`((\\\")|(\\\\)|(\\\/)|(\\b)|(\\f)|(\\n)|(\\r)|(\\t)|(\\u[0-9a-fA-F][0-9a-fA-F][0-9a-fA-F][0-9a-fA-F])|[^`])+`/
+    case 121:  //`((\\\")|(\\\\)|(\\\/)|(\\b)|(\\f)|(\\n)|(\\r)|(\\t)|(\\u[0-9a-fA-F][0-9a-fA-F][0-9a-fA-F][0-9a-fA-F])|[^`])+`/
 {
                     //this rule allows for a wider range of identifiers by escaping them
                     lval.s = yylex.Text()[1:len(yylex.Text())-1]
                     logDebugTokens("IDENTIFIER - %s", lval.s);
                     return IDENTIFIER
                   }
-    case 104:  ///
+    case 122:  //\$[a-zA-Z0-9_]+/
+{
+                    // $1 is a positional parameter, $name a named one
+                    lval.s = yylex.Text()[1:]
+                    logDebugTokens("PARAMETER - %s", lval.s);
+                    return PARAMETER
+                  }
+    case 123:  ///
 // [END]
     }
   }
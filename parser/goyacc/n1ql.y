@@ -3,6 +3,7 @@ package goyacc
 import "github.com/couchbaselabs/clog"
 import "github.com/couchbaselabs/tuqtng/parser"
 import "github.com/couchbaselabs/tuqtng/ast"
+import "strings"
 
 
 func logDebugGrammar(format string, v ...interface{}) {
@@ -40,13 +41,17 @@ f float64}
 %token CASE WHEN THEN ELSE END
 %token ANY ALL FIRST ARRAY IN SATISFIES EVERY UNNEST FOR
 %token JOIN NEST INNER LEFT OUTER
+%token UPSERT VALUES SET
+%token PREPARE EXECUTE PARAMETER
+%token STATISTICS VERBOSE USE FUNCTION
+%token OVER PARTITION ROWS RANGE UNBOUNDED PRECEDING FOLLOWING CURRENT ROW
 %left OR
 %left AND
 %left EQ LT LTE GT GTE NE LIKE BETWEEN
 %left PLUS MINUS
 %left MULT DIV MOD CONCAT
 %left IS
-%right NOT
+%right NOT EXISTS
 %left DOT LBRACKET
 
 %%
@@ -60,6 +65,31 @@ EXPLAIN stmt {
 	logDebugGrammar("INPUT - EXPLAIN")
 	parsingStatement.SetExplainOnly(true)
 }
+|
+EXPLAIN VERBOSE stmt {
+	logDebugGrammar("INPUT - EXPLAIN VERBOSE")
+	parsingStatement.SetExplainOnly(true)
+	// only a SELECT has candidate plans and indexes to explain
+	selectStmt, ok := parsingStatement.(*ast.SelectStatement)
+	if ok {
+		selectStmt.ExplainVerbose = true
+	}
+}
+|
+PREPARE IDENTIFIER FROM stmt {
+	logDebugGrammar("INPUT - PREPARE")
+	parsingStatement = ast.NewPrepareStatement($2.s, parsingStatement)
+}
+|
+PREPARE IDENTIFIER AS stmt {
+	logDebugGrammar("INPUT - PREPARE")
+	parsingStatement = ast.NewPrepareStatement($2.s, parsingStatement)
+}
+|
+EXECUTE IDENTIFIER {
+	logDebugGrammar("INPUT - EXECUTE")
+	parsingStatement = ast.NewExecuteStatement($2.s)
+}
 
 stmt:
 select_stmt {
@@ -72,6 +102,204 @@ create_index_stmt {
 drop_index_stmt {
 	logDebugGrammar("STMT - DROP INDEX")
 }
+|
+insert_stmt {
+	logDebugGrammar("STMT - INSERT")
+}
+|
+update_stmt {
+	logDebugGrammar("STMT - UPDATE")
+}
+|
+delete_stmt {
+	logDebugGrammar("STMT - DELETE")
+}
+|
+update_statistics_stmt {
+	logDebugGrammar("STMT - UPDATE STATISTICS")
+}
+|
+create_function_stmt {
+	logDebugGrammar("STMT - CREATE FUNCTION")
+}
+|
+drop_function_stmt {
+	logDebugGrammar("STMT - DROP FUNCTION")
+}
+;
+
+// INSERT/UPSERT STATEMENT
+insert_stmt:
+insert_head insert_columns VALUES insert_value_list {
+	values := parsingStack.Pop().(ast.InsertValueList)
+	parsingStatement.(*ast.InsertStatement).Values = values
+}
+;
+
+insert_head:
+INSERT INTO mutation_bucket {
+	from := parsingStack.Pop().(*ast.From)
+	insertStmt := ast.NewInsertStatement()
+	insertStmt.Pool = from.Pool
+	insertStmt.Bucket = from.Bucket
+	parsingStatement = insertStmt
+}
+|
+UPSERT INTO mutation_bucket {
+	from := parsingStack.Pop().(*ast.From)
+	insertStmt := ast.NewInsertStatement()
+	insertStmt.Pool = from.Pool
+	insertStmt.Bucket = from.Bucket
+	insertStmt.Upsert = true
+	parsingStatement = insertStmt
+}
+;
+
+insert_columns:
+/* empty */ {
+}
+|
+LPAREN KEY COMMA IDENTIFIER RPAREN {
+	// VALUE is not a keyword, it is also the name of a function
+	if strings.ToUpper($4.s) != "VALUE" {
+		panic("INSERT columns must be (KEY, VALUE)")
+	}
+}
+;
+
+insert_value_list:
+insert_value {
+	value := parsingStack.Pop().(*ast.InsertValue)
+	parsingStack.Push(ast.InsertValueList{value})
+}
+|
+insert_value_list COMMA insert_value {
+	value := parsingStack.Pop().(*ast.InsertValue)
+	value_list := parsingStack.Pop().(ast.InsertValueList)
+	parsingStack.Push(append(value_list, value))
+}
+;
+
+insert_value:
+LPAREN expression COMMA expression RPAREN {
+	value := parsingStack.Pop().(ast.Expression)
+	key := parsingStack.Pop().(ast.Expression)
+	parsingStack.Push(ast.NewInsertValue(key, value))
+}
+;
+
+// UPDATE STATEMENT
+update_stmt:
+update_head mutation_keys SET set_list select_where mutation_limit {
+}
+;
+
+update_head:
+UPDATE mutation_bucket_as {
+	from := parsingStack.Pop().(*ast.From)
+	updateStmt := ast.NewUpdateStatement()
+	updateStmt.Pool = from.Pool
+	updateStmt.Bucket = from.Bucket
+	updateStmt.As = from.As
+	parsingStatement = updateStmt
+}
+;
+
+set_list:
+set_term {
+}
+|
+set_list COMMA set_term {
+}
+;
+
+set_term:
+path EQ expression {
+	value := parsingStack.Pop().(ast.Expression)
+	path := parsingStack.Pop().(ast.Expression)
+	updateStmt := parsingStatement.(*ast.UpdateStatement)
+	updateStmt.Set = append(updateStmt.Set, ast.NewSetTerm(path, value))
+}
+;
+
+// DELETE STATEMENT
+delete_stmt:
+delete_head mutation_keys select_where mutation_limit {
+}
+;
+
+delete_head:
+DELETE FROM mutation_bucket_as {
+	from := parsingStack.Pop().(*ast.From)
+	deleteStmt := ast.NewDeleteStatement()
+	deleteStmt.Pool = from.Pool
+	deleteStmt.Bucket = from.Bucket
+	deleteStmt.As = from.As
+	parsingStatement = deleteStmt
+}
+;
+
+// UPDATE STATISTICS
+update_statistics_stmt:
+UPDATE STATISTICS FOR mutation_bucket {
+	from := parsingStack.Pop().(*ast.From)
+	updateStatisticsStmt := ast.NewUpdateStatisticsStatement()
+	updateStatisticsStmt.Pool = from.Pool
+	updateStatisticsStmt.Bucket = from.Bucket
+	parsingStatement = updateStatisticsStmt
+}
+|
+UPDATE STATISTICS FOR mutation_bucket INDEX IDENTIFIER {
+	from := parsingStack.Pop().(*ast.From)
+	updateStatisticsStmt := ast.NewUpdateStatisticsStatement()
+	updateStatisticsStmt.Pool = from.Pool
+	updateStatisticsStmt.Bucket = from.Bucket
+	updateStatisticsStmt.Index = $6.s
+	parsingStatement = updateStatisticsStmt
+}
+;
+
+mutation_bucket:
+IDENTIFIER {
+	parsingStack.Push(&ast.From{Bucket: $1.s})
+}
+|
+COLON IDENTIFIER DOT IDENTIFIER {
+	parsingStack.Push(&ast.From{Pool: $2.s, Bucket: $4.s})
+}
+;
+
+mutation_bucket_as:
+mutation_bucket {
+}
+|
+mutation_bucket AS IDENTIFIER {
+	from := parsingStack.Pop().(*ast.From)
+	from.As = $3.s
+	parsingStack.Push(from)
+}
+|
+mutation_bucket IDENTIFIER {
+	from := parsingStack.Pop().(*ast.From)
+	from.As = $2.s
+	parsingStack.Push(from)
+}
+;
+
+mutation_keys:
+/* empty */ {
+}
+|
+use_keys_expr {
+}
+;
+
+mutation_limit:
+/* empty */ {
+}
+|
+select_limit {
+}
 ;
 
 // CREATE INDEX STATEMENT
@@ -218,6 +446,43 @@ DROP INDEX COLON IDENTIFIER DOT IDENTIFIER DOT IDENTIFIER {
 }
 ;
 
+// CREATE FUNCTION
+create_function_stmt:
+CREATE FUNCTION IDENTIFIER LPAREN function_parameters RPAREN LBRACE expression RBRACE {
+	body := parsingStack.Pop().(ast.Expression)
+	parameters := parsingStack.Pop().([]string)
+	// the text of the body is filled in by Parse
+	parsingStatement = ast.NewCreateFunctionStatement(ast.NewFunctionDefinition($3.s, parameters, body))
+}
+;
+
+function_parameters:
+/* empty */ {
+	parsingStack.Push([]string{})
+}
+|
+function_parameter_list {
+}
+;
+
+function_parameter_list:
+IDENTIFIER {
+	parsingStack.Push([]string{$1.s})
+}
+|
+function_parameter_list COMMA IDENTIFIER {
+	parameters := parsingStack.Pop().([]string)
+	parsingStack.Push(append(parameters, $3.s))
+}
+;
+
+// DROP FUNCTION
+drop_function_stmt:
+DROP FUNCTION IDENTIFIER {
+	parsingStatement = ast.NewDropFunctionStatement($3.s)
+}
+;
+
 // SELECT STATEMENT
 select_stmt:
 select_compound  {
@@ -226,16 +491,60 @@ select_compound  {
 ;
 
 select_compound:
-select_core select_order select_limit_offset {
-	// future extensibility for comining queries with UNION, etc
+select_set select_order select_limit_offset {
 	logDebugGrammar("SELECT_COMPOUND")
 }
-/*
+;
+
+select_set:
+select_core {
+	logDebugGrammar("SELECT_SET")
+}
 |
-select_core select_order select_limit_offset LPAREN select_compound RPAREN {
-    logDebugGrammar("SELECT_COMPOUND NESTED")
+select_set UNION select_term {
+	logDebugGrammar("SELECT_SET UNION")
+	combineSelectStatements(ast.UNION, false)
+}
+|
+select_set UNION ALL select_term {
+	logDebugGrammar("SELECT_SET UNION ALL")
+	combineSelectStatements(ast.UNION, true)
+}
+|
+select_set INTERSECT select_term {
+	logDebugGrammar("SELECT_SET INTERSECT")
+	combineSelectStatements(ast.INTERSECT, false)
+}
+|
+select_set INTERSECT ALL select_term {
+	logDebugGrammar("SELECT_SET INTERSECT ALL")
+	combineSelectStatements(ast.INTERSECT, true)
+}
+|
+select_set EXCEPT select_term {
+	logDebugGrammar("SELECT_SET EXCEPT")
+	combineSelectStatements(ast.EXCEPT, false)
+}
+|
+select_set EXCEPT ALL select_term {
+	logDebugGrammar("SELECT_SET EXCEPT ALL")
+	combineSelectStatements(ast.EXCEPT, true)
+}
+;
+
+select_term:
+select_term_begin select_core {
+	logDebugGrammar("SELECT_TERM")
+}
+;
+
+select_term_begin:
+/* empty */ {
+	// the statement parsed so far is set aside
+	// while the clauses of the next term are parsed
+	parsingStack.Push(parsingStatement)
+	parsingStatement = ast.NewSelectStatement()
 }
-*/
 ;
 
 select_core:
@@ -704,6 +1013,102 @@ join_type JOIN path AS IDENTIFIER join_key_expr unnest_source {
     parsingStack.Push(&ast.From{Projection: proj, As:$5.s, Type:Type, Keys: key_expr, Over: rest})
 }
 |
+JOIN path join_on_expr {
+    logDebugGrammar("JOIN ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:"", On: on})
+}
+|
+JOIN path join_on_expr unnest_source {
+    logDebugGrammar("JOIN ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:"", On: on, Over: rest})
+}
+|
+JOIN path AS IDENTIFIER join_on_expr {
+    logDebugGrammar("JOIN AS ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:$4.s, On: on})
+}
+|
+JOIN path AS IDENTIFIER join_on_expr unnest_source {
+    logDebugGrammar("JOIN AS ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:$4.s, On: on, Over: rest})
+}
+|
+JOIN path IDENTIFIER join_on_expr {
+    logDebugGrammar("JOIN AS ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:$3.s, On: on})
+}
+|
+JOIN path IDENTIFIER join_on_expr unnest_source {
+    logDebugGrammar("JOIN AS ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, As:$3.s, On: on, Over: rest})
+}
+|
+join_type JOIN path join_on_expr {
+    logDebugGrammar("TYPE JOIN ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:"", Type: Type, On: on})
+}
+|
+join_type JOIN path join_on_expr unnest_source {
+    logDebugGrammar("TYPE JOIN ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:"", Type: Type, On: on, Over: rest})
+}
+|
+join_type JOIN path AS IDENTIFIER join_on_expr {
+    logDebugGrammar("TYPE JOIN AS ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:$5.s, Type: Type, On: on})
+}
+|
+join_type JOIN path AS IDENTIFIER join_on_expr unnest_source {
+    logDebugGrammar("TYPE JOIN AS ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:$5.s, Type: Type, On: on, Over: rest})
+}
+|
+join_type JOIN path IDENTIFIER join_on_expr {
+    logDebugGrammar("TYPE JOIN AS ON")
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:$4.s, Type: Type, On: on})
+}
+|
+join_type JOIN path IDENTIFIER join_on_expr unnest_source {
+    logDebugGrammar("TYPE JOIN AS ON NESTED")
+    rest := parsingStack.Pop().(*ast.From)
+    on := parsingStack.Pop().(ast.Expression)
+    proj := parsingStack.Pop().(ast.Expression)
+    Type := parsingStack.Pop().(string)
+    parsingStack.Push(&ast.From{Projection: proj, As:$4.s, Type: Type, On: on, Over: rest})
+}
+|
 NEST path join_key_expr {
     logDebugGrammar("JOIN KEY") 
     key_expr := parsingStack.Pop().(*ast.KeyExpression)
@@ -819,6 +1224,11 @@ KEYS expr {
 
 };
 
+join_on_expr:
+ON expr {
+        logDebugGrammar("FROM JOIN DATASOURCE with ON")
+};
+
 join_type:
 INNER {
     logDebugGrammar("INNER")
@@ -843,12 +1253,19 @@ path {
 	parsingStack.Push(&ast.From{Projection: proj})
 }
 |
-path key_expr {
+path use_keys_expr {
     logDebugGrammar("FROM KEY(S) DATASOURCE")
     proj := parsingStack.Pop().(ast.Expression)
     parsingStack.Push(&ast.From{Projection:proj})
 }
 |
+path index_hint {
+    logDebugGrammar("FROM DATASOURCE USE INDEX")
+    indexes := parsingStack.Pop().([]*ast.IndexRef)
+    proj := parsingStack.Pop().(ast.Expression)
+    parsingStack.Push(&ast.From{Projection: proj, Indexes: indexes})
+}
+|
 path AS IDENTIFIER {
     // fixme support over as
 	logDebugGrammar("FROM DATASOURCE AS ID")
@@ -863,19 +1280,73 @@ path IDENTIFIER {
 	parsingStack.Push(&ast.From{Projection: proj, As: $2.s})
 }
 |
-path AS IDENTIFIER key_expr {
+path AS IDENTIFIER use_keys_expr {
         logDebugGrammar("FROM DATASOURCE AS ID KEY(S)")
 	proj := parsingStack.Pop().(ast.Expression)
 	parsingStack.Push(&ast.From{Projection: proj, As: $3.s})
 
 }
 |
-path IDENTIFIER key_expr {
+path IDENTIFIER use_keys_expr {
         logDebugGrammar("FROM DATASOURCE ID KEY(s)")
 	proj := parsingStack.Pop().(ast.Expression)
 	parsingStack.Push(&ast.From{Projection: proj, As: $2.s})
 
 }
+|
+path AS IDENTIFIER index_hint {
+	logDebugGrammar("FROM DATASOURCE AS ID USE INDEX")
+	indexes := parsingStack.Pop().([]*ast.IndexRef)
+	proj := parsingStack.Pop().(ast.Expression)
+	parsingStack.Push(&ast.From{Projection: proj, As: $3.s, Indexes: indexes})
+}
+|
+path IDENTIFIER index_hint {
+	logDebugGrammar("FROM DATASOURCE ID USE INDEX")
+	indexes := parsingStack.Pop().([]*ast.IndexRef)
+	proj := parsingStack.Pop().(ast.Expression)
+	parsingStack.Push(&ast.From{Projection: proj, As: $2.s, Indexes: indexes})
+}
+;
+
+/* USE KEYS is a synonym for KEYS */
+use_keys_expr:
+key_expr {
+}
+|
+USE key_expr {
+	logDebugGrammar("FROM DATASOURCE with USE KEY(S)")
+}
+;
+
+index_hint:
+USE INDEX LPAREN index_ref_list RPAREN {
+	logDebugGrammar("USE INDEX")
+}
+;
+
+index_ref_list:
+index_ref {
+	index := parsingStack.Pop().(*ast.IndexRef)
+	parsingStack.Push([]*ast.IndexRef{index})
+}
+|
+index_ref_list COMMA index_ref {
+	index := parsingStack.Pop().(*ast.IndexRef)
+	indexes := parsingStack.Pop().([]*ast.IndexRef)
+	parsingStack.Push(append(indexes, index))
+}
+;
+
+index_ref:
+IDENTIFIER {
+	parsingStack.Push(&ast.IndexRef{Name: $1.s})
+}
+|
+IDENTIFIER USING view_using {
+	method := parsingStack.Pop().(string)
+	parsingStack.Push(&ast.IndexRef{Name: $1.s, Method: method})
+}
 ;
 
 key_expr:
@@ -885,6 +1356,10 @@ KEY expr {
 	switch parsingStatement := parsingStatement.(type) {
 	case *ast.SelectStatement:
 		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEY") 
+	case *ast.UpdateStatement:
+		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEY")
+	case *ast.DeleteStatement:
+		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEY")
 	default:
 		logDebugGrammar("This statement does not support KEY")
 	}
@@ -896,6 +1371,10 @@ KEYS expr {
 	switch parsingStatement := parsingStatement.(type) {
 	case *ast.SelectStatement:
 		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEYS")
+	case *ast.UpdateStatement:
+		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEYS")
+	case *ast.DeleteStatement:
+		parsingStatement.Keys = ast.NewKeyExpression(keys, "KEYS")
 	default:
 		logDebugGrammar("This statement does not support KEYS")
 	}
@@ -914,6 +1393,10 @@ WHERE expression {
 	switch parsingStatement := parsingStatement.(type) {
 	case *ast.SelectStatement:
 		parsingStatement.Where = where_part
+	case *ast.UpdateStatement:
+		parsingStatement.Where = where_part
+	case *ast.DeleteStatement:
+		parsingStatement.Where = where_part
 	default:
 		logDebugGrammar("This statement does not support WHERE")
 	}
@@ -923,51 +1406,50 @@ select_order:
 /* empty */
 |
 ORDER BY sorting_list {
-
+	order_by := parsingStack.Pop().(ast.SortExpressionList)
+	switch parsingStatement := parsingStatement.(type) {
+	case *ast.SelectStatement:
+		parsingStatement.OrderBy = order_by
+	default:
+		logDebugGrammar("This statement does not support ORDER BY")
+	}
 }
 ;
 
 sorting_list:
 sorting_single {
-
+	logDebugGrammar("SORT LIST SINGLE")
+	sort_list := ast.SortExpressionList{parsingStack.Pop().(*ast.SortExpression)}
+	parsingStack.Push(sort_list)
 }
 |
 sorting_single COMMA sorting_list {
-
+	logDebugGrammar("SORT LIST COMPOUND")
+	rest := parsingStack.Pop().(ast.SortExpressionList)
+	last := parsingStack.Pop().(*ast.SortExpression)
+	new_list := make(ast.SortExpressionList, 0, len(rest) + 1)
+	new_list = append(new_list, last)
+	new_list = append(new_list, rest...)
+	parsingStack.Push(new_list)
 };
 
 sorting_single:
 expression {
 	logDebugGrammar("SORT EXPR")
 	expr := parsingStack.Pop()
-	switch parsingStatement := parsingStatement.(type) {
-	case *ast.SelectStatement:
-		parsingStatement.OrderBy = append(parsingStatement.OrderBy, ast.NewSortExpression(expr.(ast.Expression), true))
-	default:
-		logDebugGrammar("This statement does not support ORDER BY")
-	}
+	parsingStack.Push(ast.NewSortExpression(expr.(ast.Expression), true))
 }
 |
 expression ASC {
 	logDebugGrammar("SORT EXPR ASC")
 	expr := parsingStack.Pop()
-	switch parsingStatement := parsingStatement.(type) {
-	case *ast.SelectStatement:
-		parsingStatement.OrderBy = append(parsingStatement.OrderBy, ast.NewSortExpression(expr.(ast.Expression), true))
-	default:
-		logDebugGrammar("This statement does not support ORDER BY")
-	}
+	parsingStack.Push(ast.NewSortExpression(expr.(ast.Expression), true))
 }
 |
 expression DESC {
 	logDebugGrammar("SORT EXPR DESC")
 	expr := parsingStack.Pop()
-	switch parsingStatement := parsingStatement.(type) {
-	case *ast.SelectStatement:
-		parsingStatement.OrderBy = append(parsingStatement.OrderBy, ast.NewSortExpression(expr.(ast.Expression), false))
-	default:
-		logDebugGrammar("This statement does not support ORDER BY")
-	}
+	parsingStack.Push(ast.NewSortExpression(expr.(ast.Expression), false))
 };
 
 select_limit_offset:
@@ -993,6 +1475,10 @@ LIMIT INT {
 	switch parsingStatement := parsingStatement.(type) {
 	case *ast.SelectStatement:
 		parsingStatement.Limit = $2.n
+	case *ast.UpdateStatement:
+		parsingStatement.Limit = $2.n
+	case *ast.DeleteStatement:
+		parsingStatement.Limit = $2.n
 	default:
 		logDebugGrammar("This statement does not support LIMIT")
 	}
@@ -1056,19 +1542,15 @@ expr NOT IN expression {
     left := parsingStack.Pop()
     thisExpression := ast.NewNotInOperator(left.(ast.Expression), right.(ast.Expression))
     parsingStack.Push(thisExpression)
-}
-|
-subquery_expr {
 };
 
 subquery_expr: 
-LBRACE select_stmt RBRACE {
+LBRACE select_term_begin select_stmt RBRACE {
     logDebugGrammar("sub-query EXPRESSION")
-    
-}
-|
-LBRACE select_stmt RBRACE subquery_expr {
-    logDebugGrammar("sub-query NESTED EXPRESSION")
+    subquery := parsingStatement.(*ast.SelectStatement)
+    parsingStatement = parsingStack.Pop().(ast.Statement)
+    thisExpression := ast.NewSubquery(subquery)
+    parsingStack.Push(thisExpression)
 }
 ;
 
@@ -1305,6 +1787,13 @@ NOT prefix_expr {
 	parsingStack.Push(thisExpression)
 }
 |
+EXISTS prefix_expr {
+	logDebugGrammar("EXPR - EXISTS")
+	operand := parsingStack.Pop()
+	thisExpression := ast.NewExistsOperator(operand.(ast.Expression))
+	parsingStack.Push(thisExpression)
+}
+|
 MINUS prefix_expr {
 	logDebugGrammar("EXPR - CHANGE SIGN")
 	operand := parsingStack.Pop()
@@ -1333,10 +1822,20 @@ literal_value {
 	logDebugGrammar("LITERAL")
 }
 |
+PARAMETER {
+	logDebugGrammar("PARAMETER - %s", $1.s)
+	thisExpression := ast.NewParameter($1.s)
+	parsingStack.Push(thisExpression)
+}
+|
 LPAREN expression RPAREN {
 	logDebugGrammar("NESTED EXPR")
 }
 |
+subquery_expr {
+	logDebugGrammar("SUBQUERY EXPR")
+}
+|
 CASE WHEN then_list else_expr END {
 	logDebugGrammar("CASE WHEN THEN ELSE END")
 	cwtee := ast.NewCaseOperator()
@@ -1472,6 +1971,22 @@ ARRAY expr IN expr END {
 	parsingStack.Push(collectionArray)
 }
 |
+function_call {
+	logDebugGrammar("FUNCTION CALL")
+}
+|
+function_call OVER LPAREN window_partition window_order window_frame RPAREN {
+	logDebugGrammar("FUNCTION CALL OVER")
+	frame := parsingStack.Pop().(*ast.WindowFrame)
+	order_by := parsingStack.Pop().(ast.SortExpressionList)
+	partition_by := parsingStack.Pop().(ast.ExpressionList)
+	function := parsingStack.Pop().(ast.FunctionCallExpression)
+	window := ast.NewWindowDefinition(partition_by, order_by, frame)
+	parsingStack.Push(ast.NewWindowOperator(function, window))
+}
+;
+
+function_call:
 IDENTIFIER LPAREN RPAREN {
 	logDebugGrammar("FUNCTION EXPR NOPARAM")
 	thisExpression := ast.NewFunctionCall($1.s, ast.FunctionArgExpressionList{})
@@ -1501,6 +2016,88 @@ IDENTIFIER LPAREN UNIQUE function_arg_list RPAREN {
 }
 ;
 
+window_partition:
+/* empty */ {
+	logDebugGrammar("WINDOW PARTITION - EMPTY")
+	parsingStack.Push(ast.ExpressionList(nil))
+}
+|
+PARTITION BY expression_list {
+	logDebugGrammar("WINDOW PARTITION")
+}
+;
+
+window_order:
+/* empty */ {
+	logDebugGrammar("WINDOW ORDER - EMPTY")
+	parsingStack.Push(ast.SortExpressionList(nil))
+}
+|
+ORDER BY sorting_list {
+	logDebugGrammar("WINDOW ORDER")
+}
+;
+
+window_frame:
+/* empty */ {
+	logDebugGrammar("WINDOW FRAME - EMPTY")
+	parsingStack.Push((*ast.WindowFrame)(nil))
+}
+|
+ROWS window_frame_bound {
+	logDebugGrammar("WINDOW FRAME ROWS")
+	start := parsingStack.Pop().(*ast.WindowFrameBound)
+	parsingStack.Push(ast.NewWindowFrame(true, start, ast.NewWindowFrameBound(ast.CURRENT_ROW, 0)))
+}
+|
+ROWS BETWEEN window_frame_bound AND window_frame_bound {
+	logDebugGrammar("WINDOW FRAME ROWS BETWEEN")
+	end := parsingStack.Pop().(*ast.WindowFrameBound)
+	start := parsingStack.Pop().(*ast.WindowFrameBound)
+	parsingStack.Push(ast.NewWindowFrame(true, start, end))
+}
+|
+RANGE window_frame_bound {
+	logDebugGrammar("WINDOW FRAME RANGE")
+	start := parsingStack.Pop().(*ast.WindowFrameBound)
+	parsingStack.Push(ast.NewWindowFrame(false, start, ast.NewWindowFrameBound(ast.CURRENT_ROW, 0)))
+}
+|
+RANGE BETWEEN window_frame_bound AND window_frame_bound {
+	logDebugGrammar("WINDOW FRAME RANGE BETWEEN")
+	end := parsingStack.Pop().(*ast.WindowFrameBound)
+	start := parsingStack.Pop().(*ast.WindowFrameBound)
+	parsingStack.Push(ast.NewWindowFrame(false, start, end))
+}
+;
+
+window_frame_bound:
+UNBOUNDED PRECEDING {
+	logDebugGrammar("WINDOW FRAME BOUND - UNBOUNDED PRECEDING")
+	parsingStack.Push(ast.NewWindowFrameBound(ast.UNBOUNDED_PRECEDING, 0))
+}
+|
+UNBOUNDED FOLLOWING {
+	logDebugGrammar("WINDOW FRAME BOUND - UNBOUNDED FOLLOWING")
+	parsingStack.Push(ast.NewWindowFrameBound(ast.UNBOUNDED_FOLLOWING, 0))
+}
+|
+CURRENT ROW {
+	logDebugGrammar("WINDOW FRAME BOUND - CURRENT ROW")
+	parsingStack.Push(ast.NewWindowFrameBound(ast.CURRENT_ROW, 0))
+}
+|
+INT PRECEDING {
+	logDebugGrammar("WINDOW FRAME BOUND - INT PRECEDING")
+	parsingStack.Push(ast.NewWindowFrameBound(ast.PRECEDING, $1.n))
+}
+|
+INT FOLLOWING {
+	logDebugGrammar("WINDOW FRAME BOUND - INT FOLLOWING")
+	parsingStack.Push(ast.NewWindowFrameBound(ast.FOLLOWING, $1.n))
+}
+;
+
 then_list:
 expr THEN expr {
 	logDebugGrammar("THEN_LIST - SINGLE")
@@ -58,5 +58,32 @@ func (u *N1qlParser) Parse(input string) (returnStatement ast.Statement, err err
 
 	yyParse(NewLexer(strings.NewReader(input)))
 	returnStatement = parsingStatement
+
+	// functions keep their body as it was written, so that
+	// it can be stored and parsed again later
+	createFunctionStmt, ok := returnStatement.(*ast.CreateFunctionStatement)
+	if ok {
+		createFunctionStmt.Definition.Text = functionBody(input)
+	}
 	return
 }
+
+// the text between the braces around the body, the parameters
+// of the function cannot contain a brace
+func functionBody(input string) string {
+	start := strings.Index(input, "{")
+	end := strings.LastIndex(input, "}")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(input[start+1 : end])
+}
+
+// the term just parsed becomes a compound term of
+// the statement that was set aside when it began
+func combineSelectStatements(operator string, all bool) {
+	term := parsingStatement.(*ast.SelectStatement)
+	stmt := parsingStack.Pop().(*ast.SelectStatement)
+	stmt.Compound = append(stmt.Compound, ast.NewCompoundTerm(operator, all, term))
+	parsingStatement = stmt
+}
@@ -204,6 +204,76 @@ var validQueries = []string{
 	`SELECT ALL * FROM orders JOIN contacts.name KEYS orders.custId`,
 	`SELECT * FROM orders INNER NEST contacts.name KEYS orders.custId LEFT JOIN contacts.children KEY orders.gifts.custId`,
 	`SELECT order_id as oid FROM orders NEST customers KEYS oid.custId LEFT NEST invoices KEYS oid.invoices`,
+
+	// compound statements
+	`SELECT name FROM contacts UNION SELECT name FROM users`,
+	`SELECT name FROM contacts UNION ALL SELECT name FROM users`,
+	`SELECT name FROM contacts INTERSECT SELECT name FROM users`,
+	`SELECT name FROM contacts INTERSECT ALL SELECT name FROM users`,
+	`SELECT name FROM contacts EXCEPT SELECT name FROM users`,
+	`SELECT name FROM contacts EXCEPT ALL SELECT name FROM users`,
+	`SELECT name FROM contacts WHERE age > 3 UNION SELECT name FROM users WHERE age < 7 ORDER BY name LIMIT 2 OFFSET 1`,
+	`SELECT name FROM contacts UNION SELECT name FROM users EXCEPT SELECT name FROM banned`,
+	`FROM contacts SELECT name UNION FROM users SELECT name`,
+	`EXPLAIN SELECT name FROM contacts UNION SELECT name FROM users`,
+	`EXPLAIN VERBOSE SELECT name FROM contacts WHERE age > 3`,
+
+	// index hints
+	`SELECT name FROM contacts USE INDEX (age_idx) WHERE age > 3`,
+	`SELECT c.name FROM contacts AS c USE INDEX (age_idx USING VIEW, name_idx) WHERE c.age > 3`,
+	`SELECT c.name FROM :apool.contacts c use index (age_idx) WHERE c.age > 3`,
+	`SELECT name FROM contacts USE KEYS ["fred", "wilma"]`,
+	`SELECT c.name FROM contacts AS c USE KEY "fred"`,
+	`DELETE FROM contacts USE KEYS ["fred"]`,
+	`explain verbose SELECT name FROM contacts UNION SELECT name FROM users`,
+
+	// subqueries
+	`SELECT * FROM contacts WHERE name IN {SELECT name FROM users}`,
+	`SELECT * FROM contacts WHERE name NOT IN {SELECT name FROM users WHERE age > 3 ORDER BY name LIMIT 2}`,
+	`SELECT {SELECT 1} AS one`,
+	`SELECT {"inner": {SELECT name FROM users}}`,
+	`SELECT * FROM contacts WHERE EXISTS {SELECT * FROM users WHERE users.id = contacts.id}`,
+	`SELECT * FROM contacts WHERE NOT EXISTS {SELECT name FROM users UNION SELECT name FROM admins}`,
+	`SELECT * FROM a WHERE x IN {SELECT y FROM b WHERE z IN {SELECT w FROM c}}`,
+	`SELECT ARRAY_LENGTH({FROM users SELECT name}) AS count`,
+
+	// data modification
+	`INSERT INTO contacts VALUES ("fred", {"name": "fred"})`,
+	`INSERT INTO contacts (KEY, VALUE) VALUES ("fred", {"name": "fred"}), ("wilma", {"name": "wilma"})`,
+	`INSERT INTO :apool.contacts (key, value) VALUES ("fred", {"name": "fred"})`,
+	`UPSERT INTO contacts VALUES ("fred", {"name": "fred", "age": 40})`,
+	`UPDATE contacts SET age = 41`,
+	`UPDATE contacts KEYS ["fred", "wilma"] SET age = age + 1, children[0].name = "pebbles" WHERE age > 30 LIMIT 2`,
+	`UPDATE :apool.contacts AS c KEY "fred" SET c.age = 41`,
+	`UPDATE contacts c SET c.address.city = "Bedrock" WHERE c.name = "fred"`,
+	`DELETE FROM contacts`,
+	`DELETE FROM contacts KEY "fred"`,
+	`DELETE FROM :apool.contacts AS c WHERE c.age > 40 LIMIT 10`,
+	`EXPLAIN DELETE FROM contacts WHERE age > 40`,
+
+	// parameters and prepared statements
+	`SELECT * FROM contacts WHERE age > $1 AND name = $name`,
+	`UPDATE contacts KEY $id SET age = $2`,
+	`PREPARE adults FROM SELECT name FROM contacts WHERE age > $1`,
+	`PREPARE adults AS SELECT name FROM contacts WHERE age > $min`,
+	`EXECUTE adults`,
+
+	// user functions
+	`CREATE FUNCTION celsius(f) { (f - 32) * 5 / 9 }`,
+	`CREATE FUNCTION now_plus_one() { NOW_MILLIS() + 1 }`,
+	`create function full_name(given, family) { given || " " || family }`,
+	`CREATE FUNCTION point(x, y) { {"x": x, "y": y} }`,
+	`EXPLAIN CREATE FUNCTION celsius(f) { (f - 32) * 5 / 9 }`,
+	`DROP FUNCTION celsius`,
+
+	// window functions
+	`SELECT name, ROW_NUMBER() OVER () FROM contacts`,
+	`SELECT name, RANK() OVER (ORDER BY age DESC) AS rank FROM contacts ORDER BY rank`,
+	`SELECT name, DENSE_RANK() OVER (PARTITION BY city, state ORDER BY age, name) FROM contacts`,
+	`SELECT name, LAG(name, 2, "none") OVER (ORDER BY age) FROM contacts`,
+	`SELECT SUM(age) OVER (ORDER BY name ROWS UNBOUNDED PRECEDING) FROM contacts`,
+	`SELECT AVG(age) OVER (PARTITION BY city ORDER BY name ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING) FROM contacts`,
+	`SELECT FIRST_VALUE(name) over (order by age range between current row and unbounded following) FROM contacts`,
 }
 
 var invalidQueries = []string{
@@ -230,6 +300,18 @@ var invalidQueries = []string{
 	`CREATE PRIMARY INDEX abv_idx ON beer-sample(abv)`,
 	`CREATE PRIMARY INDEX ON beer-sample(abv) USING VIEW`,
 	`DROP PRIMARY INDEX`,
+	`SELECT name FROM contacts ORDER BY name UNION SELECT name FROM users`, // ORDER BY applies to the whole statement
+	`SELECT name FROM contacts UNION`,
+	`SELECT name FROM contacts UNION ALL ALL SELECT name FROM users`,
+	`SELECT * FROM contacts WHERE name IN {SELECT name FROM users`,
+	`SELECT * FROM contacts WHERE EXISTS`,
+	`SELECT * FROM contacts USE INDEX ()`,
+	`SELECT * FROM contacts USE INDEX age_idx`,
+	`SELECT * FROM contacts USE USE KEYS ["fred"]`,
+	`CREATE FUNCTION celsius(f) (f - 32) * 5 / 9`,
+	`CREATE FUNCTION celsius(f, ) { f }`,
+	`CREATE FUNCTION celsius(f) { }`,
+	`DROP FUNCTION`,
 
 	// these are me trying to understand code coverage in the parser
 	`\`,
@@ -250,6 +332,21 @@ var invalidQueries = []string{
 	`"\t`,
 	`"\r`,
 	`"\u`,
+	`INSERT INTO contacts (KEY, DOC) VALUES ("fred", {})`, // columns must be KEY and VALUE
+	`INSERT INTO contacts VALUES ("fred")`,                // values are key/value pairs
+	`UPDATE contacts WHERE age > 3`,                       // update requires SET
+	`UPDATE contacts SET age + 1 = 3`,                     // SET requires a path
+	`DELETE contacts WHERE age > 3`,                       // delete requires FROM
+	`DELETE FROM contacts ORDER BY age`,                   // delete has no ORDER BY
+	`SELECT $`,                                            // parameters need a name or position
+	`PREPARE SELECT * FROM contacts`,                      // prepared statements need a name
+	`EXECUTE`,
+
+	// window functions
+	`SELECT RANK() OVER FROM contacts`,                                  // OVER requires parentheses
+	`SELECT RANK() OVER (ORDER BY age PARTITION BY city) FROM contacts`, // PARTITION BY comes first
+	`SELECT SUM(age) OVER (ROWS BETWEEN age PRECEDING AND CURRENT ROW)`, // frame offsets are numbers
+	`SELECT age OVER () FROM contacts`,                                  // only functions have windows
 }
 
 func TestParser(t *testing.T) {
@@ -431,6 +528,121 @@ func TestParserASTOutput(t *testing.T) {
 				On:     ast.ExpressionList{ast.NewProperty("abv")},
 			},
 		},
+		{"SELECT a FROM test UNION ALL SELECT b FROM test2 ORDER BY a LIMIT 5",
+			&ast.SelectStatement{
+				Select: ast.ResultExpressionList{
+					ast.NewResultExpression(ast.NewProperty("a")),
+				},
+				From: &ast.From{Projection: ast.NewProperty("test")},
+				OrderBy: []*ast.SortExpression{
+					ast.NewSortExpression(ast.NewProperty("a"), true),
+				},
+				Limit: 5,
+				Compound: ast.CompoundTermList{
+					ast.NewCompoundTerm(ast.UNION, true, &ast.SelectStatement{
+						Select: ast.ResultExpressionList{
+							ast.NewResultExpression(ast.NewProperty("b")),
+						},
+						From:  &ast.From{Projection: ast.NewProperty("test2")},
+						Limit: -1,
+					}),
+				},
+			},
+		},
+		{"SELECT a FROM test WHERE a IN {SELECT b FROM test2}",
+			&ast.SelectStatement{
+				Select: ast.ResultExpressionList{
+					ast.NewResultExpression(ast.NewProperty("a")),
+				},
+				From: &ast.From{Projection: ast.NewProperty("test")},
+				Where: ast.NewInOperator(ast.NewProperty("a"), ast.NewSubquery(&ast.SelectStatement{
+					Select: ast.ResultExpressionList{
+						ast.NewResultExpression(ast.NewProperty("b")),
+					},
+					From:  &ast.From{Projection: ast.NewProperty("test2")},
+					Limit: -1,
+				})),
+				Limit: -1,
+			},
+		},
+		{"SELECT * FROM orders AS o LEFT OUTER JOIN products p ON o.pid = p.id",
+			&ast.SelectStatement{
+				Select: ast.ResultExpressionList{
+					ast.NewStarResultExpression(),
+				},
+				From: &ast.From{
+					Projection: ast.NewProperty("orders"),
+					As:         "o",
+					Over: &ast.From{
+						Projection: ast.NewProperty("products"),
+						As:         "p",
+						Type:       "LEFT",
+						On: ast.NewEqualToOperator(
+							ast.NewDotMemberOperator(ast.NewProperty("o"), ast.NewProperty("pid")),
+							ast.NewDotMemberOperator(ast.NewProperty("p"), ast.NewProperty("id"))),
+					},
+				},
+				Limit: -1,
+			},
+		},
+		{`INSERT INTO contacts (KEY, VALUE) VALUES ("fred", {"age": 40})`,
+			&ast.InsertStatement{
+				Bucket: "contacts",
+				Values: ast.InsertValueList{
+					ast.NewInsertValue(ast.NewLiteralString("fred"), ast.NewLiteralObject(map[string]ast.Expression{"age": ast.NewLiteralNumber(40)})),
+				},
+			},
+		},
+		{`UPSERT INTO :apool.contacts VALUES ("fred", true)`,
+			&ast.InsertStatement{
+				Upsert: true,
+				Pool:   "apool",
+				Bucket: "contacts",
+				Values: ast.InsertValueList{
+					ast.NewInsertValue(ast.NewLiteralString("fred"), ast.NewLiteralBool(true)),
+				},
+			},
+		},
+		{`UPDATE contacts AS c KEY "fred" SET c.age = 41 WHERE c.age = 40 LIMIT 1`,
+			&ast.UpdateStatement{
+				Bucket: "contacts",
+				As:     "c",
+				Keys:   ast.NewKeyExpression(ast.NewLiteralString("fred"), "KEY"),
+				Set: ast.SetTermList{
+					ast.NewSetTerm(ast.NewDotMemberOperator(ast.NewProperty("c"), ast.NewProperty("age")), ast.NewLiteralNumber(41)),
+				},
+				Where: ast.NewEqualToOperator(ast.NewDotMemberOperator(ast.NewProperty("c"), ast.NewProperty("age")), ast.NewLiteralNumber(40)),
+				Limit: 1,
+			},
+		},
+		{`DELETE FROM contacts WHERE age > 40`,
+			&ast.DeleteStatement{
+				Bucket: "contacts",
+				Where:  ast.NewGreaterThanOperator(ast.NewProperty("age"), ast.NewLiteralNumber(40)),
+				Limit:  -1,
+			},
+		},
+		{"PREPARE adults FROM SELECT name FROM contacts WHERE age > $1 AND city = $city",
+			&ast.PrepareStatement{
+				Name: "adults",
+				Statement: &ast.SelectStatement{
+					Select: ast.ResultExpressionList{
+						ast.NewResultExpression(ast.NewProperty("name")),
+					},
+					From: &ast.From{Projection: ast.NewProperty("contacts")},
+					Where: ast.NewAndOperator(ast.ExpressionList{
+						ast.NewGreaterThanOperator(ast.NewProperty("age"), ast.NewParameter("1")),
+						ast.NewEqualToOperator(ast.NewProperty("city"), ast.NewParameter("city")),
+					}),
+					Limit: -1,
+				},
+			},
+		},
+		{"EXECUTE adults",
+			&ast.ExecuteStatement{
+				Name: "adults",
+			},
+		},
 		{"DROP INDEX beer-sample.abv",
 			&ast.DropIndexStatement{
 				Bucket: "beer-sample",
@@ -444,6 +656,18 @@ func TestParserASTOutput(t *testing.T) {
 				Name:   "abv",
 			},
 		},
+		{"UPDATE STATISTICS FOR beer-sample",
+			&ast.UpdateStatisticsStatement{
+				Bucket: "beer-sample",
+			},
+		},
+		{"update statistics for :apool.beer-sample index abv",
+			&ast.UpdateStatisticsStatement{
+				Pool:   "apool",
+				Bucket: "beer-sample",
+				Index:  "abv",
+			},
+		},
 	}
 
 	n1qlParser := NewN1qlParser()
@@ -471,3 +695,126 @@ func TestParserASTOutput(t *testing.T) {
 	}
 
 }
+
+func TestWindowFunctions(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{"SELECT ROW_NUMBER() OVER () FROM contacts",
+			"ROW_NUMBER() OVER ()"},
+		{"SELECT RANK() OVER (PARTITION BY city ORDER BY age DESC, name) FROM contacts",
+			"RANK() OVER (PARTITION BY city ORDER BY age DESC, name)"},
+		{"SELECT SUM(age) OVER (ORDER BY name ROWS 3 PRECEDING) FROM contacts",
+			"SUM(age) OVER (ORDER BY name ROWS BETWEEN 3 PRECEDING AND CURRENT ROW)"},
+		{"SELECT MIN(age) OVER (RANGE BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) FROM contacts",
+			"MIN(age) OVER (RANGE BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)"},
+	}
+
+	n1qlParser := NewN1qlParser()
+
+	for _, x := range tests {
+		query, err := n1qlParser.Parse(x.input)
+		if err != nil {
+			t.Errorf("Valid Query Parse Failed: %v - %v", x.input, err)
+			continue
+		}
+		window, ok := query.(*ast.SelectStatement).Select[0].Expr.(*ast.WindowOperator)
+		if !ok {
+			t.Errorf("Expected a window for %v", x.input)
+			continue
+		}
+		if window.String() != x.output {
+			t.Errorf("Expected %v, got %v", x.output, window)
+		}
+	}
+}
+
+func TestExplainVerbose(t *testing.T) {
+	tests := []struct {
+		input   string
+		verbose bool
+	}{
+		{"EXPLAIN SELECT * FROM contacts", false},
+		{"EXPLAIN VERBOSE SELECT * FROM contacts", true},
+		{"explain verbose SELECT * FROM contacts WHERE age > 3", true},
+	}
+
+	n1qlParser := NewN1qlParser()
+
+	for _, x := range tests {
+		query, err := n1qlParser.Parse(x.input)
+		if err != nil {
+			t.Errorf("Valid Query Parse Failed: %v - %v", x.input, err)
+			continue
+		}
+		stmt, ok := query.(*ast.SelectStatement)
+		if !ok {
+			t.Errorf("expected select statement for %v, got %T", x.input, query)
+			continue
+		}
+		if !stmt.ExplainOnly || stmt.ExplainVerbose != x.verbose {
+			t.Errorf("expected explain verbose %v for %v, got explain %v verbose %v", x.verbose, x.input, stmt.ExplainOnly, stmt.ExplainVerbose)
+		}
+	}
+}
+
+func TestIndexHints(t *testing.T) {
+	tests := []struct {
+		input   string
+		indexes []*ast.IndexRef
+	}{
+		{"SELECT * FROM contacts", nil},
+		{"SELECT * FROM contacts USE INDEX (age_idx)",
+			[]*ast.IndexRef{{Name: "age_idx"}}},
+		{"SELECT * FROM contacts c USE INDEX (age_idx USING VIEW, name_idx) WHERE c.age > 3",
+			[]*ast.IndexRef{{Name: "age_idx", Method: "view"}, {Name: "name_idx"}}},
+	}
+
+	n1qlParser := NewN1qlParser()
+
+	for _, x := range tests {
+		query, err := n1qlParser.Parse(x.input)
+		if err != nil {
+			t.Errorf("Valid Query Parse Failed: %v - %v", x.input, err)
+			continue
+		}
+		stmt := query.(*ast.SelectStatement)
+		if !reflect.DeepEqual(stmt.From.Indexes, x.indexes) {
+			t.Errorf("expected index hints %v for %v, got %v", x.indexes, x.input, stmt.From.Indexes)
+		}
+	}
+}
+
+func TestCreateFunction(t *testing.T) {
+	tests := []struct {
+		input      string
+		name       string
+		parameters []string
+		text       string
+	}{
+		{"CREATE FUNCTION celsius(f) { (f - 32) * 5 / 9 }", "celsius", []string{"f"}, "(f - 32) * 5 / 9"},
+		{"CREATE FUNCTION one() {1}", "one", []string{}, "1"},
+		{"CREATE FUNCTION point(x, y) {\n\t{\"x\": x, \"y\": y}\n}", "point", []string{"x", "y"}, `{"x": x, "y": y}`},
+	}
+
+	n1qlParser := NewN1qlParser()
+
+	for _, x := range tests {
+		query, err := n1qlParser.Parse(x.input)
+		if err != nil {
+			t.Errorf("Valid Query Parse Failed: %v - %v", x.input, err)
+			continue
+		}
+		stmt, ok := query.(*ast.CreateFunctionStatement)
+		if !ok {
+			t.Errorf("expected create function statement for %v, got %T", x.input, query)
+			continue
+		}
+		definition := stmt.Definition
+		if definition.Name != x.name || !reflect.DeepEqual(definition.Parameters, x.parameters) || definition.Text != x.text {
+			t.Errorf("expected function %v(%v) {%v} for %v, got %v(%v) {%v}", x.name, x.parameters, x.text, x.input,
+				definition.Name, definition.Parameters, definition.Text)
+		}
+	}
+}
@@ -22,6 +22,66 @@ import (
 
 type Plan struct {
 	Root PlanElement `json:"root"`
+	// the plans of the subqueries appearing anywhere in this plan
+	Subqueries map[*ast.SelectStatement]PlanElement `json:"-"`
+}
+
+// statements changing documents or indexes are not read only,
+// explaining them is
+func (this *Plan) ReadOnly() bool {
+	switch this.Root.(type) {
+	case *Insert, *Update, *Delete, *CreateIndex, *DropIndex, *UpdateStatistics,
+		*CreateFunction, *DropFunction:
+		return false
+	}
+	return true
+}
+
+// the pools of the site the plan reads or changes,
+// explaining and preparing a statement reads nothing
+func (this *Plan) Pools() []string {
+	seen := make(map[string]bool)
+	rv := []string{}
+	var walk func(element PlanElement)
+	walk = func(element PlanElement) {
+		if element == nil {
+			return
+		}
+		pool := ""
+		switch element := element.(type) {
+		case *Explain, *Prepare:
+			return
+		case *Scan:
+			pool = element.Pool
+		case *FastCount:
+			pool = element.Pool
+		case *Fetch:
+			pool = element.Pool
+		case *KeyJoin:
+			pool = element.Pool
+		case *CreateIndex:
+			pool = element.Pool
+		case *DropIndex:
+			pool = element.Pool
+		case *UpdateStatistics:
+			pool = element.Pool
+		case *Insert:
+			pool = element.Pool
+		case *Update:
+			pool = element.Pool
+		case *Delete:
+			pool = element.Pool
+		}
+		if pool != "" && !seen[pool] {
+			seen[pool] = true
+			rv = append(rv, pool)
+		}
+		for _, source := range element.Sources() {
+			walk(source)
+		}
+	}
+	walk(this.Root)
+	return rv
 }
 
 type PlanChannel chan Plan
@@ -33,6 +93,37 @@ type PlanElement interface {
 type Explain struct {
 	Type  string      `json:"type"`
 	Input PlanElement `json:"input"`
+	Cost  *Cost       `json:"cost,omitempty"`
+	// EXPLAIN VERBOSE also shows every plan the optimizer chose
+	// from, and why each index of the bucket was used or not
+	Verbose    bool           `json:"-"`
+	Candidates []*Candidate   `json:"candidates,omitempty"`
+	Indexes    []*IndexChoice `json:"indexes,omitempty"`
+}
+
+// a plan the optimizer chose from, with the estimates
+// of each of its elements in their #estimate fields
+type Candidate struct {
+	Plan   map[string]interface{} `json:"plan"`
+	Cost   *Cost                  `json:"cost"`
+	Chosen bool                   `json:"chosen"`
+}
+
+// why the planner used an index to scan a bucket or not
+type IndexChoice struct {
+	Bucket   string     `json:"bucket"`
+	Index    string     `json:"index"`
+	Usable   bool       `json:"usable"`
+	Reason   string     `json:"reason"`
+	Ranges   ScanRanges `json:"ranges,omitempty"`
+	Covering bool       `json:"covering,omitempty"`
+}
+
+// the optimizer's estimate of the work done by a plan, in abstract
+// units, and of the number of items it produces
+type Cost struct {
+	Cost        float64 `json:"cost"`
+	Cardinality float64 `json:"cardinality"`
 }
 
 func NewExplain(input PlanElement) *Explain {
@@ -46,6 +137,57 @@ func (this *Explain) Sources() []PlanElement {
 	return []PlanElement{}
 }
 
+// the names of the json fields holding the sources
+// of the element, in the order of its Sources()
+func SourceKeys(element PlanElement) []string {
+	switch element.(type) {
+	case *Join:
+		return []string{"input", "right"}
+	case *Union, *Intersect, *Except:
+		return []string{"input", "term"}
+	}
+	return []string{"input"}
+}
+
+// Annotate returns the element as json values, every element of the
+// plan with what annotation returns for it in its field named key
+func Annotate(element PlanElement, key string, annotation func(PlanElement) interface{}) map[string]interface{} {
+	rv := map[string]interface{}{}
+	bytes, err := json.Marshal(element)
+	if err == nil {
+		json.Unmarshal(bytes, &rv)
+	}
+
+	keys := SourceKeys(element)
+	for i, source := range element.Sources() {
+		if source != nil && i < len(keys) {
+			rv[keys[i]] = Annotate(source, key, annotation)
+		}
+	}
+	rv[key] = annotation(element)
+	return rv
+}
+
+// the result of PREPARE, the plan itself runs with EXECUTE
+type Prepare struct {
+	Type     string      `json:"type"`
+	Name     string      `json:"name"`
+	Input    PlanElement `json:"input"`
+	Replaced bool        `json:"replaced"`
+}
+
+func NewPrepare(name string, input PlanElement) *Prepare {
+	return &Prepare{
+		Type:  "prepare",
+		Name:  name,
+		Input: input,
+	}
+}
+
+func (this *Prepare) Sources() []PlanElement {
+	return []PlanElement{}
+}
+
 type ScanRange struct {
 	Low       catalog.LookupValue
 	High      catalog.LookupValue
@@ -277,6 +419,8 @@ type Scan struct {
 	Ranges    ScanRanges `json:"ranges"`
 	Cover     bool       `json:"cover"`
 	As        string     `json:"as"`
+	// the ranges must be read in order, even when scanned in parallel
+	Ordered bool `json:"ordered,omitempty"`
 }
 
 func NewScan(pool string, bucket string, index string, ranges ScanRanges) *Scan {
@@ -298,6 +442,8 @@ func (this *Scan) Sources() []PlanElement {
 type KeyScan struct {
 	Type    string   `json:"type"`
 	KeyList []string `json:"keys"`
+	// evaluated when run instead, for keys given by parameters
+	Keys *ast.KeyExpression `json:"expr,omitempty"`
 }
 
 func NewKeyScan(keylist []string) *KeyScan {
@@ -307,6 +453,13 @@ func NewKeyScan(keylist []string) *KeyScan {
 	}
 }
 
+func NewKeyScanOfExpression(keys *ast.KeyExpression) *KeyScan {
+	return &KeyScan{
+		Type: "keyscan",
+		Keys: keys,
+	}
+}
+
 func (this *KeyScan) Sources() []PlanElement {
 	return []PlanElement{}
 }
@@ -343,6 +496,8 @@ type Fetch struct {
 	Projection ast.Expression `json:"projection"`
 	As         string         `json:"as"`
 	Ids        []string       `json:"ids"`
+	// the items must keep their order, even when fetched in parallel
+	Ordered bool `json:"ordered,omitempty"`
 }
 
 func NewFetch(input PlanElement, pool string, bucket string, projection ast.Expression, as string) *Fetch {
@@ -388,6 +543,7 @@ type Order struct {
 	Input           PlanElement           `json:"input"`
 	Sort            []*ast.SortExpression `json:"sort"`
 	ExplicitAliases []string              `json:"explicit_aliases"`
+	Limit           int                   `json:"limit,omitempty"` // only the first Limit items are needed, 0 when all are
 }
 
 func NewOrder(input PlanElement, sort []*ast.SortExpression, explicitAliases []string) *Order {
@@ -529,6 +685,54 @@ func (this *KeyJoin) Sources() []PlanElement {
 	return []PlanElement{this.Input}
 }
 
+// joins the items of its input with the items produced by Right
+// for which the ON condition holds.  the hash method matches the
+// items on the equi-join keys first, building its table from the
+// side named by Build, the nested-loop method tries every pair
+type Join struct {
+	Type      string             `json:"type"`
+	Input     PlanElement        `json:"input"`
+	Right     PlanElement        `json:"right"`
+	JoinType  string             `json:"jointype"`
+	On        ast.Expression     `json:"on"`
+	As        string             `json:"as"`
+	Method    string             `json:"method"`
+	LeftKeys  ast.ExpressionList `json:"left_keys,omitempty"`
+	RightKeys ast.ExpressionList `json:"right_keys,omitempty"`
+	Build     string             `json:"build,omitempty"`
+}
+
+func NewHashJoin(input PlanElement, right PlanElement, joinType string, on ast.Expression, as string, leftKeys ast.ExpressionList, rightKeys ast.ExpressionList, build string) *Join {
+	return &Join{
+		Type:      "join",
+		Input:     input,
+		Right:     right,
+		JoinType:  joinType,
+		On:        on,
+		As:        as,
+		Method:    "hash",
+		LeftKeys:  leftKeys,
+		RightKeys: rightKeys,
+		Build:     build,
+	}
+}
+
+func NewNestedLoopJoin(input PlanElement, right PlanElement, joinType string, on ast.Expression, as string) *Join {
+	return &Join{
+		Type:     "join",
+		Input:    input,
+		Right:    right,
+		JoinType: joinType,
+		On:       on,
+		As:       as,
+		Method:   "nested-loop",
+	}
+}
+
+func (this *Join) Sources() []PlanElement {
+	return []PlanElement{this.Input, this.Right}
+}
+
 type EliminateDuplicates struct {
 	Type  string      `json:"type"`
 	Input PlanElement `json:"input"`
@@ -550,6 +754,8 @@ type Grouper struct {
 	Input      PlanElement        `json:"input"`
 	Group      ast.ExpressionList `json:"group"`
 	Aggregates ast.ExpressionList `json:"aggregates"`
+	// the input arrives ordered by the group key
+	Streaming bool `json:"streaming,omitempty"`
 }
 
 func NewGroup(input PlanElement, group ast.ExpressionList, agg ast.ExpressionList) *Grouper {
@@ -565,6 +771,25 @@ func (this *Grouper) Sources() []PlanElement {
 	return []PlanElement{this.Input}
 }
 
+// computes the window functions once all the items have arrived
+type Window struct {
+	Type    string             `json:"type"`
+	Input   PlanElement        `json:"input"`
+	Windows ast.ExpressionList `json:"windows"`
+}
+
+func NewWindow(input PlanElement, windows ast.ExpressionList) *Window {
+	return &Window{
+		Type:    "window",
+		Input:   input,
+		Windows: windows,
+	}
+}
+
+func (this *Window) Sources() []PlanElement {
+	return []PlanElement{this.Input}
+}
+
 type CreateIndex struct {
 	Type      string             `json:"type"`
 	Pool      string             `json:"pool"`
@@ -610,3 +835,184 @@ func NewDropIndex(pool string, bucket string, name string) *DropIndex {
 func (this *DropIndex) Sources() []PlanElement {
 	return []PlanElement{}
 }
+
+// an empty index name updates all the range indexes of the bucket
+type UpdateStatistics struct {
+	Type   string `json:"type"`
+	Pool   string `json:"pool"`
+	Bucket string `json:"bucket"`
+	Index  string `json:"index"`
+}
+
+func NewUpdateStatistics(pool string, bucket string, index string) *UpdateStatistics {
+	return &UpdateStatistics{
+		Type:   "update_statistics",
+		Pool:   pool,
+		Bucket: bucket,
+		Index:  index,
+	}
+}
+
+func (this *UpdateStatistics) Sources() []PlanElement {
+	return []PlanElement{}
+}
+
+type CreateFunction struct {
+	Type       string                  `json:"type"`
+	Definition *ast.FunctionDefinition `json:"definition"`
+}
+
+func NewCreateFunction(definition *ast.FunctionDefinition) *CreateFunction {
+	return &CreateFunction{
+		Type:       "create_function",
+		Definition: definition,
+	}
+}
+
+func (this *CreateFunction) Sources() []PlanElement {
+	return []PlanElement{}
+}
+
+type DropFunction struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func NewDropFunction(name string) *DropFunction {
+	return &DropFunction{
+		Type: "drop_function",
+		Name: name,
+	}
+}
+
+func (this *DropFunction) Sources() []PlanElement {
+	return []PlanElement{}
+}
+
+type Insert struct {
+	Type   string              `json:"type"`
+	Pool   string              `json:"pool"`
+	Bucket string              `json:"bucket"`
+	Values ast.InsertValueList `json:"values"`
+	Upsert bool                `json:"upsert"`
+}
+
+func NewInsert(pool string, bucket string, values ast.InsertValueList, upsert bool) *Insert {
+	return &Insert{
+		Type:   "insert",
+		Pool:   pool,
+		Bucket: bucket,
+		Values: values,
+		Upsert: upsert,
+	}
+}
+
+func (this *Insert) Sources() []PlanElement {
+	return []PlanElement{}
+}
+
+type Update struct {
+	Type   string          `json:"type"`
+	Input  PlanElement     `json:"input"`
+	Pool   string          `json:"pool"`
+	Bucket string          `json:"bucket"`
+	As     string          `json:"as"`
+	Set    ast.SetTermList `json:"set"`
+}
+
+func NewUpdate(input PlanElement, pool string, bucket string, as string, set ast.SetTermList) *Update {
+	return &Update{
+		Type:   "update",
+		Input:  input,
+		Pool:   pool,
+		Bucket: bucket,
+		As:     as,
+		Set:    set,
+	}
+}
+
+func (this *Update) Sources() []PlanElement {
+	return []PlanElement{this.Input}
+}
+
+type Delete struct {
+	Type   string      `json:"type"`
+	Input  PlanElement `json:"input"`
+	Pool   string      `json:"pool"`
+	Bucket string      `json:"bucket"`
+	As     string      `json:"as"`
+}
+
+func NewDelete(input PlanElement, pool string, bucket string, as string) *Delete {
+	return &Delete{
+		Type:   "delete",
+		Input:  input,
+		Pool:   pool,
+		Bucket: bucket,
+		As:     as,
+	}
+}
+
+func (this *Delete) Sources() []PlanElement {
+	return []PlanElement{this.Input}
+}
+
+type Union struct {
+	Type  string      `json:"type"`
+	Input PlanElement `json:"input"`
+	Term  PlanElement `json:"term"`
+	All   bool        `json:"all"`
+}
+
+func NewUnion(input PlanElement, term PlanElement, all bool) *Union {
+	return &Union{
+		Type:  "union",
+		Input: input,
+		Term:  term,
+		All:   all,
+	}
+}
+
+func (this *Union) Sources() []PlanElement {
+	return []PlanElement{this.Input, this.Term}
+}
+
+type Intersect struct {
+	Type  string      `json:"type"`
+	Input PlanElement `json:"input"`
+	Term  PlanElement `json:"term"`
+	All   bool        `json:"all"`
+}
+
+func NewIntersect(input PlanElement, term PlanElement, all bool) *Intersect {
+	return &Intersect{
+		Type:  "intersect",
+		Input: input,
+		Term:  term,
+		All:   all,
+	}
+}
+
+func (this *Intersect) Sources() []PlanElement {
+	return []PlanElement{this.Input, this.Term}
+}
+
+type Except struct {
+	Type  string      `json:"type"`
+	Input PlanElement `json:"input"`
+	Term  PlanElement `json:"term"`
+	All   bool        `json:"all"`
+}
+
+func NewExcept(input PlanElement, term PlanElement, all bool) *Except {
+	return &Except{
+		Type:  "except",
+		Input: input,
+		Term:  term,
+		All:   all,
+	}
+}
+
+func (this *Except) Sources() []PlanElement {
+	return []PlanElement{this.Input, this.Term}
+}
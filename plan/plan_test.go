@@ -24,10 +24,13 @@ func TestPlanJSON(t *testing.T) {
 	tests := []struct {
 		input *Plan
 	}{
-		{&Plan{NewScan("pool", "bucket", "index", nil)}},
-		{&Plan{NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket")}},
-		{&Plan{NewFilter(NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket"), ast.NewLiteralBool(true))}},
-		{&Plan{NewFilter(NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket"), ast.NewPlusOperator(ast.NewLiteralNumber(1.0), ast.NewLiteralNumber(1.0)))}},
+		{&Plan{Root: NewScan("pool", "bucket", "index", nil)}},
+		{&Plan{Root: NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket")}},
+		{&Plan{Root: NewFilter(NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket"), ast.NewLiteralBool(true))}},
+		{&Plan{Root: NewFilter(NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket"), ast.NewPlusOperator(ast.NewLiteralNumber(1.0), ast.NewLiteralNumber(1.0)))}},
+		{&Plan{Root: NewInsert("pool", "bucket", ast.InsertValueList{ast.NewInsertValue(ast.NewLiteralString("key"), ast.NewLiteralBool(true))}, false)}},
+		{&Plan{Root: NewUpdate(NewFetch(NewKeyScan([]string{"key"}), "pool", "bucket", nil, "bucket"), "pool", "bucket", "bucket", ast.SetTermList{ast.NewSetTerm(ast.NewDotMemberOperator(ast.NewProperty("bucket"), ast.NewProperty("a")), ast.NewLiteralNumber(1.0))})}},
+		{&Plan{Root: NewDelete(NewFetch(NewScan("pool", "bucket", "index", nil), "pool", "bucket", nil, "bucket"), "pool", "bucket", "bucket")}},
 	}
 
 	for _, x := range tests {
@@ -40,6 +43,28 @@ func TestPlanJSON(t *testing.T) {
 
 }
 
+func TestPlanPools(t *testing.T) {
+	scan := NewFetch(NewScan("p0", "b0", "index", nil), "p0", "b0", nil, "b0")
+	join := NewKeyJoin(scan, "p1", "b1", nil, "INNER", "KEYS", ast.KeyExpression{}, "b1")
+
+	tests := []struct {
+		input    *Plan
+		expected []string
+	}{
+		{&Plan{Root: scan}, []string{"p0"}},
+		{&Plan{Root: NewHashJoin(NewFetch(NewScan("p1", "b1", "index", nil), "p1", "b1", nil, "b1"), join, "", nil, "b1", nil, nil, "left")}, []string{"p1", "p0"}},
+		{&Plan{Root: NewExplain(join)}, []string{}},
+		{&Plan{Root: NewInsert("p2", "b2", ast.InsertValueList{}, false)}, []string{"p2"}},
+	}
+
+	for _, x := range tests {
+		actual := x.input.Pools()
+		if !reflect.DeepEqual(actual, x.expected) {
+			t.Errorf("expected pools %v, got %v", x.expected, actual)
+		}
+	}
+}
+
 func TestFurtherness(t *testing.T) {
 	res := compareHigh(catalog.LookupValue{dparval.NewValue(5.0)}, catalog.LookupValue{dparval.NewValue(8.0)})
 	if res != -1 {
@@ -51,20 +51,21 @@ func CanIUseThisIndexForThisProjectionNoWhereNoGroupClause(index catalog.RangeIn
 				clog.To(planner.CHANNEL, "projection not MIN")
 				allAggregateFunctionsMin = false
 			}
-			// aggregates all take 1 operand
+			// aggregates take 1 operand, or more like PERCENTILE_CONT()
 			operands := expr.GetOperands()
 			if len(operands) < 1 {
 				return false, nil, nil, nil
 			}
-			aggOperand := operands[0]
-			// preence of * means we cannot use this index, must see all (for this particular optimization)
-			if aggOperand.Star {
-				return false, nil, nil, nil
-			}
-			// look at dependencies inside this operand
-			_, err := depChecker.Visit(aggOperand.Expr)
-			if err != nil {
-				return false, nil, nil, nil
+			for _, aggOperand := range operands {
+				// preence of * means we cannot use this index, must see all (for this particular optimization)
+				if aggOperand.Star {
+					return false, nil, nil, nil
+				}
+				// look at dependencies inside this operand
+				_, err := depChecker.Visit(aggOperand.Expr)
+				if err != nil {
+					return false, nil, nil, nil
+				}
 			}
 		default:
 			// all expressions must be aggregates for this particular optimization
@@ -176,6 +177,82 @@ func MergeRanges(origr plan.ScanRanges, newr *plan.ScanRange) plan.ScanRanges {
 	return rv
 }
 
+// the items arrive ordered by the group key when they come from a
+// single range of an index whose leading keys are the group by
+// expressions, in any order
+func CanIStreamTheseGroups(bucket catalog.Bucket, input plan.PlanElement, groupBy ast.ExpressionList, as string) bool {
+	if len(groupBy) == 0 {
+		return false
+	}
+
+	// filters and fetches keep the order of the scan
+	var scan *plan.Scan
+	for scan == nil {
+		switch element := input.(type) {
+		case *plan.Filter:
+			input = element.Input
+		case *plan.Fetch:
+			if element.Input == nil {
+				return false
+			}
+			input = element.Input
+		case *plan.Scan:
+			scan = element
+		default:
+			return false
+		}
+	}
+	if len(scan.Ranges) != 1 {
+		return false
+	}
+
+	index, err := bucket.IndexByName(scan.ScanIndex)
+	if err != nil {
+		return false
+	}
+	rangeIndex, ok := index.(catalog.RangeIndex)
+	if !ok || len(rangeIndex.Key()) < len(groupBy) {
+		return false
+	}
+	indexKeyFormal, ferr := IndexKeyInFormalNotation(rangeIndex.Key(), as)
+	if ferr != nil {
+		return false
+	}
+
+	for _, key := range indexKeyFormal[:len(groupBy)] {
+		found := false
+		for _, groupExpr := range groupBy {
+			if key.EquivalentTo(groupExpr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// asks the fetches and the scan below a streaming grouper to keep
+// the order of the index when they run in parallel
+func KeepScanOrder(input plan.PlanElement) {
+	for input != nil {
+		switch element := input.(type) {
+		case *plan.Filter:
+			input = element.Input
+		case *plan.Fetch:
+			element.Ordered = true
+			input = element.Input
+		case *plan.Scan:
+			element.Ordered = true
+			return
+		default:
+			return
+		}
+	}
+}
+
 func IndexKeyInFormalNotation(key catalog.IndexKey, bucket string) (catalog.IndexKey, error) {
 	fkey := make(catalog.IndexKey, len(key))
 	fnot := ast.NewExpressionFormalNotationConverter([]string{}, []string{bucket}, bucket)
@@ -14,11 +14,13 @@ package simple
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/catalog/system"
+	"github.com/couchbaselabs/tuqtng/optimizer/cost"
 	"github.com/couchbaselabs/tuqtng/plan"
 	"github.com/couchbaselabs/tuqtng/planner"
 	"github.com/couchbaselabs/tuqtng/query"
@@ -45,6 +47,126 @@ func (this *SimplePlanner) Plan(stmt ast.Statement) (plan.PlanChannel, query.Err
 
 func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
 
+	planHeads, indexes, err := this.buildSelectPlans(stmt)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	var subqueries map[*ast.SelectStatement]plan.PlanElement
+	if len(stmt.GetSubqueries()) > 0 {
+		subqueries = make(map[*ast.SelectStatement]plan.PlanElement)
+		err = this.buildSubqueryPlans(stmt, subqueries)
+		if err != nil {
+			ec <- err
+			return
+		}
+	}
+
+	for _, lastStep := range planHeads {
+		if stmt.ExplainOnly {
+			explain := plan.NewExplain(lastStep)
+			if stmt.ExplainVerbose {
+				explain.Verbose = true
+				explain.Indexes = indexes
+			}
+			lastStep = explain
+		}
+
+		pc <- plan.Plan{Root: lastStep, Subqueries: subqueries}
+	}
+}
+
+// each subquery uses the cheapest plan built for it (the same choice
+// the optimizer makes for a statement), subqueries nested inside
+// subqueries are planned too
+func (this *SimplePlanner) buildSubqueryPlans(stmt *ast.SelectStatement, subqueries map[*ast.SelectStatement]plan.PlanElement) query.Error {
+	for _, subquery := range stmt.GetSubqueries() {
+		planHeads, _, err := this.buildSelectPlans(subquery.Select)
+		if err != nil {
+			return err
+		}
+		cheapest, _ := cost.NewEstimator(this.site).Cheapest(planHeads)
+		subqueries[subquery.Select] = planHeads[cheapest]
+
+		err = this.buildSubqueryPlans(subquery.Select, subqueries)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// builds the candidate plans for a statement, including its compound terms,
+// along with why each index of the buckets was usable or not
+func (this *SimplePlanner) buildSelectPlans(stmt *ast.SelectStatement) ([]plan.PlanElement, []*plan.IndexChoice, query.Error) {
+
+	planHeads, indexes, err := this.buildSelectCorePlans(stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stmt.IsCompound() {
+		// each side of a set operation uses the cheapest plan built for it
+		// (the same choice the optimizer makes for a single statement)
+		estimator := cost.NewEstimator(this.site)
+		cheapest, _ := estimator.Cheapest(planHeads)
+		lastStep := planHeads[cheapest]
+		for _, term := range stmt.GetCompound() {
+			termHeads, termIndexes, err := this.buildSelectCorePlans(term.Select)
+			if err != nil {
+				return nil, nil, err
+			}
+			indexes = append(indexes, termIndexes...)
+			cheapest, _ = estimator.Cheapest(termHeads)
+			termStep := termHeads[cheapest]
+			switch term.Operator {
+			case ast.UNION:
+				lastStep = plan.NewUnion(lastStep, termStep, term.All)
+			case ast.INTERSECT:
+				lastStep = plan.NewIntersect(lastStep, termStep, term.All)
+			case ast.EXCEPT:
+				lastStep = plan.NewExcept(lastStep, termStep, term.All)
+			}
+		}
+		planHeads = []plan.PlanElement{lastStep}
+	}
+
+	// now for all the plan heads, create a full plan
+	for i, lastStep := range planHeads {
+
+		if stmt.GetOrderBy() != nil {
+			// the combined result of a compound statement is ordered by the projected values
+			var explicitAliases []string
+			if !stmt.IsCompound() {
+				explicitAliases = stmt.GetExplicitProjectionAliases()
+			}
+			order := plan.NewOrder(lastStep, stmt.GetOrderBy(), explicitAliases)
+			if stmt.GetLimit() > 0 {
+				// the sort only needs to keep the items the limit lets through
+				order.Limit = stmt.GetOffset() + stmt.GetLimit()
+			}
+			lastStep = order
+		}
+
+		if stmt.GetOffset() != 0 {
+			lastStep = plan.NewOffset(lastStep, stmt.GetOffset())
+		}
+
+		if stmt.GetLimit() >= 0 {
+			lastStep = plan.NewLimit(lastStep, stmt.GetLimit())
+		}
+
+		planHeads[i] = lastStep
+	}
+
+	return planHeads, indexes, nil
+}
+
+// builds the candidate plans for a single SELECT up to
+// and including the projection and duplicate elimination
+func (this *SimplePlanner) buildSelectCorePlans(stmt *ast.SelectStatement) ([]plan.PlanElement, []*plan.IndexChoice, query.Error) {
+
 	var planHeads []plan.PlanElement
 
 	from := stmt.GetFrom()
@@ -61,33 +183,51 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 
 	pool, err := this.site.PoolByName(poolName)
 	if err != nil {
-		ec <- query.NewPoolDoesNotExist(poolName)
-		return
+		return nil, nil, query.NewPoolDoesNotExist(poolName)
 	}
 
 	bucket, err := pool.BucketByName(from.Bucket)
 	if err != nil {
-		ec <- query.NewBucketDoesNotExist(from.Bucket)
-		return
+		return nil, nil, query.NewBucketDoesNotExist(from.Bucket)
 	}
 
 	// find all docs index
 	indexes, err := bucket.Indexes()
 	if err != nil {
-		ec <- query.NewError(err, fmt.Sprintf("No indexes found for bucket %v", from.Bucket))
-		return
+		return nil, nil, query.NewError(err, fmt.Sprintf("No indexes found for bucket %v", from.Bucket))
 	}
 
 	var keylist []string
 	if stmt.Keys != nil {
 		keylist = stmt.Keys.GetKeys()
+		if keylist == nil {
+			// given by parameters
+			keylist = []string{}
+		}
 	}
 
 	clog.To(planner.CHANNEL, "Indexes in bucket %v", indexes)
 
+	hinted, err := hintedIndexes(indexes, from.Indexes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// why each index was usable or not, for EXPLAIN VERBOSE
+	choices := make([]*plan.IndexChoice, len(indexes))
+	for i, index := range indexes {
+		choices[i] = &plan.IndexChoice{Bucket: bucket.Name(), Index: index.Name()}
+	}
+
 	if keylist == nil {
-		for _, index := range indexes {
+		for i, index := range indexes {
 			var lastStep plan.PlanElement
+			choice := choices[i]
+
+			if hinted != nil && !hinted[index.Name()] {
+				choice.Reason = "not named in USE INDEX"
+				continue
+			}
 
 			switch index := index.(type) {
 			case catalog.PrimaryIndex:
@@ -97,6 +237,7 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 				// } else {
 				lastStep = plan.NewScan(pool.Name(), bucket.Name(), index.Name(), nil)
 				// }
+				choice.Reason = "primary index scans the whole bucket"
 			case catalog.RangeIndex:
 				// see if this index can be used
 				clog.To(planner.CHANNEL, "See index %v", index.Name())
@@ -105,10 +246,12 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 					possible, ranges, _, err := CanIUseThisIndexForThisWhereClause(index, stmt.Where, stmt.From.As)
 					if err != nil {
 						clog.Error(err)
+						choice.Reason = fmt.Sprintf("error matching the WHERE clause: %v", err)
 						continue
 					}
 					clog.To(planner.CHANNEL, "Can I use it1: %v", possible)
 					if possible {
+						choice.Reason = "WHERE clause is sargable on the index key"
 
 						// great, but lets check for a min optimizatin too
 						if stmt.GroupBy != nil && len(stmt.GroupBy) == 0 {
@@ -117,6 +260,7 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 								for _, r := range ranges {
 									r.Limit = minranges[0].Limit
 								}
+								choice.Reason += ", MIN() stops at the first entry"
 							}
 						}
 
@@ -125,9 +269,13 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 						if DoesIndexCoverStatement(index, stmt) {
 							scan.Cover = true
 							scan.As = from.As
+							choice.Covering = true
+							choice.Reason += ", index covers the statement"
 						}
+						choice.Ranges = ranges
 						lastStep = scan
 					} else {
+						choice.Reason = "WHERE clause is not sargable on the leading index key"
 						continue
 					}
 				} else if from.Projection == nil {
@@ -149,37 +297,40 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 						possible, ranges, _, err := CanIUseThisIndexForThisProjectionNoWhereNoGroupClause(index, stmt.Select, stmt.From.As)
 						if err != nil {
 							clog.Error(err)
+							choice.Reason = fmt.Sprintf("error matching the projection: %v", err)
 							continue
 						}
 						clog.To(planner.CHANNEL, "Can I use it2: %v", possible)
 						if possible {
+							choice.Reason = "MIN() over the whole bucket stops at the first entry"
+							choice.Ranges = ranges
 							lastStep = plan.NewScan(pool.Name(), bucket.Name(), index.Name(), ranges)
 						} else {
+							choice.Reason = "no WHERE clause, and the aggregates are not a MIN() of the index key"
 							continue
 						}
 					} else if !doingFastCount {
+						choice.Reason = "no WHERE clause to restrict the scan"
 						continue
 					}
+				} else {
+					choice.Reason = "FROM clause is a path into the documents"
+					continue
 				}
 
 			default:
 				clog.To(planner.CHANNEL, "Unsupported type of index %T", index)
+				choice.Reason = fmt.Sprintf("unsupported type of index %T", index)
 				continue
 			}
+			choice.Usable = true
 			scanOp, lastStepWasScan := lastStep.(*plan.Scan)
 			if lastStepWasScan {
 				if !scanOp.Cover {
 					lastStep = plan.NewFetch(lastStep, pool.Name(), bucket.Name(), from.Projection, from.As)
-					nextFrom := from.Over
-					for nextFrom != nil {
-						// add document joins
-						if nextFrom.Keys != nil {
-							// This is a key-join
-							lastStep = plan.NewKeyJoin(lastStep, pool.Name(), nextFrom.Bucket, nextFrom.Projection, nextFrom.Type, nextFrom.Oper, *nextFrom.Keys, nextFrom.As)
-						} else {
-							lastStep = plan.NewUnnest(lastStep, nextFrom.Projection, nextFrom.Type, nextFrom.As)
-						}
-						nextFrom = nextFrom.Over
+					lastStep, err = this.buildJoins(lastStep, pool, from)
+					if err != nil {
+						return nil, nil, err
 					}
 				}
 			}
@@ -188,29 +339,35 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 		}
 	} else if keylist != nil {
 		// if keylist is present then we avoid a bucket scan
+		for _, choice := range choices {
+			choice.Reason = "KEYS clause fetches the documents without a scan"
+		}
 		var lastStep plan.PlanElement
-		lastStep = plan.NewKeyScan(keylist)
+		lastStep = newKeyScan(stmt.Keys)
 		lastStep = plan.NewFetch(lastStep, pool.Name(), bucket.Name(), from.Projection, from.As)
-		nextFrom := from.Over
-		for nextFrom != nil {
-			// add in-document joins
-			if nextFrom.Keys != nil {
-				lastStep = plan.NewKeyJoin(lastStep, pool.Name(), nextFrom.Bucket, nextFrom.Projection, nextFrom.Type, nextFrom.Oper, *nextFrom.Keys, nextFrom.As)
-			} else {
-				lastStep = plan.NewUnnest(lastStep, nextFrom.Projection, nextFrom.Type, nextFrom.As)
-			}
-			nextFrom = nextFrom.Over
+		lastStep, err = this.buildJoins(lastStep, pool, from)
+		if err != nil {
+			return nil, nil, err
 		}
 		planHeads = append(planHeads, lastStep)
 	}
 
+	if len(planHeads) == 0 && hinted != nil {
+		reasons := ""
+		for _, choice := range choices {
+			if hinted[choice.Index] {
+				reasons += fmt.Sprintf("; %v: %v", choice.Index, choice.Reason)
+			}
+		}
+		return nil, nil, query.NewError(nil, fmt.Sprintf("None of the indexes in USE INDEX can be used for bucket %v%v", from.Bucket, reasons))
+	}
+
 	if len(planHeads) == 0 {
-		ec <- query.NewError(nil, fmt.Sprintf("No usable indexes found for bucket %v", from.Bucket))
-		return
+		return nil, nil, query.NewError(nil, fmt.Sprintf("No usable indexes found for bucket %v", from.Bucket))
 	}
 
-	// now for all the plan heads, create a full plan
-	for _, lastStep := range planHeads {
+	// now for all the plan heads, add the filtering, grouping and projection
+	for i, lastStep := range planHeads {
 
 		if stmt.GetWhere() != nil {
 			ids := WhereClauseFindById(stmt.GetWhere())
@@ -225,7 +382,12 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 		if stmt.GetGroupBy() != nil {
 			_, isFastCount := lastStep.(*plan.FastCount)
 			if !isFastCount {
-				lastStep = plan.NewGroup(lastStep, stmt.GetGroupBy(), stmt.GetAggregateReferences())
+				grouper := plan.NewGroup(lastStep, stmt.GetGroupBy(), stmt.GetAggregateReferences())
+				grouper.Streaming = CanIStreamTheseGroups(bucket, lastStep, stmt.GetGroupBy(), from.As)
+				if grouper.Streaming {
+					KeepScanOrder(lastStep)
+				}
+				lastStep = grouper
 			}
 		}
 
@@ -233,33 +395,20 @@ func (this *SimplePlanner) buildSelectStatementPlans(stmt *ast.SelectStatement,
 			lastStep = plan.NewFilter(lastStep, stmt.GetHaving())
 		}
 
+		if len(stmt.GetWindowReferences()) > 0 {
+			lastStep = plan.NewWindow(lastStep, stmt.GetWindowReferences())
+		}
+
 		lastStep = plan.NewProjector(lastStep, stmt.GetResultExpressionList(), true)
 
 		if stmt.IsDistinct() {
 			lastStep = plan.NewEliminateDuplicates(lastStep)
 		}
 
-		if stmt.GetOrderBy() != nil {
-			explicitAliases := stmt.GetExplicitProjectionAliases()
-			lastStep = plan.NewOrder(lastStep, stmt.GetOrderBy(), explicitAliases)
-		}
-
-		if stmt.GetOffset() != 0 {
-			lastStep = plan.NewOffset(lastStep, stmt.GetOffset())
-		}
-
-		if stmt.GetLimit() >= 0 {
-			lastStep = plan.NewLimit(lastStep, stmt.GetLimit())
-		}
-
-		if stmt.ExplainOnly {
-			lastStep = plan.NewExplain(lastStep)
-		}
-
-		pc <- plan.Plan{Root: lastStep}
-
+		planHeads[i] = lastStep
 	}
 
+	return planHeads, choices, nil
 }
 
 func (this *SimplePlanner) buildCreateIndexStatementPlans(stmt *ast.CreateIndexStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
@@ -322,6 +471,244 @@ func (this *SimplePlanner) buildDropIndexStatementPlans(stmt *ast.DropIndexState
 	return
 }
 
+func (this *SimplePlanner) buildUpdateStatisticsStatementPlans(stmt *ast.UpdateStatisticsStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	pool, bucket, err := this.lookupBucket(stmt.Pool, stmt.Bucket)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	var lastStep plan.PlanElement
+
+	lastStep = plan.NewUpdateStatistics(pool.Name(), bucket.Name(), stmt.Index)
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+func (this *SimplePlanner) buildCreateFunctionStatementPlans(stmt *ast.CreateFunctionStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	var lastStep plan.PlanElement
+
+	lastStep = plan.NewCreateFunction(stmt.Definition)
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+func (this *SimplePlanner) buildDropFunctionStatementPlans(stmt *ast.DropFunctionStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	var lastStep plan.PlanElement
+
+	lastStep = plan.NewDropFunction(stmt.Name)
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+func (this *SimplePlanner) buildInsertStatementPlans(stmt *ast.InsertStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	pool, bucket, err := this.lookupBucket(stmt.Pool, stmt.Bucket)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	var lastStep plan.PlanElement
+
+	lastStep = plan.NewInsert(pool.Name(), bucket.Name(), stmt.Values, stmt.Upsert)
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+func (this *SimplePlanner) buildUpdateStatementPlans(stmt *ast.UpdateStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	pool, bucket, err := this.lookupBucket(stmt.Pool, stmt.Bucket)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	lastStep, err := this.buildMutationSourcePlan(pool, bucket, stmt.GetAlias(), stmt.Keys, stmt.Where, stmt.Limit)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	lastStep = plan.NewUpdate(lastStep, pool.Name(), bucket.Name(), stmt.GetAlias(), stmt.Set)
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+func (this *SimplePlanner) buildDeleteStatementPlans(stmt *ast.DeleteStatement, pc plan.PlanChannel, ec query.ErrorChannel) {
+
+	pool, bucket, err := this.lookupBucket(stmt.Pool, stmt.Bucket)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	lastStep, err := this.buildMutationSourcePlan(pool, bucket, stmt.GetAlias(), stmt.Keys, stmt.Where, stmt.Limit)
+	if err != nil {
+		ec <- err
+		return
+	}
+
+	lastStep = plan.NewDelete(lastStep, pool.Name(), bucket.Name(), stmt.GetAlias())
+	if stmt.ExplainOnly {
+		lastStep = plan.NewExplain(lastStep)
+	}
+
+	pc <- plan.Plan{Root: lastStep}
+	return
+}
+
+// adds the key joins, ON joins and unnests following the first bucket
+func (this *SimplePlanner) buildJoins(lastStep plan.PlanElement, pool catalog.Pool, from *ast.From) (plan.PlanElement, query.Error) {
+	estimator := cost.NewEstimator(this.site)
+	leftAliases := []string{from.As}
+	for nextFrom := from.Over; nextFrom != nil; nextFrom = nextFrom.Over {
+		if nextFrom.Keys != nil {
+			// This is a key-join
+			lastStep = plan.NewKeyJoin(lastStep, pool.Name(), nextFrom.Bucket, nextFrom.Projection, nextFrom.Type, nextFrom.Oper, *nextFrom.Keys, nextFrom.As)
+		} else if nextFrom.On != nil {
+			bucket, err := pool.BucketByName(nextFrom.Bucket)
+			if err != nil {
+				return nil, query.NewBucketDoesNotExist(nextFrom.Bucket)
+			}
+			primary, err := bucket.IndexByPrimary()
+			if err != nil {
+				return nil, query.NewError(err, fmt.Sprintf("No primary index found for bucket %v", bucket.Name()))
+			}
+			right := plan.NewFetch(plan.NewScan(pool.Name(), bucket.Name(), primary.Name(), nil), pool.Name(), bucket.Name(), nextFrom.Projection, nextFrom.As)
+
+			leftKeys, rightKeys := nextFrom.EquiJoinKeys(leftAliases)
+			if len(leftKeys) > 0 {
+				// build the hash table from the smaller side
+				build := "right"
+				if estimator.Estimate(lastStep).Cardinality < estimator.Estimate(right).Cardinality {
+					build = "left"
+				}
+				lastStep = plan.NewHashJoin(lastStep, right, nextFrom.Type, nextFrom.On, nextFrom.As, leftKeys, rightKeys, build)
+			} else {
+				lastStep = plan.NewNestedLoopJoin(lastStep, right, nextFrom.Type, nextFrom.On, nextFrom.As)
+			}
+		} else {
+			lastStep = plan.NewUnnest(lastStep, nextFrom.Projection, nextFrom.Type, nextFrom.As)
+		}
+		leftAliases = append(leftAliases, nextFrom.As)
+	}
+	return lastStep, nil
+}
+
+// keys given by parameters are only known when the statement is run
+func newKeyScan(keys *ast.KeyExpression) *plan.KeyScan {
+	if keys.HasParameters() {
+		return plan.NewKeyScanOfExpression(keys)
+	}
+	return plan.NewKeyScan(keys.GetKeys())
+}
+
+// UPDATE and DELETE work on the documents a SELECT * with the
+// same KEYS, WHERE and LIMIT clauses would produce, fetched
+// under the alias of the bucket
+func (this *SimplePlanner) buildMutationSourcePlan(pool catalog.Pool, bucket catalog.Bucket, as string, keys *ast.KeyExpression, where ast.Expression, limit int) (plan.PlanElement, query.Error) {
+
+	var lastStep plan.PlanElement
+
+	if keys != nil {
+		lastStep = newKeyScan(keys)
+	} else {
+		primary, err := bucket.IndexByPrimary()
+		if err != nil {
+			return nil, query.NewError(err, fmt.Sprintf("No primary index found for bucket %v", bucket.Name()))
+		}
+		lastStep = plan.NewScan(pool.Name(), bucket.Name(), primary.Name(), nil)
+	}
+
+	fetch := plan.NewFetch(lastStep, pool.Name(), bucket.Name(), nil, as)
+	lastStep = fetch
+
+	if where != nil {
+		ids := WhereClauseFindById(where)
+		if ids != nil && keys == nil {
+			fetch.ConvertToIds(ids)
+		} else {
+			lastStep = plan.NewFilter(lastStep, where)
+		}
+	}
+
+	if limit >= 0 {
+		lastStep = plan.NewLimit(lastStep, limit)
+	}
+
+	return lastStep, nil
+}
+
+// the names of the indexes the planner may use, nil when there are no
+// hints.  every hinted index must exist, with the method it was named with
+func hintedIndexes(indexes []catalog.Index, hints []*ast.IndexRef) (map[string]bool, query.Error) {
+	if len(hints) == 0 {
+		return nil, nil
+	}
+
+	rv := make(map[string]bool, len(hints))
+	for _, hint := range hints {
+		var found catalog.Index
+		for _, index := range indexes {
+			if index.Name() == hint.Name {
+				found = index
+				break
+			}
+		}
+		if found == nil {
+			return nil, query.NewIndexDoesNotExist(hint.Name)
+		}
+		// indexes with no particular type can serve any method
+		if hint.Method != "" && found.Type() != catalog.UNSPECIFIED && found.Type() != catalog.IndexType(strings.ToLower(hint.Method)) {
+			return nil, query.NewError(nil, fmt.Sprintf("Index %v is not a %v index", hint.Name, hint.Method))
+		}
+		rv[hint.Name] = true
+	}
+	return rv, nil
+}
+
+func (this *SimplePlanner) lookupBucket(poolName string, bucketName string) (catalog.Pool, catalog.Bucket, query.Error) {
+	if poolName == "" {
+		poolName = this.defaultPool
+	}
+
+	pool, err := this.site.PoolByName(poolName)
+	if err != nil {
+		return nil, nil, query.NewPoolDoesNotExist(poolName)
+	}
+
+	bucket, err := pool.BucketByName(bucketName)
+	if err != nil {
+		return nil, nil, query.NewBucketDoesNotExist(bucketName)
+	}
+
+	return pool, bucket, nil
+}
+
 func (this *SimplePlanner) buildPlans(stmt ast.Statement, pc plan.PlanChannel, ec query.ErrorChannel) {
 	defer close(pc)
 	defer close(ec)
@@ -332,6 +719,18 @@ func (this *SimplePlanner) buildPlans(stmt ast.Statement, pc plan.PlanChannel, e
 		this.buildCreateIndexStatementPlans(stmt, pc, ec)
 	case *ast.DropIndexStatement:
 		this.buildDropIndexStatementPlans(stmt, pc, ec)
+	case *ast.UpdateStatisticsStatement:
+		this.buildUpdateStatisticsStatementPlans(stmt, pc, ec)
+	case *ast.CreateFunctionStatement:
+		this.buildCreateFunctionStatementPlans(stmt, pc, ec)
+	case *ast.DropFunctionStatement:
+		this.buildDropFunctionStatementPlans(stmt, pc, ec)
+	case *ast.InsertStatement:
+		this.buildInsertStatementPlans(stmt, pc, ec)
+	case *ast.UpdateStatement:
+		this.buildUpdateStatementPlans(stmt, pc, ec)
+	case *ast.DeleteStatement:
+		this.buildDeleteStatementPlans(stmt, pc, ec)
 	}
 
 }
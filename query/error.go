@@ -147,10 +147,54 @@ func NewPoolDoesNotExist(pool string) Error {
 	return &err{level: EXCEPTION, ICode: 4041, IKey: "pool_not_found", InternalMsg: fmt.Sprintf("Pool %s does not exist", pool), InternalCaller: misc.CallerN(1)}
 }
 
+func NewKeyNotFound(key string) Error {
+	return &err{level: EXCEPTION, ICode: 4042, IKey: "key_not_found", InternalMsg: fmt.Sprintf("Key %s does not exist", key), InternalCaller: misc.CallerN(1)}
+}
+
+func NewPreparedDoesNotExist(name string) Error {
+	return &err{level: EXCEPTION, ICode: 4043, IKey: "prepared_not_found", InternalMsg: fmt.Sprintf("Prepared statement %s does not exist", name), InternalCaller: misc.CallerN(1)}
+}
+
+func NewRequestDoesNotExist(id string) Error {
+	return &err{level: EXCEPTION, ICode: 4044, IKey: "request_not_found", InternalMsg: fmt.Sprintf("Request %s does not exist", id), InternalCaller: misc.CallerN(1)}
+}
+
+func NewIndexDoesNotExist(index string) Error {
+	return &err{level: EXCEPTION, ICode: 4045, IKey: "index_not_found", InternalMsg: fmt.Sprintf("Index %s does not exist", index), InternalCaller: misc.CallerN(1)}
+}
+
+func NewFunctionDoesNotExist(name string) Error {
+	return &err{level: EXCEPTION, ICode: 4046, IKey: "function_not_found", InternalMsg: fmt.Sprintf("Function %s does not exist", name), InternalCaller: misc.CallerN(1)}
+}
+
+func NewReadOnlyError() Error {
+	return &err{level: EXCEPTION, ICode: 4030, IKey: "readonly_violation", InternalMsg: "Statement would change data in a read only request", InternalCaller: misc.CallerN(1)}
+}
+
+func NewKeyExists(key string) Error {
+	return &err{level: EXCEPTION, ICode: 4090, IKey: "key_exists", InternalMsg: fmt.Sprintf("Key %s already exists", key), InternalCaller: misc.CallerN(1)}
+}
+
+func NewFunctionExists(name string) Error {
+	return &err{level: EXCEPTION, ICode: 4091, IKey: "function_exists", InternalMsg: fmt.Sprintf("Function %s already exists", name), InternalCaller: misc.CallerN(1)}
+}
+
 func NewTimeoutError(timeout *time.Duration) Error {
 	return &err{level: EXCEPTION, ICode: 4080, IKey: "timeout", InternalMsg: fmt.Sprintf("Timeout %v exceeded", timeout), InternalCaller: misc.CallerN(1)}
 }
 
+func NewCancelledError() Error {
+	return &err{level: EXCEPTION, ICode: 4081, IKey: "request_cancelled", InternalMsg: "Request was cancelled", InternalCaller: misc.CallerN(1)}
+}
+
+func NewQueueFullError() Error {
+	return &err{level: EXCEPTION, ICode: 5030, IKey: "queue_full", InternalMsg: "Request queue is full, try again later", InternalCaller: misc.CallerN(1)}
+}
+
+func NewPoolBusyError(pool string) Error {
+	return &err{level: EXCEPTION, ICode: 5031, IKey: "pool_busy", InternalMsg: fmt.Sprintf("Too many requests running against pool %s, try again later", pool), InternalCaller: misc.CallerN(1)}
+}
+
 func NewTotalRowsInfo(rows int) Error {
 	return &err{level: INFO, ICode: 100, IKey: "total_rows", InternalMsg: fmt.Sprintf("%d", rows), InternalCaller: misc.CallerN(1)}
 }
@@ -158,3 +202,15 @@ func NewTotalRowsInfo(rows int) Error {
 func NewTotalElapsedTimeInfo(time string) Error {
 	return &err{level: INFO, ICode: 101, IKey: "total_elapsed_time", InternalMsg: fmt.Sprintf("%s", time), InternalCaller: misc.CallerN(1)}
 }
+
+func NewQueueDepthInfo(depth int) Error {
+	return &err{level: INFO, ICode: 103, IKey: "queue_depth", InternalMsg: fmt.Sprintf("%d", depth), InternalCaller: misc.CallerN(1)}
+}
+
+func NewQueueWaitTimeInfo(time string) Error {
+	return &err{level: INFO, ICode: 104, IKey: "queue_wait_time", InternalMsg: fmt.Sprintf("%s", time), InternalCaller: misc.CallerN(1)}
+}
+
+func NewMutationCountInfo(count int) Error {
+	return &err{level: INFO, ICode: 102, IKey: "mutation_count", InternalMsg: fmt.Sprintf("%d", count), InternalCaller: misc.CallerN(1)}
+}
@@ -0,0 +1,112 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// how many requests are run at once, and how many more may wait
+// for a worker before requests are refused
+var Workers = 4 * runtime.NumCPU()
+var QueueSize = 1000
+
+// how many requests may run at once against each pool of the site,
+// pools without a limit are only limited by the number of workers
+var PoolLimits = map[string]int{}
+
+// ParsePoolLimits reads pool limits written like default=4,beer-sample=2
+func ParsePoolLimits(s string) (map[string]int, error) {
+	rv := make(map[string]int)
+	if s == "" {
+		return rv, nil
+	}
+	for _, limit := range strings.Split(s, ",") {
+		nameAndLimit := strings.SplitN(limit, "=", 2)
+		if len(nameAndLimit) != 2 || nameAndLimit[0] == "" {
+			return nil, fmt.Errorf("Invalid pool limit %s, expected pool=limit", limit)
+		}
+		n, err := strconv.Atoi(nameAndLimit[1])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("Invalid pool limit %s, the limit must be a positive number", limit)
+		}
+		rv[nameAndLimit[0]] = n
+	}
+	return rv, nil
+}
+
+// a query waiting for a worker
+type queuedQuery struct {
+	query network.Query
+	// when it was queued, and how many queries were ahead of it
+	queued time.Time
+	depth  int
+}
+
+func newQueuedQuery(q network.Query, depth int) *queuedQuery {
+	return &queuedQuery{
+		query:  q,
+		queued: time.Now(),
+		depth:  depth,
+	}
+}
+
+// tells the client how long its query waited
+func (this *queuedQuery) sendMetrics() {
+	response := this.query.Response()
+	response.SendError(query.NewQueueDepthInfo(this.depth))
+	response.SendError(query.NewQueueWaitTimeInfo(time.Since(this.queued).String()))
+}
+
+// the number of requests running against each pool
+type poolCounter struct {
+	mutex   sync.Mutex
+	running map[string]int
+}
+
+var runningPools = &poolCounter{running: make(map[string]int)}
+
+// acquire counts a request against each of its pools,
+// unless one of them has reached its limit
+func (this *poolCounter) acquire(pools []string) query.Error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for _, pool := range pools {
+		limit, ok := PoolLimits[pool]
+		if ok && this.running[pool] >= limit {
+			return query.NewPoolBusyError(pool)
+		}
+	}
+	for _, pool := range pools {
+		this.running[pool]++
+	}
+	return nil
+}
+
+func (this *poolCounter) release(pools []string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for _, pool := range pools {
+		this.running[pool]--
+		if this.running[pool] <= 0 {
+			delete(this.running, pool)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePoolLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int
+	}{
+		{"", map[string]int{}},
+		{"default=4", map[string]int{"default": 4}},
+		{"default=4,beer-sample=2", map[string]int{"default": 4, "beer-sample": 2}},
+		{"default", nil},
+		{"=4", nil},
+		{"default=0", nil},
+		{"default=many", nil},
+	}
+
+	for _, x := range tests {
+		actual, err := ParsePoolLimits(x.input)
+		if x.expected == nil {
+			if err == nil {
+				t.Errorf("expected error for %v, got %v", x.input, actual)
+			}
+		} else if err != nil || !reflect.DeepEqual(actual, x.expected) {
+			t.Errorf("expected %v for %v, got %v, err: %v", x.expected, x.input, actual, err)
+		}
+	}
+}
+
+func TestPoolCounter(t *testing.T) {
+	defer func(limits map[string]int) {
+		PoolLimits = limits
+	}(PoolLimits)
+	PoolLimits = map[string]int{"p0": 1}
+
+	counter := &poolCounter{running: make(map[string]int)}
+	err := counter.acquire([]string{"p0", "p1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// nothing is counted when one of the pools is busy
+	err = counter.acquire([]string{"p1", "p0"})
+	if err == nil || err.TranslationKey() != "pool_busy" {
+		t.Errorf("expected pool_busy, got %v", err)
+	}
+	if counter.running["p1"] != 1 {
+		t.Errorf("expected 1 request running against p1, got %v", counter.running["p1"])
+	}
+
+	counter.release([]string{"p0", "p1"})
+	if len(counter.running) != 0 {
+		t.Errorf("expected no running requests, got %v", counter.running)
+	}
+	err = counter.acquire([]string{"p0"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
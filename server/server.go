@@ -11,11 +11,13 @@ package server
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/couchbaselabs/clog"
 
+	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/catalog/couchbase"
 	"github.com/couchbaselabs/tuqtng/catalog/file"
@@ -24,11 +26,15 @@ import (
 	"github.com/couchbaselabs/tuqtng/compiler"
 	"github.com/couchbaselabs/tuqtng/executor"
 	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
 
 	standardCompiler "github.com/couchbaselabs/tuqtng/compiler/standard"
 	interpretedExecutor "github.com/couchbaselabs/tuqtng/executor/interpreted"
+	yaccParser "github.com/couchbaselabs/tuqtng/parser/goyacc"
 )
 
+const CHANNEL = "SERVER"
+
 func Site(s string) (catalog.Site, error) {
 	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") {
 		return file.NewSite(s)
@@ -42,6 +48,22 @@ func Site(s string) (catalog.Site, error) {
 	return couchbase.NewSite(s)
 }
 
+// the functions created with CREATE FUNCTION are kept in this file
+// when the server is not told where
+const FUNCTIONS_FILE = "n1ql_functions.json"
+
+// DefaultFunctionsFile is in the directory of a file-based site, and
+// in the working directory for other sites
+func DefaultFunctionsFile(s string) string {
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") {
+		return filepath.Join(s, FUNCTIONS_FILE)
+	}
+	if strings.HasPrefix(s, "dir:") {
+		return filepath.Join(s[4:], FUNCTIONS_FILE)
+	}
+	return FUNCTIONS_FILE
+}
+
 func Server(version, siteName, defaultPoolName string,
 	queryChannel network.QueryChannel, timeout *time.Duration) error {
 	site, err := Site(siteName)
@@ -54,6 +76,11 @@ func Server(version, siteName, defaultPoolName string,
 		return fmt.Errorf("Unable to instantiate system catalog: %v", err)
 	}
 
+	err = LoadFunctions()
+	if err != nil {
+		return err
+	}
+
 	// create a StaticQueryPipeline we use to process queries
 	comp := standardCompiler.NewCompiler(systemProxySite, defaultPoolName)
 	exec := interpretedExecutor.NewExecutor(systemProxySite, defaultPoolName)
@@ -62,26 +89,119 @@ func Server(version, siteName, defaultPoolName string,
 	clog.Log("version: %s", version)
 	clog.Log("site: %s", siteName)
 
-	// dispatch each query that comes in
-	for query := range queryChannel {
-		go Dispatch(query, comp, exec, timeout)
+	// a fixed number of workers run the queries waiting in a bounded
+	// queue, queries arriving when the queue is full are refused
+	workers := Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan *queuedQuery, QueueSize)
+	defer close(queue)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for queued := range queue {
+				queued.sendMetrics()
+				Dispatch(queued.query, comp, exec, timeout)
+			}
+		}()
+	}
+
+	for q := range queryChannel {
+		select {
+		case queue <- newQueuedQuery(q, len(queue)):
+		default:
+			clog.To(CHANNEL, "request queue is full, refusing query")
+			q.Response().SendError(query.NewQueueFullError())
+		}
 	}
 
 	return nil
 }
 
+// LoadFunctions defines again the functions created with CREATE FUNCTION
+// before the server was restarted, in the order they were created.  a
+// function that cannot be defined again is skipped, it should not keep
+// the server from starting
+func LoadFunctions() error {
+	definitions, err := system.StoredFunctions()
+	if err != nil {
+		return fmt.Errorf("Unable to load functions: %v", err)
+	}
+	parser := yaccParser.NewN1qlParser()
+	for _, definition := range definitions {
+		parameters := make([]string, len(definition.Parameters))
+		for i, parameter := range definition.Parameters {
+			parameters[i] = "`" + parameter + "`"
+		}
+		statement := fmt.Sprintf("CREATE FUNCTION `%s`(%s) { %s }", definition.Name, strings.Join(parameters, ", "), definition.Text)
+		stmt, err := parser.Parse(statement)
+		if err != nil {
+			clog.Warnf("Unable to load function %s: %v", definition.Name, err)
+			continue
+		}
+		createFunctionStmt, ok := stmt.(*ast.CreateFunctionStatement)
+		if !ok {
+			clog.Warnf("Unable to load function %s: not a function definition", definition.Name)
+			continue
+		}
+		err = ast.DefineFunction(createFunctionStmt.Definition)
+		if err != nil {
+			clog.Warnf("Unable to load function %s: %v", definition.Name, err)
+			continue
+		}
+		clog.To(CHANNEL, "loaded function %s", definition.Name)
+	}
+	return nil
+}
+
 func Dispatch(q network.Query, comp compiler.Compiler, exec executor.Executor,
 	timeout *time.Duration) {
-	request := q.Request()
-	response := q.Response()
+	var statement string
+	readOnly := false
 
-	switch request := request.(type) {
+	switch request := q.Request().(type) {
 	case network.StringQueryRequest:
-		plan, err := comp.Compile(request.QueryString)
-		if err != nil {
-			response.SendError(err)
-			return
+		statement = request.QueryString
+	case network.StructuredQueryRequest:
+		statement = request.QueryString
+		readOnly = request.ReadOnly
+		// the request may choose its own timeout
+		if request.Timeout > 0 {
+			timeout = &request.Timeout
 		}
-		exec.Execute(plan, q, timeout)
+	default:
+		return
+	}
+
+	// keep track of the request while it runs, for :system.active_requests
+	active := network.Requests.Add(q, statement)
+	defer network.Requests.Complete(active)
+	response := active.Response()
+
+	plan, err := comp.Compile(statement)
+	if err != nil {
+		response.SendError(err)
+		return
+	}
+	if readOnly && !plan.ReadOnly() {
+		response.SendError(query.NewReadOnlyError())
+		return
+	}
+	// the pools of the site may limit how many requests they run
+	pools := plan.Pools()
+	err = runningPools.acquire(pools)
+	if err != nil {
+		response.SendError(err)
+		return
+	}
+	active.OnComplete(func() {
+		runningPools.release(pools)
+	})
+
+	if active.Cancelled() {
+		response.SendError(query.NewCancelledError())
+		return
 	}
+	active.SetPhase(network.PHASE_EXECUTING)
+	exec.Execute(plan, active, timeout)
 }
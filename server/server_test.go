@@ -0,0 +1,34 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package server
+
+import (
+	"testing"
+)
+
+func TestDefaultFunctionsFile(t *testing.T) {
+	tests := []struct {
+		site     string
+		expected string
+	}{
+		{"dir:test", "test/n1ql_functions.json"},
+		{"./test", "test/n1ql_functions.json"},
+		{"/var/data", "/var/data/n1ql_functions.json"},
+		{"http://localhost:8091", "n1ql_functions.json"},
+		{"mock:", "n1ql_functions.json"},
+	}
+
+	for _, x := range tests {
+		actual := DefaultFunctionsFile(x.site)
+		if actual != x.expected {
+			t.Errorf("expected %v for %v, got %v", x.expected, x.site, actual)
+		}
+	}
+}
@@ -10,6 +10,7 @@
 package test
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/couchbaselabs/tuqtng/misc"
@@ -42,13 +43,21 @@ func (this *MockQuery) StartTime() time.Time {
 }
 
 type MockResponse struct {
-	err      query.Error
-	results  []interface{}
-	warnings []query.Error
-	done     chan bool
+	err       query.Error
+	results   []interface{}
+	warnings  []query.Error
+	mutations int
+	profile   interface{}
+	done      chan bool
 }
 
 func (this *MockResponse) SendError(err query.Error) {
+	if err.Level() == query.INFO {
+		if err.TranslationKey() == "mutation_count" {
+			this.mutations, _ = strconv.Atoi(err.Error())
+		}
+		return
+	}
 	this.err = err
 	if err.IsFatal() {
 		close(this.done)
@@ -61,23 +70,60 @@ func (this *MockResponse) SendResult(val interface{}) {
 	this.results = append(this.results, sanitizedValue)
 }
 
+func (this *MockResponse) SetProfile(profile interface{}) {
+	this.profile = profile
+}
+
 func (this *MockResponse) NoMoreResults() {
 	close(this.done)
 }
 
 func Run(qc network.QueryChannel, q string) ([]interface{}, []query.Error, query.Error) {
+	mr := run(qc, q)
+	return mr.results, mr.warnings, mr.err
+}
+
+// like Run, but returns the number of documents changed by the statement
+func RunMutation(qc network.QueryChannel, q string) (int, query.Error) {
+	mr := run(qc, q)
+	return mr.mutations, mr.err
+}
+
+// like Run, with values for the parameters of the statement
+func RunWithArgs(qc network.QueryChannel, q string, args network.QueryArgs) ([]interface{}, query.Error) {
+	mr := runRequest(qc, network.StringQueryRequest{QueryString: q, QueryArgs: args})
+	return mr.results, mr.err
+}
+
+// like Run, returning the plan annotated with what each operator did
+func RunProfiled(qc network.QueryChannel, q string) ([]interface{}, interface{}, query.Error) {
+	mr := runRequest(qc, network.StructuredQueryRequest{StringQueryRequest: network.StringQueryRequest{QueryString: q}, Profile: true})
+	return mr.results, mr.profile, mr.err
+}
+
+func run(qc network.QueryChannel, q string) *MockResponse {
+	return runRequest(qc, network.StringQueryRequest{QueryString: q})
+}
+
+// like Run, for any kind of request
+func RunRequest(qc network.QueryChannel, request network.QueryRequest) ([]interface{}, query.Error) {
+	mr := runRequest(qc, request)
+	return mr.results, mr.err
+}
+
+func runRequest(qc network.QueryChannel, request network.QueryRequest) *MockResponse {
 	mr := &MockResponse{
 		results: []interface{}{}, warnings: []query.Error{}, done: make(chan bool),
 	}
 
 	query := MockQuery{
-		request:   network.StringQueryRequest{QueryString: q},
+		request:   request,
 		response:  mr,
 		startTime: time.Now(),
 	}
 	qc <- &query
 	<-mr.done
-	return mr.results, mr.warnings, mr.err
+	return mr
 }
 
 func Start(site, pool string) network.QueryChannel {
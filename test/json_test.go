@@ -13,11 +13,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog/system"
+	"github.com/couchbaselabs/tuqtng/compiler/standard"
 	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/server"
 	"github.com/dustin/go-jsonpointer"
 )
 
@@ -60,6 +67,916 @@ func TestSimpleSelect(t *testing.T) {
 	}
 }
 
+func TestMutations(t *testing.T) {
+	// the fixture buckets are left alone, whatever happens to the run
+	dir, e := ioutil.TempDir("", "tuqtng-test")
+	if e != nil {
+		t.Fatalf("failed to create temp dir: %v", e)
+	}
+	defer os.RemoveAll(dir)
+	e = os.MkdirAll(filepath.Join(dir, "pool", "contacts"), 0777)
+	if e != nil {
+		t.Fatalf("failed to create bucket dir: %v", e)
+	}
+
+	qc := Start("dir:"+dir, "pool")
+	defer close(qc)
+
+	mutations, err := RunMutation(qc, `INSERT INTO contacts VALUES ("dml_anna", {"name": "anna", "age": 31}), ("dml_bert", {"name": "bert", "age": 45})`)
+	if err != nil || mutations != 2 {
+		t.Errorf("expected 2 inserts, got %v, err: %v", mutations, err)
+	}
+
+	mutations, err = RunMutation(qc, `INSERT INTO contacts VALUES ("dml_anna", {"name": "anna"})`)
+	if err == nil || err.TranslationKey() != "key_exists" || mutations != 0 {
+		t.Errorf("expected key_exists error and 0 inserts, got %v, err: %v", mutations, err)
+	}
+
+	mutations, err = RunMutation(qc, `UPDATE contacts KEYS ["dml_anna", "dml_bert"] SET age = age + 1 WHERE name = "bert"`)
+	if err != nil || mutations != 1 {
+		t.Errorf("expected 1 update, got %v, err: %v", mutations, err)
+	}
+
+	// documents the SET does not change are not written
+	mutations, err = RunMutation(qc, `UPDATE contacts KEYS ["dml_anna", "dml_bert"] SET address.city = "x"`)
+	if err != nil || mutations != 0 {
+		t.Errorf("expected no updates for a missing parent, got %v, err: %v", mutations, err)
+	}
+	mutations, err = RunMutation(qc, `UPDATE contacts KEYS ["dml_anna", "dml_bert"] SET age = 31`)
+	if err != nil || mutations != 1 {
+		t.Errorf("expected 1 update, got %v, err: %v", mutations, err)
+	}
+	mutations, err = RunMutation(qc, `UPDATE contacts KEYS ["dml_bert"] SET age = 46`)
+	if err != nil || mutations != 1 {
+		t.Errorf("expected 1 update, got %v, err: %v", mutations, err)
+	}
+
+	r, _, err := Run(qc, `SELECT name, age FROM contacts KEYS ["dml_anna", "dml_bert"] ORDER BY name`)
+	expected := []interface{}{
+		map[string]interface{}{"name": "anna", "age": 31.0},
+		map[string]interface{}{"name": "bert", "age": 46.0},
+	}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	mutations, err = RunMutation(qc, `DELETE FROM contacts KEYS ["dml_anna", "dml_bert"]`)
+	if err != nil || mutations != 2 {
+		t.Errorf("expected 2 deletes, got %v, err: %v", mutations, err)
+	}
+
+	r, _, err = Run(qc, `SELECT * FROM contacts KEYS ["dml_anna", "dml_bert"]`)
+	if err != nil || len(r) != 0 {
+		t.Errorf("expected no documents left, got %v, err: %v", r, err)
+	}
+}
+
+func TestFileIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = os.MkdirAll(filepath.Join(dir, "pool", "people"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+
+	qc := Start("dir:"+dir, "pool")
+	defer close(qc)
+
+	_, err = RunMutation(qc, `INSERT INTO people VALUES ("anna", {"age": 31}), ("bert", {"age": 45}), ("carl", {"age": 52})`)
+	if err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	// enough younger people for the index to be cheaper than a scan
+	for i := 0; i < 10; i++ {
+		_, err = RunMutation(qc, fmt.Sprintf(`INSERT INTO people VALUES ("young%d", {"age": %d})`, i, 20+i))
+		if err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+	_, _, err = Run(qc, `CREATE INDEX age_idx ON people(age)`)
+	if err != nil {
+		t.Fatalf("failed to create index: %v", err)
+	}
+
+	r, _, err := Run(qc, `EXPLAIN SELECT META().id AS id FROM people WHERE age > 40`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain: %v", err)
+	}
+	explain, _ := json.Marshal(r[0])
+	if !strings.Contains(string(explain), `"index":"age_idx"`) {
+		t.Errorf("expected a scan of age_idx, got %s", explain)
+	}
+
+	// every candidate with its estimate, and why each index was usable or not
+	r, _, err = Run(qc, `EXPLAIN VERBOSE SELECT META().id AS id FROM people WHERE age > 40`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain verbose: %v", err)
+	}
+	verbose := r[0].(map[string]interface{})
+	candidates, _ := verbose["candidates"].([]interface{})
+	if len(candidates) != 2 {
+		t.Errorf("expected 2 candidate plans, got %v", verbose["candidates"])
+	}
+	chosen := 0
+	for _, candidate := range candidates {
+		candidate := candidate.(map[string]interface{})
+		if candidate["chosen"] == true {
+			chosen++
+		}
+		if candidate["plan"].(map[string]interface{})["#estimate"] == nil {
+			t.Errorf("expected an estimate, got %v", candidate)
+		}
+	}
+	if chosen != 1 {
+		t.Errorf("expected one chosen candidate, got %v", chosen)
+	}
+	indexes, _ := verbose["indexes"].([]interface{})
+	if len(indexes) != 2 {
+		t.Fatalf("expected 2 indexes, got %v", verbose["indexes"])
+	}
+	for _, index := range indexes {
+		index := index.(map[string]interface{})
+		if index["usable"] != true || index["reason"] == "" {
+			t.Errorf("expected a usable index with a reason, got %v", index)
+		}
+		if index["index"] == "age_idx" && index["ranges"] == nil {
+			t.Errorf("expected the ranges of age_idx, got %v", index)
+		}
+	}
+
+	r, _, err = Run(qc, `EXPLAIN VERBOSE SELECT META().id AS id FROM people WHERE name = "anna"`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain verbose: %v", err)
+	}
+	explain, _ = json.Marshal(r[0])
+	if !strings.Contains(string(explain), `"reason":"WHERE clause is not sargable on the leading index key"`) {
+		t.Errorf("expected age_idx to be rejected, got %s", explain)
+	}
+
+	// hints restrict the indexes the planner may use
+	r, _, err = Run(qc, `EXPLAIN SELECT META().id AS id FROM people USE INDEX (all_docs) WHERE age > 40`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain with hint: %v", err)
+	}
+	explain, _ = json.Marshal(r[0])
+	if strings.Contains(string(explain), `"index":"age_idx"`) {
+		t.Errorf("expected a scan of all_docs, got %s", explain)
+	}
+	r, _, err = Run(qc, `SELECT META().id AS id FROM people USE INDEX (age_idx) WHERE age > 40`)
+	if err != nil || len(r) != 2 {
+		t.Errorf("expected 2 results using age_idx, got %v, err: %v", r, err)
+	}
+	_, _, err = Run(qc, `SELECT META().id AS id FROM people USE INDEX (age_idx) WHERE name = "anna"`)
+	if err == nil || !strings.Contains(err.Error(), "not sargable") {
+		t.Errorf("expected age_idx to be unusable, got %v", err)
+	}
+	_, _, err = Run(qc, `SELECT META().id AS id FROM people USE INDEX (no_idx) WHERE age > 40`)
+	if err == nil || !strings.Contains(err.Error(), "no_idx does not exist") {
+		t.Errorf("expected missing index error, got %v", err)
+	}
+	r, _, err = Run(qc, `SELECT META().id AS id FROM people USE KEYS ["bert"]`)
+	if err != nil || len(r) != 1 {
+		t.Errorf("expected 1 result for USE KEYS, got %v, err: %v", r, err)
+	}
+
+	// documents written after the index was created are found too
+	_, err = RunMutation(qc, `UPDATE people KEYS ["anna"] SET age = 41`)
+	if err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	r, _, err = Run(qc, `SELECT META().id AS id FROM people WHERE age > 40 ORDER BY id`)
+	expected := []interface{}{
+		map[string]interface{}{"id": "anna"},
+		map[string]interface{}{"id": "bert"},
+		map[string]interface{}{"id": "carl"},
+	}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	_, _, err = Run(qc, `UPDATE STATISTICS FOR people`)
+	if err != nil {
+		t.Fatalf("failed to update statistics: %v", err)
+	}
+	r, _, err = Run(qc, `UPDATE STATISTICS FOR people INDEX age_idx`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to update statistics: %v, got %v", err, r)
+	}
+	r, _, err = Run(qc, "SELECT `statistics`.count, `statistics`.distinct_count, `statistics`.missing_count FROM :system.indexes WHERE name = \"age_idx\"")
+	expected = []interface{}{
+		map[string]interface{}{"count": 13.0, "distinct_count": 13.0, "missing_count": 0.0},
+	}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	_, _, err = Run(qc, `DROP INDEX people.age_idx`)
+	if err != nil {
+		t.Errorf("failed to drop index: %v", err)
+	}
+}
+
+func TestStreamingGroups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = os.MkdirAll(filepath.Join(dir, "pool", "people"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+
+	qc := Start("dir:"+dir, "pool")
+	defer close(qc)
+
+	_, err = RunMutation(qc, `INSERT INTO people VALUES ("anna", {"age": 31}), ("bert", {"age": 45}), ("carl", {"age": 31}), ("dora", {"age": 52})`)
+	if err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	// enough younger people for the index to be cheaper than a scan
+	for i := 0; i < 10; i++ {
+		_, err = RunMutation(qc, fmt.Sprintf(`INSERT INTO people VALUES ("young%d", {"age": %d})`, i, 20+i))
+		if err != nil {
+			t.Fatalf("failed to insert: %v", err)
+		}
+	}
+	_, _, err = Run(qc, `CREATE INDEX age_idx ON people(age)`)
+	if err != nil {
+		t.Fatalf("failed to create index: %v", err)
+	}
+
+	// the index returns the items ordered by age
+	r, _, err := Run(qc, `EXPLAIN SELECT age, COUNT(*) AS n FROM people WHERE age > 40 GROUP BY age`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain: %v", err)
+	}
+	explain, _ := json.Marshal(r[0])
+	if !strings.Contains(string(explain), `"index":"age_idx"`) || !strings.Contains(string(explain), `"streaming":true`) {
+		t.Errorf("expected a streaming grouper over a scan of age_idx, got %s", explain)
+	}
+	// and the scan and fetch below it keep that order when run in parallel
+	if strings.Count(string(explain), `"ordered":true`) != 2 {
+		t.Errorf("expected an ordered scan and fetch, got %s", explain)
+	}
+
+	r, _, err = Run(qc, `SELECT age, COUNT(*) AS n FROM people WHERE age > 30 GROUP BY age ORDER BY age`)
+	expected := []interface{}{
+		map[string]interface{}{"age": 31.0, "n": 2.0},
+		map[string]interface{}{"age": 45.0, "n": 1.0},
+		map[string]interface{}{"age": 52.0, "n": 1.0},
+	}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	// the primary index returns the items in any order
+	r, _, err = Run(qc, `EXPLAIN SELECT age, COUNT(*) AS n FROM people GROUP BY age`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to explain: %v", err)
+	}
+	explain, _ = json.Marshal(r[0])
+	if strings.Contains(string(explain), `"streaming":true`) {
+		t.Errorf("expected a hash grouper, got %s", explain)
+	}
+}
+
+func TestPreparedStatements(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	r, _, err := Run(qc, `PREPARE by_type FROM SELECT META().id AS id FROM contacts WHERE type = $1 AND name = $name ORDER BY id`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+	prepared, _ := r[0].(map[string]interface{})
+	if prepared["name"] != "by_type" || prepared["input"] == nil || prepared["replaced"] != false {
+		t.Errorf("expected the name and plan of the prepared statement, got %v", r[0])
+	}
+
+	// the same plan runs with other values
+	tests := []struct {
+		args     network.QueryArgs
+		expected []interface{}
+	}{
+		{network.QueryArgs{Positional: []interface{}{"contact"}, Named: map[string]interface{}{"name": "dave"}},
+			[]interface{}{map[string]interface{}{"id": "dave"}}},
+		{network.QueryArgs{Positional: []interface{}{"contact"}, Named: map[string]interface{}{"name": "nobody"}},
+			[]interface{}{}},
+	}
+	for _, x := range tests {
+		r, err = RunWithArgs(qc, `EXECUTE by_type`, x.args)
+		if err != nil || !reflect.DeepEqual(r, x.expected) {
+			t.Errorf("expected %v for %v, got %v, err: %v", x.expected, x.args, r, err)
+		}
+	}
+
+	// parameters work without preparing too
+	r, err = RunWithArgs(qc, `SELECT $1 + 1 AS two, $x AS x`, network.QueryArgs{Positional: []interface{}{1.0}, Named: map[string]interface{}{"x": "y"}})
+	expected := []interface{}{map[string]interface{}{"two": 2.0, "x": "y"}}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	_, err = RunWithArgs(qc, `EXECUTE by_type`, network.QueryArgs{})
+	if err == nil {
+		t.Errorf("expected error for missing parameter values")
+	}
+
+	_, _, err = Run(qc, `EXECUTE unknown`)
+	if err == nil || err.TranslationKey() != "prepared_not_found" {
+		t.Errorf("expected prepared_not_found error, got %v", err)
+	}
+}
+
+func TestPreparedLimit(t *testing.T) {
+	defer func(limit int) {
+		standard.PreparedLimit = limit
+	}(standard.PreparedLimit)
+	standard.PreparedLimit = 2
+
+	qc := start()
+	defer close(qc)
+
+	for _, name := range []string{"one", "two"} {
+		_, _, err := Run(qc, `PREPARE `+name+` FROM SELECT "`+name+`" AS name`)
+		if err != nil {
+			t.Fatalf("failed to prepare: %v", err)
+		}
+	}
+
+	// preparing a name again replaces its plan, and says so
+	r, _, err := Run(qc, `PREPARE one FROM SELECT "uno" AS name`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+	prepared, _ := r[0].(map[string]interface{})
+	if prepared["replaced"] != true {
+		t.Errorf("expected the plan to be replaced, got %v", r[0])
+	}
+	r, _, err = Run(qc, `EXECUTE one`)
+	expected := []interface{}{map[string]interface{}{"name": "uno"}}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	// the plan executed least recently is forgotten first
+	_, _, err = Run(qc, `EXECUTE two`)
+	if err != nil {
+		t.Errorf("unexpected error executing: %v", err)
+	}
+	_, _, err = Run(qc, `PREPARE three FROM SELECT "three" AS name`)
+	if err != nil {
+		t.Fatalf("failed to prepare: %v", err)
+	}
+	_, _, err = Run(qc, `EXECUTE one`)
+	if err == nil || err.TranslationKey() != "prepared_not_found" {
+		t.Errorf("expected prepared_not_found error, got %v", err)
+	}
+	for _, name := range []string{"two", "three"} {
+		r, _, err = Run(qc, `EXECUTE `+name)
+		expected = []interface{}{map[string]interface{}{"name": name}}
+		if err != nil || !reflect.DeepEqual(r, expected) {
+			t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+		}
+	}
+}
+
+func TestPreparedDroppedIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = os.MkdirAll(filepath.Join(dir, "pool", "people"), 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+
+	qc := Start("dir:"+dir, "pool")
+	defer close(qc)
+
+	_, qerr := RunMutation(qc, `INSERT INTO people VALUES ("anna", {"age": 31}), ("bert", {"age": 45})`)
+	if qerr != nil {
+		t.Fatalf("failed to insert: %v", qerr)
+	}
+	// enough younger people for the index to be cheaper than a scan
+	for i := 0; i < 10; i++ {
+		_, qerr = RunMutation(qc, fmt.Sprintf(`INSERT INTO people VALUES ("young%d", {"age": %d})`, i, 20+i))
+		if qerr != nil {
+			t.Fatalf("failed to insert: %v", qerr)
+		}
+	}
+	_, _, qerr = Run(qc, `CREATE INDEX age_idx ON people(age)`)
+	if qerr != nil {
+		t.Fatalf("failed to create index: %v", qerr)
+	}
+	statement := `PREPARE by_age FROM SELECT META().id AS id FROM people WHERE age > 40`
+	r, _, qerr := Run(qc, statement)
+	if qerr != nil || len(r) != 1 {
+		t.Fatalf("failed to prepare: %v", qerr)
+	}
+	prepared, _ := json.Marshal(r[0])
+	if !strings.Contains(string(prepared), `"index":"age_idx"`) {
+		t.Errorf("expected a scan of age_idx, got %s", prepared)
+	}
+	expected := []interface{}{map[string]interface{}{"id": "bert"}}
+	r, _, qerr = Run(qc, `EXECUTE by_age`)
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+
+	// the plan still names the dropped index
+	_, _, qerr = Run(qc, `DROP INDEX people.age_idx`)
+	if qerr != nil {
+		t.Fatalf("failed to drop index: %v", qerr)
+	}
+	r, _, qerr = Run(qc, `EXECUTE by_age`)
+	if qerr == nil || !strings.Contains(qerr.Error(), "age_idx") {
+		t.Errorf("expected an error about the dropped index, got %v, err: %v", r, qerr)
+	}
+
+	// preparing it again chooses another index
+	r, _, qerr = Run(qc, statement)
+	if qerr != nil || len(r) != 1 {
+		t.Fatalf("failed to prepare: %v", qerr)
+	}
+	prepared, _ = json.Marshal(r[0])
+	if strings.Contains(string(prepared), `"index":"age_idx"`) || !strings.Contains(string(prepared), `"replaced":true`) {
+		t.Errorf("expected a replaced plan without age_idx, got %s", prepared)
+	}
+	r, _, qerr = Run(qc, `EXECUTE by_age`)
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+}
+
+func TestUserFunctions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "functions")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	system.FunctionsFile = filepath.Join(dir, "functions.json")
+	defer func() {
+		system.FunctionsFile = ""
+	}()
+
+	qc := start()
+	defer close(qc)
+
+	r, _, qerr := Run(qc, `CREATE FUNCTION celsius(f) { (f - 32) * 5 / 9 }`)
+	expected := []interface{}{map[string]interface{}{"name": "celsius", "created": true}}
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Fatalf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+	defer ast.DropFunction("celsius")
+
+	r, _, qerr = Run(qc, `SELECT CELSIUS(212) AS boiling, celsius(missing_field) AS nothing`)
+	expected = []interface{}{map[string]interface{}{"boiling": 100.0}}
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+
+	// EXPLAIN shows the body with the argument in place of the parameter
+	r, _, qerr = Run(qc, `EXPLAIN SELECT celsius(age) AS boiling FROM contacts`)
+	if qerr != nil || len(r) != 1 {
+		t.Fatalf("failed to explain: %v", qerr)
+	}
+	explain, _ := json.Marshal(r[0])
+	if !strings.Contains(string(explain), `"body":{`) || !strings.Contains(string(explain), `"path":"age"`) || strings.Contains(string(explain), `"path":"f"`) {
+		t.Errorf("expected the inlined body of celsius, got %s", explain)
+	}
+
+	_, _, qerr = Run(qc, `CREATE FUNCTION celsius(x) { x }`)
+	if qerr == nil || qerr.TranslationKey() != "function_exists" {
+		t.Errorf("expected function_exists error, got %v", qerr)
+	}
+	_, _, qerr = Run(qc, `CREATE FUNCTION lower(x) { x }`)
+	if qerr == nil || qerr.TranslationKey() != "semantic_error" {
+		t.Errorf("expected semantic_error for the name of a system function, got %v", qerr)
+	}
+	_, _, qerr = Run(qc, `CREATE FUNCTION broken(x) { y }`)
+	if qerr == nil || qerr.TranslationKey() != "semantic_error" {
+		t.Errorf("expected semantic_error for a reference to something else than a parameter, got %v", qerr)
+	}
+
+	r, _, qerr = Run(qc, `SELECT name, parameters, body, language FROM :system.functions`)
+	expected = []interface{}{map[string]interface{}{"name": "celsius", "parameters": []interface{}{"f"}, "body": "(f - 32) * 5 / 9", "language": "n1ql"}}
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+
+	// the functions are defined again from the file after a restart
+	ast.DropFunction("celsius")
+	err = server.LoadFunctions()
+	if err != nil {
+		t.Fatalf("unexpected error loading functions: %v", err)
+	}
+	r, _, qerr = Run(qc, `SELECT celsius(32) AS freezing`)
+	expected = []interface{}{map[string]interface{}{"freezing": 0.0}}
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+
+	_, _, qerr = Run(qc, `DROP FUNCTION celsius`)
+	if qerr != nil {
+		t.Errorf("unexpected error dropping function: %v", qerr)
+	}
+	_, _, qerr = Run(qc, `DROP FUNCTION celsius`)
+	if qerr == nil || qerr.TranslationKey() != "function_not_found" {
+		t.Errorf("expected function_not_found error, got %v", qerr)
+	}
+
+	// deleting from the system bucket drops the function too
+	_, _, qerr = Run(qc, `CREATE FUNCTION fahrenheit(c) { c * 9 / 5 + 32 }`)
+	if qerr != nil {
+		t.Fatalf("unexpected error creating function: %v", qerr)
+	}
+	_, qerr = RunMutation(qc, `DELETE FROM :system.functions WHERE name = "fahrenheit"`)
+	if qerr != nil {
+		t.Errorf("unexpected error deleting function: %v", qerr)
+	}
+	r, _, qerr = Run(qc, `SELECT name FROM :system.functions`)
+	if qerr != nil || len(r) != 0 {
+		t.Errorf("expected no functions, got %v, err: %v", r, qerr)
+	}
+	stored, qerr := system.StoredFunctions()
+	if qerr != nil || len(stored) != 0 {
+		t.Errorf("expected no stored functions, got %v, err: %v", stored, qerr)
+	}
+}
+
+func TestDropCalledFunction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "functions")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	system.FunctionsFile = filepath.Join(dir, "functions.json")
+	defer func() {
+		system.FunctionsFile = ""
+	}()
+
+	qc := start()
+	defer close(qc)
+
+	_, _, qerr := Run(qc, `CREATE FUNCTION g(x) { x * 2 }`)
+	if qerr != nil {
+		t.Fatalf("unexpected error creating function: %v", qerr)
+	}
+	defer ast.DropFunction("g")
+	_, _, qerr = Run(qc, `CREATE FUNCTION h(x) { g(x) + 1 }`)
+	if qerr != nil {
+		t.Fatalf("unexpected error creating function: %v", qerr)
+	}
+	defer ast.DropFunction("h")
+
+	// h could not be defined again after a restart without g
+	_, _, qerr = Run(qc, `DROP FUNCTION g`)
+	if qerr == nil {
+		t.Errorf("expected error dropping a function called by another one")
+	}
+
+	// restart
+	ast.DropFunction("h")
+	ast.DropFunction("g")
+	err = server.LoadFunctions()
+	if err != nil {
+		t.Fatalf("unexpected error loading functions: %v", err)
+	}
+	r, _, qerr := Run(qc, `SELECT h(2) AS h`)
+	expected := []interface{}{map[string]interface{}{"h": 5.0}}
+	if qerr != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, qerr)
+	}
+
+	// once h is gone, g can be dropped
+	_, _, qerr = Run(qc, `DROP FUNCTION h`)
+	if qerr != nil {
+		t.Errorf("unexpected error dropping function: %v", qerr)
+	}
+	_, _, qerr = Run(qc, `DROP FUNCTION g`)
+	if qerr != nil {
+		t.Errorf("unexpected error dropping function: %v", qerr)
+	}
+
+	// a function that cannot be defined again is skipped on restart
+	err = ioutil.WriteFile(system.FunctionsFile, []byte(`[
+		{"name": "h", "parameters": ["x"], "body": "g(x) + 1"},
+		{"name": "g", "parameters": ["x"], "body": "x * 2"}
+	]`), 0666)
+	if err != nil {
+		t.Fatalf("unable to write functions: %v", err)
+	}
+	err = server.LoadFunctions()
+	if err != nil {
+		t.Fatalf("unexpected error loading functions: %v", err)
+	}
+	names := ast.UserFunctionNames()
+	if !reflect.DeepEqual(names, []string{"g"}) {
+		t.Errorf("expected [g], got %v", names)
+	}
+}
+
+func TestStructuredRequests(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	request := network.StructuredQueryRequest{
+		StringQueryRequest: network.StringQueryRequest{QueryString: `SELECT name FROM contacts WHERE name = $1`, QueryArgs: network.QueryArgs{Positional: []interface{}{"dave"}}},
+		ReadOnly:           true,
+		Timeout:            time.Minute,
+	}
+	r, err := RunRequest(qc, request)
+	expected := []interface{}{map[string]interface{}{"name": "dave"}}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	// nothing is changed by a read only request
+	request.QueryString = `DELETE FROM contacts KEYS ["dave"]`
+	_, err = RunRequest(qc, request)
+	if err == nil || err.TranslationKey() != "readonly_violation" {
+		t.Errorf("expected readonly_violation error, got %v", err)
+	}
+	r, _, err = Run(qc, `SELECT name FROM contacts KEYS ["dave"]`)
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	// explaining is reading
+	request.QueryString = `EXPLAIN DELETE FROM contacts KEYS ["dave"]`
+	_, err = RunRequest(qc, request)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the timeout of the request replaces the one of the server
+	request.QueryString = `SELECT * FROM contacts c1 JOIN contacts c2 ON c1.name != c2.name JOIN contacts c3 ON c2.name != c3.name JOIN contacts c4 ON c3.name != c4.name`
+	request.Timeout = time.Nanosecond
+	_, err = RunRequest(qc, request)
+	if err == nil || err.TranslationKey() != "timeout" {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	r, profile, err := RunProfiled(qc, `SELECT name FROM contacts WHERE name = "dave"`)
+	if err != nil || len(r) != 1 {
+		t.Fatalf("expected 1 result, got %v, err: %v", r, err)
+	}
+
+	// every operator down to the scan has its profile
+	fetched := 0.0
+	operators := 0
+	element, _ := profile.(map[string]interface{})
+	for element != nil {
+		stats, ok := element["#profile"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected a profile for %v", element["type"])
+		}
+		if operators == 0 && stats["items_out"] != 1.0 {
+			t.Errorf("expected the root to return 1 item, got %v", stats["items_out"])
+		}
+		if element["type"] == "fetch" {
+			fetched, _ = stats["documents_fetched"].(float64)
+		}
+		operators++
+		element, _ = element["input"].(map[string]interface{})
+	}
+	if operators < 3 || fetched == 0 {
+		t.Errorf("expected a profiled fetch, got %v", profile)
+	}
+
+	// nothing is profiled unless asked for
+	_, profile, _ = RunProfiled(qc, `SELECT name FROM contacts WHERE name = "dave"`)
+	mr := run(qc, `SELECT name FROM contacts WHERE name = "dave"`)
+	if profile == nil || mr.profile != nil {
+		t.Errorf("expected a profile only when asked for, got %v", mr.profile)
+	}
+}
+
+func TestRequestBuckets(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	// the query asking for the active requests is one of them
+	r, _, err := Run(qc, `SELECT statement, phase FROM :system.active_requests WHERE statement LIKE "%phase%"`)
+	expected := []interface{}{map[string]interface{}{"statement": `SELECT statement, phase FROM :system.active_requests WHERE statement LIKE "%phase%"`, "phase": "executing"}}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	// the requests are kept for the whole process, so those of this
+	// run are told apart from earlier ones, and from the query below
+	marker := fmt.Sprintf("requested_%d", time.Now().UnixNano())
+	_, _, err = Run(qc, `SELECT name AS `+marker+` FROM contacts WHERE name = "fred" OR name = "dave"`)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, _, err = Run(qc, `SELECT name AS `+marker+` FROM contacts WHERE nothing(name)`)
+	if err == nil {
+		t.Errorf("expected error for unknown function")
+	}
+
+	r, _, err = Run(qc, `SELECT result_count, error_count FROM :system.completed_requests WHERE statement LIKE "%AS `+marker+`%" AND statement NOT LIKE "%completed_requests%" ORDER BY start_time`)
+	expected = []interface{}{
+		map[string]interface{}{"result_count": 2.0, "error_count": 0.0},
+		map[string]interface{}{"result_count": 0.0, "error_count": 1.0},
+	}
+	if err != nil || !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v, err: %v", expected, r, err)
+	}
+
+	r, _, err = Run(qc, `SELECT id FROM :system.active_requests WHERE id IS VALUED AND elapsed_time IS VALUED AND start_time IS VALUED`)
+	if err != nil || len(r) != 1 {
+		t.Errorf("expected one active request, got %v, err: %v", r, err)
+	}
+}
+
+// a response stuck on its first result, like a slow client
+type stuckResponse struct {
+	*MockResponse
+	stuck chan bool
+}
+
+func (this *stuckResponse) SendResult(val interface{}) {
+	<-this.stuck
+	this.MockResponse.SendResult(val)
+}
+
+type stuckQuery struct {
+	MockQuery
+	response *stuckResponse
+}
+
+func newStuckQuery(statement string) *stuckQuery {
+	mr := &MockResponse{results: []interface{}{}, done: make(chan bool)}
+	return &stuckQuery{
+		MockQuery: MockQuery{request: network.StringQueryRequest{QueryString: statement}, startTime: time.Now()},
+		response:  &stuckResponse{MockResponse: mr, stuck: make(chan bool)},
+	}
+}
+
+func (this *stuckQuery) Response() network.QueryResponse {
+	return this.response
+}
+
+// waits for a worker of the server to take the query
+func waitUntilActive(t *testing.T, statement string) {
+	for i := 0; i < 1000; i++ {
+		for _, id := range network.Requests.ActiveIds() {
+			doc, ok := network.Requests.Active(id)
+			if ok && doc["statement"] == statement {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("request %v never became active", statement)
+}
+
+func TestCancelRequests(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	stuck := newStuckQuery(`SELECT name AS stuck FROM contacts`)
+	mr := stuck.response.MockResponse
+	qc <- stuck
+	waitUntilActive(t, `SELECT name AS stuck FROM contacts`)
+
+	count, err := RunMutation(qc, `DELETE FROM :system.active_requests WHERE statement = "SELECT name AS stuck FROM contacts"`)
+	if err != nil || count != 1 {
+		t.Errorf("expected to cancel 1 request, got %v, err: %v", count, err)
+	}
+
+	close(stuck.response.stuck)
+	<-mr.done
+	if mr.err == nil || mr.err.TranslationKey() != "request_cancelled" {
+		t.Errorf("expected request_cancelled error, got %v", mr.err)
+	}
+
+	// it is gone once it was stopped
+	r, _, err := Run(qc, `SELECT id FROM :system.active_requests WHERE statement = "SELECT name AS stuck FROM contacts"`)
+	if err != nil || len(r) != 0 {
+		t.Errorf("expected no stuck request, got %v, err: %v", r, err)
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	defer func(workers, queueSize int, limits map[string]int) {
+		server.Workers = workers
+		server.QueueSize = queueSize
+		server.PoolLimits = limits
+	}(server.Workers, server.QueueSize, server.PoolLimits)
+
+	// one query running and one waiting fill the server
+	server.Workers = 1
+	server.QueueSize = 1
+	qc := start()
+
+	first := newStuckQuery(`SELECT name AS earlier FROM contacts`)
+	qc <- first
+	waitUntilActive(t, `SELECT name AS earlier FROM contacts`)
+	second := newStuckQuery(`SELECT name AS later FROM contacts`)
+	qc <- second
+
+	_, _, err := Run(qc, `SELECT name FROM contacts`)
+	if err == nil || err.TranslationKey() != "queue_full" {
+		t.Errorf("expected queue_full error, got %v", err)
+	}
+
+	close(first.response.stuck)
+	<-first.response.done
+	close(second.response.stuck)
+	<-second.response.done
+	if first.response.err != nil || second.response.err != nil {
+		t.Errorf("unexpected errors: %v, %v", first.response.err, second.response.err)
+	}
+	close(qc)
+
+	// one query running against the pool of contacts fills it
+	server.Workers = 4
+	server.QueueSize = 4
+	server.PoolLimits = map[string]int{"json": 1}
+	qc = start()
+	defer close(qc)
+
+	first = newStuckQuery(`SELECT name AS earlier FROM contacts`)
+	qc <- first
+
+	waitUntilActive(t, `SELECT name AS earlier FROM contacts`)
+
+	_, _, err = Run(qc, `SELECT name FROM contacts`)
+	if err == nil || err.TranslationKey() != "pool_busy" {
+		t.Errorf("expected pool_busy error, got %v", err)
+	}
+	// other pools are not limited
+	_, _, err = Run(qc, `SELECT name FROM :system.pools`)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	close(first.response.stuck)
+	<-first.response.done
+	_, _, err = Run(qc, `SELECT name FROM contacts`)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParameterKeys(t *testing.T) {
+	qc := start()
+	defer close(qc)
+
+	positional := func(args ...interface{}) network.QueryArgs {
+		return network.QueryArgs{Positional: args}
+	}
+	tests := []struct {
+		q        string
+		args     network.QueryArgs
+		expected []interface{}
+	}{
+		{`SELECT META().id AS id FROM contacts KEYS $1`, positional([]interface{}{"dave", "earl"}),
+			[]interface{}{map[string]interface{}{"id": "dave"}, map[string]interface{}{"id": "earl"}}},
+		{`SELECT META().id AS id FROM contacts KEY $1`, positional("fred"),
+			[]interface{}{map[string]interface{}{"id": "fred"}}},
+		{`SELECT META().id AS id FROM contacts KEY $1`, positional("nobody"),
+			[]interface{}{}},
+		// parameters have one value for every group
+		{`SELECT name, $1 AS x FROM contacts KEY "dave" GROUP BY name`, positional("y"),
+			[]interface{}{map[string]interface{}{"name": "dave", "x": "y"}}},
+	}
+	for _, x := range tests {
+		r, err := RunWithArgs(qc, x.q, x.args)
+		if err != nil || !reflect.DeepEqual(r, x.expected) {
+			t.Errorf("expected %v for %v, got %v, err: %v", x.expected, x.q, r, err)
+		}
+	}
+
+	r, err := RunWithArgs(qc, `EXPLAIN UPDATE contacts KEY $1 SET x = 1`, positional("dave"))
+	if err != nil || len(r) != 1 {
+		t.Errorf("expected a plan for UPDATE with a KEY parameter, got %v, err: %v", r, err)
+	}
+
+	// the values still have to be keys
+	_, err = RunWithArgs(qc, `SELECT META().id AS id FROM contacts KEY $1`, positional([]interface{}{"dave"}))
+	if err == nil {
+		t.Errorf("expected error for KEY given multiple values")
+	}
+	_, err = RunWithArgs(qc, `SELECT META().id AS id FROM contacts KEYS $1`, positional([]interface{}{1.0}))
+	if err == nil {
+		t.Errorf("expected error for KEYS that are not strings")
+	}
+}
+
 func TestAllCaseFiles(t *testing.T) {
 	qc := start()
 	defer close(qc)
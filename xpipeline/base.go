@@ -140,10 +140,25 @@ func (this *BaseOperator) SetQuery(q network.Query) {
 
 func (this *BaseOperator) Evaluate(e ast.Expression, item *dparval.Value) (*dparval.Value, error) {
 	// first ensure the query is avaliable
-	item.SetAttachment("query", this.query)
+	q := this.itemQuery()
+	item.SetAttachment("query", q)
+	subqueryQuery, ok := q.(*SubqueryQuery)
+	if ok {
+		subqueryQuery.bindOuter(item)
+	}
 	return e.Evaluate(item)
 }
 
+// the query attached to the items this operator evaluates expressions
+// over, subqueries evaluated with it stop when this operator is stopped
+func (this *BaseOperator) itemQuery() network.Query {
+	subqueryQuery, ok := this.query.(*SubqueryQuery)
+	if ok {
+		return subqueryQuery.stoppedBy(this.downstreamStopChannel)
+	}
+	return this.query
+}
+
 func (this *BaseOperator) projectedValueOfResultExpression(item *dparval.Value, resultExpr *ast.ResultExpression) (*dparval.Value, error) {
 
 	if resultExpr.Star {
@@ -0,0 +1,70 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// items leaving a set operation are their own projection, this
+// allows rows from either side to be compared and ordered alike
+// the key returned identifies equal rows, it is their JSON encoding
+func compoundItem(item *dparval.Value) (*dparval.Value, string) {
+	projection, _ := item.GetAttachment("projection").(*dparval.Value)
+	if projection == nil {
+		return nil, ""
+	}
+	projection.SetAttachment("projection", projection)
+	projection.SetAttachment("meta", item.GetAttachment("meta"))
+	return projection, string(projection.Bytes())
+}
+
+// runs the pipeline of a compound term (or the other side
+// of a join) to completion passing each of its items to the
+// handler and forwarding everything else downstream, returns
+// false if it had to stop early, also when the operator running
+// it is stopped
+func runCompoundTerm(base *BaseOperator, term Operator, handler func(item *dparval.Value) bool) bool {
+	termStopChannel := make(misc.StopChannel)
+	defer close(termStopChannel)
+
+	go term.Run(termStopChannel)
+
+	var item *dparval.Value
+	var obj interface{}
+	itemChannel, supportChannel := term.GetChannels()
+	ok := true
+	stopped := false
+	for ok {
+		select {
+		case item, ok = <-itemChannel:
+			if ok {
+				ok = handler(item)
+				stopped = !ok
+			}
+		case obj, ok = <-supportChannel:
+			if ok {
+				switch obj := obj.(type) {
+				case query.Error:
+					ok = base.SendError(obj)
+				default:
+					ok = base.SendOther(obj)
+				}
+				stopped = !ok
+			}
+		case _, ok = <-base.downstreamStopChannel:
+			// downstream has asked us to stop
+			stopped = !ok
+		}
+	}
+	return !stopped
+}
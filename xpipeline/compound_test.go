@@ -0,0 +1,85 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+)
+
+func compoundTestData(names ...string) dparval.ValueCollection {
+	rv := dparval.ValueCollection{}
+	for i, name := range names {
+		doc := dparval.NewValue(map[string]interface{}{
+			"name": name,
+			"age":  float64(i),
+		})
+		doc.SetAttachment("projection", dparval.NewValue(map[string]interface{}{"name": name}))
+		rv = append(rv, doc)
+	}
+	return rv
+}
+
+func runCompoundOperator(op Operator) []string {
+	itemChannel, _ := op.GetChannels()
+
+	stopChannel := make(misc.StopChannel)
+	go op.Run(stopChannel)
+
+	rv := []string{}
+	for item := range itemChannel {
+		name, err := item.Path("name")
+		if err == nil {
+			rv = append(rv, name.Value().(string))
+		}
+	}
+	return rv
+}
+
+func TestCompoundOperators(t *testing.T) {
+
+	tests := []struct {
+		op       Operator
+		left     []string
+		expected []string
+	}{
+		{NewUnion(NewStubSource(compoundTestData("b", "c")), false), []string{"a", "b", "a"}, []string{"a", "b", "c"}},
+		{NewUnion(NewStubSource(compoundTestData("b", "c")), true), []string{"a", "b", "a"}, []string{"a", "b", "a", "b", "c"}},
+		{NewIntersect(NewStubSource(compoundTestData("b", "a", "b")), false), []string{"a", "b", "b", "c"}, []string{"a", "b"}},
+		{NewIntersect(NewStubSource(compoundTestData("b", "a", "b")), true), []string{"a", "b", "b", "b", "c"}, []string{"a", "b", "b"}},
+		{NewExcept(NewStubSource(compoundTestData("b")), false), []string{"a", "b", "c", "b", "a"}, []string{"a", "c"}},
+		{NewExcept(NewStubSource(compoundTestData("b")), true), []string{"a", "b", "c", "b", "a"}, []string{"a", "c", "b", "a"}},
+	}
+
+	for _, x := range tests {
+		x.op.SetSource(NewStubSource(compoundTestData(x.left...)))
+		result := runCompoundOperator(x.op)
+		if len(result) != len(x.expected) {
+			t.Errorf("Expected %v, got %v", x.expected, result)
+			continue
+		}
+		for i := range result {
+			if result[i] != x.expected[i] {
+				t.Errorf("Expected %v, got %v", x.expected, result)
+				break
+			}
+		}
+	}
+}
+
+func TestCompoundTermStops(t *testing.T) {
+	// nothing is sent while the term is read, so only
+	// the stop channel can end it
+	op := NewExcept(NewEndlessSource(compoundTestData("b")[0]), false)
+	op.SetSource(NewStubSource(compoundTestData("a")))
+	expectStopped(t, "EXCEPT", op)
+}
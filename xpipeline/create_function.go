@@ -0,0 +1,114 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog/system"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type CreateFunction struct {
+	itemChannel           dparval.ValueChannel
+	supportChannel        PipelineSupportChannel
+	definition            *ast.FunctionDefinition
+	downstreamStopChannel misc.StopChannel
+	query                 network.Query
+}
+
+func NewCreateFunction(definition *ast.FunctionDefinition) *CreateFunction {
+	return &CreateFunction{
+		itemChannel:    make(dparval.ValueChannel),
+		supportChannel: make(PipelineSupportChannel),
+		definition:     definition,
+	}
+}
+
+func (this *CreateFunction) SetSource(source Operator) {}
+
+func (this *CreateFunction) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.itemChannel, this.supportChannel
+}
+
+func (this *CreateFunction) Run(stopChannel misc.StopChannel) {
+	defer close(this.itemChannel)
+	defer close(this.supportChannel)
+	// this MUST be here so that it runs before the channels are closed
+	defer this.RecoverPanic()
+
+	this.downstreamStopChannel = stopChannel
+	clog.To(CHANNEL, "create_function operator starting")
+	err := system.DefineFunction(this.definition)
+	if err != nil {
+		this.SendError(err)
+	} else {
+		item := dparval.NewValue(map[string]interface{}{})
+		item.SetAttachment("projection", dparval.NewValue(map[string]interface{}{
+			"name":    this.definition.Name,
+			"created": true,
+		}))
+		this.SendItem(item)
+	}
+	clog.To(CHANNEL, "create_function operator finished")
+}
+
+func (this *CreateFunction) processItem(item *dparval.Value) bool {
+	return true
+}
+
+func (this *CreateFunction) afterItems() {}
+
+func (this *CreateFunction) SendItem(item *dparval.Value) bool {
+	ok := true
+	for ok {
+		select {
+		case this.itemChannel <- item:
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return ok
+}
+
+func (this *CreateFunction) SendError(err query.Error) bool {
+	ok := true
+	for ok {
+		select {
+		case this.supportChannel <- err:
+			if err.IsFatal() {
+				return false
+			}
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return false
+}
+
+func (this *CreateFunction) RecoverPanic() {
+	r := recover()
+	if r != nil {
+		clog.Error(fmt.Errorf("Query Execution Panic: %v\n%s", r, debug.Stack()))
+		this.SendError(query.NewError(nil, "Panic In Exeuction Pipeline"))
+	}
+}
+
+func (this *CreateFunction) SetQuery(q network.Query) {
+	this.query = q
+}
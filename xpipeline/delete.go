@@ -0,0 +1,67 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+type Delete struct {
+	mutationCounter
+	bucket catalog.Bucket
+	as     string
+}
+
+func NewDelete(bucket catalog.Bucket, as string) *Delete {
+	return &Delete{
+		mutationCounter: mutationCounter{Base: NewBaseOperator()},
+		bucket:          bucket,
+		as:              as,
+	}
+}
+
+func (this *Delete) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *Delete) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Delete) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "delete operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "delete operator finished, deleted %d", this.count)
+}
+
+func (this *Delete) processItem(item *dparval.Value) bool {
+	id, _, err := mutationKey(item, this.as)
+	if err != nil {
+		return this.fail(err)
+	}
+	err = this.bucket.Delete(id)
+	if err != nil {
+		return this.fail(err)
+	}
+	this.mutated()
+	return true
+}
+
+func (this *Delete) afterItems() {
+	this.report()
+}
+
+func (this *Delete) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
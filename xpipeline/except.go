@@ -0,0 +1,100 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+// sends the items of its source which are not produced by the term
+// with All set, each row produced by the term removes only one
+// matching row, otherwise matching rows are removed entirely
+// and the remaining rows are sent once
+type Except struct {
+	Base   *BaseOperator
+	Term   Operator
+	All    bool
+	buffer dparval.ValueCollection
+	keys   []string
+	counts map[string]int
+}
+
+func NewExcept(term Operator, all bool) *Except {
+	return &Except{
+		Base:   NewBaseOperator(),
+		Term:   term,
+		All:    all,
+		buffer: make(dparval.ValueCollection, 0),
+		keys:   make([]string, 0),
+		counts: make(map[string]int),
+	}
+}
+
+func (this *Except) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *Except) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Except) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "except operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "except operator finished")
+}
+
+func (this *Except) processItem(item *dparval.Value) bool {
+	item, key := compoundItem(item)
+	if item != nil {
+		this.buffer = append(this.buffer, item)
+		this.keys = append(this.keys, key)
+	}
+	return true
+}
+
+func (this *Except) afterItems() {
+	// count the rows produced by the term
+	runCompoundTerm(this.Base, this.Term, func(item *dparval.Value) bool {
+		_, key := compoundItem(item)
+		this.counts[key]++
+		return true
+	})
+
+	// write the output
+	sent := make(map[string]bool)
+	for i, item := range this.buffer {
+		key := this.keys[i]
+		if this.All {
+			if this.counts[key] > 0 {
+				this.counts[key]--
+				continue
+			}
+		} else {
+			if this.counts[key] > 0 || sent[key] {
+				continue
+			}
+			sent[key] = true
+		}
+		if !this.Base.SendItem(item) {
+			break
+		}
+	}
+	this.buffer = make(dparval.ValueCollection, 0)
+	this.keys = make([]string, 0)
+	this.counts = make(map[string]int)
+}
+
+func (this *Except) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
@@ -27,14 +27,18 @@ type Explain struct {
 	supportChannel        PipelineSupportChannel
 	downstreamStopChannel misc.StopChannel
 	Plan                  plan.PlanElement
+	Cost                  *plan.Cost
+	Candidates            []*plan.Candidate
+	Indexes               []*plan.IndexChoice
 	query                 network.Query
 }
 
-func NewExplain(plan plan.PlanElement) *Explain {
+func NewExplain(plan plan.PlanElement, cost *plan.Cost) *Explain {
 	return &Explain{
 		itemChannel:    make(dparval.ValueChannel),
 		supportChannel: make(PipelineSupportChannel),
 		Plan:           plan,
+		Cost:           cost,
 	}
 }
 
@@ -59,6 +63,20 @@ func (this *Explain) Run(stopChannel misc.StopChannel) {
 		this.SendError(query.NewError(err, "error serializing plan to JSON"))
 	} else {
 		projection := dparval.NewValueFromBytes(planBytes)
+		if this.Cost != nil {
+			// the estimate of the optimizer for the whole plan
+			projection.SetPath("cost", map[string]interface{}{
+				"cost":        this.Cost.Cost,
+				"cardinality": this.Cost.Cardinality,
+			})
+		}
+		// EXPLAIN VERBOSE
+		if len(this.Candidates) > 0 {
+			projection.SetPath("candidates", jsonValues(this.Candidates))
+		}
+		if len(this.Indexes) > 0 {
+			projection.SetPath("indexes", jsonValues(this.Indexes))
+		}
 		item.SetAttachment("projection", projection)
 		this.SendItem(item)
 	}
@@ -66,6 +84,16 @@ func (this *Explain) Run(stopChannel misc.StopChannel) {
 	clog.To(CHANNEL, "explain operator finished")
 }
 
+// the value as plain json values, as the projection expects them
+func jsonValues(value interface{}) interface{} {
+	var rv interface{}
+	bytes, err := json.Marshal(value)
+	if err == nil {
+		json.Unmarshal(bytes, &rv)
+	}
+	return rv
+}
+
 func (this *Explain) processItem(item *dparval.Value) bool {
 	return true
 }
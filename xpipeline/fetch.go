@@ -21,6 +21,9 @@ import (
 
 const FETCH_BATCH_SIZE = 1000
 
+// the number of bulk fetches a fetch operator keeps in flight
+var FetchParallelism = 1
+
 type Fetch struct {
 	Base        *BaseOperator
 	bucket      catalog.Bucket
@@ -29,6 +32,22 @@ type Fetch struct {
 	as          string
 	ids         []string
 	rowsFetched int
+	// when the batches are fetched in parallel, keep the order of the
+	// items for the operators that need it
+	ordered  bool
+	results  chan *fetchResult
+	inflight int
+	next     int
+	sent     int
+	done     map[int]*fetchResult
+}
+
+// the response to the bulk fetch of a batch
+type fetchResult struct {
+	sequence int
+	ids      []string
+	docs     map[string]*dparval.Value
+	err      query.Error
 }
 
 func NewFetch(bucket catalog.Bucket, projection ast.Expression, as string) *Fetch {
@@ -49,6 +68,10 @@ func (this *Fetch) SetIds(ids []string) {
 	this.ids = ids
 }
 
+func (this *Fetch) SetOrdered(ordered bool) {
+	this.ordered = ordered
+}
+
 func (this *Fetch) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
 	return this.Base.GetChannels()
 }
@@ -85,6 +108,11 @@ func (this *Fetch) processItem(item *dparval.Value) bool {
 func (this *Fetch) afterItems() {
 	// source may have closed with a parital batch
 	this.flushBatch()
+
+	// wait for the batches still in flight
+	for this.inflight > 0 {
+		this.receiveResult()
+	}
 }
 
 func (this *Fetch) flushBatch() bool {
@@ -95,6 +123,10 @@ func (this *Fetch) flushBatch() bool {
 		this.batch = make(dparval.ValueCollection, 0, FETCH_BATCH_SIZE)
 	}()
 
+	if len(this.batch) == 0 {
+		return true
+	}
+
 	// gather the ids
 	ids := make([]string, 0, FETCH_BATCH_SIZE)
 	for _, v := range this.batch {
@@ -111,12 +143,78 @@ func (this *Fetch) flushBatch() bool {
 		}
 	}
 
-	// now do a bulk fetch
-	bulkResponse, err := this.bucket.BulkFetch(ids)
-	if err != nil {
-		return this.Base.SendError(query.NewError(err, "error getting bulk response"))
+	if FetchParallelism <= 1 {
+		// now do a bulk fetch
+		bulkResponse, err := this.bucket.BulkFetch(ids)
+		if err != nil {
+			return this.Base.SendError(query.NewError(err, "error getting bulk response"))
+		}
+		return this.sendFetched(ids, bulkResponse)
+	}
+
+	if this.results == nil {
+		this.results = make(chan *fetchResult, FetchParallelism)
+		this.done = make(map[int]*fetchResult)
 	}
 
+	// the batches fetched but not sent yet count
+	// too, so that an ordered fetch holds at most
+	// FetchParallelism batches in memory
+	ok := true
+	for ok && this.inflight+len(this.done) >= FetchParallelism {
+		ok = this.receiveResult()
+	}
+	if !ok {
+		return false
+	}
+
+	this.inflight++
+	go func(sequence int) {
+		result := &fetchResult{sequence: sequence, ids: ids}
+		bulkResponse, err := this.bucket.BulkFetch(ids)
+		if err != nil {
+			result.err = query.NewError(err, "error getting bulk response")
+		} else {
+			result.docs = bulkResponse
+		}
+		// never blocks, there is room for every batch in flight
+		this.results <- result
+	}(this.next)
+	this.next++
+	return true
+}
+
+// waits for a batch in flight and sends what can be sent, which is
+// every batch in the order they were fetched when the fetch is ordered
+func (this *Fetch) receiveResult() bool {
+	result := <-this.results
+	this.inflight--
+	if !this.ordered {
+		return this.sendResult(result)
+	}
+
+	this.done[result.sequence] = result
+	ok := true
+	for ok {
+		result, found := this.done[this.sent]
+		if !found {
+			break
+		}
+		delete(this.done, this.sent)
+		this.sent++
+		ok = this.sendResult(result)
+	}
+	return ok
+}
+
+func (this *Fetch) sendResult(result *fetchResult) bool {
+	if result.err != nil {
+		return this.Base.SendError(result.err)
+	}
+	return this.sendFetched(result.ids, result.docs)
+}
+
+func (this *Fetch) sendFetched(ids []string, bulkResponse map[string]*dparval.Value) bool {
 	// now we need to emit the bulk fetched items in the correct order (from the id list)
 	for _, v := range ids {
 		item, ok := bulkResponse[v]
@@ -151,6 +249,10 @@ func (this *Fetch) flushBatch() bool {
 	return true
 }
 
+func (this *Fetch) documentsFetched() int {
+	return this.rowsFetched
+}
+
 func (this *Fetch) SetQuery(q network.Query) {
 	this.Base.SetQuery(q)
 }
@@ -10,6 +10,8 @@
 package xpipeline
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/couchbaselabs/tuqtng/catalog"
@@ -69,3 +71,53 @@ func TestFetch(t *testing.T) {
 	}
 
 }
+
+func TestParallelFetch(t *testing.T) {
+	// a few batches
+	mocksite, err := mock.NewSite("mock:items=3500")
+	if err != nil {
+		t.Fatalf("Error creating mock site")
+	}
+	pool, _ := mocksite.PoolByName("p0")
+	bucket, _ := pool.BucketByName("b0")
+	index, _ := bucket.IndexByName("all_docs")
+
+	fetchValues := func(parallelism int, ordered bool) []float64 {
+		defer func(saved int) { FetchParallelism = saved }(FetchParallelism)
+		FetchParallelism = parallelism
+
+		fetch := NewFetch(bucket, nil, "")
+		fetch.SetSource(NewScan(bucket, index.(catalog.ScanIndex), nil, ""))
+		fetch.SetOrdered(ordered)
+		fetchItemChannel, _ := fetch.GetChannels()
+		go fetch.Run(make(misc.StopChannel))
+
+		values := []float64{}
+		for item := range fetchItemChannel {
+			i, err := item.Path("i")
+			if err != nil {
+				t.Fatalf("Expected item to contain value at path i")
+			}
+			values = append(values, i.Value().(float64))
+		}
+		if fetch.documentsFetched() != len(values) {
+			t.Errorf("Expected %d documents fetched, got %d", len(values), fetch.documentsFetched())
+		}
+		return values
+	}
+
+	sequential := fetchValues(1, false)
+	if len(sequential) != 3500 {
+		t.Fatalf("Expected %d items, got %d", 3500, len(sequential))
+	}
+	ordered := fetchValues(3, true)
+	if !reflect.DeepEqual(ordered, sequential) {
+		t.Errorf("Expected the order of a sequential fetch")
+	}
+	unordered := fetchValues(3, false)
+	sort.Float64s(unordered)
+	sort.Float64s(sequential)
+	if !reflect.DeepEqual(unordered, sequential) {
+		t.Errorf("Expected the items of a sequential fetch")
+	}
+}
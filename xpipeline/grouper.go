@@ -10,6 +10,9 @@
 package xpipeline
 
 import (
+	"hash/fnv"
+	"os"
+
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/dparval"
 	"github.com/couchbaselabs/tuqtng/ast"
@@ -18,19 +21,39 @@ import (
 	"github.com/couchbaselabs/tuqtng/query"
 )
 
+// the memory, in bytes, the groups of one GROUP BY may use before
+// the items of new groups are written to disk in partitions, which
+// are aggregated one at a time once all the items have arrived
+var GroupMemory int64 = 64 * 1024 * 1024
+
+// the number of partitions the items of new groups are spread over,
+// and how many times a partition too large for memory is split again
+const (
+	GROUP_PARTITIONS = 8
+	GROUP_MAX_DEPTH  = 4
+)
+
+// the memory we guess the state of one aggregate uses
+const AGGREGATE_SIZE = 64
+
 type Grouper struct {
 	Base       *BaseOperator
 	GroupBy    ast.ExpressionList
-	groups     map[string]*dparval.Value
+	groups     *groupTable
 	Aggregates ast.ExpressionList
 	groupAll   bool
+	streaming  bool
+	// the group being aggregated when streaming
+	current    *dparval.Value
+	currentKey string
+	spills     int
 }
 
 func NewGrouper(groupBy ast.ExpressionList, aggs ast.ExpressionList) *Grouper {
 	rv := &Grouper{
 		Base:       NewBaseOperator(),
 		GroupBy:    groupBy,
-		groups:     make(map[string]*dparval.Value),
+		groups:     newGroupTable(0),
 		Aggregates: aggs,
 	}
 	if len(groupBy) == 0 {
@@ -41,6 +64,12 @@ func NewGrouper(groupBy ast.ExpressionList, aggs ast.ExpressionList) *Grouper {
 	return rv
 }
 
+// the items will arrive ordered by the group key, so each
+// group is complete as soon as an item of the next one arrives
+func (this *Grouper) SetStreaming() {
+	this.streaming = true
+}
+
 func (this *Grouper) SetSource(source Operator) {
 	this.Base.SetSource(source)
 }
@@ -52,10 +81,32 @@ func (this *Grouper) GetChannels() (dparval.ValueChannel, PipelineSupportChannel
 func (this *Grouper) Run(stopChannel misc.StopChannel) {
 	clog.To(CHANNEL, "group operator starting")
 	this.Base.RunOperator(this, stopChannel)
-	clog.To(CHANNEL, "group operator finished")
+	clog.To(CHANNEL, "group operator finished, spilled %d partitions", this.spills)
 }
 
 func (this *Grouper) processItem(item *dparval.Value) bool {
+	groupkeystring, err := this.groupKey(item)
+	if err != nil {
+		return this.Base.SendError(err)
+	}
+
+	if this.streaming {
+		if this.current == nil || groupkeystring != this.currentKey {
+			if this.current != nil && !this.Base.SendItem(this.current) {
+				return false
+			}
+			this.current = item
+			this.currentKey = groupkeystring
+			this.setGroupDefaults(item)
+		}
+		this.updateGroup(this.current, item)
+		return true
+	}
+
+	return this.addItem(this.groups, groupkeystring, item)
+}
+
+func (this *Grouper) groupKey(item *dparval.Value) (string, query.Error) {
 	groupkey := dparval.NewValue(make([]interface{}, len(this.GroupBy)))
 	for i, groupElement := range this.GroupBy {
 		groupkeyval, err := this.Base.Evaluate(groupElement, item)
@@ -67,18 +118,30 @@ func (this *Grouper) processItem(item *dparval.Value) bool {
 				// FIXME better way?
 				groupkey.SetIndex(i, "__tuqtng__MISSING__")
 			default:
-				return this.Base.SendError(query.NewError(err, "error evaluating group by"))
+				return "", query.NewError(err, "error evaluating group by")
 			}
 		}
 	}
 	// FIXME slow, but lets me use map to match same groups
 	groupkeybytes := groupkey.Bytes()
-	groupkeystring := string(groupkeybytes)
+	return string(groupkeybytes), nil
+}
 
-	group, ok := this.groups[groupkeystring]
+// the item goes to its group if we have it in memory, or starts a new
+// group while there is memory left, otherwise it is written to a partition
+func (this *Grouper) addItem(table *groupTable, groupkeystring string, item *dparval.Value) bool {
+	group, ok := table.groups[groupkeystring]
 	if !ok {
+		if table.size > GroupMemory && table.depth < GROUP_MAX_DEPTH {
+			err := table.spill(groupkeystring, item)
+			if err != nil {
+				return this.Base.SendError(err)
+			}
+			return true
+		}
 		// new group
-		this.groups[groupkeystring] = item
+		table.groups[groupkeystring] = item
+		table.size += int64(len(groupkeystring)) + approximateSize(item) + AGGREGATE_SIZE*int64(len(this.Aggregates))
 		group = item
 		this.setGroupDefaults(group)
 	}
@@ -113,18 +176,158 @@ func (this *Grouper) updateGroup(group *dparval.Value, val *dparval.Value) {
 }
 
 func (this *Grouper) afterItems() {
-	for _, group := range this.groups {
-		this.Base.SendItem(group)
+	if this.streaming {
+		if this.current != nil {
+			this.Base.SendItem(this.current)
+		}
+		return
 	}
 
-	if this.groupAll && len(this.groups) == 0 {
+	if this.groupAll && len(this.groups.groups) == 0 {
 		// need to report correctly on the empty group
 		group := dparval.NewValue(map[string]interface{}{})
 		this.setGroupDefaults(group)
 		this.Base.SendItem(group)
+		return
 	}
+
+	this.sendGroups(this.groups)
+}
+
+// sends the groups in memory, then aggregates the partitions on disk,
+// returns false if the rest of the pipeline stopped
+func (this *Grouper) sendGroups(table *groupTable) bool {
+	defer table.removePartitions()
+
+	for _, group := range table.groups {
+		if !this.Base.SendItem(group) {
+			return false
+		}
+	}
+	table.groups = nil
+
+	partitions, err := table.finishPartitions()
+	if err != nil {
+		this.Base.SendError(err)
+		return false
+	}
+	this.spills += len(partitions)
+	for _, name := range partitions {
+		if !this.aggregatePartition(name, table.depth+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *Grouper) aggregatePartition(name string, depth int) bool {
+	reader, err := openSpillReader(name)
+	if err != nil {
+		this.Base.SendError(err)
+		return false
+	}
+	defer reader.close()
+
+	table := newGroupTable(depth)
+	for {
+		var spilled spilledItem
+		ok, err := reader.read(&spilled)
+		if err != nil {
+			table.removePartitions()
+			this.Base.SendError(err)
+			return false
+		}
+		if !ok {
+			break
+		}
+		item := spilled.item()
+		groupkeystring, err := this.groupKey(item)
+		if err != nil {
+			ok = this.Base.SendError(err)
+		} else {
+			ok = this.addItem(table, groupkeystring, item)
+		}
+		if !ok {
+			table.removePartitions()
+			return false
+		}
+	}
+	return this.sendGroups(table)
 }
 
 func (this *Grouper) SetQuery(q network.Query) {
 	this.Base.SetQuery(q)
 }
+
+// the groups being aggregated in memory, and the partitions
+// holding the items of the groups that did not fit
+type groupTable struct {
+	groups     map[string]*dparval.Value
+	size       int64
+	depth      int
+	partitions []*spillWriter
+	names      []string
+}
+
+func newGroupTable(depth int) *groupTable {
+	return &groupTable{
+		groups: make(map[string]*dparval.Value),
+		depth:  depth,
+	}
+}
+
+func (this *groupTable) spill(groupkeystring string, item *dparval.Value) query.Error {
+	if this.partitions == nil {
+		this.partitions = make([]*spillWriter, GROUP_PARTITIONS)
+	}
+
+	// the depth is hashed too, so a partition split
+	// again does not put everything in the same place
+	hash := fnv.New32a()
+	hash.Write([]byte{byte(this.depth)})
+	hash.Write([]byte(groupkeystring))
+	partition := hash.Sum32() % GROUP_PARTITIONS
+
+	writer := this.partitions[partition]
+	if writer == nil {
+		var err query.Error
+		writer, err = newSpillWriter("tuqtng-group-")
+		if err != nil {
+			return err
+		}
+		this.partitions[partition] = writer
+	}
+	return writer.write(newSpilledItem(item))
+}
+
+// closes the partitions written and returns their names
+func (this *groupTable) finishPartitions() ([]string, query.Error) {
+	for i, writer := range this.partitions {
+		if writer == nil {
+			continue
+		}
+		this.partitions[i] = nil
+		name, err := writer.finish()
+		if err != nil {
+			return nil, err
+		}
+		this.names = append(this.names, name)
+	}
+	return this.names, nil
+}
+
+func (this *groupTable) removePartitions() {
+	for _, writer := range this.partitions {
+		if writer != nil {
+			writer.discard()
+		}
+	}
+	this.partitions = nil
+	for _, name := range this.names {
+		err := os.Remove(name)
+		if err != nil {
+			clog.Error(err)
+		}
+	}
+	this.names = nil
+}
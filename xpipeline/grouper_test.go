@@ -0,0 +1,145 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+)
+
+// items with the numbers 0 to groups-1 in k, each appearing perGroup times
+func groupTestData(groups, perGroup int, ordered bool) dparval.ValueCollection {
+	rv := make(dparval.ValueCollection, 0, groups*perGroup)
+	for i := 0; i < groups*perGroup; i++ {
+		k := i % groups
+		if ordered {
+			k = i / perGroup
+		}
+		rv = append(rv, dparval.NewValue(map[string]interface{}{"k": float64(k), "n": 1.0}))
+	}
+	return rv
+}
+
+// runs the grouper summing n, returns the sums by k in the order they arrived
+func runGrouper(grouper *Grouper, sum ast.Expression, data dparval.ValueCollection) ([]float64, map[float64]float64, error) {
+	grouper.SetSource(NewStubSource(data))
+	itemChannel, supportChannel := grouper.GetChannels()
+	go grouper.Run(make(misc.StopChannel))
+
+	keys := []float64{}
+	sums := map[float64]float64{}
+	var err error
+	ok := true
+	for ok {
+		select {
+		case item, more := <-itemChannel:
+			if !more {
+				ok = false
+				break
+			}
+			k, kerr := item.Path("k")
+			if kerr != nil {
+				return nil, nil, kerr
+			}
+			s, serr := sum.Evaluate(item)
+			if serr != nil {
+				return nil, nil, serr
+			}
+			keys = append(keys, k.Value().(float64))
+			sums[k.Value().(float64)] += s.Value().(float64)
+		case obj, more := <-supportChannel:
+			if more {
+				if e, isErr := obj.(error); isErr {
+					err = e
+				}
+			}
+		}
+	}
+	return keys, sums, err
+}
+
+func TestGrouperSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-group-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(memory int64, tempDir string) {
+		GroupMemory = memory
+		TempDir = tempDir
+	}(GroupMemory, TempDir)
+	GroupMemory = 1000
+	TempDir = dir
+
+	sum := ast.NewFunctionCallSum(ast.FunctionArgExpressionList{ast.NewFunctionArgExpression(ast.NewProperty("n"))})
+	grouper := NewGrouper(ast.ExpressionList{ast.NewProperty("k")}, ast.ExpressionList{sum})
+	keys, sums, err := runGrouper(grouper, sum, groupTestData(300, 4, false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// every group is sent exactly once, with all its items
+	if len(keys) != 300 || len(sums) != 300 {
+		t.Errorf("expected 300 groups, got %d with %d keys", len(keys), len(sums))
+	}
+	for k, s := range sums {
+		if s != 4 {
+			t.Errorf("expected sum 4 for group %v, got %v", k, s)
+		}
+	}
+	if grouper.spills == 0 {
+		t.Errorf("expected the grouper to spill")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected group files to be removed, found %d", len(files))
+	}
+}
+
+func TestGrouperStreaming(t *testing.T) {
+	sum := ast.NewFunctionCallSum(ast.FunctionArgExpressionList{ast.NewFunctionArgExpression(ast.NewProperty("n"))})
+	grouper := NewGrouper(ast.ExpressionList{ast.NewProperty("k")}, ast.ExpressionList{sum})
+	grouper.SetStreaming()
+	keys, sums, err := runGrouper(grouper, sum, groupTestData(50, 3, true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the groups keep the order of the input
+	if len(keys) != 50 {
+		t.Fatalf("expected 50 groups, got %d", len(keys))
+	}
+	for i, k := range keys {
+		if k != float64(i) {
+			t.Errorf("expected group %d at %d, got %v", i, i, k)
+		}
+		if sums[k] != 3 {
+			t.Errorf("expected sum 3 for group %v, got %v", k, sums[k])
+		}
+	}
+
+	// nothing at all for no items
+	grouper = NewGrouper(ast.ExpressionList{ast.NewProperty("k")}, ast.ExpressionList{sum})
+	grouper.SetStreaming()
+	keys, _, err = runGrouper(grouper, sum, dparval.ValueCollection{})
+	if err != nil || len(keys) != 0 {
+		t.Errorf("expected no groups, got %v, %v", keys, err)
+	}
+}
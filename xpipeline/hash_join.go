@@ -0,0 +1,189 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+// joins the items of its source with the items of the right hand side
+// having the same join keys and satisfying the ON condition.  the hash
+// table is built from the right hand side before the first item is
+// joined, or with BuildLeft from the source, in which case the joined
+// items are sent once the source is done
+type HashJoin struct {
+	Base       *BaseOperator
+	Right      Operator
+	Type       string
+	On         ast.Expression
+	As         string
+	LeftKeys   ast.ExpressionList
+	RightKeys  ast.ExpressionList
+	BuildLeft  bool
+	built      bool
+	table      map[string]dparval.ValueCollection
+	buffer     dparval.ValueCollection
+	matched    map[*dparval.Value]bool
+	rowsJoined int
+}
+
+func NewHashJoin(right Operator, joinType string, on ast.Expression, as string, leftKeys ast.ExpressionList, rightKeys ast.ExpressionList, buildLeft bool) *HashJoin {
+	return &HashJoin{
+		Base:      NewBaseOperator(),
+		Right:     right,
+		Type:      joinType,
+		On:        on,
+		As:        as,
+		LeftKeys:  leftKeys,
+		RightKeys: rightKeys,
+		BuildLeft: buildLeft,
+		table:     make(map[string]dparval.ValueCollection),
+		buffer:    make(dparval.ValueCollection, 0),
+		matched:   make(map[*dparval.Value]bool),
+	}
+}
+
+func (this *HashJoin) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *HashJoin) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *HashJoin) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "hash join operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "hash join operator finished, joined %d", this.rowsJoined)
+}
+
+func (this *HashJoin) processItem(item *dparval.Value) bool {
+	if this.BuildLeft {
+		return this.buildLeft(item)
+	}
+
+	if !this.built {
+		this.built = true
+		ok := runCompoundTerm(this.Base, this.Right, func(right *dparval.Value) bool {
+			key, ok, err := joinHashKey(this.Base, this.RightKeys, right)
+			if err != nil {
+				return this.Base.SendError(err)
+			}
+			if ok {
+				this.table[key] = append(this.table[key], right)
+			}
+			return true
+		})
+		if !ok {
+			return false
+		}
+	}
+
+	key, ok, err := joinHashKey(this.Base, this.LeftKeys, item)
+	if err != nil {
+		return this.Base.SendError(err)
+	}
+	found := false
+	if ok {
+		for _, right := range this.table[key] {
+			joined, ok := this.join(item, right)
+			if !ok {
+				return false
+			}
+			found = found || joined
+		}
+	}
+	if !found && this.Type == "LEFT" {
+		return this.Base.SendItem(item)
+	}
+	return true
+}
+
+// keeps the item in the hash table until the source is done
+func (this *HashJoin) buildLeft(item *dparval.Value) bool {
+	key, ok, err := joinHashKey(this.Base, this.LeftKeys, item)
+	if err != nil {
+		return this.Base.SendError(err)
+	}
+	if ok {
+		this.table[key] = append(this.table[key], item)
+	} else if this.Type != "LEFT" {
+		// it cannot be joined and will not be sent
+		return true
+	}
+	this.buffer = append(this.buffer, item)
+	return true
+}
+
+func (this *HashJoin) afterItems() {
+	if !this.BuildLeft || len(this.buffer) == 0 {
+		return
+	}
+
+	ok := runCompoundTerm(this.Base, this.Right, func(right *dparval.Value) bool {
+		key, ok, err := joinHashKey(this.Base, this.RightKeys, right)
+		if err != nil {
+			return this.Base.SendError(err)
+		}
+		if !ok {
+			return true
+		}
+		for _, item := range this.table[key] {
+			joined, ok := this.join(item, right)
+			if !ok {
+				return false
+			}
+			if joined {
+				this.matched[item] = true
+			}
+		}
+		return true
+	})
+
+	// with an outer join, send the items nothing joined with
+	if ok && this.Type == "LEFT" {
+		for _, item := range this.buffer {
+			if !this.matched[item] && !this.Base.SendItem(item) {
+				break
+			}
+		}
+	}
+
+	this.table = make(map[string]dparval.ValueCollection)
+	this.buffer = make(dparval.ValueCollection, 0)
+	this.matched = make(map[*dparval.Value]bool)
+}
+
+// sends the joined item if it satisfies the ON condition, returns
+// whether it did and false for ok if the operator has to stop
+func (this *HashJoin) join(item *dparval.Value, right *dparval.Value) (joined bool, ok bool) {
+	value := joinedValue(right, this.As)
+	if value == nil {
+		return false, true
+	}
+	newItem := joinItems(item, value, this.As)
+	holds, err := joinConditionHolds(this.Base, this.On, newItem)
+	if err != nil {
+		return false, this.Base.SendError(err)
+	}
+	if !holds {
+		return false, true
+	}
+	this.rowsJoined += 1
+	return true, this.Base.SendItem(newItem)
+}
+
+func (this *HashJoin) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
@@ -0,0 +1,104 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type Insert struct {
+	mutationCounter
+	bucket catalog.Bucket
+	values ast.InsertValueList
+	upsert bool
+}
+
+func NewInsert(bucket catalog.Bucket, values ast.InsertValueList, upsert bool) *Insert {
+	return &Insert{
+		mutationCounter: mutationCounter{Base: NewBaseOperator()},
+		bucket:          bucket,
+		values:          values,
+		upsert:          upsert,
+	}
+}
+
+func (this *Insert) SetSource(source Operator) {}
+
+func (this *Insert) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Insert) Run(stopChannel misc.StopChannel) {
+	defer close(this.Base.itemChannel)
+	defer close(this.Base.supportChannel)
+	defer close(this.Base.upstreamStopChannel)
+	// this MUST be here so that it runs before the channels are closed
+	defer this.Base.RecoverPanic()
+
+	this.Base.downstreamStopChannel = stopChannel
+
+	clog.To(CHANNEL, "insert operator starting")
+	for _, value := range this.values {
+		if !this.insert(value) {
+			return
+		}
+	}
+	this.report()
+	clog.To(CHANNEL, "insert operator finished, inserted %d", this.count)
+}
+
+func (this *Insert) insert(value *ast.InsertValue) bool {
+	// the keys and values do not refer to any document
+	item := dparval.NewValue(map[string]interface{}{})
+
+	key, err := this.Base.Evaluate(value.Key, item)
+	if err != nil {
+		return this.fail(query.NewError(err, "error evaluating INSERT key"))
+	}
+	id, ok := key.Value().(string)
+	if !ok {
+		return this.fail(query.NewError(nil, fmt.Sprintf("INSERT key %v is not a string", key.Value())))
+	}
+
+	val, err := this.Base.Evaluate(value.Value, item)
+	if err != nil {
+		return this.fail(query.NewError(err, fmt.Sprintf("error evaluating INSERT value for key %s", id)))
+	}
+
+	var qerr query.Error
+	if this.upsert {
+		qerr = this.bucket.Upsert(id, val)
+	} else {
+		qerr = this.bucket.Insert(id, val)
+	}
+	if qerr != nil {
+		return this.fail(qerr)
+	}
+	this.mutated()
+	return true
+}
+
+func (this *Insert) processItem(item *dparval.Value) bool {
+	return true
+}
+
+func (this *Insert) afterItems() {}
+
+func (this *Insert) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
@@ -0,0 +1,78 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"encoding/json"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// helpers shared by the operators joining on an ON condition
+
+// the items of the right hand side of a join are objects holding
+// the joined value under the alias of the join
+func joinedValue(right *dparval.Value, as string) *dparval.Value {
+	val, err := right.Path(as)
+	if err != nil {
+		return nil
+	}
+	return val
+}
+
+// the item sent for a pair of items satisfying the join
+func joinItems(left *dparval.Value, right *dparval.Value, as string) *dparval.Value {
+	rv := left.Duplicate()
+	rv.SetPath(as, right)
+	return rv
+}
+
+// does the joined item satisfy the ON condition
+func joinConditionHolds(base *BaseOperator, on ast.Expression, item *dparval.Value) (bool, query.Error) {
+	val, err := base.Evaluate(on, item)
+	if err != nil {
+		switch err := err.(type) {
+		case *dparval.Undefined:
+			return false, nil
+		default:
+			return false, query.NewError(err, "error evaluating join condition")
+		}
+	}
+	boolVal, ok := ast.ValueInBooleanContext(val.Value()).(bool)
+	return ok && boolVal, nil
+}
+
+// the key an item is hashed on, ok is false if one of the keys
+// is null or missing since such an item cannot equal anything
+func joinHashKey(base *BaseOperator, keys ast.ExpressionList, item *dparval.Value) (string, bool, query.Error) {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		val, err := base.Evaluate(key, item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				return "", false, nil
+			default:
+				return "", false, query.NewError(err, "error evaluating join key")
+			}
+		}
+		values[i] = val.Value()
+		if values[i] == nil {
+			return "", false, nil
+		}
+	}
+	bytes, err := json.Marshal(values)
+	if err != nil {
+		return "", false, query.NewError(err, "error hashing join key")
+	}
+	return string(bytes), true, nil
+}
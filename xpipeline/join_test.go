@@ -0,0 +1,107 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+)
+
+func joinTestData(alias string, field string, values ...interface{}) dparval.ValueCollection {
+	rv := dparval.ValueCollection{}
+	for _, value := range values {
+		doc := map[string]interface{}{}
+		if value != nil {
+			doc[field] = value
+		}
+		rv = append(rv, dparval.NewValue(map[string]interface{}{alias: doc}))
+	}
+	return rv
+}
+
+func joinTestValue(item *dparval.Value, alias string, field string) string {
+	doc, err := item.Path(alias)
+	if err != nil {
+		return ""
+	}
+	value, err := doc.Path(field)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value.Value())
+}
+
+// the pairs of left and right values joined, sorted since
+// building from the left changes the order of the items
+func runJoinOperator(op Operator) []string {
+	itemChannel, _ := op.GetChannels()
+
+	stopChannel := make(misc.StopChannel)
+	go op.Run(stopChannel)
+
+	rv := []string{}
+	for item := range itemChannel {
+		rv = append(rv, joinTestValue(item, "o", "cust")+"-"+joinTestValue(item, "c", "name"))
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+func TestJoinOperators(t *testing.T) {
+	leftKey := ast.NewDotMemberOperator(ast.NewProperty("o"), ast.NewProperty("cust"))
+	rightKey := ast.NewDotMemberOperator(ast.NewProperty("c"), ast.NewProperty("name"))
+	equals := ast.NewEqualToOperator(leftKey, rightKey)
+	lessThan := ast.NewLessThanOperator(leftKey, rightKey)
+
+	left := []interface{}{"a", "b", "b", "x", nil}
+	right := func() Operator {
+		return NewStubSource(joinTestData("c", "name", "b", "a", "b", "c", nil))
+	}
+
+	innerEquals := []string{"a-a", "b-b", "b-b", "b-b", "b-b"}
+	outerEquals := []string{"-", "a-a", "b-b", "b-b", "b-b", "b-b", "x-"}
+
+	tests := []struct {
+		op       Operator
+		expected []string
+	}{
+		{NewHashJoin(right(), "", equals, "c", ast.ExpressionList{leftKey}, ast.ExpressionList{rightKey}, false), innerEquals},
+		{NewHashJoin(right(), "", equals, "c", ast.ExpressionList{leftKey}, ast.ExpressionList{rightKey}, true), innerEquals},
+		{NewHashJoin(right(), "LEFT", equals, "c", ast.ExpressionList{leftKey}, ast.ExpressionList{rightKey}, false), outerEquals},
+		{NewHashJoin(right(), "LEFT", equals, "c", ast.ExpressionList{leftKey}, ast.ExpressionList{rightKey}, true), outerEquals},
+		{NewNestedLoopJoin(right(), "", equals, "c"), innerEquals},
+		{NewNestedLoopJoin(right(), "LEFT", equals, "c"), outerEquals},
+		{NewNestedLoopJoin(right(), "", lessThan, "c"), []string{"a-b", "a-b", "a-c", "b-c", "b-c"}},
+		{NewNestedLoopJoin(right(), "LEFT", lessThan, "c"), []string{"-", "a-b", "a-b", "a-c", "b-c", "b-c", "x-"}},
+	}
+
+	for i, x := range tests {
+		x.op.SetSource(NewStubSource(joinTestData("o", "cust", left...)))
+		result := runJoinOperator(x.op)
+		if !reflect.DeepEqual(result, x.expected) {
+			t.Errorf("Expected %v for join %d, got %v", x.expected, i, result)
+		}
+	}
+}
+
+func TestJoinRightSideStops(t *testing.T) {
+	leftKey := ast.NewDotMemberOperator(ast.NewProperty("o"), ast.NewProperty("cust"))
+	rightKey := ast.NewDotMemberOperator(ast.NewProperty("c"), ast.NewProperty("name"))
+	right := NewEndlessSource(joinTestData("c", "name", "b")[0])
+	op := NewHashJoin(right, "", ast.NewEqualToOperator(leftKey, rightKey), "c", ast.ExpressionList{leftKey}, ast.ExpressionList{rightKey}, false)
+	op.SetSource(NewStubSource(joinTestData("o", "cust", "a")))
+	expectStopped(t, "hash join", op)
+}
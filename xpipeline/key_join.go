@@ -235,6 +235,10 @@ func (this *KeyJoin) flushBatch(baseItem *dparval.Value, ids []string) bool {
 	return true
 }
 
+func (this *KeyJoin) documentsFetched() int {
+	return this.rowsFetched
+}
+
 func (this *KeyJoin) SetQuery(q network.Query) {
 	this.Base.SetQuery(q)
 }
@@ -258,6 +258,10 @@ func (this *KeyNest) afterItems() {
 
 }
 
+func (this *KeyNest) documentsFetched() int {
+	return this.rowsFetched
+}
+
 func (this *KeyNest) SetQuery(q network.Query) {
 	this.Base.SetQuery(q)
 }
@@ -0,0 +1,60 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// the mutation operators do not produce any items, they
+// report the number of documents changed as a mutation count
+type mutationCounter struct {
+	Base     *BaseOperator
+	count    int
+	reported bool
+}
+
+func (this *mutationCounter) mutated() {
+	this.count++
+}
+
+// the count is always reported before a failure, so that the
+// client knows how many documents were changed before it
+func (this *mutationCounter) fail(err query.Error) bool {
+	this.report()
+	return this.Base.SendError(err)
+}
+
+func (this *mutationCounter) report() {
+	if !this.reported {
+		this.reported = true
+		this.Base.SendError(query.NewMutationCountInfo(this.count))
+	}
+}
+
+// the key of the document fetched under the alias
+func mutationKey(item *dparval.Value, as string) (string, *dparval.Value, query.Error) {
+	doc, err := item.Path(as)
+	if err != nil {
+		return "", nil, query.NewError(err, fmt.Sprintf("item has no document %s", as))
+	}
+	meta, ok := doc.GetAttachment("meta").(map[string]interface{})
+	if !ok {
+		return "", nil, query.NewError(nil, "document has no meta")
+	}
+	id, ok := meta["id"].(string)
+	if !ok {
+		return "", nil, query.NewError(nil, "document has no key")
+	}
+	return id, doc, nil
+}
@@ -0,0 +1,101 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+// joins every item of its source with every item of the right hand
+// side satisfying the ON condition, this works for any condition
+// but the right hand side is read into memory before the first item
+type NestedLoopJoin struct {
+	Base       *BaseOperator
+	Right      Operator
+	Type       string
+	On         ast.Expression
+	As         string
+	loaded     bool
+	rights     dparval.ValueCollection
+	rowsJoined int
+}
+
+func NewNestedLoopJoin(right Operator, joinType string, on ast.Expression, as string) *NestedLoopJoin {
+	return &NestedLoopJoin{
+		Base:   NewBaseOperator(),
+		Right:  right,
+		Type:   joinType,
+		On:     on,
+		As:     as,
+		rights: make(dparval.ValueCollection, 0),
+	}
+}
+
+func (this *NestedLoopJoin) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *NestedLoopJoin) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *NestedLoopJoin) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "nested loop join operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "nested loop join operator finished, joined %d", this.rowsJoined)
+}
+
+func (this *NestedLoopJoin) processItem(item *dparval.Value) bool {
+	if !this.loaded {
+		this.loaded = true
+		ok := runCompoundTerm(this.Base, this.Right, func(right *dparval.Value) bool {
+			value := joinedValue(right, this.As)
+			if value != nil {
+				this.rights = append(this.rights, value)
+			}
+			return true
+		})
+		if !ok {
+			return false
+		}
+	}
+
+	found := false
+	for _, right := range this.rights {
+		newItem := joinItems(item, right, this.As)
+		holds, err := joinConditionHolds(this.Base, this.On, newItem)
+		if err != nil {
+			return this.Base.SendError(err)
+		}
+		if holds {
+			found = true
+			this.rowsJoined += 1
+			if !this.Base.SendItem(newItem) {
+				return false
+			}
+		}
+	}
+	if !found && this.Type == "LEFT" {
+		return this.Base.SendItem(item)
+	}
+	return true
+}
+
+func (this *NestedLoopJoin) afterItems() {
+	this.rights = make(dparval.ValueCollection, 0)
+}
+
+func (this *NestedLoopJoin) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
@@ -10,6 +10,8 @@
 package xpipeline
 
 import (
+	"container/heap"
+	"os"
 	"sort"
 
 	"github.com/couchbaselabs/clog"
@@ -19,10 +21,18 @@ import (
 	"github.com/couchbaselabs/tuqtng/network"
 )
 
+// sorts the items in memory until they use more than SortMemory,
+// then writes them to disk in sorted runs and merges those at the
+// end.  with a limit, only that many items are kept at any time
 type Order struct {
 	Base            *BaseOperator
 	OrderBy         []*ast.SortExpression
-	buffer          dparval.ValueCollection
+	buffer          []*sortEntry
+	bufferSize      int64
+	runs            []string
+	spills          int
+	top             *topEntries
+	limit           int
 	explicitAliases []string
 }
 
@@ -30,11 +40,17 @@ func NewOrder(orderBy []*ast.SortExpression, explicitAliases []string) *Order {
 	return &Order{
 		Base:            NewBaseOperator(),
 		OrderBy:         orderBy,
-		buffer:          make(dparval.ValueCollection, 0),
+		buffer:          make([]*sortEntry, 0),
 		explicitAliases: explicitAliases,
 	}
 }
 
+// only the first limit items will be used by the rest of the pipeline
+func (this *Order) SetLimit(limit int) {
+	this.limit = limit
+	this.top = &topEntries{orderBy: this.OrderBy, entries: make([]*sortEntry, 0, limit)}
+}
+
 func (this *Order) SetSource(source Operator) {
 	this.Base.SetSource(source)
 }
@@ -46,7 +62,7 @@ func (this *Order) GetChannels() (dparval.ValueChannel, PipelineSupportChannel)
 func (this *Order) Run(stopChannel misc.StopChannel) {
 	clog.To(CHANNEL, "order operator starting")
 	this.Base.RunOperator(this, stopChannel)
-	clog.To(CHANNEL, "order operator finished")
+	clog.To(CHANNEL, "order operator finished, spilled %d runs", this.spills)
 }
 
 func (this *Order) processItem(item *dparval.Value) bool {
@@ -63,20 +79,107 @@ func (this *Order) processItem(item *dparval.Value) bool {
 			}
 		}
 	}
-	this.buffer = append(this.buffer, item)
+	entry := this.sortEntry(item)
+
+	if this.top != nil {
+		if this.top.Len() < this.limit {
+			heap.Push(this.top, entry)
+		} else if compareSortEntries(this.OrderBy, entry, this.top.entries[0]) < 0 {
+			// replace the largest of the items kept
+			this.top.entries[0] = entry
+			heap.Fix(this.top, 0)
+		}
+		return true
+	}
+
+	this.buffer = append(this.buffer, entry)
+	this.bufferSize += approximateSize(item) + approximateSize(item.GetAttachment("projection"))
+	if this.bufferSize > SortMemory {
+		sort.Sort(this)
+		run, err := writeSortRun(this.buffer)
+		if err != nil {
+			return this.Base.SendError(err)
+		}
+		this.runs = append(this.runs, run)
+		this.spills += 1
+		this.buffer = make([]*sortEntry, 0)
+		this.bufferSize = 0
+	}
 	return true
 }
 
+// evaluates the sort keys of the item once, rather than on every comparison
+func (this *Order) sortEntry(item *dparval.Value) *sortEntry {
+	return newSortEntry(this.Base, this.OrderBy, item)
+}
+
 func (this *Order) afterItems() {
+	defer this.removeRuns()
+
+	if this.top != nil {
+		this.buffer = this.top.entries
+		this.top.entries = make([]*sortEntry, 0)
+	}
+
 	// sort
 	sort.Sort(this)
 
 	// write the output
-	for _, item := range this.buffer {
-		this.Base.SendItem(item)
+	if len(this.runs) == 0 {
+		for _, entry := range this.buffer {
+			if !this.Base.SendItem(entry.item) {
+				break
+			}
+		}
+		this.buffer = make([]*sortEntry, 0)
+		return
+	}
+
+	// merge the runs on disk with what is left in memory
+	sources := make([]sortSource, 0, len(this.runs)+1)
+	for _, name := range this.runs {
+		run, err := openSortRun(name)
+		if err != nil {
+			for _, source := range sources {
+				source.close()
+			}
+			this.Base.SendError(err)
+			return
+		}
+		sources = append(sources, run)
+	}
+	remaining := sortedEntries(this.buffer)
+	sources = append(sources, &remaining)
+	this.buffer = make([]*sortEntry, 0)
+
+	merge, err := newSortMerge(this.OrderBy, sources)
+	if err != nil {
+		this.Base.SendError(err)
+		return
+	}
+	defer merge.close()
+	for {
+		entry, err := merge.next()
+		if err != nil {
+			this.Base.SendError(err)
+			return
+		}
+		if entry == nil || !this.Base.SendItem(entry.item) {
+			return
+		}
 	}
 }
 
+func (this *Order) removeRuns() {
+	for _, run := range this.runs {
+		err := os.Remove(run)
+		if err != nil {
+			clog.Error(err)
+		}
+	}
+	this.runs = nil
+}
+
 func (this *Order) SetQuery(q network.Query) {
 	this.Base.SetQuery(q)
 }
@@ -86,61 +189,5 @@ func (this *Order) SetQuery(q network.Query) {
 func (this *Order) Len() int      { return len(this.buffer) }
 func (this *Order) Swap(i, j int) { this.buffer[i], this.buffer[j] = this.buffer[j], this.buffer[i] }
 func (this *Order) Less(i, j int) bool {
-	left := this.buffer[i]
-	right := this.buffer[j]
-
-	for _, oe := range this.OrderBy {
-		leftVal, lerr := this.Base.Evaluate(oe.Expr, left)
-		if lerr != nil {
-			switch lerr := lerr.(type) {
-			case *dparval.Undefined:
-			default:
-				clog.Error(lerr)
-				return false
-			}
-		}
-		rightVal, rerr := this.Base.Evaluate(oe.Expr, right)
-		if rerr != nil {
-			switch rerr := rerr.(type) {
-			case *dparval.Undefined:
-			default:
-				clog.Error(rerr)
-				return false
-			}
-		}
-
-		// at this point, the only errors left should be MISSING/UNDEFINED
-		if oe.Ascending && lerr != nil && rerr == nil {
-			// ascending, left missing, right not, left is less
-			return true
-		} else if !oe.Ascending && rerr != nil && lerr == nil {
-			// descending right missing, left not, left is more
-			return true
-		} else if !oe.Ascending && lerr != nil && rerr == nil {
-			// descending, left missing, right not, left is less
-			return false
-		} else if oe.Ascending && rerr != nil && lerr == nil {
-			//ascending, left not, left is more
-			return false
-		} else if lerr == nil && rerr == nil {
-			lv := leftVal.Value()
-			rv := rightVal.Value()
-
-			// both not missing, compare values
-			result := ast.CollateJSON(lv, rv)
-			if result != 0 {
-				if oe.Ascending && result < 0 {
-					return true
-				} else if !oe.Ascending && result > 0 {
-					return true
-				} else {
-					return false
-				}
-			}
-		}
-		// at this level they are the same, keep going
-	}
-
-	// if we go to this point the order expressions could not differentiate between the elements
-	return false
+	return compareSortEntries(this.OrderBy, this.buffer[i], this.buffer[j]) < 0
 }
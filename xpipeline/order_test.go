@@ -10,8 +10,12 @@
 package xpipeline
 
 import (
+	"io/ioutil"
+	"os"
+	"reflect"
 	"testing"
 
+	"github.com/couchbaselabs/dparval"
 	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/misc"
 )
@@ -75,3 +79,121 @@ func TestOrderDescending(t *testing.T) {
 		count++
 	}
 }
+
+// items numbered in a scrambled order, every tenth without a number
+func orderTestData(count int) dparval.ValueCollection {
+	rv := dparval.ValueCollection{}
+	for i := 0; i < count; i++ {
+		n := (i * 37) % count
+		doc := dparval.NewValue(map[string]interface{}{"i": float64(i)})
+		if n%10 != 0 {
+			doc.SetPath("n", float64(n))
+		}
+		doc.SetAttachment("projection", dparval.NewValue(map[string]interface{}{"p": float64(n)}))
+		rv = append(rv, doc)
+	}
+	return rv
+}
+
+// the projected numbers of the items in the order they are sent
+func runOrder(order *Order, data dparval.ValueCollection) []float64 {
+	order.SetSource(NewStubSource(data))
+	orderItemChannel, _ := order.GetChannels()
+
+	stopChannel := make(misc.StopChannel)
+	go order.Run(stopChannel)
+
+	rv := []float64{}
+	for item := range orderItemChannel {
+		projection, ok := item.GetAttachment("projection").(*dparval.Value)
+		if !ok {
+			rv = append(rv, -1)
+			continue
+		}
+		p, _ := projection.Path("p")
+		rv = append(rv, p.Value().(float64))
+	}
+	return rv
+}
+
+func orderExpected(count int, ascending bool) []float64 {
+	missing := []float64{}
+	present := []float64{}
+	for n := 0; n < count; n++ {
+		if n%10 == 0 {
+			missing = append(missing, float64(n))
+		} else {
+			present = append(present, float64(n))
+		}
+	}
+	if ascending {
+		return append(missing, present...)
+	}
+	rv := []float64{}
+	for i := len(present) - 1; i >= 0; i-- {
+		rv = append(rv, present[i])
+	}
+	return append(rv, missing...)
+}
+
+func TestOrderSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-order-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(memory int64, tempDir string) {
+		SortMemory = memory
+		TempDir = tempDir
+	}(SortMemory, TempDir)
+	SortMemory = 1000
+	TempDir = dir
+
+	for _, ascending := range []bool{true, false} {
+		order := NewOrder([]*ast.SortExpression{ast.NewSortExpression(ast.NewProperty("n"), ascending)}, nil)
+		result := runOrder(order, orderTestData(200))
+
+		// numbers are unique, apart from the missing ones
+		if len(result) != 200 {
+			t.Fatalf("expected 200 items, got %d", len(result))
+		}
+		expected := orderExpected(200, ascending)
+		for i := range result {
+			if int(expected[i])%10 == 0 {
+				if int(result[i])%10 != 0 {
+					t.Errorf("expected a missing number at %d, got %v", i, result[i])
+					break
+				}
+			} else if result[i] != expected[i] {
+				t.Errorf("expected %v at %d, got %v", expected[i], i, result[i])
+				break
+			}
+		}
+		if order.spills < 2 {
+			t.Errorf("expected the sort to spill, got %d runs", order.spills)
+		}
+		if len(order.runs) != 0 {
+			t.Errorf("expected runs to be removed, got %v", order.runs)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected sort files to be removed, found %d", len(files))
+	}
+}
+
+func TestOrderLimit(t *testing.T) {
+	order := NewOrder([]*ast.SortExpression{ast.NewSortExpression(ast.NewProperty("n"), false)}, nil)
+	order.SetLimit(5)
+	result := runOrder(order, orderTestData(100))
+
+	expected := []float64{99, 98, 97, 96, 95}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -17,6 +17,8 @@ import (
 
 type ExecutablePipeline struct {
 	Root Operator
+	// what the operators did, when the query asked for a profile
+	Profile *OperatorProfile
 }
 
 type Operator interface {
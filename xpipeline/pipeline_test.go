@@ -10,6 +10,9 @@
 package xpipeline
 
 import (
+	"testing"
+	"time"
+
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/dparval"
 	"github.com/couchbaselabs/tuqtng/misc"
@@ -57,3 +60,72 @@ func (this *StubSource) afterItems() {}
 func (this *StubSource) SetQuery(q network.Query) {
 
 }
+
+// a source that never runs out of items, until it is stopped
+type EndlessSource struct {
+	item           *dparval.Value
+	itemChannel    dparval.ValueChannel
+	supportChannel PipelineSupportChannel
+}
+
+func NewEndlessSource(item *dparval.Value) *EndlessSource {
+	return &EndlessSource{
+		item:           item,
+		itemChannel:    make(dparval.ValueChannel),
+		supportChannel: make(PipelineSupportChannel),
+	}
+}
+
+func (this *EndlessSource) SetSource(Operator) {}
+
+func (this *EndlessSource) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.itemChannel, this.supportChannel
+}
+
+func (this *EndlessSource) Run(stopChannel misc.StopChannel) {
+	defer close(this.itemChannel)
+	defer close(this.supportChannel)
+
+	for {
+		select {
+		case this.itemChannel <- this.item:
+		case <-stopChannel:
+			return
+		}
+	}
+}
+
+func (this *EndlessSource) processItem(item *dparval.Value) bool {
+	return true
+}
+
+func (this *EndlessSource) afterItems() {}
+
+func (this *EndlessSource) SetQuery(q network.Query) {}
+
+// stops the operator once it has started, and fails unless it
+// finishes soon after
+func expectStopped(t *testing.T, what string, op Operator) {
+	itemChannel, supportChannel := op.GetChannels()
+	stopChannel := make(misc.StopChannel)
+	go op.Run(stopChannel)
+	time.Sleep(10 * time.Millisecond)
+	close(stopChannel)
+
+	timeout := time.After(time.Second)
+	for itemChannel != nil || supportChannel != nil {
+		select {
+		case _, ok := <-itemChannel:
+			if !ok {
+				itemChannel = nil
+			}
+		case _, ok := <-supportChannel:
+			if !ok {
+				supportChannel = nil
+			}
+		case <-timeout:
+			t.Errorf("%s did not stop", what)
+			return
+		}
+	}
+}
@@ -0,0 +1,248 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/plan"
+)
+
+// how long the profile waits for the operators still stopping
+// after the last result, like those feeding a LIMIT
+const PROFILE_WAIT = 100 * time.Millisecond
+
+// OperatorProfile is what an operator did while running a query
+type OperatorProfile struct {
+	element plan.PlanElement
+	// closed when the operator has finished
+	done      chan bool
+	itemsOut  int64
+	documents int64
+	// nanoseconds the consumers of the operator waited for its items
+	waited   int64
+	children []*OperatorProfile
+}
+
+// the operators fetching documents tell how many they fetched
+type documentFetcher interface {
+	documentsFetched() int
+}
+
+func (this *OperatorProfile) ItemsIn() int64 {
+	rv := int64(0)
+	for _, child := range this.children {
+		rv += child.ItemsOut()
+	}
+	return rv
+}
+
+func (this *OperatorProfile) ItemsOut() int64 {
+	return atomic.LoadInt64(&this.itemsOut)
+}
+
+func (this *OperatorProfile) DocumentsFetched() int64 {
+	return atomic.LoadInt64(&this.documents)
+}
+
+// the time spent in the operator and the operators feeding it
+func (this *OperatorProfile) Time() time.Duration {
+	return time.Duration(atomic.LoadInt64(&this.waited))
+}
+
+// the time spent in the operator alone, the operators run
+// concurrently so this is only an estimate
+func (this *OperatorProfile) SelfTime() time.Duration {
+	rv := this.Time()
+	for _, child := range this.children {
+		rv -= child.Time()
+	}
+	if rv < 0 {
+		return 0
+	}
+	return rv
+}
+
+// Annotate returns the plan the profile was built for as json
+// values, with what each operator did in its #profile field
+func (this *OperatorProfile) Annotate() map[string]interface{} {
+	this.wait(time.Now().Add(PROFILE_WAIT))
+	return this.annotate()
+}
+
+func (this *OperatorProfile) wait(deadline time.Time) {
+	select {
+	case <-this.done:
+	case <-time.After(deadline.Sub(time.Now())):
+		return
+	}
+	for _, child := range this.children {
+		child.wait(deadline)
+	}
+}
+
+func (this *OperatorProfile) annotate() map[string]interface{} {
+	rv := map[string]interface{}{}
+	bytes, err := json.Marshal(this.element)
+	if err == nil {
+		json.Unmarshal(bytes, &rv)
+	}
+
+	// the sources are replaced by their own annotated plans
+	keys := plan.SourceKeys(this.element)
+	for i, child := range this.children {
+		if i < len(keys) {
+			rv[keys[i]] = child.annotate()
+		}
+	}
+
+	stats := map[string]interface{}{
+		"items_in":  float64(this.ItemsIn()),
+		"items_out": float64(this.ItemsOut()),
+		"time":      this.Time().String(),
+		"self_time": this.SelfTime().String(),
+	}
+	if this.DocumentsFetched() > 0 {
+		stats["documents_fetched"] = float64(this.DocumentsFetched())
+	}
+	rv["#profile"] = stats
+	return rv
+}
+
+// Profile wraps an operator, recording its profile
+// as its items and errors are passed on
+type Profile struct {
+	operator              Operator
+	profile               *OperatorProfile
+	itemChannel           dparval.ValueChannel
+	supportChannel        PipelineSupportChannel
+	downstreamStopChannel misc.StopChannel
+}
+
+func NewProfile(operator Operator, element plan.PlanElement) *Profile {
+	return &Profile{
+		operator:       operator,
+		profile:        &OperatorProfile{element: element, done: make(chan bool)},
+		itemChannel:    make(dparval.ValueChannel),
+		supportChannel: make(PipelineSupportChannel),
+	}
+}
+
+func (this *Profile) OperatorProfile() *OperatorProfile {
+	return this.profile
+}
+
+// sources built by the operator itself, like the right side of a join
+func (this *Profile) AddSource(source Operator) {
+	profiled, ok := source.(*Profile)
+	if ok {
+		this.profile.children = append(this.profile.children, profiled.profile)
+	}
+}
+
+func (this *Profile) SetSource(source Operator) {
+	// the source comes first, before any added by the operator itself
+	profiled, ok := source.(*Profile)
+	if ok {
+		this.profile.children = append([]*OperatorProfile{profiled.profile}, this.profile.children...)
+	}
+	this.operator.SetSource(source)
+}
+
+func (this *Profile) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.itemChannel, this.supportChannel
+}
+
+func (this *Profile) Run(stopChannel misc.StopChannel) {
+	defer close(this.itemChannel)
+	defer close(this.supportChannel)
+
+	this.downstreamStopChannel = stopChannel
+	go this.operator.Run(stopChannel)
+
+	// forward everything until the operator closes its channels,
+	// after a stop the items are no longer sent but still drained
+	stopped := false
+	itemChannel, supportChannel := this.operator.GetChannels()
+	for itemChannel != nil || supportChannel != nil {
+		waitStart := time.Now()
+		select {
+		case item, ok := <-itemChannel:
+			atomic.AddInt64(&this.profile.waited, int64(time.Since(waitStart)))
+			if !ok {
+				itemChannel = nil
+				continue
+			}
+			// the items drained after a stop are not counted
+			if !stopped {
+				stopped = !this.sendItem(item)
+				if !stopped {
+					atomic.AddInt64(&this.profile.itemsOut, 1)
+				}
+			}
+		case obj, ok := <-supportChannel:
+			atomic.AddInt64(&this.profile.waited, int64(time.Since(waitStart)))
+			if !ok {
+				supportChannel = nil
+				continue
+			}
+			if !stopped {
+				stopped = !this.sendOther(obj)
+			}
+		}
+	}
+
+	fetcher, ok := this.operator.(documentFetcher)
+	if ok {
+		atomic.StoreInt64(&this.profile.documents, int64(fetcher.documentsFetched()))
+	}
+	close(this.profile.done)
+}
+
+func (this *Profile) sendItem(item *dparval.Value) bool {
+	ok := true
+	for ok {
+		select {
+		case this.itemChannel <- item:
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return false
+}
+
+func (this *Profile) sendOther(obj interface{}) bool {
+	ok := true
+	for ok {
+		select {
+		case this.supportChannel <- obj:
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return false
+}
+
+func (this *Profile) processItem(item *dparval.Value) bool {
+	return true
+}
+
+func (this *Profile) afterItems() {}
+
+func (this *Profile) SetQuery(q network.Query) {
+	this.operator.SetQuery(q)
+}
@@ -0,0 +1,60 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/plan"
+)
+
+func TestProfile(t *testing.T) {
+
+	expr := ast.NewGreaterThanOperator(ast.NewProperty("age"), ast.NewLiteralNumber(99.0))
+	scanElement := plan.NewKeyScan([]string{"1", "2", "3", "4"})
+	filterElement := plan.NewFilter(scanElement, expr)
+
+	source := NewProfile(NewStubSource(testData), scanElement)
+	filter := NewProfile(NewFilter(expr), filterElement)
+	filter.SetSource(source)
+
+	filterItemChannel, _ := filter.GetChannels()
+
+	stopChannel := make(misc.StopChannel)
+	go filter.Run(stopChannel)
+
+	count := 0
+	for _ = range filterItemChannel {
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Expected %d items, got %d", 4, count)
+	}
+
+	profile := filter.OperatorProfile()
+	if profile.ItemsIn() != 8 || profile.ItemsOut() != 4 {
+		t.Errorf("Expected 8 items in and 4 out, got %d and %d", profile.ItemsIn(), profile.ItemsOut())
+	}
+	if profile.Time() < profile.SelfTime() {
+		t.Errorf("Expected time %v to include self time %v", profile.Time(), profile.SelfTime())
+	}
+
+	annotated := filter.OperatorProfile().Annotate()
+	input, ok := annotated["input"].(map[string]interface{})
+	if !ok || annotated["type"] != "filter" || input["type"] != scanElement.Type {
+		t.Fatalf("Expected a filter of a key scan, got %v", annotated)
+	}
+	stats, ok := input["#profile"].(map[string]interface{})
+	if !ok || stats["items_out"] != 8.0 {
+		t.Errorf("Expected 8 items out of the scan, got %v", input["#profile"])
+	}
+}
@@ -12,6 +12,7 @@ package xpipeline
 import (
 	"fmt"
 	"runtime/debug"
+	"sync"
 
 	"github.com/couchbaselabs/clog"
 	"github.com/couchbaselabs/dparval"
@@ -23,6 +24,13 @@ import (
 	"github.com/couchbaselabs/tuqtng/query"
 )
 
+// the number of ranges a scan operator scans at the same time
+var ScanParallelism = 1
+
+// the number of entries each range scanned in parallel
+// may read ahead of the operator
+const SCAN_RANGE_BUFFER = 256
+
 type Scan struct {
 	itemChannel           dparval.ValueChannel
 	supportChannel        PipelineSupportChannel
@@ -33,6 +41,7 @@ type Scan struct {
 	as                    string
 	query                 network.Query
 	rowsScanned           int
+	ordered               bool
 }
 
 func NewScan(bucket catalog.Bucket, index catalog.ScanIndex, ranges plan.ScanRanges, as string) *Scan {
@@ -48,6 +57,12 @@ func NewScan(bucket catalog.Bucket, index catalog.ScanIndex, ranges plan.ScanRan
 
 func (this *Scan) SetSource(source Operator) {}
 
+// when the ranges are scanned in parallel, send the entries
+// in the order of the ranges, as a sequential scan would
+func (this *Scan) SetOrdered(ordered bool) {
+	this.ordered = ordered
+}
+
 func (this *Scan) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
 	return this.itemChannel, this.supportChannel
 }
@@ -58,10 +73,13 @@ func (this *Scan) Run(stopChannel misc.StopChannel) {
 	// this MUST be here so that it runs before the channels are closed
 	defer this.RecoverPanic()
 
+	this.downstreamStopChannel = stopChannel
 	clog.To(CHANNEL, "scan operator starting")
 
 	if this.ranges == nil {
 		this.scanRange(nil)
+	} else if ScanParallelism > 1 && len(this.ranges) > 1 {
+		this.scanRangesInParallel()
 	} else {
 		for _, scanRange := range this.ranges {
 			ok := this.scanRange(scanRange)
@@ -74,23 +92,29 @@ func (this *Scan) Run(stopChannel misc.StopChannel) {
 	clog.To(CHANNEL, "scan operator finished, scanned %d", this.rowsScanned)
 }
 
+func (this *Scan) startRange(scanRange *plan.ScanRange, indexItemChannel catalog.EntryChannel, indexWarnChannel query.ErrorChannel, indexErrorChannel query.ErrorChannel) bool {
+	clog.To(CHANNEL, "scanning range %v", scanRange)
+	if scanRange == nil {
+		go this.index.ScanEntries(0, indexItemChannel, indexWarnChannel, indexErrorChannel)
+		return true
+	}
+	rangeScan, ok := this.index.(catalog.RangeIndex)
+	if !ok {
+		return false
+	}
+	go rangeScan.ScanRange(scanRange.Low, scanRange.High, scanRange.Inclusion, scanRange.Limit, indexItemChannel, indexWarnChannel, indexErrorChannel)
+	return true
+}
+
 func (this *Scan) scanRange(scanRange *plan.ScanRange) bool {
 
 	indexItemChannel := make(catalog.EntryChannel)
 	indexWarnChannel := make(query.ErrorChannel)
 	indexErrorChannel := make(query.ErrorChannel)
 
-	clog.To(CHANNEL, "scanning range %v", scanRange)
-	if scanRange == nil {
-		go this.index.ScanEntries(0, indexItemChannel, indexWarnChannel, indexErrorChannel)
-	} else {
-		rangeScan, ok := this.index.(catalog.RangeIndex)
-		if ok {
-			go rangeScan.ScanRange(scanRange.Low, scanRange.High, scanRange.Inclusion, scanRange.Limit, indexItemChannel, indexWarnChannel, indexErrorChannel)
-		} else {
-			this.SendError(query.NewError(nil, "Cannot range scan this"))
-			return false
-		}
+	if !this.startRange(scanRange, indexItemChannel, indexWarnChannel, indexErrorChannel) {
+		this.SendError(query.NewError(nil, "Cannot range scan this"))
+		return false
 	}
 
 	var item *catalog.IndexEntry
@@ -102,28 +126,7 @@ func (this *Scan) scanRange(scanRange *plan.ScanRange) bool {
 		select {
 		case item, ok = <-indexItemChannel:
 			if ok {
-				this.rowsScanned += 1
-				// rematerialize an object from the data returned by this index entry
-				doc := dparval.NewValue(map[string]interface{}{})
-
-				hackIndex, ok := this.index.(catalog.Index)
-				if ok {
-					if hackIndex.Key() != nil && item.EntryKey != nil && len(hackIndex.Key()) == len(item.EntryKey) {
-						for i, key := range hackIndex.Key() {
-							entry := item.EntryKey[i]
-							doc = this.buildValue(key, entry)
-						}
-					}
-				}
-
-				// attach metadata
-				doc.SetAttachment("meta", map[string]interface{}{"id": item.PrimaryKey})
-
-				if this.as != "" {
-					this.SendItem(dparval.NewValue(map[string]interface{}{this.as: doc}))
-				} else {
-					this.SendItem(doc)
-				}
+				this.sendEntry(item)
 			}
 		case warn, ok = <-indexWarnChannel:
 			if warn != nil {
@@ -143,6 +146,154 @@ func (this *Scan) scanRange(scanRange *plan.ScanRange) bool {
 	return true
 }
 
+func (this *Scan) sendEntry(item *catalog.IndexEntry) bool {
+	this.rowsScanned += 1
+	// rematerialize an object from the data returned by this index entry
+	doc := dparval.NewValue(map[string]interface{}{})
+
+	hackIndex, ok := this.index.(catalog.Index)
+	if ok {
+		if hackIndex.Key() != nil && item.EntryKey != nil && len(hackIndex.Key()) == len(item.EntryKey) {
+			for i, key := range hackIndex.Key() {
+				entry := item.EntryKey[i]
+				doc = this.buildValue(key, entry)
+			}
+		}
+	}
+
+	// attach metadata
+	doc.SetAttachment("meta", map[string]interface{}{"id": item.PrimaryKey})
+
+	if this.as != "" {
+		return this.SendItem(dparval.NewValue(map[string]interface{}{this.as: doc}))
+	}
+	return this.SendItem(doc)
+}
+
+// scans up to ScanParallelism ranges at the same time, the ranges are
+// started in order so the earliest range not finished is always running
+func (this *Scan) scanRangesInParallel() {
+	stop := make(chan bool)
+	defer close(stop)
+
+	warnings := make(query.ErrorChannel)
+	errors := make(query.ErrorChannel)
+	running := make(chan bool, ScanParallelism)
+
+	// an ordered scan reads every range from its own channel in turn,
+	// otherwise the ranges share one
+	entries := make([]catalog.EntryChannel, len(this.ranges))
+	for i := range entries {
+		if this.ordered || i == 0 {
+			entries[i] = make(catalog.EntryChannel, SCAN_RANGE_BUFFER)
+		} else {
+			entries[i] = entries[0]
+		}
+	}
+
+	go func() {
+		var finished sync.WaitGroup
+		for i, scanRange := range this.ranges {
+			select {
+			case running <- true:
+			case <-stop:
+				return
+			}
+			finished.Add(1)
+			go func(scanRange *plan.ScanRange, out catalog.EntryChannel) {
+				defer func() { <-running }()
+				defer finished.Done()
+				if this.ordered {
+					defer close(out)
+				}
+				this.forwardRange(scanRange, out, warnings, errors, stop)
+			}(scanRange, entries[i])
+		}
+		if !this.ordered {
+			finished.Wait()
+			close(entries[0])
+		}
+	}()
+
+	var item *catalog.IndexEntry
+	var warn query.Error
+	var err query.Error
+
+	for i, channel := range entries {
+		if i > 0 && !this.ordered {
+			break
+		}
+		ok := true
+		for ok {
+			select {
+			case item, ok = <-channel:
+				if ok {
+					this.sendEntry(item)
+				}
+			case warn = <-warnings:
+				this.SendError(warn)
+			case err = <-errors:
+				this.SendError(err)
+				return
+			case _, ok = <-this.downstreamStopChannel:
+				// downstream has asked us to stop
+				return
+			}
+		}
+	}
+}
+
+// sends the entries of a range scanned in parallel to out
+func (this *Scan) forwardRange(scanRange *plan.ScanRange, out catalog.EntryChannel, warnings, errors query.ErrorChannel, stop chan bool) {
+	indexItemChannel := make(catalog.EntryChannel)
+	indexWarnChannel := make(query.ErrorChannel)
+	indexErrorChannel := make(query.ErrorChannel)
+
+	if !this.startRange(scanRange, indexItemChannel, indexWarnChannel, indexErrorChannel) {
+		select {
+		case errors <- query.NewError(nil, "Cannot range scan this"):
+		case <-stop:
+		}
+		return
+	}
+
+	var item *catalog.IndexEntry
+	var warn query.Error
+	var err query.Error
+
+	ok := true
+	for ok {
+		select {
+		case item, ok = <-indexItemChannel:
+			if ok {
+				select {
+				case out <- item:
+				case <-stop:
+					return
+				}
+			}
+		case warn, ok = <-indexWarnChannel:
+			if warn != nil {
+				select {
+				case warnings <- warn:
+				case <-stop:
+					return
+				}
+			}
+		case err, ok = <-indexErrorChannel:
+			if err != nil {
+				select {
+				case errors <- err:
+				case <-stop:
+				}
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
 func (this *Scan) processItem(item *dparval.Value) bool {
 	return true
 }
@@ -216,6 +367,7 @@ type KeyScan struct {
 	supportChannel        PipelineSupportChannel
 	downstreamStopChannel misc.StopChannel
 	keylist               []string
+	keys                  *ast.KeyExpression
 	query                 network.Query
 	rowsKeyScanned        int
 }
@@ -228,6 +380,16 @@ func NewKeyScan(keylist []string) *KeyScan {
 	}
 }
 
+// the keys are evaluated when the scan is run, with the parameters
+// of the request
+func NewKeyScanOfExpression(keys *ast.KeyExpression) *KeyScan {
+	return &KeyScan{
+		itemChannel:    make(dparval.ValueChannel),
+		supportChannel: make(PipelineSupportChannel),
+		keys:           keys,
+	}
+}
+
 func (this *KeyScan) SetSource(source Operator) {}
 
 func (this *KeyScan) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
@@ -242,6 +404,17 @@ func (this *KeyScan) Run(stopChannel misc.StopChannel) {
 
 	clog.To(CHANNEL, "key scan operator starting")
 
+	if this.keys != nil {
+		item := dparval.NewValue(map[string]interface{}{})
+		item.SetAttachment("query", this.query)
+		keylist, err := this.keys.EvaluateKeys(item)
+		if err != nil {
+			this.SendError(query.NewError(err, "Error evaluating KEYS"))
+			return
+		}
+		this.keylist = keylist
+	}
+
 	for _, item := range this.keylist {
 		this.rowsKeyScanned += 1
 		// rematerialize an object from the data returned by this index entry
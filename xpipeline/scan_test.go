@@ -10,11 +10,21 @@
 package xpipeline
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
 	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/catalog/file"
 	"github.com/couchbaselabs/tuqtng/catalog/mock"
 	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/plan"
 )
 
 func TestScan(t *testing.T) {
@@ -62,3 +72,86 @@ func TestScan(t *testing.T) {
 	}
 
 }
+
+func TestParallelScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tuqtng-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	docs := filepath.Join(dir, "pool", "docs")
+	err = os.MkdirAll(docs, 0777)
+	if err != nil {
+		t.Fatalf("failed to create bucket dir: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		err = ioutil.WriteFile(filepath.Join(docs, fmt.Sprintf("%02d.json", i)), []byte(fmt.Sprintf(`{"v": %d}`, i)), 0666)
+		if err != nil {
+			t.Fatalf("failed to write doc: %v", err)
+		}
+	}
+
+	site, qerr := file.NewSite(dir)
+	if qerr != nil {
+		t.Fatalf("failed to create site: %v", qerr)
+	}
+	pool, _ := site.PoolByName("pool")
+	bucket, _ := pool.BucketByName("docs")
+	index, qerr := bucket.CreateIndex("v_idx", catalog.IndexKey{ast.NewProperty("v")}, "")
+	if qerr != nil {
+		t.Fatalf("failed to create index: %v", qerr)
+	}
+
+	scanRange := func(low, high float64) *plan.ScanRange {
+		return &plan.ScanRange{
+			Low:       catalog.LookupValue{dparval.NewValue(low)},
+			High:      catalog.LookupValue{dparval.NewValue(high)},
+			Inclusion: catalog.Both,
+		}
+	}
+	// not in the order of the index
+	ranges := plan.ScanRanges{scanRange(10, 14), scanRange(0, 4), scanRange(15, 19), scanRange(5, 9)}
+
+	scanIds := func(parallelism int, ordered bool) []string {
+		defer func(saved int) { ScanParallelism = saved }(ScanParallelism)
+		ScanParallelism = parallelism
+
+		scan := NewScan(bucket, index.(catalog.ScanIndex), ranges, "")
+		scan.SetOrdered(ordered)
+		itemChannel, _ := scan.GetChannels()
+		go scan.Run(make(misc.StopChannel))
+
+		ids := []string{}
+		for item := range itemChannel {
+			ids = append(ids, item.GetAttachment("meta").(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+
+	sequential := scanIds(1, false)
+	if len(sequential) != 20 || sequential[0] != "10" {
+		t.Fatalf("expected 20 ids starting with the first range, got %v", sequential)
+	}
+	ordered := scanIds(3, true)
+	if !reflect.DeepEqual(ordered, sequential) {
+		t.Errorf("expected the order of a sequential scan %v, got %v", sequential, ordered)
+	}
+	// downstream stops reading, as a LIMIT does
+	defer func(saved int) { ScanParallelism = saved }(ScanParallelism)
+	ScanParallelism = 3
+	scan := NewScan(bucket, index.(catalog.ScanIndex), ranges, "")
+	itemChannel, _ := scan.GetChannels()
+	stopChannel := make(misc.StopChannel)
+	go scan.Run(stopChannel)
+	<-itemChannel
+	close(stopChannel)
+	for _ = range itemChannel {
+	}
+
+	unordered := scanIds(3, false)
+	sort.Strings(unordered)
+	sort.Strings(sequential)
+	if !reflect.DeepEqual(unordered, sequential) {
+		t.Errorf("expected the ids of a sequential scan %v, got %v", sequential, unordered)
+	}
+}
@@ -0,0 +1,252 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"container/heap"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// the memory, in bytes, the items being sorted by one ORDER BY may
+// use before they are written to disk in sorted runs, which are
+// merged once all the items have arrived
+var SortMemory int64 = 64 * 1024 * 1024
+
+// an item waiting to be sorted with the values of the
+// ORDER BY expressions, missing values are flagged
+type sortEntry struct {
+	item    *dparval.Value
+	keys    []interface{}
+	missing []bool
+}
+
+func newSortEntry(base *BaseOperator, orderBy []*ast.SortExpression, item *dparval.Value) *sortEntry {
+	entry := &sortEntry{
+		item:    item,
+		keys:    make([]interface{}, len(orderBy)),
+		missing: make([]bool, len(orderBy)),
+	}
+	for i, oe := range orderBy {
+		val, err := base.Evaluate(oe.Expr, item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+			default:
+				clog.Error(err)
+			}
+			entry.missing[i] = true
+			continue
+		}
+		entry.keys[i] = val.Value()
+	}
+	return entry
+}
+
+// MISSING sorts before any value, descending reverses everything
+func compareSortEntries(orderBy []*ast.SortExpression, left, right *sortEntry) int {
+	for i, oe := range orderBy {
+		result := 0
+		switch {
+		case left.missing[i] && right.missing[i]:
+		case left.missing[i]:
+			result = -1
+		case right.missing[i]:
+			result = 1
+		default:
+			result = ast.CollateJSON(left.keys[i], right.keys[i])
+		}
+		if result != 0 {
+			if !oe.Ascending {
+				return -result
+			}
+			return result
+		}
+	}
+	return 0
+}
+
+// the form of an entry in a sorted run, the sort keys were
+// evaluated already and are kept with the item
+type spilledEntry struct {
+	Keys    []interface{} `json:"keys"`
+	Missing []bool        `json:"missing"`
+	Item    spilledItem   `json:"item"`
+}
+
+// writes sorted entries to a new temporary file and returns its name
+func writeSortRun(entries []*sortEntry) (string, query.Error) {
+	writer, err := newSpillWriter("tuqtng-sort-")
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		err = writer.write(&spilledEntry{
+			Keys:    entry.keys,
+			Missing: entry.missing,
+			Item:    newSpilledItem(entry.item),
+		})
+		if err != nil {
+			writer.discard()
+			return "", err
+		}
+	}
+	return writer.finish()
+}
+
+// a source of sorted entries taking part in the merge
+type sortSource interface {
+	next() (*sortEntry, query.Error) // nil at the end
+	close()
+}
+
+// the entries of a sorted run on disk
+type sortRun struct {
+	reader *spillReader
+}
+
+func openSortRun(name string) (*sortRun, query.Error) {
+	reader, err := openSpillReader(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sortRun{reader: reader}, nil
+}
+
+func (this *sortRun) next() (*sortEntry, query.Error) {
+	var spilled spilledEntry
+	ok, err := this.reader.read(&spilled)
+	if !ok {
+		return nil, err
+	}
+	return &sortEntry{item: spilled.Item.item(), keys: spilled.Keys, missing: spilled.Missing}, nil
+}
+
+func (this *sortRun) close() {
+	this.reader.close()
+}
+
+// the entries still in memory
+type sortedEntries []*sortEntry
+
+func (this *sortedEntries) next() (*sortEntry, query.Error) {
+	if len(*this) == 0 {
+		return nil, nil
+	}
+	rv := (*this)[0]
+	*this = (*this)[1:]
+	return rv, nil
+}
+
+func (this *sortedEntries) close() {}
+
+// merges the sorted sources, smallest head first
+type sortMerge struct {
+	orderBy []*ast.SortExpression
+	sources []sortSource
+	heads   []*sortEntry
+}
+
+func newSortMerge(orderBy []*ast.SortExpression, sources []sortSource) (*sortMerge, query.Error) {
+	rv := &sortMerge{orderBy: orderBy}
+	for i, source := range sources {
+		head, err := source.next()
+		if err != nil {
+			rv.close()
+			for _, source := range sources[i:] {
+				source.close()
+			}
+			return nil, err
+		}
+		if head != nil {
+			rv.sources = append(rv.sources, source)
+			rv.heads = append(rv.heads, head)
+		} else {
+			source.close()
+		}
+	}
+	heap.Init(rv)
+	return rv, nil
+}
+
+// returns the next entry of the merge, nil when all sources are done
+func (this *sortMerge) next() (*sortEntry, query.Error) {
+	if len(this.heads) == 0 {
+		return nil, nil
+	}
+	rv := this.heads[0]
+	head, err := this.sources[0].next()
+	if err != nil {
+		return nil, err
+	}
+	if head != nil {
+		this.heads[0] = head
+		heap.Fix(this, 0)
+	} else {
+		this.sources[0].close()
+		heap.Pop(this)
+	}
+	return rv, nil
+}
+
+func (this *sortMerge) close() {
+	for _, source := range this.sources {
+		source.close()
+	}
+	this.sources = nil
+	this.heads = nil
+}
+
+// heap.Interface interface
+
+func (this *sortMerge) Len() int { return len(this.heads) }
+func (this *sortMerge) Less(i, j int) bool {
+	return compareSortEntries(this.orderBy, this.heads[i], this.heads[j]) < 0
+}
+func (this *sortMerge) Swap(i, j int) {
+	this.heads[i], this.heads[j] = this.heads[j], this.heads[i]
+	this.sources[i], this.sources[j] = this.sources[j], this.sources[i]
+}
+func (this *sortMerge) Push(x interface{}) {
+	// sources are only added in newSortMerge
+}
+func (this *sortMerge) Pop() interface{} {
+	last := len(this.heads) - 1
+	rv := this.heads[last]
+	this.heads = this.heads[:last]
+	this.sources = this.sources[:last]
+	return rv
+}
+
+// keeps the smallest entries seen, with the largest of them on top
+type topEntries struct {
+	orderBy []*ast.SortExpression
+	entries []*sortEntry
+}
+
+func (this *topEntries) Len() int { return len(this.entries) }
+func (this *topEntries) Less(i, j int) bool {
+	return compareSortEntries(this.orderBy, this.entries[i], this.entries[j]) > 0
+}
+func (this *topEntries) Swap(i, j int) {
+	this.entries[i], this.entries[j] = this.entries[j], this.entries[i]
+}
+func (this *topEntries) Push(x interface{}) {
+	this.entries = append(this.entries, x.(*sortEntry))
+}
+func (this *topEntries) Pop() interface{} {
+	last := len(this.entries) - 1
+	rv := this.entries[last]
+	this.entries = this.entries[:last]
+	return rv
+}
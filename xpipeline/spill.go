@@ -0,0 +1,162 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+// operators that may hold more items than fit in memory write
+// them to temporary files, one JSON object per line
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// the directory holding the files of operators spilling
+// to disk, the default directory for temporary files when empty
+var TempDir = ""
+
+// a rough count of the bytes used by a value
+func approximateSize(val interface{}) int64 {
+	switch val := val.(type) {
+	case string:
+		return int64(len(val)) + 16
+	case []interface{}:
+		rv := int64(24)
+		for _, v := range val {
+			rv += approximateSize(v)
+		}
+		return rv
+	case map[string]interface{}:
+		rv := int64(48)
+		for k, v := range val {
+			rv += int64(len(k)) + 16 + approximateSize(v)
+		}
+		return rv
+	case *dparval.Value:
+		return approximateSize(val.Value())
+	}
+	return 16
+}
+
+// the form of an item written to disk, with the attachments
+// the operators after the one spilling it may use
+type spilledItem struct {
+	Value      interface{} `json:"value"`
+	Projection interface{} `json:"projection,omitempty"`
+	Meta       interface{} `json:"meta,omitempty"`
+}
+
+func newSpilledItem(item *dparval.Value) spilledItem {
+	rv := spilledItem{
+		Value: item.Value(),
+		Meta:  item.GetAttachment("meta"),
+	}
+	projection, ok := item.GetAttachment("projection").(*dparval.Value)
+	if ok {
+		rv.Projection = projection.Value()
+	}
+	return rv
+}
+
+func (this *spilledItem) item() *dparval.Value {
+	rv := dparval.NewValue(this.Value)
+	if this.Projection != nil {
+		rv.SetAttachment("projection", dparval.NewValue(this.Projection))
+	}
+	if this.Meta != nil {
+		rv.SetAttachment("meta", this.Meta)
+	}
+	return rv
+}
+
+type spillWriter struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newSpillWriter(prefix string) (*spillWriter, query.Error) {
+	file, err := ioutil.TempFile(TempDir, prefix)
+	if err != nil {
+		return nil, query.NewError(err, "Unable to create file for spilling to disk")
+	}
+	writer := bufio.NewWriter(file)
+	return &spillWriter{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func (this *spillWriter) write(val interface{}) query.Error {
+	err := this.encoder.Encode(val)
+	if err != nil {
+		return query.NewError(err, "Unable to write file for spilling to disk")
+	}
+	return nil
+}
+
+// closes the file and returns its name, the file is removed on error
+func (this *spillWriter) finish() (string, query.Error) {
+	err := this.writer.Flush()
+	if err == nil {
+		err = this.file.Close()
+	} else {
+		this.file.Close()
+	}
+	if err != nil {
+		os.Remove(this.file.Name())
+		return "", query.NewError(err, "Unable to write file for spilling to disk")
+	}
+	return this.file.Name(), nil
+}
+
+// closes and removes the file
+func (this *spillWriter) discard() {
+	this.file.Close()
+	os.Remove(this.file.Name())
+}
+
+type spillReader struct {
+	file    *os.File
+	decoder *json.Decoder
+}
+
+func openSpillReader(name string) (*spillReader, query.Error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, query.NewError(err, "Unable to read file spilled to disk")
+	}
+	return &spillReader{
+		file:    file,
+		decoder: json.NewDecoder(bufio.NewReader(file)),
+	}, nil
+}
+
+// returns false at the end of the file
+func (this *spillReader) read(val interface{}) (bool, query.Error) {
+	err := this.decoder.Decode(val)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, query.NewError(err, "Unable to read file spilled to disk")
+	}
+	return true, nil
+}
+
+func (this *spillReader) close() {
+	this.file.Close()
+}
@@ -0,0 +1,176 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/plan"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// builds the operators for a plan element, supplied by the pipeline builder
+type SubqueryBuilder func(element plan.PlanElement, q network.Query) (Operator, error)
+
+// SubqueryQuery is the query given to the operators of a plan containing
+// subqueries, it evaluates those subqueries when an expression needs them
+// uncorrelated subqueries are run once and their results reused
+// correlated subqueries are run again for each item of the enclosing statement
+type SubqueryQuery struct {
+	network.Query
+	subqueries   map[*ast.SelectStatement]plan.PlanElement
+	build        SubqueryBuilder
+	outer        *dparval.Value
+	outerAliases []string
+	results      map[*ast.SelectStatement]*dparval.Value
+	mutex        *sync.Mutex
+	// of the operator evaluating the subqueries
+	stop misc.StopChannel
+}
+
+func NewSubqueryQuery(q network.Query, subqueries map[*ast.SelectStatement]plan.PlanElement, build SubqueryBuilder) *SubqueryQuery {
+	return &SubqueryQuery{
+		Query:      q,
+		subqueries: subqueries,
+		build:      build,
+		results:    make(map[*ast.SelectStatement]*dparval.Value),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+func (this *SubqueryQuery) EvaluateSubquery(subquery *ast.Subquery, item *dparval.Value) (*dparval.Value, error) {
+	if !subquery.Correlated {
+		this.mutex.Lock()
+		result, ok := this.results[subquery.Select]
+		this.mutex.Unlock()
+		if ok {
+			return result, nil
+		}
+	}
+
+	element, ok := this.subqueries[subquery.Select]
+	if !ok {
+		return nil, fmt.Errorf("no plan found for subquery")
+	}
+
+	q := &SubqueryQuery{
+		Query:      this.Query,
+		subqueries: this.subqueries,
+		build:      this.build,
+		results:    this.results,
+		mutex:      this.mutex,
+	}
+	if subquery.Correlated {
+		q.outer = item
+		q.outerAliases = subquery.GetOuterAliases()
+	}
+
+	root, err := this.build(element, q)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := runSubquery(root, this.stop)
+	if err != nil {
+		return nil, err
+	}
+
+	// a single projected expression contributes just its value
+	resultExprs := subquery.Select.GetResultExpressionList()
+	if len(resultExprs) == 1 && !resultExprs[0].Star {
+		for i, value := range values {
+			projected, isObject := value.(map[string]interface{})
+			if isObject {
+				values[i] = projected[resultExprs[0].As]
+			}
+		}
+	}
+
+	result := dparval.NewValue(values)
+	if !subquery.Correlated {
+		this.mutex.Lock()
+		this.results[subquery.Select] = result
+		this.mutex.Unlock()
+	}
+	return result, nil
+}
+
+// stoppedBy returns the query as seen by one operator, whose stop
+// channel also stops the subqueries it evaluates
+func (this *SubqueryQuery) stoppedBy(stop misc.StopChannel) *SubqueryQuery {
+	rv := *this
+	rv.stop = stop
+	return &rv
+}
+
+// inside a correlated subquery the items of the enclosing statement
+// are available under their aliases, unless the subquery uses them itself
+func (this *SubqueryQuery) bindOuter(item *dparval.Value) {
+	if this.outer == nil {
+		return
+	}
+	for _, alias := range this.outerAliases {
+		_, err := item.Path(alias)
+		if err != nil {
+			outerValue, err := this.outer.Path(alias)
+			if err == nil {
+				item.SetPath(alias, outerValue)
+			}
+		}
+	}
+}
+
+// runs the pipeline of a subquery to completion
+// and returns the values it projected, unless stop
+// is closed first
+func runSubquery(root Operator, stop misc.StopChannel) ([]interface{}, error) {
+	stopChannel := make(misc.StopChannel)
+	defer close(stopChannel)
+
+	go root.Run(stopChannel)
+
+	rv := make([]interface{}, 0)
+	var item *dparval.Value
+	var obj interface{}
+	itemChannel, supportChannel := root.GetChannels()
+	ok := true
+	for ok {
+		select {
+		case item, ok = <-itemChannel:
+			if ok {
+				projection, isValue := item.GetAttachment("projection").(*dparval.Value)
+				if isValue {
+					rv = append(rv, projection.Value())
+				}
+			}
+		case obj, ok = <-supportChannel:
+			if ok {
+				switch obj := obj.(type) {
+				case query.Error:
+					if obj.IsFatal() {
+						return nil, obj
+					}
+					clog.To(CHANNEL, "subquery: %v", obj)
+				}
+			}
+		case _, ok = <-stop:
+			if !ok {
+				return nil, fmt.Errorf("subquery stopped")
+			}
+		}
+	}
+	return rv, nil
+}
@@ -0,0 +1,37 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+)
+
+func TestSubqueryStops(t *testing.T) {
+	stop := make(misc.StopChannel)
+	done := make(chan error)
+	go func() {
+		_, err := runSubquery(NewEndlessSource(dparval.NewValue(map[string]interface{}{})), stop)
+		done <- err
+	}()
+	close(stop)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected an error from a stopped subquery")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("subquery did not stop")
+	}
+}
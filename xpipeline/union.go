@@ -0,0 +1,72 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+)
+
+// streams the items of its source followed by the items of the term
+// unless All is set, rows already sent are not sent again
+type Union struct {
+	Base *BaseOperator
+	Term Operator
+	All  bool
+	sent map[string]bool
+}
+
+func NewUnion(term Operator, all bool) *Union {
+	return &Union{
+		Base: NewBaseOperator(),
+		Term: term,
+		All:  all,
+		sent: make(map[string]bool),
+	}
+}
+
+func (this *Union) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *Union) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Union) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "union operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "union operator finished")
+}
+
+func (this *Union) processItem(item *dparval.Value) bool {
+	item, key := compoundItem(item)
+	if item == nil {
+		return true
+	}
+	if !this.All {
+		if this.sent[key] {
+			return true
+		}
+		this.sent[key] = true
+	}
+	return this.Base.SendItem(item)
+}
+
+func (this *Union) afterItems() {
+	runCompoundTerm(this.Base, this.Term, this.processItem)
+	this.sent = make(map[string]bool)
+}
+
+func (this *Union) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
@@ -0,0 +1,157 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"reflect"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type Update struct {
+	mutationCounter
+	bucket catalog.Bucket
+	as     string
+	set    ast.SetTermList
+}
+
+func NewUpdate(bucket catalog.Bucket, as string, set ast.SetTermList) *Update {
+	return &Update{
+		mutationCounter: mutationCounter{Base: NewBaseOperator()},
+		bucket:          bucket,
+		as:              as,
+		set:             set,
+	}
+}
+
+func (this *Update) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *Update) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Update) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "update operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "update operator finished, updated %d", this.count)
+}
+
+func (this *Update) processItem(item *dparval.Value) bool {
+	id, doc, qerr := mutationKey(item, this.as)
+	if qerr != nil {
+		return this.fail(qerr)
+	}
+
+	// all the values are computed from the document as it was
+	values := make([]*dparval.Value, len(this.set))
+	for i, term := range this.set {
+		val, err := this.Base.Evaluate(term.Value, item)
+		if err != nil {
+			switch err := err.(type) {
+			case *dparval.Undefined:
+				// setting a field to missing leaves it alone
+				continue
+			default:
+				return this.fail(query.NewError(err, "error evaluating SET value"))
+			}
+		}
+		values[i] = val
+	}
+
+	updated := doc.Duplicate()
+	context := dparval.NewValue(map[string]interface{}{this.as: updated})
+	for i, term := range this.set {
+		if values[i] == nil {
+			continue
+		}
+		err := this.setPath(context, term.Path, values[i])
+		if err != nil {
+			return this.fail(query.NewError(err, "error evaluating SET path"))
+		}
+	}
+
+	// SET terms whose parent is missing, or that set the value the
+	// document already has, do not change it
+	if reflect.DeepEqual(updated.Value(), doc.Value()) {
+		return true
+	}
+
+	qerr = this.bucket.Replace(id, updated)
+	if qerr != nil {
+		return this.fail(qerr)
+	}
+	this.mutated()
+	return true
+}
+
+// sets the value at the path, if the parent of the path
+// does not exist (or is of the wrong type) nothing is set
+func (this *Update) setPath(context *dparval.Value, path ast.Expression, value *dparval.Value) error {
+	switch path := path.(type) {
+	case *ast.DotMemberOperator:
+		parent, err := this.parentOf(context, path.Left)
+		if parent == nil || err != nil {
+			return err
+		}
+		if parent.Type() == dparval.OBJECT {
+			parent.SetPath(path.Right.Path, value)
+		}
+	case *ast.BracketMemberOperator:
+		parent, err := this.parentOf(context, path.Left)
+		if parent == nil || err != nil {
+			return err
+		}
+		index, err := this.parentOf(context, path.Right)
+		if index == nil || err != nil {
+			return err
+		}
+		switch parent.Type() {
+		case dparval.ARRAY:
+			i, ok := index.Value().(float64)
+			if ok {
+				parent.SetIndex(int(i), value)
+			}
+		case dparval.OBJECT:
+			field, ok := index.Value().(string)
+			if ok {
+				parent.SetPath(field, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (this *Update) parentOf(context *dparval.Value, expr ast.Expression) (*dparval.Value, error) {
+	val, err := this.Base.Evaluate(expr, context)
+	if err != nil {
+		switch err.(type) {
+		case *dparval.Undefined:
+			return nil, nil
+		}
+		return nil, err
+	}
+	return val, nil
+}
+
+func (this *Update) afterItems() {
+	this.report()
+}
+
+func (this *Update) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
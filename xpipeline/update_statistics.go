@@ -0,0 +1,128 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/catalog"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+type UpdateStatistics struct {
+	itemChannel           dparval.ValueChannel
+	supportChannel        PipelineSupportChannel
+	indexes               []catalog.RangeIndex
+	downstreamStopChannel misc.StopChannel
+	query                 network.Query
+}
+
+func NewUpdateStatistics(indexes []catalog.RangeIndex) *UpdateStatistics {
+	return &UpdateStatistics{
+		itemChannel:    make(dparval.ValueChannel),
+		supportChannel: make(PipelineSupportChannel),
+		indexes:        indexes,
+	}
+}
+
+func (this *UpdateStatistics) SetSource(source Operator) {}
+
+func (this *UpdateStatistics) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.itemChannel, this.supportChannel
+}
+
+func (this *UpdateStatistics) Run(stopChannel misc.StopChannel) {
+	defer close(this.itemChannel)
+	defer close(this.supportChannel)
+	// this MUST be here so that it runs before the channels are closed
+	defer this.RecoverPanic()
+
+	this.downstreamStopChannel = stopChannel
+	clog.To(CHANNEL, "update_statistics operator starting")
+	for _, index := range this.indexes {
+		statistics, err := index.UpdateStatistics()
+		if err != nil {
+			this.SendError(err)
+			break
+		}
+		item := dparval.NewValue(map[string]interface{}{})
+		item.SetAttachment("projection", dparval.NewValue(map[string]interface{}{
+			"index":      index.Name(),
+			"statistics": statisticsToJSON(statistics),
+		}))
+		if !this.SendItem(item) {
+			break
+		}
+	}
+	clog.To(CHANNEL, "update_statistics operator finished")
+}
+
+// the statistics as plain json values
+func statisticsToJSON(statistics catalog.RangeStatistics) interface{} {
+	var rv interface{}
+	bytes, err := json.Marshal(statistics)
+	if err == nil {
+		json.Unmarshal(bytes, &rv)
+	}
+	return rv
+}
+
+func (this *UpdateStatistics) processItem(item *dparval.Value) bool {
+	return true
+}
+
+func (this *UpdateStatistics) afterItems() {}
+
+func (this *UpdateStatistics) SendItem(item *dparval.Value) bool {
+	ok := true
+	for ok {
+		select {
+		case this.itemChannel <- item:
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return ok
+}
+
+func (this *UpdateStatistics) SendError(err query.Error) bool {
+	ok := true
+	for ok {
+		select {
+		case this.supportChannel <- err:
+			if err.IsFatal() {
+				return false
+			}
+			return true
+		case _, ok = <-this.downstreamStopChannel:
+			// someone closed the stop channel
+		}
+	}
+	return false
+}
+
+func (this *UpdateStatistics) RecoverPanic() {
+	r := recover()
+	if r != nil {
+		clog.Error(fmt.Errorf("Query Execution Panic: %v\n%s", r, debug.Stack()))
+		this.SendError(query.NewError(nil, "Panic In Exeuction Pipeline"))
+	}
+}
+
+func (this *UpdateStatistics) SetQuery(q network.Query) {
+	this.query = q
+}
@@ -0,0 +1,171 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"sort"
+
+	"github.com/couchbaselabs/clog"
+	"github.com/couchbaselabs/dparval"
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+	"github.com/couchbaselabs/tuqtng/network"
+	"github.com/couchbaselabs/tuqtng/query"
+)
+
+// computes the window functions once all the items have arrived.
+// the items are sorted by the PARTITION BY and ORDER BY of each
+// window (once for all the windows sorted the same way), the values
+// of each partition are attached to its items, and the items are
+// sent on in the order they arrived
+type Window struct {
+	Base    *BaseOperator
+	Windows ast.ExpressionList
+	items   []*dparval.Value
+}
+
+func NewWindow(windows ast.ExpressionList) *Window {
+	return &Window{
+		Base:    NewBaseOperator(),
+		Windows: windows,
+		items:   make([]*dparval.Value, 0),
+	}
+}
+
+func (this *Window) SetSource(source Operator) {
+	this.Base.SetSource(source)
+}
+
+func (this *Window) GetChannels() (dparval.ValueChannel, PipelineSupportChannel) {
+	return this.Base.GetChannels()
+}
+
+func (this *Window) Run(stopChannel misc.StopChannel) {
+	clog.To(CHANNEL, "window operator starting")
+	this.Base.RunOperator(this, stopChannel)
+	clog.To(CHANNEL, "window operator finished")
+}
+
+func (this *Window) processItem(item *dparval.Value) bool {
+	// the functions are evaluated over the items later on
+	item.SetAttachment("query", this.Base.itemQuery())
+	item.SetAttachment("windows", make(map[string]*dparval.Value, len(this.Windows)))
+	this.items = append(this.items, item)
+	return true
+}
+
+func (this *Window) afterItems() {
+	defer func() {
+		this.items = nil
+	}()
+
+	sorted := make(map[string]*windowEntries)
+	for _, expr := range this.Windows {
+		window, ok := expr.(*ast.WindowOperator)
+		if !ok {
+			continue
+		}
+		sortKey := window.Window.SortKey()
+		entries, ok := sorted[sortKey]
+		if !ok {
+			entries = this.sortItems(window.Window)
+			sorted[sortKey] = entries
+		}
+		if !this.computeWindow(window, entries) {
+			return
+		}
+	}
+
+	for _, item := range this.items {
+		if !this.Base.SendItem(item) {
+			return
+		}
+	}
+}
+
+// the items in the order of the window, items that
+// compare the same keep the order they arrived in
+func (this *Window) sortItems(window *ast.WindowDefinition) *windowEntries {
+	rv := &windowEntries{
+		partitions: len(window.PartitionBy),
+		orderBy:    make([]*ast.SortExpression, 0, len(window.PartitionBy)+len(window.OrderBy)),
+		entries:    make([]*sortEntry, len(this.items)),
+	}
+	for _, expr := range window.PartitionBy {
+		rv.orderBy = append(rv.orderBy, ast.NewSortExpression(expr, true))
+	}
+	rv.orderBy = append(rv.orderBy, window.OrderBy...)
+	for i, item := range this.items {
+		rv.entries[i] = newSortEntry(this.Base, rv.orderBy, item)
+	}
+	sort.Stable(rv)
+	return rv
+}
+
+// computes the window one partition at a time, returns
+// false if the rest of the pipeline stopped
+func (this *Window) computeWindow(window *ast.WindowOperator, sorted *windowEntries) bool {
+	key := window.Key()
+	start := 0
+	for start < len(sorted.entries) {
+		// the partition runs until the PARTITION BY values change
+		end := start + 1
+		for end < len(sorted.entries) && compareSortEntries(sorted.orderBy[:sorted.partitions], sorted.entries[start], sorted.entries[end]) == 0 {
+			end++
+		}
+
+		items := make([]*dparval.Value, end-start)
+		peerGroups := make([]int, end-start)
+		for i, entry := range sorted.entries[start:end] {
+			items[i] = entry.item
+			if i > 0 {
+				peerGroups[i] = peerGroups[i-1]
+				if compareSortEntries(sorted.orderBy, sorted.entries[start+i-1], entry) != 0 {
+					peerGroups[i]++
+				}
+			}
+		}
+
+		values, err := window.EvaluatePartition(ast.NewWindowPartition(items, peerGroups))
+		if err != nil {
+			return this.Base.SendError(query.NewError(err, "error evaluating window"))
+		}
+		for i, item := range items {
+			windows, ok := item.GetAttachment("windows").(map[string]*dparval.Value)
+			if ok {
+				windows[key] = values[i]
+			}
+		}
+		start = end
+	}
+	return true
+}
+
+func (this *Window) SetQuery(q network.Query) {
+	this.Base.SetQuery(q)
+}
+
+// the items sorted by the PARTITION BY and
+// then the ORDER BY values of a window
+type windowEntries struct {
+	partitions int
+	orderBy    []*ast.SortExpression
+	entries    []*sortEntry
+}
+
+// sort.Interface interface
+
+func (this *windowEntries) Len() int { return len(this.entries) }
+func (this *windowEntries) Less(i, j int) bool {
+	return compareSortEntries(this.orderBy, this.entries[i], this.entries[j]) < 0
+}
+func (this *windowEntries) Swap(i, j int) {
+	this.entries[i], this.entries[j] = this.entries[j], this.entries[i]
+}
@@ -0,0 +1,56 @@
+//  Copyright (c) 2013 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package xpipeline
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/tuqtng/ast"
+	"github.com/couchbaselabs/tuqtng/misc"
+)
+
+func TestWindow(t *testing.T) {
+	partition := ast.NewWindowDefinition(ast.ExpressionList{ast.NewProperty("k")}, nil, nil)
+	rowNumber := ast.NewWindowOperator(ast.NewFunctionCall("ROW_NUMBER", ast.FunctionArgExpressionList{}), partition)
+	sum := ast.NewWindowOperator(ast.NewFunctionCallSum(ast.FunctionArgExpressionList{ast.NewFunctionArgExpression(ast.NewProperty("n"))}), partition)
+
+	// the partitions are interleaved: k is 0, 1, 2, 0, 1, 2...
+	data := groupTestData(3, 4, false)
+	window := NewWindow(ast.ExpressionList{rowNumber, sum})
+	window.SetSource(NewStubSource(data))
+	itemChannel, _ := window.GetChannels()
+	go window.Run(make(misc.StopChannel))
+
+	count := 0
+	for item := range itemChannel {
+		// the items leave in the order they arrived
+		if item != data[count] {
+			t.Errorf("expected item %d to be %v, got %v", count, data[count], item)
+		}
+		n, err := rowNumber.Evaluate(item)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n.Value() != float64(count/3+1) {
+			t.Errorf("expected row number %d for item %d, got %v", count/3+1, count, n.Value())
+		}
+		s, err := sum.Evaluate(item)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.Value() != 4.0 {
+			t.Errorf("expected sum 4 for item %d, got %v", count, s.Value())
+		}
+		count++
+	}
+	if count != len(data) {
+		t.Errorf("expected %d items, got %d", len(data), count)
+	}
+}
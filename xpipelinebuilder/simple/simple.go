@@ -10,10 +10,12 @@
 package simple
 
 import (
-	"github.com/couchbaselabs/clog"
+	"fmt"
+
 	"github.com/couchbaselabs/tuqtng/catalog"
 	"github.com/couchbaselabs/tuqtng/network"
 	"github.com/couchbaselabs/tuqtng/plan"
+	"github.com/couchbaselabs/tuqtng/query"
 	"github.com/couchbaselabs/tuqtng/xpipeline"
 )
 
@@ -30,13 +32,41 @@ func NewSimpleExecutablePipelineBuilder(site catalog.Site, defaultPool string) *
 }
 
 func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query) (*xpipeline.ExecutablePipeline, error) {
-	rv := &xpipeline.ExecutablePipeline{}
+	if len(p.Subqueries) > 0 {
+		// the operators evaluate subqueries through the query
+		q = xpipeline.NewSubqueryQuery(q, p.Subqueries, this.buildOperators)
+	}
+	// only the statement is profiled, not each run of its subqueries
+	request, ok := q.Request().(network.StructuredQueryRequest)
+	profile := ok && request.Profile
+	root, err := this.buildOperatorTree(p.Root, q, profile)
+	if err != nil {
+		return nil, err
+	}
+	rv := &xpipeline.ExecutablePipeline{Root: root}
+	if profile {
+		rv.Profile = root.(*xpipeline.Profile).OperatorProfile()
+	}
+	return rv, nil
+}
+
+// builds the chain of operators starting at this element
+// and returns the operator at the root of the chain
+func (this *SimpleExecutablePipelineBuilder) buildOperators(element plan.PlanElement, q network.Query) (xpipeline.Operator, error) {
+	return this.buildOperatorTree(element, q, false)
+}
 
+// like buildOperators, every operator is wrapped to record
+// what it does when profile is true
+func (this *SimpleExecutablePipelineBuilder) buildOperatorTree(element plan.PlanElement, q network.Query, profile bool) (xpipeline.Operator, error) {
+	var root xpipeline.Operator = nil
 	var lastOperator xpipeline.Operator = nil
-	currentElement := p.Root
+	currentElement := element
 
 	for currentElement != nil {
 		var currentOperator xpipeline.Operator = nil
+		// the source of a join or set operation built as its own chain
+		var otherSource xpipeline.Operator = nil
 		switch currentElement := currentElement.(type) {
 		case *plan.FastCount:
 			pool, err := this.site.PoolByName(currentElement.Pool)
@@ -71,9 +101,15 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 				return nil, err
 			}
 			scanIndex := index.(catalog.ScanIndex) // FIXME: need static type safety
-			currentOperator = xpipeline.NewScan(bucket, scanIndex, currentElement.Ranges, currentElement.As)
+			scanOperator := xpipeline.NewScan(bucket, scanIndex, currentElement.Ranges, currentElement.As)
+			scanOperator.SetOrdered(currentElement.Ordered)
+			currentOperator = scanOperator
 		case *plan.KeyScan:
-			currentOperator = xpipeline.NewKeyScan(currentElement.KeyList)
+			if currentElement.Keys != nil {
+				currentOperator = xpipeline.NewKeyScanOfExpression(currentElement.Keys)
+			} else {
+				currentOperator = xpipeline.NewKeyScan(currentElement.KeyList)
+			}
 		case *plan.KeyJoin:
 			pool, err := this.site.PoolByName(currentElement.Pool)
 			if err != nil {
@@ -88,6 +124,17 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 			} else {
 				currentOperator = xpipeline.NewKeyJoin(bucket, currentElement.Projection, currentElement.JoinType, currentElement.Keys, currentElement.As)
 			}
+		case *plan.Join:
+			right, err := this.buildOperatorTree(currentElement.Right, q, profile)
+			if err != nil {
+				return nil, err
+			}
+			otherSource = right
+			if currentElement.Method == "hash" {
+				currentOperator = xpipeline.NewHashJoin(right, currentElement.JoinType, currentElement.On, currentElement.As, currentElement.LeftKeys, currentElement.RightKeys, currentElement.Build == "left")
+			} else {
+				currentOperator = xpipeline.NewNestedLoopJoin(right, currentElement.JoinType, currentElement.On, currentElement.As)
+			}
 		case *plan.Fetch:
 			pool, err := this.site.PoolByName(currentElement.Pool)
 			if err != nil {
@@ -97,15 +144,20 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 			if err != nil {
 				return nil, err
 			}
-			currentOperator = xpipeline.NewFetch(bucket, currentElement.Projection, currentElement.As)
+			fetchOperator := xpipeline.NewFetch(bucket, currentElement.Projection, currentElement.As)
 			if currentElement.Ids != nil {
-				fetchOperator := currentOperator.(*xpipeline.Fetch)
 				fetchOperator.SetIds(currentElement.Ids)
 			}
+			fetchOperator.SetOrdered(currentElement.Ordered)
+			currentOperator = fetchOperator
 		case *plan.Filter:
 			currentOperator = xpipeline.NewFilter(currentElement.Expr)
 		case *plan.Order:
-			currentOperator = xpipeline.NewOrder(currentElement.Sort, currentElement.ExplicitAliases)
+			orderOperator := xpipeline.NewOrder(currentElement.Sort, currentElement.ExplicitAliases)
+			if currentElement.Limit > 0 {
+				orderOperator.SetLimit(currentElement.Limit)
+			}
+			currentOperator = orderOperator
 		case *plan.Limit:
 			currentOperator = xpipeline.NewLimit(currentElement.Val)
 		case *plan.Offset:
@@ -117,9 +169,21 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 		case *plan.EliminateDuplicates:
 			currentOperator = xpipeline.NewEliminateDuplicates()
 		case *plan.Grouper:
-			currentOperator = xpipeline.NewGrouper(currentElement.Group, currentElement.Aggregates)
+			grouperOperator := xpipeline.NewGrouper(currentElement.Group, currentElement.Aggregates)
+			if currentElement.Streaming {
+				grouperOperator.SetStreaming()
+			}
+			currentOperator = grouperOperator
+		case *plan.Window:
+			currentOperator = xpipeline.NewWindow(currentElement.Windows)
 		case *plan.Explain:
-			currentOperator = xpipeline.NewExplain(currentElement.Input)
+			explain := xpipeline.NewExplain(currentElement.Input, currentElement.Cost)
+			explain.Candidates = currentElement.Candidates
+			explain.Indexes = currentElement.Indexes
+			currentOperator = explain
+		case *plan.Prepare:
+			// the name and the plan are all that is returned
+			currentOperator = xpipeline.NewExplain(currentElement, nil)
 		case *plan.CreateIndex:
 			pool, err := this.site.PoolByName(currentElement.Pool)
 			if err != nil {
@@ -144,14 +208,91 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 				return nil, err
 			}
 			currentOperator = xpipeline.NewDropIndex(index)
+		case *plan.UpdateStatistics:
+			pool, err := this.site.PoolByName(currentElement.Pool)
+			if err != nil {
+				return nil, err
+			}
+			bucket, err := pool.BucketByName(currentElement.Bucket)
+			if err != nil {
+				return nil, err
+			}
+			indexes, err := rangeIndexes(bucket, currentElement.Index)
+			if err != nil {
+				return nil, err
+			}
+			currentOperator = xpipeline.NewUpdateStatistics(indexes)
+		case *plan.CreateFunction:
+			currentOperator = xpipeline.NewCreateFunction(currentElement.Definition)
+		case *plan.DropFunction:
+			currentOperator = xpipeline.NewDropFunction(currentElement.Name)
+		case *plan.Insert:
+			pool, err := this.site.PoolByName(currentElement.Pool)
+			if err != nil {
+				return nil, err
+			}
+			bucket, err := pool.BucketByName(currentElement.Bucket)
+			if err != nil {
+				return nil, err
+			}
+			currentOperator = xpipeline.NewInsert(bucket, currentElement.Values, currentElement.Upsert)
+		case *plan.Update:
+			pool, err := this.site.PoolByName(currentElement.Pool)
+			if err != nil {
+				return nil, err
+			}
+			bucket, err := pool.BucketByName(currentElement.Bucket)
+			if err != nil {
+				return nil, err
+			}
+			currentOperator = xpipeline.NewUpdate(bucket, currentElement.As, currentElement.Set)
+		case *plan.Delete:
+			pool, err := this.site.PoolByName(currentElement.Pool)
+			if err != nil {
+				return nil, err
+			}
+			bucket, err := pool.BucketByName(currentElement.Bucket)
+			if err != nil {
+				return nil, err
+			}
+			currentOperator = xpipeline.NewDelete(bucket, currentElement.As)
+		case *plan.Union:
+			term, err := this.buildOperatorTree(currentElement.Term, q, profile)
+			if err != nil {
+				return nil, err
+			}
+			otherSource = term
+			currentOperator = xpipeline.NewUnion(term, currentElement.All)
+		case *plan.Intersect:
+			term, err := this.buildOperatorTree(currentElement.Term, q, profile)
+			if err != nil {
+				return nil, err
+			}
+			otherSource = term
+			currentOperator = xpipeline.NewIntersect(term, currentElement.All)
+		case *plan.Except:
+			term, err := this.buildOperatorTree(currentElement.Term, q, profile)
+			if err != nil {
+				return nil, err
+			}
+			otherSource = term
+			currentOperator = xpipeline.NewExcept(term, currentElement.All)
+		}
+
+		if profile {
+			profiled := xpipeline.NewProfile(currentOperator, currentElement)
+			if otherSource != nil {
+				profiled.AddSource(otherSource)
+			}
+			currentOperator = profiled
 		}
 
 		// pass reference to query
 		currentOperator.SetQuery(q)
 
 		//link root of xpipeline
-		if rv.Root == nil {
-			rv.Root = currentOperator
+		if root == nil {
+			root = currentOperator
 		}
 
 		// link previous operator to this one
@@ -162,16 +303,43 @@ func (this *SimpleExecutablePipelineBuilder) Build(p *plan.Plan, q network.Query
 		// advance to next
 		lastOperator = currentOperator
 
+		// operators with more than one source have already
+		// built their additional sources as separate chains
 		sources := currentElement.Sources()
-		if len(sources) > 1 {
-			// FIXME future operators like JOIN will have more than one source
-			clog.Fatalf("multiple sources not yet supported")
-		} else if len(sources) == 1 {
+		if len(sources) > 0 {
 			currentElement = sources[0]
 		} else {
 			currentElement = nil
 		}
 	}
 
+	return root, nil
+}
+
+// the named range index of the bucket, or all of them without a name
+func rangeIndexes(bucket catalog.Bucket, name string) ([]catalog.RangeIndex, query.Error) {
+	if name != "" {
+		index, err := bucket.IndexByName(name)
+		if err != nil {
+			return nil, err
+		}
+		rangeIndex, ok := index.(catalog.RangeIndex)
+		if !ok {
+			return nil, query.NewError(nil, fmt.Sprintf("Index %s has no statistics", name))
+		}
+		return []catalog.RangeIndex{rangeIndex}, nil
+	}
+
+	indexes, err := bucket.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	rv := make([]catalog.RangeIndex, 0, len(indexes))
+	for _, index := range indexes {
+		rangeIndex, ok := index.(catalog.RangeIndex)
+		if ok {
+			rv = append(rv, rangeIndex)
+		}
+	}
 	return rv, nil
 }